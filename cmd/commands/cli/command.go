@@ -183,6 +183,7 @@ func (c *cliApp) handleActions(line string) {
 		{"version", c.version},
 		{"license", c.license},
 		{"proposals", c.proposals},
+		{"providers", c.providers},
 		{"service", c.service},
 	}
 
@@ -656,6 +657,14 @@ func newAutocompleter(tequilapi *tequilapi_client.Client, proposals []tequilapi_
 		readline.PcItem("healthcheck"),
 		readline.PcItem("nat"),
 		readline.PcItem("proposals"),
+		readline.PcItem(
+			"providers",
+			readline.PcItem("list", readline.PcItemDynamic(getIdentityOptionList(tequilapi))),
+			readline.PcItem("favourite", readline.PcItemDynamic(getIdentityOptionList(tequilapi), readline.PcItemDynamic(getProposalOptionList(proposals)))),
+			readline.PcItem("unfavourite", readline.PcItemDynamic(getIdentityOptionList(tequilapi))),
+			readline.PcItem("block", readline.PcItemDynamic(getIdentityOptionList(tequilapi), readline.PcItemDynamic(getProposalOptionList(proposals)))),
+			readline.PcItem("unblock", readline.PcItemDynamic(getIdentityOptionList(tequilapi))),
+		),
 		readline.PcItem("location"),
 		readline.PcItem("disconnect"),
 		readline.PcItem("help"),
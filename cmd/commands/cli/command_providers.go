@@ -0,0 +1,104 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cli
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	usageListProviders       = "list <consumerID>"
+	usageFavouriteProvider   = "favourite <consumerID> <providerID>"
+	usageUnfavouriteProvider = "unfavourite <consumerID> <providerID>"
+	usageBlockProvider       = "block <consumerID> <providerID>"
+	usageUnblockProvider     = "unblock <consumerID> <providerID>"
+)
+
+func (c *cliApp) providers(argsString string) {
+	var usage = strings.Join([]string{
+		"Usage: providers <action> [args]",
+		"Available actions:",
+		"  " + usageListProviders,
+		"  " + usageFavouriteProvider,
+		"  " + usageUnfavouriteProvider,
+		"  " + usageBlockProvider,
+		"  " + usageUnblockProvider,
+	}, "\n")
+
+	if len(argsString) == 0 {
+		info(usage)
+		return
+	}
+
+	args := strings.Fields(argsString)
+	action := args[0]
+	actionArgs := args[1:]
+
+	switch action {
+	case "list":
+		c.listProviderPreferences(actionArgs)
+	case "favourite":
+		c.changeProviderPreference(actionArgs, usageFavouriteProvider, "Provider added to favourites", c.tequilapi.AddFavouriteProvider)
+	case "unfavourite":
+		c.changeProviderPreference(actionArgs, usageUnfavouriteProvider, "Provider removed from favourites", c.tequilapi.RemoveFavouriteProvider)
+	case "block":
+		c.changeProviderPreference(actionArgs, usageBlockProvider, "Provider blocked", c.tequilapi.BlockProvider)
+	case "unblock":
+		c.changeProviderPreference(actionArgs, usageUnblockProvider, "Provider unblocked", c.tequilapi.UnblockProvider)
+	default:
+		warnf("Unknown sub-command '%s'\n", argsString)
+		fmt.Println(usage)
+	}
+}
+
+func (c *cliApp) listProviderPreferences(args []string) {
+	if len(args) != 1 {
+		info("Usage: " + usageListProviders)
+		return
+	}
+
+	prefs, err := c.tequilapi.ProviderPreferences(args[0])
+	if err != nil {
+		warn(err)
+		return
+	}
+
+	info("Favourite providers:")
+	for _, id := range prefs.Favourites {
+		status("+", id)
+	}
+	info("Blocked providers:")
+	for _, id := range prefs.Blocked {
+		status("-", id)
+	}
+}
+
+func (c *cliApp) changeProviderPreference(args []string, usage, successMsg string, change func(consumerID, providerID string) error) {
+	if len(args) != 2 {
+		info("Usage: " + usage)
+		return
+	}
+
+	if err := change(args[0], args[1]); err != nil {
+		warn(err)
+		return
+	}
+
+	success(successMsg)
+}
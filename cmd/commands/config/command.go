@@ -0,0 +1,79 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package config
+
+import (
+	"fmt"
+	"io"
+	"path"
+
+	"github.com/BurntSushi/toml"
+	"github.com/mysteriumnetwork/node/config"
+	"github.com/pkg/errors"
+	"github.com/urfave/cli/v2"
+)
+
+// NewCommand function creates config command
+func NewCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "config",
+		Usage: "Manages node configuration",
+		Subcommands: []*cli.Command{
+			{
+				Name:      "validate",
+				Usage:     "Validates configuration file against the configuration schema",
+				ArgsUsage: "[file]",
+				Action: func(ctx *cli.Context) error {
+					file := ctx.Args().First()
+					if file == "" {
+						file = path.Join(ctx.String(config.FlagConfigDir.Name), "config.toml")
+					}
+					schema, err := config.NodeSchema()
+					if err != nil {
+						return err
+					}
+					return validate(ctx.App.Writer, schema, file)
+				},
+			},
+		},
+	}
+}
+
+func validate(w io.Writer, schema *config.Schema, file string) error {
+	values := make(map[string]interface{})
+	if _, err := toml.DecodeFile(file, &values); err != nil {
+		return errors.Wrapf(err, "could not read config file %s", file)
+	}
+
+	var invalid int
+	for _, fieldErr := range schema.ValidateAll(values) {
+		// Keys unknown to the node, e.g. settings of UI clients, are kept in the config file as is.
+		if fieldErr.Code == config.FieldErrorUnknown {
+			fmt.Fprintf(w, "warning: [%s] %s: %s\n", fieldErr.Code, fieldErr.Key, fieldErr.Message)
+			continue
+		}
+		fmt.Fprintf(w, "[%s] %s: %s\n", fieldErr.Code, fieldErr.Key, fieldErr.Message)
+		invalid++
+	}
+	if invalid > 0 {
+		return errors.Errorf("config file %s has %d invalid value(s)", file, invalid)
+	}
+
+	fmt.Fprintf(w, "Config file %s is valid\n", file)
+	return nil
+}
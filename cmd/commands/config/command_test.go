@@ -0,0 +1,103 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package config
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/mysteriumnetwork/node/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func writeConfigFile(t *testing.T, content string) string {
+	dir, err := ioutil.TempDir("", "config-validate")
+	assert.NoError(t, err)
+	file := path.Join(dir, "config.toml")
+	assert.NoError(t, ioutil.WriteFile(file, []byte(content), 0600))
+	return file
+}
+
+func TestValidate_ValidFile(t *testing.T) {
+	file := writeConfigFile(t, `
+		[openvpn]
+		port = 5522
+		proto = "tcp"
+		[discovery]
+		type = ["api", "broker"]
+	`)
+	defer os.RemoveAll(path.Dir(file))
+	schema, err := config.NodeSchema()
+	assert.NoError(t, err)
+	output := bytes.NewBufferString("")
+
+	err = validate(output, schema, file)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Config file "+file+" is valid\n", output.String())
+}
+
+func TestValidate_InvalidFile(t *testing.T) {
+	file := writeConfigFile(t, `
+		[openvpn]
+		port = "abc"
+		[wireguard.allowed]
+		subnet = "10.182.0.0"
+	`)
+	defer os.RemoveAll(path.Dir(file))
+	schema, err := config.NodeSchema()
+	assert.NoError(t, err)
+	output := bytes.NewBufferString("")
+
+	err = validate(output, schema, file)
+
+	assert.EqualError(t, err, "config file "+file+" has 2 invalid value(s)")
+	assert.Equal(t, "[invalid_type] openvpn.port: Value must be of type int\n"+
+		"[invalid] wireguard.allowed.subnet: \"10.182.0.0\" is not a subnet in CIDR notation\n", output.String())
+}
+
+func TestValidate_UnknownKeysAreWarnings(t *testing.T) {
+	file := writeConfigFile(t, `
+		[openvpn]
+		port = 5522
+		[ui]
+		theme = "dark"
+	`)
+	defer os.RemoveAll(path.Dir(file))
+	schema, err := config.NodeSchema()
+	assert.NoError(t, err)
+	output := bytes.NewBufferString("")
+
+	err = validate(output, schema, file)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "warning: [unknown] ui.theme: Unknown configuration key\n"+
+		"Config file "+file+" is valid\n", output.String())
+}
+
+func TestValidate_MissingFile(t *testing.T) {
+	schema, err := config.NodeSchema()
+	assert.NoError(t, err)
+
+	err = validate(bytes.NewBufferString(""), schema, "/non/existing/config.toml")
+
+	assert.Error(t, err)
+}
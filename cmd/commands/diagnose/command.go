@@ -0,0 +1,74 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package diagnose
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/mysteriumnetwork/node/config"
+	"github.com/mysteriumnetwork/node/config/urfavecli/clicontext"
+	"github.com/mysteriumnetwork/node/core/diagnostics"
+	"github.com/mysteriumnetwork/node/core/node"
+	tequilapi_client "github.com/mysteriumnetwork/node/tequilapi/client"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+	"github.com/pkg/errors"
+	"github.com/urfave/cli/v2"
+)
+
+type diagnosticsClient interface {
+	Diagnostics() (contract.DiagnosticsDTO, error)
+}
+
+// NewCommand function creates diagnose command
+func NewCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "diagnose",
+		Usage:     "Checks the services and system facilities the running node depends on",
+		ArgsUsage: " ",
+		Before:    clicontext.LoadUserConfigQuietly,
+		Action: func(ctx *cli.Context) error {
+			config.ParseFlagsNode(ctx)
+			nodeOptions := node.GetOptions()
+			client := tequilapi_client.NewClient(nodeOptions.TequilapiAddress, nodeOptions.TequilapiPort)
+
+			return diagnose(ctx.App.Writer, client)
+		},
+	}
+}
+
+func diagnose(w io.Writer, client diagnosticsClient) error {
+	report, err := client.Diagnostics()
+	if err != nil {
+		return errors.Wrap(err, "could not run diagnostics, is the node running?")
+	}
+
+	for _, check := range report.Checks {
+		fmt.Fprintf(w, "[%s] %s: %s (%dms)\n", strings.ToUpper(check.Status), check.Name, check.Message, check.DurationMs)
+		if check.Hint != "" && check.Status != string(diagnostics.StatusPass) {
+			fmt.Fprintf(w, "       hint: %s\n", check.Hint)
+		}
+	}
+	fmt.Fprintf(w, "Overall status: %s\n", report.Status)
+
+	if report.Status == string(diagnostics.StatusFail) {
+		return errors.New("some diagnostic checks failed")
+	}
+	return nil
+}
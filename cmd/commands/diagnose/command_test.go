@@ -0,0 +1,74 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package diagnose
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockClient struct {
+	report contract.DiagnosticsDTO
+	err    error
+}
+
+func (m *mockClient) Diagnostics() (contract.DiagnosticsDTO, error) {
+	return m.report, m.err
+}
+
+func TestDiagnose_PrintsReport(t *testing.T) {
+	output := bytes.NewBufferString("")
+	client := &mockClient{report: contract.DiagnosticsDTO{
+		Status: "warn",
+		Checks: []contract.DiagnosticCheckDTO{
+			{Name: "broker", Status: "pass", Message: "broker is reachable", DurationMs: 12},
+			{Name: "wireguard", Status: "warn", Message: "kernel module is not loaded", Hint: "Install it"},
+		},
+	}}
+
+	err := diagnose(output, client)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "[PASS] broker: broker is reachable (12ms)\n"+
+		"[WARN] wireguard: kernel module is not loaded (0ms)\n"+
+		"       hint: Install it\n"+
+		"Overall status: warn\n", output.String())
+}
+
+func TestDiagnose_FailsOnFailedCheck(t *testing.T) {
+	client := &mockClient{report: contract.DiagnosticsDTO{
+		Status: "fail",
+		Checks: []contract.DiagnosticCheckDTO{{Name: "disk", Status: "fail", Message: "10 MB free", Hint: "Free up disk space"}},
+	}}
+
+	err := diagnose(bytes.NewBufferString(""), client)
+
+	assert.EqualError(t, err, "some diagnostic checks failed")
+}
+
+func TestDiagnose_NodeNotRunning(t *testing.T) {
+	client := &mockClient{err: errors.New("connection refused")}
+
+	err := diagnose(bytes.NewBufferString(""), client)
+
+	assert.EqualError(t, err, "could not run diagnostics, is the node running?: connection refused")
+}
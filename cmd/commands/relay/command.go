@@ -0,0 +1,54 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package relay
+
+import (
+	"fmt"
+
+	"github.com/mysteriumnetwork/node/cmd"
+	"github.com/mysteriumnetwork/node/config"
+	"github.com/mysteriumnetwork/node/p2p/relay"
+	"github.com/rs/zerolog/log"
+	"github.com/urfave/cli/v2"
+)
+
+// NewCommand function creates relay command
+func NewCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "relay",
+		Usage:     "Starts relay server which forwards traffic of peers unable to connect directly",
+		ArgsUsage: " ",
+		Flags:     []cli.Flag{&config.FlagP2PRelayPort},
+		Action: func(ctx *cli.Context) error {
+			config.Current.ParseIntFlag(ctx, config.FlagP2PRelayPort)
+
+			server := relay.NewServer(fmt.Sprintf(":%d", config.GetInt(config.FlagP2PRelayPort)), relay.DefaultIdleTimeout)
+			if err := server.Start(); err != nil {
+				return err
+			}
+
+			quit := make(chan struct{})
+			cmd.RegisterSignalCallback(func() { close(quit) })
+			<-quit
+
+			log.Info().Msg("Stopping relay server")
+			server.Stop()
+			return nil
+		},
+	}
+}
@@ -18,13 +18,16 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"net"
+	"net/http"
 	"path/filepath"
 	"time"
 
 	"github.com/ethereum/go-ethereum/accounts/keystore"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/mysteriumnetwork/node/communication"
 	"github.com/mysteriumnetwork/node/communication/nats"
 	nats_dialog "github.com/mysteriumnetwork/node/communication/nats/dialog"
@@ -34,8 +37,10 @@ import (
 	"github.com/mysteriumnetwork/node/consumer/bandwidth"
 	consumer_session "github.com/mysteriumnetwork/node/consumer/session"
 	"github.com/mysteriumnetwork/node/consumer/statistics"
+	"github.com/mysteriumnetwork/node/core/audit"
 	"github.com/mysteriumnetwork/node/core/auth"
 	"github.com/mysteriumnetwork/node/core/connection"
+	"github.com/mysteriumnetwork/node/core/diagnostics"
 	"github.com/mysteriumnetwork/node/core/discovery/brokerdiscovery"
 	"github.com/mysteriumnetwork/node/core/discovery/proposal"
 	"github.com/mysteriumnetwork/node/core/ip"
@@ -44,6 +49,7 @@ import (
 	nodevent "github.com/mysteriumnetwork/node/core/node/event"
 	"github.com/mysteriumnetwork/node/core/policy"
 	"github.com/mysteriumnetwork/node/core/port"
+	"github.com/mysteriumnetwork/node/core/preference"
 	"github.com/mysteriumnetwork/node/core/quality"
 	"github.com/mysteriumnetwork/node/core/service"
 	"github.com/mysteriumnetwork/node/core/state"
@@ -63,9 +69,11 @@ import (
 	"github.com/mysteriumnetwork/node/nat"
 	"github.com/mysteriumnetwork/node/nat/event"
 	"github.com/mysteriumnetwork/node/nat/mapping"
+	"github.com/mysteriumnetwork/node/nat/stun"
 	"github.com/mysteriumnetwork/node/nat/traversal"
 	"github.com/mysteriumnetwork/node/nat/upnp"
 	"github.com/mysteriumnetwork/node/p2p"
+	"github.com/mysteriumnetwork/node/p2p/relay"
 	"github.com/mysteriumnetwork/node/requests"
 	"github.com/mysteriumnetwork/node/services"
 	service_noop "github.com/mysteriumnetwork/node/services/noop"
@@ -110,9 +118,10 @@ type Dependencies struct {
 
 	DiscoveryFactory   service.DiscoveryFactory
 	ProposalRepository proposal.Repository
-	DiscoveryWorker    brokerdiscovery.Worker
+	DiscoveryWorkers   []brokerdiscovery.Worker
 
-	QualityClient *quality.MysteriumMORQA
+	QualityClient  *quality.MysteriumMORQA
+	QualityHistory *quality.History
 
 	IPResolver       ip.Resolver
 	LocationResolver *location.Cache
@@ -131,20 +140,26 @@ type Dependencies struct {
 	ServicesManager       *service.Manager
 	ServiceRegistry       *service.Registry
 	ServiceSessionStorage *session.EventBasedStorage
+	SessionDrain          *session.Drain
+	ServicesDrainer       *service.Drainer
 	ServiceFirewall       firewall.IncomingTrafficFirewall
 
-	NATPinger  traversal.NATPinger
-	NATTracker *event.Tracker
-	PortPool   *port.Pool
-	PortMapper mapping.PortMapper
+	NATPinger       traversal.NATPinger
+	NATTracker      *event.Tracker
+	NATTypeDetector *stun.Detector
+	PortPool        *port.Pool
+	PortMapper      mapping.PortMapper
 
 	StateKeeper *state.Keeper
 
 	P2PDialer   p2p.Dialer
 	P2PListener p2p.Listener
+	RelayServer *relay.Server
 
 	Authenticator     *auth.Authenticator
 	JWTAuthenticator  *auth.JWTAuthenticator
+	AuditLog          *audit.Log
+	Diagnostics       *diagnostics.Runner
 	UIServer          UIServer
 	Transactor        *registry.Transactor
 	BCHelper          *paymentClient.BlockchainWithRetries
@@ -156,6 +171,7 @@ type Dependencies struct {
 	ProviderInvoiceStorage   *pingpong.ProviderInvoiceStorage
 	ConsumerTotalsStorage    *pingpong.ConsumerTotalsStorage
 	AccountantPromiseStorage *pingpong.AccountantPromiseStorage
+	ProviderPreferences      *preference.Storage
 	ConsumerBalanceTracker   *pingpong.ConsumerBalanceTracker
 	AccountantPromiseSettler pingpong.AccountantPromiseSettler
 	AccountantCaller         *pingpong.AccountantCaller
@@ -167,11 +183,19 @@ func (di *Dependencies) Bootstrap(nodeOptions node.Options) error {
 	logconfig.Configure(&nodeOptions.LogOptions)
 	nats_discovery.Bootstrap()
 	p2p.RegisterContactUnserializer()
-	di.BrokerConnector = nats.NewBrokerConnector()
 
 	log.Info().Msg("Starting Mysterium Node " + metadata.VersionAsString())
 
-	di.HTTPClient = requests.NewHTTPClient(nodeOptions.BindAddress, requests.DefaultTimeout)
+	var proxy *requests.Proxy
+	if nodeOptions.ProxyURL != "" {
+		var err error
+		if proxy, err = requests.NewProxy(nodeOptions.ProxyURL, nodeOptions.NoProxy); err != nil {
+			return err
+		}
+		log.Info().Msgf("Using upstream proxy %s", proxy)
+	}
+	di.BrokerConnector = nats.NewBrokerConnectorWithProxy(proxy)
+	di.HTTPClient = requests.NewHTTPClientWithProxy(nodeOptions.BindAddress, requests.DefaultTimeout, proxy)
 
 	// Check early for presence of an already running node
 	tequilaListener, err := di.createTequilaListener(nodeOptions)
@@ -224,8 +248,15 @@ func (di *Dependencies) Bootstrap(nodeOptions node.Options) error {
 		di.PortMapper = mapping.NewNoopPortMapper(di.EventBus)
 	}
 
-	di.P2PListener = p2p.NewListener(di.BrokerConnection, di.SignerFactory, identity.NewVerifierSigned(), di.IPResolver, di.NATPinger, di.PortPool, di.PortMapper)
-	di.P2PDialer = p2p.NewDialer(di.BrokerConnector, di.SignerFactory, identity.NewVerifierSigned(), di.IPResolver, di.NATPinger, di.PortPool)
+	relayConfig := p2p.RelayConfig{
+		Addresses: config.GetStringSlice(config.FlagP2PRelayAddresses),
+		Force:     config.GetBool(config.FlagP2PRelayForce),
+	}
+	di.P2PListener = p2p.NewListener(di.BrokerConnection, di.SignerFactory, identity.NewVerifierSigned(), di.IPResolver, di.NATPinger, di.PortPool, di.PortMapper, relayConfig, di.NATTypeDetector)
+	di.P2PDialer = p2p.NewDialer(di.BrokerConnector, di.SignerFactory, identity.NewVerifierSigned(), di.IPResolver, di.NATPinger, di.PortPool, relayConfig, di.NATTypeDetector)
+	if err := di.bootstrapRelayServer(); err != nil {
+		return err
+	}
 	di.SessionConnectivityStatusStorage = connectivity.NewStatusStorage()
 
 	if err := di.bootstrapServices(nodeOptions, services.SharedConfiguredOptions()); err != nil {
@@ -270,6 +301,8 @@ func (di *Dependencies) bootstrapStateKeeper(options node.Options) error {
 	var lastStageName string
 	if options.ExperimentNATPunching {
 		lastStageName = traversal.StageName
+	} else if config.GetBool(config.FlagPortMapping) {
+		lastStageName = mapping.PCPStageName
 	} else {
 		lastStageName = mapping.StageName
 	}
@@ -322,6 +355,16 @@ func (di *Dependencies) Shutdown() (err error) {
 		}
 	}()
 
+	if timeout := config.GetDuration(config.FlagDrainTimeout); timeout > 0 && di.ServicesDrainer != nil {
+		if err := di.ServicesDrainer.Drain(timeout); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if di.HTTPClient != nil {
+		di.HTTPClient.Stop()
+	}
+
 	if di.ServicesManager != nil {
 		if err := di.ServicesManager.Kill(); err != nil {
 			errs = append(errs, err)
@@ -337,12 +380,18 @@ func (di *Dependencies) Shutdown() (err error) {
 			errs = append(errs, err)
 		}
 	}
-	if di.DiscoveryWorker != nil {
-		di.DiscoveryWorker.Stop()
+	for _, worker := range di.DiscoveryWorkers {
+		worker.Stop()
 	}
 	if di.BrokerConnection != nil {
 		di.BrokerConnection.Close()
 	}
+	if di.RelayServer != nil {
+		di.RelayServer.Stop()
+	}
+	if di.NATTypeDetector != nil {
+		di.NATTypeDetector.Stop()
+	}
 
 	if di.QualityClient != nil {
 		di.QualityClient.Stop()
@@ -385,6 +434,7 @@ func (di *Dependencies) bootstrapStorage(path string) error {
 	di.ProviderInvoiceStorage = pingpong.NewProviderInvoiceStorage(invoiceStorage)
 	di.ConsumerTotalsStorage = pingpong.NewConsumerTotalsStorage(di.Storage, di.EventBus)
 	di.AccountantPromiseStorage = pingpong.NewAccountantPromiseStorage(di.Storage)
+	di.ProviderPreferences = preference.NewStorage(di.Storage)
 	di.SessionStorage = consumer_session.NewSessionStorage(di.Storage)
 	return di.SessionStorage.Subscribe(di.EventBus)
 }
@@ -452,6 +502,9 @@ func (di *Dependencies) bootstrapNodeComponents(nodeOptions node.Options, tequil
 		return errors.Wrap(err, "could not subscribe consumer balance tracker to relevant events")
 	}
 
+	connectionConfig := connection.DefaultConfig()
+	connectionConfig.KeyRotation.Interval = config.GetDuration(config.FlagWireguardKeyRotationInterval)
+
 	di.ConnectionRegistry = connection.NewRegistry()
 	di.ConnectionManager = connection.NewManager(
 		dialogFactory,
@@ -468,13 +521,15 @@ func (di *Dependencies) bootstrapNodeComponents(nodeOptions node.Options, tequil
 		di.EventBus,
 		connectivity.NewStatusSender(),
 		di.IPResolver,
-		connection.DefaultConfig(),
+		connectionConfig,
 		connection.DefaultStatsReportInterval,
 		connection.NewValidator(
 			di.ConsumerBalanceTracker,
 			di.IdentityManager,
+			di.ProviderPreferences,
 		),
 		di.P2PDialer,
+		di.SignerFactory,
 	)
 
 	di.LogCollector = logconfig.NewCollector(&logconfig.CurrentLogOptions)
@@ -487,6 +542,7 @@ func (di *Dependencies) bootstrapNodeComponents(nodeOptions node.Options, tequil
 	if err := di.bootstrapStateKeeper(nodeOptions); err != nil {
 		return err
 	}
+	di.bootstrapDiagnostics(nodeOptions)
 
 	tequilapiHTTPServer, err := di.bootstrapTequilapi(nodeOptions, tequilaListener)
 	if err != nil {
@@ -502,23 +558,36 @@ func (di *Dependencies) bootstrapTequilapi(nodeOptions node.Options, listener ne
 		return tequilapi.NewNoopAPIServer(), nil
 	}
 
+	var drainer tequilapi_endpoints.ProviderDrainer
+	if di.ServicesDrainer != nil {
+		drainer = di.ServicesDrainer
+	}
+
 	router := tequilapi.NewAPIRouter()
-	tequilapi_endpoints.AddRouteForStop(router, utils.SoftKiller(di.Shutdown))
+	tequilapi_endpoints.AddRouteForStop(router, utils.SoftKiller(di.Shutdown), drainer)
 	tequilapi_endpoints.AddRoutesForAuthentication(router, di.Authenticator, di.JWTAuthenticator)
 	tequilapi_endpoints.AddRoutesForIdentities(router, di.IdentityManager, di.IdentitySelector, di.IdentityRegistry, di.ConsumerBalanceTracker, di.ChannelAddressCalculator, di.AccountantPromiseSettler)
 	tequilapi_endpoints.AddRoutesForConnection(router, di.ConnectionManager, di.StateKeeper, di.ProposalRepository, di.IdentityRegistry)
 	tequilapi_endpoints.AddRoutesForConnectionSessions(router, di.SessionStorage)
 	tequilapi_endpoints.AddRoutesForConnectionLocation(router, di.ConnectionManager, di.IPResolver, di.LocationResolver, di.LocationResolver)
-	tequilapi_endpoints.AddRoutesForProposals(router, di.ProposalRepository, di.QualityClient)
+	tequilapi_endpoints.AddRoutesForProposals(router, di.ProposalRepository, di.QualityClient, di.QualityHistory, di.ProviderPreferences)
+	tequilapi_endpoints.AddRoutesForProviderPreferences(router, di.ProviderPreferences)
 	tequilapi_endpoints.AddRoutesForService(router, di.ServicesManager, serviceTypesRequestParser)
 	tequilapi_endpoints.AddRoutesForServiceSessions(router, di.StateKeeper)
 	tequilapi_endpoints.AddRoutesForPayout(router, di.IdentityManager, di.SignerFactory, di.MysteriumAPI)
 	tequilapi_endpoints.AddRoutesForAccessPolicies(di.HTTPClient, router, services.SharedConfiguredOptions().AccessPolicyAddress)
-	tequilapi_endpoints.AddRoutesForNAT(router, di.StateKeeper)
+	tequilapi_endpoints.AddRoutesForNAT(router, di.StateKeeper, di.NATTypeDetector)
 	tequilapi_endpoints.AddRoutesForTransactor(router, di.Transactor, di.AccountantPromiseSettler)
-	tequilapi_endpoints.AddRoutesForConfig(router)
+	configSchema, err := config.NodeSchema()
+	if err != nil {
+		return nil, err
+	}
+	tequilapi_endpoints.AddRoutesForConfig(router, configSchema)
 	tequilapi_endpoints.AddRoutesForFeedback(router, di.Reporter)
 	tequilapi_endpoints.AddRoutesForConnectivityStatus(router, di.SessionConnectivityStatusStorage)
+	tequilapi_endpoints.AddRoutesForCircuitBreakers(router, di.HTTPClient)
+	tequilapi_endpoints.AddRoutesForLogs(router, logconfig.LogLevels(), logconfig.Tail())
+	tequilapi_endpoints.AddRoutesForDiagnostics(router, di.Diagnostics)
 	if err := tequilapi_endpoints.AddRoutesForSSE(router, di.StateKeeper, di.EventBus); err != nil {
 		return nil, err
 	}
@@ -527,14 +596,68 @@ func (di *Dependencies) bootstrapTequilapi(nodeOptions node.Options, listener ne
 		tequilapi_endpoints.AddRoutesForPProf(router)
 	}
 
+	var handler http.Handler = router
+	if err := di.bootstrapAuditLog(); err != nil {
+		return nil, err
+	}
+	if di.AuditLog != nil {
+		tequilapi_endpoints.AddRoutesForAudit(router, di.AuditLog)
+		handler = tequilapi.ApplyAudit(router, di.AuditLog, di.JWTAuthenticator)
+	}
+
 	corsPolicy := tequilapi.NewMysteriumCorsPolicy()
-	return tequilapi.NewServer(listener, router, corsPolicy), nil
+	return tequilapi.NewServer(listener, handler, corsPolicy), nil
+}
+
+func (di *Dependencies) bootstrapDiagnostics(nodeOptions node.Options) {
+	checks := []diagnostics.Check{
+		diagnostics.HTTPCheck("mysterium_api", di.HTTPClient, di.NetworkDefinition.MysteriumAPIAddress, "Check connectivity to the Mysterium API or the --api.address flag"),
+		diagnostics.HTTPCheck("accountant", di.HTTPClient, nodeOptions.Accountant.AccountantEndpointAddress, "Check connectivity to the accountant or the --accountant.address flag"),
+		diagnostics.TransactorCheck(di.Transactor),
+		diagnostics.EthereumCheck(func(ctx context.Context) (*types.Header, error) {
+			return di.EtherClient.Client().HeaderByNumber(ctx, nil)
+		}),
+		diagnostics.NATCheck(di.NATTypeDetector, di.StateKeeper),
+		diagnostics.FirewallCheck(),
+		diagnostics.WireGuardCheck(),
+		diagnostics.DiskSpaceCheck(nodeOptions.Directories.Data),
+		diagnostics.ClockSkewCheck(di.HTTPClient, di.NetworkDefinition.MysteriumAPIAddress),
+		diagnostics.IdentityCheck(di.IdentityManager, di.IdentityRegistry),
+	}
+	if brokerURL, err := nats.ParseServerURI(di.NetworkDefinition.BrokerAddress); err == nil {
+		var dialer diagnostics.ContextDialer = &net.Dialer{}
+		if proxy := di.HTTPClient.Proxy(); proxy != nil {
+			dialer = proxy.Dialer(&net.Dialer{})
+		}
+		checks = append(checks, diagnostics.TCPCheck("broker", dialer, brokerURL.Host, "Check connectivity to the broker or the --broker-address flag"))
+	}
+	if nodeOptions.Openvpn != nil {
+		checks = append(checks, diagnostics.OpenVPNCheck(nodeOptions.Openvpn))
+	}
+
+	di.Diagnostics = diagnostics.NewRunner(diagnostics.DefaultTimeout, checks...)
+}
+
+func (di *Dependencies) bootstrapAuditLog() error {
+	logDir := config.GetString(config.FlagLogDir)
+	if logDir == "" {
+		log.Warn().Msg("Log directory is not set, audit log is disabled")
+		return nil
+	}
+
+	auditLog, err := audit.NewLog(logDir)
+	if err != nil {
+		return err
+	}
+	di.AuditLog = auditLog
+	return nil
 }
 
 func newSessionManagerFactory(
 	nodeOptions node.Options,
 	proposal market.ServiceProposal,
 	sessionStorage *session.EventBasedStorage,
+	drain *session.Drain,
 	providerInvoiceStorage *pingpong.ProviderInvoiceStorage,
 	accountantPromiseStorage *pingpong.AccountantPromiseStorage,
 	natPingerChan traversal.NATPinger,
@@ -573,6 +696,7 @@ func newSessionManagerFactory(
 			serviceID,
 			eventbus,
 			nil,
+			drain,
 			session.DefaultConfig(),
 		)
 	}
@@ -681,13 +805,18 @@ func (di *Dependencies) bootstrapIdentityComponents(options node.Options) {
 }
 
 func (di *Dependencies) bootstrapQualityComponents(bindAddress string, options node.OptionsQuality) (err error) {
+	di.QualityHistory = quality.NewHistory(di.Storage)
+	if err := di.QualityHistory.Subscribe(di.EventBus); err != nil {
+		return err
+	}
+
 	if _, err := firewall.AllowURLAccess(options.Address); err != nil {
 		return err
 	}
 	if _, err := di.ServiceFirewall.AllowURLAccess(options.Address); err != nil {
 		return err
 	}
-	di.QualityClient = quality.NewMorqaClient(bindAddress, options.Address, 20*time.Second)
+	di.QualityClient = quality.NewMorqaClient(bindAddress, options.Address, 20*time.Second, di.HTTPClient.Proxy())
 	go di.QualityClient.Start()
 
 	var transport quality.Transport
@@ -795,9 +924,22 @@ func (di *Dependencies) bootstrapNATComponents(options node.Options) error {
 	} else {
 		di.NATPinger = &traversal.NoopPinger{}
 	}
+
+	di.NATTypeDetector = stun.NewDetector(config.GetStringSlice(config.FlagSTUNServers), di.EventBus)
+	di.NATTypeDetector.Start()
 	return nil
 }
 
+// bootstrapRelayServer starts relay server when node opts in to forward traffic of the peers unable to connect directly.
+func (di *Dependencies) bootstrapRelayServer() error {
+	if !config.GetBool(config.FlagP2PRelayServe) {
+		return nil
+	}
+
+	di.RelayServer = relay.NewServer(fmt.Sprintf(":%d", config.GetInt(config.FlagP2PRelayPort)), relay.DefaultIdleTimeout)
+	return errors.Wrap(di.RelayServer.Start(), "could not start relay server")
+}
+
 func (di *Dependencies) bootstrapFirewall(options node.OptionsFirewall) error {
 	firewall.DefaultOutgoingFirewall = firewall.NewOutgoingTrafficFirewall()
 	if err := firewall.DefaultOutgoingFirewall.Setup(); err != nil {
@@ -809,6 +951,12 @@ func (di *Dependencies) bootstrapFirewall(options node.OptionsFirewall) error {
 		return err
 	}
 
+	if proxy := di.HTTPClient.Proxy(); proxy != nil {
+		if _, err := firewall.AllowURLAccess(proxy.String()); err != nil {
+			return err
+		}
+	}
+
 	if options.BlockAlways {
 		bindAddress := "0.0.0.0"
 		resolver := ip.NewResolver(di.HTTPClient, bindAddress, "")
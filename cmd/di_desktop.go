@@ -36,6 +36,7 @@ import (
 	"github.com/mysteriumnetwork/node/market"
 	"github.com/mysteriumnetwork/node/mmn"
 	"github.com/mysteriumnetwork/node/nat"
+	nat_noop "github.com/mysteriumnetwork/node/nat/noop"
 	"github.com/mysteriumnetwork/node/nat/traversal"
 	"github.com/mysteriumnetwork/node/p2p"
 	service_noop "github.com/mysteriumnetwork/node/services/noop"
@@ -50,6 +51,7 @@ import (
 	"github.com/mysteriumnetwork/node/session"
 	"github.com/mysteriumnetwork/node/session/connectivity"
 	"github.com/mysteriumnetwork/node/session/pingpong"
+	pingpong_event "github.com/mysteriumnetwork/node/session/pingpong/event"
 	pingpong_noop "github.com/mysteriumnetwork/node/session/pingpong/noop"
 	"github.com/mysteriumnetwork/node/ui"
 	uinoop "github.com/mysteriumnetwork/node/ui/noop"
@@ -60,7 +62,7 @@ import (
 
 // bootstrapServices loads all the components required for running services
 func (di *Dependencies) bootstrapServices(nodeOptions node.Options, servicesOptions config.ServicesOptions) error {
-	if nodeOptions.MobileConsumer {
+	if nodeOptions.MobileConsumer && !nodeOptions.MobileProvider {
 		return nil
 	}
 
@@ -69,6 +71,11 @@ func (di *Dependencies) bootstrapServices(nodeOptions node.Options, servicesOpti
 		return errors.Wrap(err, "service bootstrap failed")
 	}
 
+	if nodeOptions.MobileProvider {
+		// Mobile bindings register services on top of TUN devices provided by the OS.
+		return nil
+	}
+
 	di.bootstrapServiceOpenvpn(nodeOptions)
 	di.bootstrapServiceNoop(nodeOptions)
 	di.bootstrapServiceWireguard(nodeOptions)
@@ -77,6 +84,12 @@ func (di *Dependencies) bootstrapServices(nodeOptions node.Options, servicesOpti
 }
 
 func (di *Dependencies) bootstrapServiceWireguard(nodeOptions node.Options) {
+	di.RegisterWireguardService(nil)
+}
+
+// RegisterWireguardService registers wireguard service, its multi peer device is created by the given factory
+// or by the default one if the factory is nil.
+func (di *Dependencies) RegisterWireguardService(deviceFactory func() (wireguard_service.MultiPeerDevice, error)) {
 	di.ServiceRegistry.Register(
 		wireguard.ServiceType,
 		func(serviceOptions service.Options) (service.Service, market.ServiceProposal, error) {
@@ -111,7 +124,10 @@ func (di *Dependencies) bootstrapServiceWireguard(nodeOptions node.Options) {
 				di.PortMapper,
 				di.ServiceFirewall,
 			)
-			return svc, wireguard_service.GetProposal(loc), nil
+			if deviceFactory != nil {
+				svc.SetMultiPeerDeviceFactory(deviceFactory)
+			}
+			return svc, wireguard_service.GetProposal(loc, wgOptions), nil
 		},
 	)
 }
@@ -175,7 +191,7 @@ func (di *Dependencies) bootstrapServiceNoop(nodeOptions node.Options) {
 }
 
 func (di *Dependencies) bootstrapProviderRegistrar(nodeOptions node.Options) error {
-	if nodeOptions.MobileConsumer {
+	if nodeOptions.MobileConsumer && !nodeOptions.MobileProvider {
 		return nil
 	}
 
@@ -191,11 +207,22 @@ func (di *Dependencies) bootstrapProviderRegistrar(nodeOptions node.Options) err
 }
 
 func (di *Dependencies) bootstrapAccountantPromiseSettler(nodeOptions node.Options) error {
-	if nodeOptions.MobileConsumer {
+	if nodeOptions.MobileConsumer && !nodeOptions.MobileProvider {
 		di.AccountantPromiseSettler = &pingpong_noop.NoopAccountantPromiseSettler{}
 		return nil
 	}
 
+	policy := pingpong_event.SettlementPolicy{
+		Type:        pingpong_event.SettlementPolicyType(nodeOptions.Payments.AccountantPromiseSettlingPolicy),
+		Threshold:   nodeOptions.Payments.AccountantPromiseSettlingThreshold,
+		Amount:      nodeOptions.Payments.AccountantPromiseSettlingAmount,
+		Time:        nodeOptions.Payments.AccountantPromiseSettlingTime,
+		MaxFeeRatio: nodeOptions.Payments.AccountantPromiseSettlingMaxFee,
+	}
+	if err := pingpong.ValidateSettlementPolicy(policy); err != nil {
+		return err
+	}
+
 	di.AccountantPromiseSettler = pingpong.NewAccountantPromiseSettler(
 		di.EventBus,
 		di.Transactor,
@@ -203,9 +230,10 @@ func (di *Dependencies) bootstrapAccountantPromiseSettler(nodeOptions node.Optio
 		di.BCHelper,
 		di.IdentityRegistry,
 		di.Keystore,
+		config.Current,
 		pingpong.AccountantPromiseSettlerConfig{
 			AccountantAddress:    common.HexToAddress(nodeOptions.Accountant.AccountantID),
-			Threshold:            nodeOptions.Payments.AccountantPromiseSettlingThreshold,
+			Policy:               policy,
 			MaxWaitForSettlement: nodeOptions.Payments.SettlementTimeout,
 		},
 	)
@@ -214,7 +242,12 @@ func (di *Dependencies) bootstrapAccountantPromiseSettler(nodeOptions node.Optio
 
 // bootstrapServiceComponents initiates ServicesManager dependency
 func (di *Dependencies) bootstrapServiceComponents(nodeOptions node.Options, servicesOptions config.ServicesOptions) error {
-	di.NATService = nat.NewService()
+	if nodeOptions.MobileProvider {
+		// Traffic of mobile provider is forwarded by the embedding application, see mobile OverrideWireguardProvider.
+		di.NATService = nat_noop.NewService()
+	} else {
+		di.NATService = nat.NewService()
+	}
 	if err := di.NATService.Enable(); err != nil {
 		log.Warn().Err(err).Msg("Failed to enable NAT forwarding")
 	}
@@ -225,6 +258,7 @@ func (di *Dependencies) bootstrapServiceComponents(nodeOptions node.Options, ser
 		return errors.Wrap(err, "could not subscribe session to node events")
 	}
 	di.ServiceSessionStorage = storage
+	di.SessionDrain = session.NewDrain()
 
 	di.PolicyOracle = policy.NewOracle(di.HTTPClient, servicesOptions.AccessPolicyAddress, servicesOptions.AccessPolicyFetchInterval)
 	go di.PolicyOracle.Start()
@@ -238,6 +272,8 @@ func (di *Dependencies) bootstrapServiceComponents(nodeOptions node.Options, ser
 		), nil
 	}
 	newP2PSessionHandler := func(proposal market.ServiceProposal, serviceID string, channel p2p.Channel) *session.Manager {
+		// Session can be resumed by consumer over another p2p channel.
+		channel = p2p.NewSwitchableChannel(channel)
 		paymentEngineFactory := pingpong.InvoiceFactoryCreator(nil,
 			channel, nodeOptions.Payments.ProviderInvoiceFrequency,
 			pingpong.PromiseWaitTimeout, di.ProviderInvoiceStorage,
@@ -263,6 +299,7 @@ func (di *Dependencies) bootstrapServiceComponents(nodeOptions node.Options, ser
 			serviceID,
 			di.EventBus,
 			channel,
+			di.SessionDrain,
 			session.DefaultConfig(),
 		)
 	}
@@ -272,6 +309,7 @@ func (di *Dependencies) bootstrapServiceComponents(nodeOptions node.Options, ser
 			nodeOptions,
 			proposal,
 			di.ServiceSessionStorage,
+			di.SessionDrain,
 			di.ProviderInvoiceStorage,
 			di.AccountantPromiseStorage,
 			di.NATPinger,
@@ -304,6 +342,18 @@ func (di *Dependencies) bootstrapServiceComponents(nodeOptions node.Options, ser
 		newP2PSessionHandler,
 		di.SessionConnectivityStatusStorage,
 	)
+	if err := di.ServicesManager.Subscribe(di.EventBus); err != nil {
+		return err
+	}
+
+	accountantID := identity.FromAddress(nodeOptions.Accountant.AccountantID)
+	di.ServicesDrainer = service.NewDrainer(di.ServicesManager, di.ServiceSessionStorage, di.SessionDrain, func(providerID identity.Identity) error {
+		err := di.AccountantPromiseSettler.ForceSettle(providerID, accountantID)
+		if err == pingpong.ErrNothingToSettle {
+			return nil
+		}
+		return err
+	})
 
 	serviceCleaner := service.Cleaner{SessionStorage: di.ServiceSessionStorage}
 	if err := di.EventBus.Subscribe(servicestate.AppTopicServiceStatus, serviceCleaner.HandleServiceStatus); err != nil {
@@ -23,6 +23,7 @@ import (
 	"github.com/mysteriumnetwork/node/core/discovery"
 	"github.com/mysteriumnetwork/node/core/discovery/apidiscovery"
 	"github.com/mysteriumnetwork/node/core/discovery/brokerdiscovery"
+	"github.com/mysteriumnetwork/node/core/discovery/filediscovery"
 	"github.com/mysteriumnetwork/node/core/node"
 	"github.com/mysteriumnetwork/node/core/service"
 	"github.com/pkg/errors"
@@ -42,17 +43,33 @@ func (di *Dependencies) bootstrapDiscoveryComponents(options node.OptionsDiscove
 			storage := brokerdiscovery.NewStorage(di.EventBus)
 			brokerRepository := brokerdiscovery.NewRepository(di.BrokerConnection, storage, options.PingInterval+time.Second, 1*time.Second)
 			if options.FetchEnabled {
-				di.DiscoveryWorker = brokerRepository
-				if err := di.DiscoveryWorker.Start(); err != nil {
+				if err := brokerRepository.Start(); err != nil {
 					return errors.Wrap(err, "failed to enable broker discovery")
 				}
+				di.DiscoveryWorkers = append(di.DiscoveryWorkers, brokerRepository)
 			}
 			proposalRepository.Add(brokerRepository)
+		case node.DiscoveryTypeFile:
+			storage := brokerdiscovery.NewStorage(di.EventBus)
+			fileRepository := filediscovery.NewRepository(options.FilePath, storage, 1*time.Second)
+			if err := fileRepository.Start(); err != nil {
+				return errors.Wrap(err, "failed to enable file discovery")
+			}
+			di.DiscoveryWorkers = append(di.DiscoveryWorkers, fileRepository)
+			proposalRepository.Add(fileRepository)
 		default:
 			return errors.Errorf("unknown discovery adapter: %s", discoveryType)
 		}
 	}
 
+	if options.CacheTTL > 0 {
+		proposalRepository.UseCache(discovery.NewProposalCache(di.Storage, options.CacheTTL), options.FetchInterval)
+		if err := proposalRepository.Start(); err != nil {
+			return errors.Wrap(err, "failed to start proposal cache refresh")
+		}
+		di.DiscoveryWorkers = append(di.DiscoveryWorkers, proposalRepository)
+	}
+
 	di.ProposalRepository = proposalRepository
 	di.DiscoveryFactory = func() service.Discovery {
 		return discovery.NewService(di.IdentityRegistry, discoveryRegistry, options.PingInterval, di.SignerFactory, di.EventBus)
@@ -21,12 +21,15 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+
+	"github.com/rs/zerolog/log"
 )
 
 // SignalCallback is invoked when process receives signals defined below
 type SignalCallback func()
 
-// RegisterSignalCallback registers given callback to call on SIGTERM and SIGHUP interrupts
+// RegisterSignalCallback registers given callback to call on SIGTERM and SIGHUP interrupts.
+// Shutdown may take a while when provider sessions are drained, the second interrupt exits immediately.
 func RegisterSignalCallback(callback SignalCallback) {
 	sigterm := make(chan os.Signal, 1)
 	signal.Notify(sigterm, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
@@ -37,4 +40,8 @@ func RegisterSignalCallback(callback SignalCallback) {
 func waitTerminationSignal(termination chan os.Signal, callback SignalCallback) {
 	<-termination
 	callback()
+
+	<-termination
+	log.Warn().Msg("Second interrupt received, exiting immediately")
+	os.Exit(1)
 }
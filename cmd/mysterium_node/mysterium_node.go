@@ -21,8 +21,11 @@ import (
 	"os"
 
 	command_cli "github.com/mysteriumnetwork/node/cmd/commands/cli"
+	command_config "github.com/mysteriumnetwork/node/cmd/commands/config"
 	"github.com/mysteriumnetwork/node/cmd/commands/daemon"
+	"github.com/mysteriumnetwork/node/cmd/commands/diagnose"
 	"github.com/mysteriumnetwork/node/cmd/commands/license"
+	"github.com/mysteriumnetwork/node/cmd/commands/relay"
 	"github.com/mysteriumnetwork/node/cmd/commands/service"
 	"github.com/mysteriumnetwork/node/cmd/commands/version"
 	"github.com/mysteriumnetwork/node/config"
@@ -37,12 +40,15 @@ var (
 		"run command 'license --warranty'",
 		"run command 'license --conditions'",
 	)
-	versionSummary = metadata.VersionAsSummary(licenseCopyright)
-	daemonCommand  = daemon.NewCommand()
-	versionCommand = version.NewCommand(versionSummary)
-	licenseCommand = license.NewCommand(licenseCopyright)
-	serviceCommand = service.NewCommand(licenseCommand.Name)
-	cliCommand     = command_cli.NewCommand()
+	versionSummary  = metadata.VersionAsSummary(licenseCopyright)
+	daemonCommand   = daemon.NewCommand()
+	versionCommand  = version.NewCommand(versionSummary)
+	licenseCommand  = license.NewCommand(licenseCopyright)
+	serviceCommand  = service.NewCommand(licenseCommand.Name)
+	cliCommand      = command_cli.NewCommand()
+	relayCommand    = relay.NewCommand()
+	diagnoseCommand = diagnose.NewCommand()
+	configCommand   = command_config.NewCommand()
 )
 
 func main() {
@@ -82,6 +88,9 @@ func NewCommand() (*cli.App, error) {
 		serviceCommand,
 		daemonCommand,
 		cliCommand,
+		relayCommand,
+		diagnoseCommand,
+		configCommand,
 	}
 
 	return app, nil
@@ -31,6 +31,8 @@ import (
 const (
 	// DefaultBrokerPort broker port.
 	DefaultBrokerPort = 4222
+
+	connectTimeout = 5 * time.Second
 )
 
 // ParseServerURI validates given NATS server address.
@@ -73,6 +75,7 @@ type ConnectionWrap struct {
 	*nats_lib.Conn
 	servers []string
 	onClose func()
+	dialer  nats_lib.CustomDialer
 }
 
 func (c *ConnectionWrap) connectOptions() nats_lib.Options {
@@ -80,7 +83,8 @@ func (c *ConnectionWrap) connectOptions() nats_lib.Options {
 	options.Servers = c.servers
 	options.MaxReconnect = -1
 	options.ReconnectWait = 1 * time.Second
-	options.Timeout = 5 * time.Second
+	options.Timeout = connectTimeout
+	options.CustomDialer = c.dialer
 	options.PingInterval = 10 * time.Second
 	options.ClosedCB = func(conn *nats_lib.Conn) { log.Warn().Msg("NATS: connection closed") }
 	options.DisconnectedCB = func(nc *nats_lib.Conn) { log.Warn().Msg("NATS: disconnected") }
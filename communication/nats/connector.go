@@ -18,11 +18,14 @@
 package nats
 
 import (
+	"net"
 	"strings"
 	"sync"
 
 	"github.com/gofrs/uuid"
 	"github.com/mysteriumnetwork/node/firewall"
+	"github.com/mysteriumnetwork/node/requests"
+	nats_lib "github.com/nats-io/go-nats"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog/log"
 )
@@ -31,6 +34,7 @@ import (
 type BrokerConnector struct {
 	registry map[uuid.UUID]Connection
 	mu       sync.Mutex
+	dialer   nats_lib.CustomDialer
 }
 
 // NewBrokerConnector creates a new BrokerConnector.
@@ -40,6 +44,15 @@ func NewBrokerConnector() *BrokerConnector {
 	}
 }
 
+// NewBrokerConnectorWithProxy creates a new BrokerConnector which connects to NATS servers through the given upstream proxy.
+func NewBrokerConnectorWithProxy(proxy *requests.Proxy) *BrokerConnector {
+	connector := NewBrokerConnector()
+	if proxy != nil {
+		connector.dialer = proxy.Dialer(&net.Dialer{Timeout: connectTimeout})
+	}
+	return connector
+}
+
 // Connect establishes a new connection to the broker(s).
 func (b *BrokerConnector) Connect(serverURIs ...string) (Connection, error) {
 	log.Debug().Msg("Connecting to NATS servers: " + strings.Join(serverURIs, ","))
@@ -48,6 +61,7 @@ func (b *BrokerConnector) Connect(serverURIs ...string) (Connection, error) {
 	if err != nil {
 		return nil, err
 	}
+	conn.dialer = b.dialer
 
 	removeFirewallRule, err := firewall.AllowURLAccess(conn.servers...)
 	if err != nil {
@@ -36,7 +36,7 @@ var (
 	// FlagDiscoveryType proposal discovery adapter.
 	FlagDiscoveryType = cli.StringSliceFlag{
 		Name:  "discovery.type",
-		Usage: `Proposal discovery adapter(s) separated by comma Options: { "api", "broker", "api,broker" }`,
+		Usage: `Proposal discovery adapter(s) separated by comma Options: { "api", "broker", "file", "api,broker" }`,
 		Value: cli.NewStringSlice("api", "broker"),
 	}
 	// FlagDiscoveryPingInterval proposal ping interval in seconds.
@@ -51,6 +51,18 @@ var (
 		Usage: `Proposal fetch interval { "30s", "3m", "1h20m30s" }`,
 		Value: 180 * time.Second,
 	}
+	// FlagDiscoveryFile path to the proposals file used by the file discovery adapter.
+	FlagDiscoveryFile = cli.StringFlag{
+		Name:  "discovery.file",
+		Usage: `Path to a JSON file with a list of proposals, used by the "file" discovery adapter`,
+		Value: "",
+	}
+	// FlagDiscoveryCacheTTL duration for which proposals are kept in the persistent cache.
+	FlagDiscoveryCacheTTL = cli.DurationFlag{
+		Name:  "discovery.cache.ttl",
+		Usage: `Duration for which the last seen proposals are kept in the persistent cache { "0s" disables the cache, "24h" }`,
+		Value: 24 * time.Hour,
+	}
 	// FlagBindAddress IP address to bind to.
 	FlagBindAddress = cli.StringFlag{
 		Name:  "bind.address",
@@ -189,6 +201,8 @@ func RegisterFlagsNode(flags *[]cli.Flag) error {
 		&FlagDiscoveryType,
 		&FlagDiscoveryPingInterval,
 		&FlagDiscoveryFetchInterval,
+		&FlagDiscoveryFile,
+		&FlagDiscoveryCacheTTL,
 		&FlagFeedbackURL,
 		&FlagFirewallKillSwitch,
 		&FlagFirewallProtectedNetworks,
@@ -225,6 +239,8 @@ func ParseFlagsNode(ctx *cli.Context) {
 	Current.ParseStringSliceFlag(ctx, FlagDiscoveryType)
 	Current.ParseDurationFlag(ctx, FlagDiscoveryPingInterval)
 	Current.ParseDurationFlag(ctx, FlagDiscoveryFetchInterval)
+	Current.ParseStringFlag(ctx, FlagDiscoveryFile)
+	Current.ParseDurationFlag(ctx, FlagDiscoveryCacheTTL)
 	Current.ParseStringFlag(ctx, FlagFeedbackURL)
 	Current.ParseBoolFlag(ctx, FlagFirewallKillSwitch)
 	Current.ParseStringFlag(ctx, FlagFirewallProtectedNetworks)
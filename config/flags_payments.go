@@ -42,6 +42,30 @@ var (
 		Value: 0.1,
 		Usage: "The percentage of balance before we settle promises",
 	}
+	// FlagPaymentsAccountantPromiseSettlePolicy represents the automatic promise settlement policy.
+	FlagPaymentsAccountantPromiseSettlePolicy = cli.StringFlag{
+		Name:  "payments.accountant.promise.policy",
+		Value: "threshold",
+		Usage: "The automatic promise settlement policy: threshold, amount, schedule or never",
+	}
+	// FlagPaymentsAccountantPromiseSettleAmount represents the unsettled amount at which the amount policy settles promises.
+	FlagPaymentsAccountantPromiseSettleAmount = cli.Uint64Flag{
+		Name:  "payments.accountant.promise.amount",
+		Value: 1_000_000_000,
+		Usage: "The unsettled amount of MYST (in the smallest units) at which promises are settled by the amount policy",
+	}
+	// FlagPaymentsAccountantPromiseSettleTime represents the daily time at which the schedule policy settles promises.
+	FlagPaymentsAccountantPromiseSettleTime = cli.StringFlag{
+		Name:  "payments.accountant.promise.time",
+		Value: "00:00",
+		Usage: "The daily time in UTC (HH:MM) at which promises are settled by the schedule policy",
+	}
+	// FlagPaymentsAccountantPromiseSettleMaxFeeRatio represents the maximum transactor fee to unsettled balance ratio for automatic settlement.
+	FlagPaymentsAccountantPromiseSettleMaxFeeRatio = cli.Float64Flag{
+		Name:  "payments.accountant.promise.max-fee-ratio",
+		Value: 0,
+		Usage: "The maximum ratio of settlement fee to unsettled balance for automatic settlement. 0 means any fee is accepted",
+	}
 	// FlagPaymentsAccountantPromiseSettleTimeout represents the time we wait for confirmation of the promise settlement.
 	FlagPaymentsAccountantPromiseSettleTimeout = cli.DurationFlag{
 		Name:  "payments.accountant.promise.timeout",
@@ -99,6 +123,10 @@ func RegisterFlagsPayments(flags *[]cli.Flag) {
 		&FlagPaymentsMaxAccountantFee,
 		&FlagPaymentsBCTimeout,
 		&FlagPaymentsAccountantPromiseSettleThreshold,
+		&FlagPaymentsAccountantPromiseSettlePolicy,
+		&FlagPaymentsAccountantPromiseSettleAmount,
+		&FlagPaymentsAccountantPromiseSettleTime,
+		&FlagPaymentsAccountantPromiseSettleMaxFeeRatio,
 		&FlagPaymentsAccountantPromiseSettleTimeout,
 		&FlagPaymentsMystSCAddress,
 		&FlagPaymentsProviderInvoiceFrequency,
@@ -115,6 +143,10 @@ func ParseFlagsPayments(ctx *cli.Context) {
 	Current.ParseIntFlag(ctx, FlagPaymentsMaxAccountantFee)
 	Current.ParseDurationFlag(ctx, FlagPaymentsBCTimeout)
 	Current.ParseFloat64Flag(ctx, FlagPaymentsAccountantPromiseSettleThreshold)
+	Current.ParseStringFlag(ctx, FlagPaymentsAccountantPromiseSettlePolicy)
+	Current.ParseUInt64Flag(ctx, FlagPaymentsAccountantPromiseSettleAmount)
+	Current.ParseStringFlag(ctx, FlagPaymentsAccountantPromiseSettleTime)
+	Current.ParseFloat64Flag(ctx, FlagPaymentsAccountantPromiseSettleMaxFeeRatio)
 	Current.ParseDurationFlag(ctx, FlagPaymentsAccountantPromiseSettleTimeout)
 	Current.ParseStringFlag(ctx, FlagPaymentsMystSCAddress)
 	Current.ParseDurationFlag(ctx, FlagPaymentsProviderInvoiceFrequency)
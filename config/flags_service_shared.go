@@ -73,6 +73,12 @@ var (
 		Name:  "shaper.enabled",
 		Usage: "Limit service bandwidth",
 	}
+	// FlagDrainTimeout time to wait for provider sessions to end when the node is stopped.
+	FlagDrainTimeout = cli.DurationFlag{
+		Name:  "drain.timeout",
+		Usage: `Time to wait for running sessions to end when the node is stopped, new sessions are rejected meanwhile { "0s" stops immediately, "10m" }`,
+		Value: 0,
+	}
 	// FlagNoopPriceMinute sets the price per minute for provided noop service.
 	FlagNoopPriceMinute = cli.Float64Flag{
 		Name:   "noop.price-minute",
@@ -92,6 +98,7 @@ func RegisterFlagsServiceShared(flags *[]cli.Flag) {
 		&FlagAccessPolicyList,
 		&FlagAccessPolicyFetchInterval,
 		&FlagShaperEnabled,
+		&FlagDrainTimeout,
 		&FlagNoopPriceMinute,
 	)
 }
@@ -105,5 +112,6 @@ func ParseFlagsServiceShared(ctx *cli.Context) {
 	Current.ParseStringFlag(ctx, FlagAccessPolicyList)
 	Current.ParseDurationFlag(ctx, FlagAccessPolicyFetchInterval)
 	Current.ParseBoolFlag(ctx, FlagShaperEnabled)
+	Current.ParseDurationFlag(ctx, FlagDrainTimeout)
 	Current.ParseFloat64Flag(ctx, FlagNoopPriceMinute)
 }
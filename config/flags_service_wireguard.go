@@ -18,6 +18,8 @@
 package config
 
 import (
+	"time"
+
 	"github.com/urfave/cli/v2"
 )
 
@@ -40,6 +42,24 @@ var (
 		Usage: "Subnet to be used by the wireguard service",
 		Value: "10.182.0.0/16",
 	}
+	// FlagWireguardListenSubnet6 optional IPv6 subnet to be used by the wireguard service.
+	FlagWireguardListenSubnet6 = cli.StringFlag{
+		Name:  "wireguard.allowed.subnet6",
+		Usage: "IPv6 ULA subnet (e.g. fd00:182::/48) to be used by the wireguard service for IPv6 egress, IPv6 is disabled if empty",
+		Value: "",
+	}
+	// FlagWireguardMultiPeer makes the wireguard service run a single device for all the sessions.
+	FlagWireguardMultiPeer = cli.BoolFlag{
+		Name:  "wireguard.multi-peer",
+		Usage: "Serve all the sessions through a single wireguard device and listen port, instead of one per session. Listen port must be reachable from the consumers",
+		Value: false,
+	}
+	// FlagWireguardKeyRotationInterval sets how often consumer rotates the keys of the wireguard session.
+	FlagWireguardKeyRotationInterval = cli.DurationFlag{
+		Name:  "wireguard.key-rotation-interval",
+		Usage: "Interval of the consumer session keys rotation, 0 disables it",
+		Value: 24 * time.Hour,
+	}
 	// FlagWireguardPriceMinute sets the price per minute for provided wireguard service.
 	FlagWireguardPriceMinute = cli.Float64Flag{
 		Name:  "wireguard.price-minute",
@@ -60,6 +80,9 @@ func RegisterFlagsServiceWireguard(flags *[]cli.Flag) {
 		&FlagWireguardConnectDelay,
 		&FlagWireguardListenPorts,
 		&FlagWireguardListenSubnet,
+		&FlagWireguardListenSubnet6,
+		&FlagWireguardMultiPeer,
+		&FlagWireguardKeyRotationInterval,
 		&FlagWireguardPriceMinute,
 		&FlagWireguardPriceGB,
 	)
@@ -70,6 +93,9 @@ func ParseFlagsServiceWireguard(ctx *cli.Context) {
 	Current.ParseIntFlag(ctx, FlagWireguardConnectDelay)
 	Current.ParseStringFlag(ctx, FlagWireguardListenPorts)
 	Current.ParseStringFlag(ctx, FlagWireguardListenSubnet)
+	Current.ParseStringFlag(ctx, FlagWireguardListenSubnet6)
+	Current.ParseBoolFlag(ctx, FlagWireguardMultiPeer)
+	Current.ParseDurationFlag(ctx, FlagWireguardKeyRotationInterval)
 	Current.ParseFloat64Flag(ctx, FlagWireguardPriceMinute)
 	Current.ParseFloat64Flag(ctx, FlagWireguardPriceGB)
 }
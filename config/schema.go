@@ -0,0 +1,367 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package config
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"sort"
+	"strings"
+
+	"github.com/mysteriumnetwork/node/core/port"
+	"github.com/spf13/cast"
+	"github.com/urfave/cli/v2"
+)
+
+// FieldType is a type of configuration value.
+type FieldType string
+
+const (
+	// FieldTypeBool is a boolean value.
+	FieldTypeBool FieldType = "bool"
+	// FieldTypeInt is an integer value.
+	FieldTypeInt FieldType = "int"
+	// FieldTypeUint64 is an unsigned integer value.
+	FieldTypeUint64 FieldType = "uint64"
+	// FieldTypeFloat64 is a floating point value.
+	FieldTypeFloat64 FieldType = "float64"
+	// FieldTypeDuration is a duration value, e.g. "1h20m".
+	FieldTypeDuration FieldType = "duration"
+	// FieldTypeString is a string value.
+	FieldTypeString FieldType = "string"
+	// FieldTypeStringSlice is a list of strings.
+	FieldTypeStringSlice FieldType = "string_slice"
+)
+
+// Field error codes.
+const (
+	FieldErrorUnknown     = "unknown"
+	FieldErrorInvalidType = "invalid_type"
+	FieldErrorNotAllowed  = "not_allowed"
+	FieldErrorInvalid     = "invalid"
+)
+
+// FieldError describes an invalid configuration value.
+type FieldError struct {
+	Key     string
+	Code    string
+	Message string
+}
+
+// Error returns the error message.
+func (e FieldError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Key, e.Message)
+}
+
+// Field describes a single configuration option.
+type Field struct {
+	Key             string
+	Type            FieldType
+	Default         interface{}
+	AllowedValues   []string
+	Description     string
+	RequiresRestart bool
+	validate        func(value interface{}) error
+}
+
+// fieldRule holds constraints of an option which can not be derived from its flag.
+type fieldRule struct {
+	allowed  []string
+	validate func(value interface{}) error
+}
+
+var fieldRules = map[string]fieldRule{
+	FlagLogLevel.Name:                  {allowed: []string{"trace", "debug", "info", "warn", "error", "fatal", "panic"}},
+	FlagDiscoveryType.Name:             {allowed: []string{"api", "broker", "file"}},
+	FlagQualityType.Name:               {allowed: []string{"elastic", "morqa", "none"}},
+	FlagLocationType.Name:              {allowed: []string{"oracle", "builtin", "mmdb", "manual"}},
+	FlagOpenvpnProtocol.Name:           {allowed: []string{"udp", "tcp"}},
+	FlagOpenvpnPort.Name:               {validate: validatePort},
+	FlagOpenvpnSubnet.Name:             {validate: validateIP},
+	FlagOpenvpnNetmask.Name:            {validate: validateIP},
+	FlagTequilapiPort.Name:             {validate: validatePort},
+	FlagUIPort.Name:                    {validate: validatePort},
+	FlagP2PRelayPort.Name:              {validate: validatePort},
+	FlagWireguardListenPorts.Name:      {validate: validatePortRange},
+	FlagWireguardListenSubnet.Name:     {validate: validateSubnet},
+	FlagWireguardListenSubnet6.Name:    {validate: validateOptionalSubnet},
+	FlagFirewallProtectedNetworks.Name: {validate: validateSubnetList},
+}
+
+// runtimeFields are applied without restarting the node.
+var runtimeFields = map[string]bool{
+	FlagShaperEnabled.Name: true,
+}
+
+// Schema describes configuration options of the node.
+type Schema struct {
+	fields map[string]Field
+	keys   []string
+}
+
+// NewSchema creates schema of the options defined by the given flags.
+func NewSchema(flags []cli.Flag) *Schema {
+	schema := &Schema{fields: make(map[string]Field)}
+	for _, flag := range flags {
+		field, ok := newField(flag)
+		if !ok {
+			continue
+		}
+		if _, exists := schema.fields[field.Key]; !exists {
+			schema.keys = append(schema.keys, field.Key)
+		}
+		schema.fields[field.Key] = field
+	}
+	sort.Strings(schema.keys)
+	return schema
+}
+
+// NodeSchema creates schema of the node and service options.
+func NodeSchema() (*Schema, error) {
+	var flags []cli.Flag
+	if err := RegisterFlagsNode(&flags); err != nil {
+		return nil, err
+	}
+	RegisterFlagsServiceShared(&flags)
+	RegisterFlagsServiceOpenvpn(&flags)
+	RegisterFlagsServiceWireguard(&flags)
+	return NewSchema(flags), nil
+}
+
+func newField(flag cli.Flag) (Field, bool) {
+	var field Field
+	switch f := flag.(type) {
+	case *cli.BoolFlag:
+		field = Field{Key: f.Name, Type: FieldTypeBool, Default: f.Value, Description: f.Usage}
+	case *cli.IntFlag:
+		field = Field{Key: f.Name, Type: FieldTypeInt, Default: f.Value, Description: f.Usage}
+	case *cli.Uint64Flag:
+		field = Field{Key: f.Name, Type: FieldTypeUint64, Default: f.Value, Description: f.Usage}
+	case *cli.Float64Flag:
+		field = Field{Key: f.Name, Type: FieldTypeFloat64, Default: f.Value, Description: f.Usage}
+	case *cli.DurationFlag:
+		field = Field{Key: f.Name, Type: FieldTypeDuration, Default: f.Value.String(), Description: f.Usage}
+	case *cli.StringFlag:
+		field = Field{Key: f.Name, Type: FieldTypeString, Default: f.Value, Description: f.Usage}
+	case *cli.StringSliceFlag:
+		value := []string{}
+		if f.Value != nil {
+			value = f.Value.Value()
+		}
+		field = Field{Key: f.Name, Type: FieldTypeStringSlice, Default: value, Description: f.Usage}
+	default:
+		return field, false
+	}
+
+	field.Key = strings.ToLower(field.Key)
+	field.RequiresRestart = !runtimeFields[field.Key]
+	if rule, ok := fieldRules[field.Key]; ok {
+		field.AllowedValues = rule.allowed
+		field.validate = rule.validate
+	}
+	return field, true
+}
+
+// Fields returns all options sorted by key.
+func (s *Schema) Fields() []Field {
+	fields := make([]Field, len(s.keys))
+	for i, key := range s.keys {
+		fields[i] = s.fields[key]
+	}
+	return fields
+}
+
+// Field returns the option of the given key.
+func (s *Schema) Field(key string) (Field, bool) {
+	field, ok := s.fields[strings.ToLower(key)]
+	return field, ok
+}
+
+// Validate checks if the value can be used for the option of the given key.
+func (s *Schema) Validate(key string, value interface{}) *FieldError {
+	field, ok := s.Field(key)
+	if !ok {
+		return &FieldError{Key: key, Code: FieldErrorUnknown, Message: "Unknown configuration key"}
+	}
+	return field.Validate(value)
+}
+
+// ValidateAll checks configuration values, which can be nested like in the config file, and returns errors sorted by key.
+func (s *Schema) ValidateAll(values map[string]interface{}) []FieldError {
+	var errs []FieldError
+	for key, value := range Flatten(values) {
+		if value == nil {
+			continue
+		}
+		if err := s.Validate(key, value); err != nil {
+			errs = append(errs, *err)
+		}
+	}
+	sort.Slice(errs, func(i, j int) bool {
+		return errs[i].Key < errs[j].Key
+	})
+	return errs
+}
+
+// Validate checks if the value can be used for the option.
+func (f Field) Validate(value interface{}) *FieldError {
+	invalid := func(code, format string, args ...interface{}) *FieldError {
+		return &FieldError{Key: f.Key, Code: code, Message: fmt.Sprintf(format, args...)}
+	}
+
+	values, err := f.normalize(value)
+	if err != nil {
+		return invalid(FieldErrorInvalidType, "Value must be of type %s", f.Type)
+	}
+
+	if len(f.AllowedValues) > 0 {
+		for _, v := range values {
+			if !contains(f.AllowedValues, v) {
+				return invalid(FieldErrorNotAllowed, "Value %q is not one of: %s", v, strings.Join(f.AllowedValues, ", "))
+			}
+		}
+	}
+
+	if f.validate != nil {
+		if err := f.validate(value); err != nil {
+			return invalid(FieldErrorInvalid, "%s", err)
+		}
+	}
+	return nil
+}
+
+// normalize checks that the value is readable as the field type and returns its string representations.
+func (f Field) normalize(value interface{}) ([]string, error) {
+	switch f.Type {
+	case FieldTypeBool:
+		v, err := cast.ToBoolE(value)
+		return []string{fmt.Sprint(v)}, err
+	case FieldTypeInt:
+		if v, ok := value.(float64); ok && v != math.Trunc(v) {
+			return nil, fmt.Errorf("%v is not an integer", v)
+		}
+		v, err := cast.ToIntE(value)
+		return []string{fmt.Sprint(v)}, err
+	case FieldTypeUint64:
+		if v, ok := value.(float64); ok && v != math.Trunc(v) {
+			return nil, fmt.Errorf("%v is not an integer", v)
+		}
+		v, err := cast.ToUint64E(value)
+		return []string{fmt.Sprint(v)}, err
+	case FieldTypeFloat64:
+		v, err := cast.ToFloat64E(value)
+		return []string{fmt.Sprint(v)}, err
+	case FieldTypeDuration:
+		if _, ok := value.(bool); ok {
+			return nil, fmt.Errorf("%v is not a duration", value)
+		}
+		v, err := cast.ToDurationE(value)
+		return []string{v.String()}, err
+	case FieldTypeString:
+		switch value.(type) {
+		case []interface{}, []string, map[string]interface{}:
+			return nil, fmt.Errorf("%v is not a string", value)
+		}
+		v, err := cast.ToStringE(value)
+		return []string{v}, err
+	case FieldTypeStringSlice:
+		switch value.(type) {
+		case []interface{}, []string:
+			return cast.ToStringSliceE(value)
+		}
+		return nil, fmt.Errorf("%v is not a list", value)
+	}
+	return nil, fmt.Errorf("unsupported type %s", f.Type)
+}
+
+// Flatten converts nested configuration values to a map of dotted keys.
+func Flatten(values map[string]interface{}) map[string]interface{} {
+	flat := make(map[string]interface{})
+	flatten("", values, flat)
+	return flat
+}
+
+func flatten(prefix string, values map[string]interface{}, flat map[string]interface{}) {
+	for key, value := range values {
+		key = strings.ToLower(prefix + key)
+		if nested, ok := value.(map[string]interface{}); ok {
+			flatten(key+".", nested, flat)
+			continue
+		}
+		flat[key] = value
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func validatePort(value interface{}) error {
+	p := cast.ToInt(value)
+	if p < 0 || p > 65535 {
+		return fmt.Errorf("port %d is out of range 0-65535", p)
+	}
+	return nil
+}
+
+func validatePortRange(value interface{}) error {
+	portRange, err := port.ParseRange(cast.ToString(value))
+	if err != nil {
+		return err
+	}
+	if portRange.Start < 0 || portRange.End > 65535 {
+		return fmt.Errorf("port range %s is out of range 0-65535", value)
+	}
+	return nil
+}
+
+func validateIP(value interface{}) error {
+	if net.ParseIP(cast.ToString(value)) == nil {
+		return fmt.Errorf("%q is not an IP address", value)
+	}
+	return nil
+}
+
+func validateSubnet(value interface{}) error {
+	if _, _, err := net.ParseCIDR(cast.ToString(value)); err != nil {
+		return fmt.Errorf("%q is not a subnet in CIDR notation", value)
+	}
+	return nil
+}
+
+func validateOptionalSubnet(value interface{}) error {
+	if cast.ToString(value) == "" {
+		return nil
+	}
+	return validateSubnet(value)
+}
+
+func validateSubnetList(value interface{}) error {
+	for _, subnet := range strings.Split(cast.ToString(value), ",") {
+		if err := validateSubnet(strings.TrimSpace(subnet)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
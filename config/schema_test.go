@@ -0,0 +1,124 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/urfave/cli/v2"
+)
+
+func TestSchema_Fields(t *testing.T) {
+	schema := NewSchema([]cli.Flag{
+		&cli.StringFlag{Name: "b.string", Usage: "string option", Value: "value"},
+		&cli.DurationFlag{Name: "a.duration", Value: 90e9},
+		&cli.StringSliceFlag{Name: "c.slice", Value: cli.NewStringSlice("x", "y")},
+	})
+
+	fields := schema.Fields()
+	assert.Len(t, fields, 3)
+	assert.Equal(t, "a.duration", fields[0].Key)
+	assert.Equal(t, FieldTypeDuration, fields[0].Type)
+	assert.Equal(t, "1m30s", fields[0].Default)
+	assert.Equal(t, "b.string", fields[1].Key)
+	assert.Equal(t, "string option", fields[1].Description)
+	assert.Equal(t, "value", fields[1].Default)
+	assert.True(t, fields[1].RequiresRestart)
+	assert.Equal(t, []string{"x", "y"}, fields[2].Default)
+}
+
+func TestNodeSchema(t *testing.T) {
+	schema, err := NodeSchema()
+	assert.NoError(t, err)
+
+	field, ok := schema.Field(FlagLogLevel.Name)
+	assert.True(t, ok)
+	assert.Contains(t, field.AllowedValues, "debug")
+
+	field, ok = schema.Field(FlagShaperEnabled.Name)
+	assert.True(t, ok)
+	assert.Equal(t, FieldTypeBool, field.Type)
+	assert.False(t, field.RequiresRestart)
+
+	field, ok = schema.Field(FlagWireguardListenSubnet.Name)
+	assert.True(t, ok)
+	assert.True(t, field.RequiresRestart)
+}
+
+func TestSchema_Validate(t *testing.T) {
+	schema, err := NodeSchema()
+	assert.NoError(t, err)
+
+	tests := []struct {
+		key   string
+		value interface{}
+		code  string
+	}{
+		{key: "openvpn.port", value: float64(1194)},
+		{key: "openvpn.port", value: 1.5, code: FieldErrorInvalidType},
+		{key: "openvpn.port", value: "abc", code: FieldErrorInvalidType},
+		{key: "openvpn.port", value: 70000, code: FieldErrorInvalid},
+		{key: "openvpn.proto", value: "tcp"},
+		{key: "openvpn.proto", value: "sctp", code: FieldErrorNotAllowed},
+		{key: "shaper.enabled", value: true},
+		{key: "shaper.enabled", value: "maybe", code: FieldErrorInvalidType},
+		{key: "wireguard.allowed.subnet", value: "10.182.0.0/16"},
+		{key: "wireguard.allowed.subnet", value: "10.182.0.0", code: FieldErrorInvalid},
+		{key: "wireguard.listen.ports", value: "52820:53075"},
+		{key: "wireguard.listen.ports", value: "52820", code: FieldErrorInvalid},
+		{key: "discovery.type", value: []interface{}{"api", "broker"}},
+		{key: "discovery.type", value: []interface{}{"api", "dht"}, code: FieldErrorNotAllowed},
+		{key: "discovery.type", value: "api", code: FieldErrorInvalidType},
+		{key: "log-level", value: "verbose", code: FieldErrorNotAllowed},
+		{key: "discovery.ping", value: "1m"},
+		{key: "discovery.ping", value: "soon", code: FieldErrorInvalidType},
+		{key: "no.such.key", value: 1, code: FieldErrorUnknown},
+	}
+	for _, tc := range tests {
+		err := schema.Validate(tc.key, tc.value)
+		if tc.code == "" {
+			assert.Nil(t, err, "%s=%v", tc.key, tc.value)
+			continue
+		}
+		if assert.NotNil(t, err, "%s=%v", tc.key, tc.value) {
+			assert.Equal(t, tc.code, err.Code, "%s=%v", tc.key, tc.value)
+			assert.Equal(t, tc.key, err.Key)
+		}
+	}
+}
+
+func TestSchema_ValidateAll(t *testing.T) {
+	schema, err := NodeSchema()
+	assert.NoError(t, err)
+
+	errs := schema.ValidateAll(map[string]interface{}{
+		"openvpn": map[string]interface{}{
+			"port":  "abc",
+			"proto": "udp",
+		},
+		"wireguard.allowed.subnet": "bad",
+		"shaper.enabled":           nil,
+	})
+
+	assert.Len(t, errs, 2)
+	assert.Equal(t, "openvpn.port", errs[0].Key)
+	assert.Equal(t, FieldErrorInvalidType, errs[0].Code)
+	assert.Equal(t, "wireguard.allowed.subnet", errs[1].Key)
+	assert.Equal(t, FieldErrorInvalid, errs[1].Code)
+}
@@ -18,6 +18,7 @@
 package session
 
 import (
+	"sort"
 	"sync"
 	"time"
 
@@ -80,6 +81,32 @@ func (repo *Storage) GetAll() ([]History, error) {
 	return sessions, nil
 }
 
+// GetPage returns a single page of sessions ordered from the newest to the oldest,
+// along with the total count of stored sessions. Pages are numbered starting from 1.
+func (repo *Storage) GetPage(page, pageSize int) ([]History, int, error) {
+	sessions, err := repo.GetAll()
+	if err != nil {
+		return nil, 0, err
+	}
+	sort.SliceStable(sessions, func(i, j int) bool {
+		return sessions[i].Started.After(sessions[j].Started)
+	})
+
+	total := len(sessions)
+	if page < 1 || pageSize < 1 {
+		return []History{}, total, nil
+	}
+	from := (page - 1) * pageSize
+	if from >= total {
+		return []History{}, total, nil
+	}
+	to := from + pageSize
+	if to > total {
+		to = total
+	}
+	return sessions[from:to], total, nil
+}
+
 // consumeSessionEvent consumes the session state change events
 func (repo *Storage) consumeSessionEvent(sessionEvent connection.AppEventConnectionSession) {
 	switch sessionEvent.Status {
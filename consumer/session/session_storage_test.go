@@ -20,6 +20,7 @@ package session
 import (
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/mysteriumnetwork/node/core/connection"
 	"github.com/mysteriumnetwork/node/identity"
@@ -68,6 +69,56 @@ func TestSessionStorageGetAllReturnsError(t *testing.T) {
 	assert.Nil(t, sessions)
 }
 
+func TestSessionStorageGetPage(t *testing.T) {
+	started := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	storer := &StubSessionStorer{
+		Sessions: []History{
+			{SessionID: "s1", Started: started},
+			{SessionID: "s3", Started: started.Add(2 * time.Hour)},
+			{SessionID: "s2", Started: started.Add(time.Hour)},
+		},
+	}
+	storage := NewSessionStorage(storer)
+
+	sessions, total, err := storage.GetPage(1, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, total)
+	assert.Equal(t, []node_session.ID{"s3", "s2"}, sessionIDs(sessions))
+
+	sessions, total, err = storage.GetPage(2, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, total)
+	assert.Equal(t, []node_session.ID{"s1"}, sessionIDs(sessions))
+
+	sessions, total, err = storage.GetPage(3, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, total)
+	assert.Len(t, sessions, 0)
+
+	sessions, _, err = storage.GetPage(0, 2)
+	assert.NoError(t, err)
+	assert.Len(t, sessions, 0)
+}
+
+func TestSessionStorageGetPageReturnsError(t *testing.T) {
+	storer := &StubSessionStorer{
+		GetAllError: errMock,
+	}
+	storage := NewSessionStorage(storer)
+	sessions, total, err := storage.GetPage(1, 10)
+	assert.Equal(t, errMock, err)
+	assert.Equal(t, 0, total)
+	assert.Nil(t, sessions)
+}
+
+func sessionIDs(sessions []History) []node_session.ID {
+	ids := make([]node_session.ID, len(sessions))
+	for i := range sessions {
+		ids[i] = sessions[i].SessionID
+	}
+	return ids
+}
+
 func TestSessionStorageConsumeEventEndedOK(t *testing.T) {
 	storer := &StubSessionStorer{}
 
@@ -102,6 +153,7 @@ type StubSessionStorer struct {
 	UpdateCalled bool
 	GetAllCalled bool
 	GetAllError  error
+	Sessions     []History
 }
 
 func (sss *StubSessionStorer) Store(from string, object interface{}) error {
@@ -116,7 +168,13 @@ func (sss *StubSessionStorer) Update(from string, object interface{}) error {
 
 func (sss *StubSessionStorer) GetAllFrom(from string, array interface{}) error {
 	sss.GetAllCalled = true
-	return sss.GetAllError
+	if sss.GetAllError != nil {
+		return sss.GetAllError
+	}
+	if sessions, ok := array.(*[]History); ok {
+		*sessions = append([]History(nil), sss.Sessions...)
+	}
+	return nil
 }
 
 type StubServiceDefinition struct{}
@@ -0,0 +1,126 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Package audit keeps an append-only record of security relevant actions performed on the node.
+package audit
+
+import (
+	"strings"
+	"time"
+)
+
+// Outcome describes how an audited action ended.
+type Outcome string
+
+const (
+	// OutcomeSuccess marks an action which completed successfully.
+	OutcomeSuccess = Outcome("success")
+	// OutcomeFailure marks an action which was rejected or failed.
+	OutcomeFailure = Outcome("failure")
+)
+
+// ActorAnonymous is used when the actor of an action could not be identified.
+const ActorAnonymous = "anonymous"
+
+// Entry is a single audit log record.
+type Entry struct {
+	Timestamp  time.Time              `json:"timestamp"`
+	Actor      string                 `json:"actor"`
+	Source     string                 `json:"source,omitempty"`
+	Action     string                 `json:"action"`
+	Parameters map[string]interface{} `json:"parameters,omitempty"`
+	Outcome    Outcome                `json:"outcome"`
+	Error      string                 `json:"error,omitempty"`
+}
+
+// Filter narrows down audit log entries returned by a query.
+// Zero valued fields are not used for filtering.
+type Filter struct {
+	Actor   string
+	Action  string
+	Outcome Outcome
+	From    time.Time
+	To      time.Time
+	Limit   int
+}
+
+// Matches checks whether the given entry satisfies the filter.
+func (f Filter) Matches(entry Entry) bool {
+	if f.Actor != "" && f.Actor != entry.Actor {
+		return false
+	}
+	if f.Action != "" && f.Action != entry.Action && !strings.HasPrefix(entry.Action, f.Action+".") {
+		return false
+	}
+	if f.Outcome != "" && f.Outcome != entry.Outcome {
+		return false
+	}
+	if !f.From.IsZero() && entry.Timestamp.Before(f.From) {
+		return false
+	}
+	if !f.To.IsZero() && entry.Timestamp.After(f.To) {
+		return false
+	}
+	return true
+}
+
+// Redacted is the value which replaces secrets in audit log parameters.
+const Redacted = "[REDACTED]"
+
+var secretKeyParts = []string{"password", "passphrase", "secret", "token", "private", "key"}
+
+// Redact returns a copy of the given parameters with secret values replaced.
+// Nested objects and lists are redacted recursively.
+func Redact(params map[string]interface{}) map[string]interface{} {
+	if params == nil {
+		return nil
+	}
+	result := make(map[string]interface{}, len(params))
+	for key, value := range params {
+		if isSecretKey(key) {
+			result[key] = Redacted
+			continue
+		}
+		result[key] = redactValue(value)
+	}
+	return result
+}
+
+func redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return Redact(v)
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i := range v {
+			result[i] = redactValue(v[i])
+		}
+		return result
+	default:
+		return v
+	}
+}
+
+func isSecretKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, part := range secretKeyParts {
+		if strings.Contains(key, part) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,87 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package audit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedact(t *testing.T) {
+	params := map[string]interface{}{
+		"username":     "myst",
+		"old_password": "old",
+		"passphrase":   "secret",
+		"options": map[string]interface{}{
+			"apiToken": "abc",
+			"port":     float64(1194),
+		},
+		"peers": []interface{}{
+			map[string]interface{}{"private_key": "key"},
+		},
+	}
+
+	assert.Equal(t, map[string]interface{}{
+		"username":     "myst",
+		"old_password": Redacted,
+		"passphrase":   Redacted,
+		"options": map[string]interface{}{
+			"apiToken": Redacted,
+			"port":     float64(1194),
+		},
+		"peers": []interface{}{
+			map[string]interface{}{"private_key": Redacted},
+		},
+	}, Redact(params))
+	assert.Equal(t, "old", params["old_password"], "original parameters should not be modified")
+	assert.Nil(t, Redact(nil))
+}
+
+func TestFilter_Matches(t *testing.T) {
+	now := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+	entry := Entry{
+		Timestamp: now,
+		Actor:     "myst",
+		Action:    "identity.unlock",
+		Outcome:   OutcomeSuccess,
+	}
+
+	tests := []struct {
+		name    string
+		filter  Filter
+		matches bool
+	}{
+		{name: "empty filter", filter: Filter{}, matches: true},
+		{name: "same actor", filter: Filter{Actor: "myst"}, matches: true},
+		{name: "other actor", filter: Filter{Actor: "other"}, matches: false},
+		{name: "exact action", filter: Filter{Action: "identity.unlock"}, matches: true},
+		{name: "action group", filter: Filter{Action: "identity"}, matches: true},
+		{name: "action prefix without separator", filter: Filter{Action: "ident"}, matches: false},
+		{name: "other outcome", filter: Filter{Outcome: OutcomeFailure}, matches: false},
+		{name: "inside period", filter: Filter{From: now.Add(-time.Hour), To: now.Add(time.Hour)}, matches: true},
+		{name: "before period", filter: Filter{From: now.Add(time.Second)}, matches: false},
+		{name: "after period", filter: Filter{To: now.Add(-time.Second)}, matches: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.matches, tt.filter.Matches(entry))
+		})
+	}
+}
@@ -0,0 +1,187 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package audit
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/arthurkiller/rollingwriter"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+// FileName is the base name of audit log files.
+const FileName = "audit"
+
+// Log is an append-only audit log stored as JSON lines in rolling files.
+type Log struct {
+	mu     sync.Mutex
+	dir    string
+	writer io.Writer
+	now    func() time.Time
+}
+
+// NewLog creates audit log writing to the given directory.
+func NewLog(dir string) (*Log, error) {
+	writer, err := rollingwriter.NewWriterFromConfig(&rollingwriter.Config{
+		TimeTagFormat:     "20060102T150405",
+		LogPath:           dir,
+		FileName:          FileName,
+		RollingPolicy:     rollingwriter.VolumeRolling,
+		RollingVolumeSize: "50MB",
+		Compress:          true,
+		WriterMode:        "lock",
+		MaxRemain:         5,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "could not create audit log writer")
+	}
+
+	return &Log{
+		dir:    dir,
+		writer: writer,
+		now:    time.Now,
+	}, nil
+}
+
+// Record appends an entry to the audit log.
+func (l *Log) Record(entry Entry) error {
+	if entry.Timestamp.IsZero() {
+		entry.Timestamp = l.now()
+	}
+	entry.Timestamp = entry.Timestamp.UTC()
+	if entry.Actor == "" {
+		entry.Actor = ActorAnonymous
+	}
+	entry.Parameters = Redact(entry.Parameters)
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return errors.Wrap(err, "could not marshal audit entry")
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	_, err = l.writer.Write(append(line, '\n'))
+	return errors.Wrap(err, "could not write audit entry")
+}
+
+// Query returns entries matching the filter, newest first.
+func (l *Log) Query(filter Filter) ([]Entry, error) {
+	files, err := l.files()
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]Entry, 0)
+	for _, file := range files {
+		fileEntries, err := readEntries(file)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range fileEntries {
+			if filter.Matches(entry) {
+				entries = append(entries, entry)
+			}
+		}
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Timestamp.After(entries[j].Timestamp)
+	})
+	if filter.Limit > 0 && len(entries) > filter.Limit {
+		entries = entries[:filter.Limit]
+	}
+	return entries, nil
+}
+
+// files lists audit log files from the oldest rolled file to the current one.
+func (l *Log) files() ([]string, error) {
+	infos, err := ioutil.ReadDir(l.dir)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not list audit log files")
+	}
+
+	current := FileName + ".log"
+	var rolled []string
+	for _, info := range infos {
+		name := info.Name()
+		if info.IsDir() || name == current || !strings.HasPrefix(name, current+".") || strings.HasSuffix(name, ".tmp") {
+			continue
+		}
+		rolled = append(rolled, name)
+	}
+	sort.Slice(rolled, func(i, j int) bool {
+		return rollTag(rolled[i]) < rollTag(rolled[j])
+	})
+
+	files := make([]string, 0, len(rolled)+1)
+	for _, name := range rolled {
+		files = append(files, path.Join(l.dir, name))
+	}
+	return append(files, path.Join(l.dir, current)), nil
+}
+
+func rollTag(name string) string {
+	return name[strings.LastIndex(name, ".")+1:]
+}
+
+func readEntries(filepath string) ([]Entry, error) {
+	file, err := os.Open(filepath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "could not open audit log file")
+	}
+	defer file.Close()
+
+	var reader io.Reader = file
+	if strings.Contains(path.Base(filepath), ".gz.") {
+		gzipReader, err := gzip.NewReader(file)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not decompress audit log file")
+		}
+		defer gzipReader.Close()
+		reader = gzipReader
+	}
+
+	var entries []Entry
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			log.Debug().Err(err).Msgf("Skipping malformed audit log line in %s", filepath)
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries, errors.Wrap(scanner.Err(), "could not read audit log file")
+}
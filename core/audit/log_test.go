@@ -0,0 +1,99 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package audit
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLog_RecordAndQuery(t *testing.T) {
+	dir, err := ioutil.TempDir("", "audit")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	auditLog, err := NewLog(dir)
+	require.NoError(t, err)
+
+	start := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, auditLog.Record(Entry{Timestamp: start, Actor: "myst", Action: "auth.login", Outcome: OutcomeSuccess}))
+	require.NoError(t, auditLog.Record(Entry{
+		Timestamp:  start.Add(time.Minute),
+		Action:     "identity.unlock",
+		Parameters: map[string]interface{}{"id": "0x1", "passphrase": "secret"},
+		Outcome:    OutcomeFailure,
+		Error:      "wrong passphrase",
+	}))
+	require.NoError(t, auditLog.Record(Entry{Timestamp: start.Add(2 * time.Minute), Actor: "myst", Action: "service.start", Outcome: OutcomeSuccess}))
+
+	entries, err := auditLog.Query(Filter{})
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+	assert.Equal(t, "service.start", entries[0].Action)
+	assert.Equal(t, "auth.login", entries[2].Action)
+
+	assert.Equal(t, ActorAnonymous, entries[1].Actor)
+	assert.Equal(t, map[string]interface{}{"id": "0x1", "passphrase": Redacted}, entries[1].Parameters)
+	assert.Equal(t, "wrong passphrase", entries[1].Error)
+
+	entries, err = auditLog.Query(Filter{Actor: "myst", Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "service.start", entries[0].Action)
+
+	entries, err = auditLog.Query(Filter{Outcome: OutcomeFailure})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "identity.unlock", entries[0].Action)
+}
+
+func TestLog_QueryReadsRolledFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "audit")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	rolled, err := os.Create(path.Join(dir, FileName+".log.gz.20200601T120000"))
+	require.NoError(t, err)
+	gzipWriter := gzip.NewWriter(rolled)
+	require.NoError(t, json.NewEncoder(gzipWriter).Encode(Entry{
+		Timestamp: time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC),
+		Actor:     "myst",
+		Action:    "connection.create",
+		Outcome:   OutcomeSuccess,
+	}))
+	require.NoError(t, gzipWriter.Close())
+	require.NoError(t, rolled.Close())
+
+	auditLog, err := NewLog(dir)
+	require.NoError(t, err)
+	require.NoError(t, auditLog.Record(Entry{Actor: "myst", Action: "connection.kill", Outcome: OutcomeSuccess}))
+
+	entries, err := auditLog.Query(Filter{Action: "connection"})
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "connection.kill", entries[0].Action)
+	assert.Equal(t, "connection.create", entries[1].Action)
+}
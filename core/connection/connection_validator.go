@@ -20,6 +20,7 @@ package connection
 import (
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/market"
+	"github.com/pkg/errors"
 )
 
 type consumerBalanceGetter interface {
@@ -30,17 +31,23 @@ type unlockChecker interface {
 	IsUnlocked(ID string) bool
 }
 
+type providerBlocklist interface {
+	IsBlocked(consumerID identity.Identity, providerID string) (bool, error)
+}
+
 // Validator validates pre connection conditions.
 type Validator struct {
 	consumerBalanceGetter consumerBalanceGetter
 	unlockChecker         unlockChecker
+	providerBlocklist     providerBlocklist
 }
 
 // NewValidator returns a new instance of connection validator.
-func NewValidator(consumerBalanceGetter consumerBalanceGetter, unlockChecker unlockChecker) *Validator {
+func NewValidator(consumerBalanceGetter consumerBalanceGetter, unlockChecker unlockChecker, providerBlocklist providerBlocklist) *Validator {
 	return &Validator{
 		consumerBalanceGetter: consumerBalanceGetter,
 		unlockChecker:         unlockChecker,
+		providerBlocklist:     providerBlocklist,
 	}
 }
 
@@ -66,6 +73,14 @@ func (v *Validator) Validate(consumerID identity.Identity, proposal market.Servi
 		return ErrUnlockRequired
 	}
 
+	blocked, err := v.providerBlocklist.IsBlocked(consumerID, proposal.ProviderID)
+	if err != nil {
+		return errors.Wrap(err, "could not check provider blocklist")
+	}
+	if blocked {
+		return ErrProviderBlocked
+	}
+
 	if !v.validateBalance(consumerID, proposal) {
 		return ErrInsufficientBalance
 	}
@@ -30,6 +30,7 @@ func TestValidator_Validate(t *testing.T) {
 	type fields struct {
 		consumerBalanceGetter consumerBalanceGetter
 		unlockChecker         unlockChecker
+		providerBlocklist     providerBlocklist
 	}
 	type args struct {
 		consumerID identity.Identity
@@ -79,6 +80,26 @@ func TestValidator_Validate(t *testing.T) {
 				consumerID: identity.FromAddress("whatever"),
 			},
 		},
+		{
+			name:    "returns provider blocked",
+			wantErr: ErrProviderBlocked,
+			fields: fields{
+				unlockChecker: &mockUnlockChecker{
+					toReturn: true,
+				},
+				providerBlocklist: &mockProviderBlocklist{
+					blocked: activeProviderID.Address,
+				},
+			},
+			args: args{
+				consumerID: identity.FromAddress("whatever"),
+				proposal: market.ServiceProposal{
+					ProviderID:       activeProviderID.Address,
+					ProviderContacts: []market.Contact{activeProviderContact},
+					ServiceType:      activeServiceType,
+				},
+			},
+		},
 		{
 			name:    "returns no error if conditions are satisfied",
 			wantErr: nil,
@@ -111,6 +132,10 @@ func TestValidator_Validate(t *testing.T) {
 			v := &Validator{
 				consumerBalanceGetter: tt.fields.consumerBalanceGetter,
 				unlockChecker:         tt.fields.unlockChecker,
+				providerBlocklist:     tt.fields.providerBlocklist,
+			}
+			if v.providerBlocklist == nil {
+				v.providerBlocklist = &mockProviderBlocklist{}
 			}
 			err := v.Validate(tt.args.consumerID, tt.args.proposal)
 			if tt.wantErr != nil {
@@ -137,3 +162,11 @@ type mockConsumerBalanceGetter struct {
 func (mcbg *mockConsumerBalanceGetter) GetBalance(id identity.Identity) uint64 {
 	return mcbg.toReturn
 }
+
+type mockProviderBlocklist struct {
+	blocked string
+}
+
+func (mpb *mockProviderBlocklist) IsBlocked(consumerID identity.Identity, providerID string) (bool, error) {
+	return mpb.blocked != "" && mpb.blocked == providerID, nil
+}
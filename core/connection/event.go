@@ -22,6 +22,7 @@ import (
 
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/p2p"
 	"github.com/mysteriumnetwork/node/session"
 )
 
@@ -72,6 +73,19 @@ type Status struct {
 	State      State
 	SessionID  session.ID
 	Proposal   market.ServiceProposal
+
+	KeyRotatedAt time.Time
+	KeyRotations int
+
+	// ResumedAt is the time the session was last resumed over a new p2p channel after network change.
+	ResumedAt time.Time
+	Resumes   int
+
+	// ProviderDrainDeadline is the time by which provider, which is shutting down, ends the session.
+	ProviderDrainDeadline time.Time
+
+	// ChannelStats is the last known p2p channel statistics of the session.
+	ChannelStats *p2p.ChannelStats
 }
 
 // IsActive checks if session is active
@@ -92,6 +106,10 @@ const (
 	SessionCreatedStatus = "Created"
 	// SessionEndedStatus represents a session end
 	SessionEndedStatus = "Ended"
+	// SessionResumedStatus represents a session resumed over a new p2p channel
+	SessionResumedStatus = "Resumed"
+	// SessionProviderDrainingStatus represents provider shutting down and asking to end the session
+	SessionProviderDrainingStatus = "ProviderDraining"
 )
 
 // AppEventConnectionSession represents a session related event
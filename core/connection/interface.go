@@ -19,6 +19,7 @@ package connection
 
 import (
 	"context"
+	"net"
 
 	"github.com/mysteriumnetwork/node/communication"
 	"github.com/mysteriumnetwork/node/identity"
@@ -41,6 +42,24 @@ type Connection interface {
 	Statistics() (Statistics, error)
 }
 
+// KeyRotator is a connection able to replace its session keys without dropping the tunnel.
+type KeyRotator interface {
+	// RotateKeys generates new keys and hands their config to send, the keys are applied locally once it succeeds.
+	RotateKeys(send func(config ConsumerConfig) error) error
+}
+
+// ChannelConnUpdater is a connection which has to know p2p channel connection of the session,
+// e.g. to exclude its traffic from the tunnel. It is called once the session is resumed over a new p2p channel.
+type ChannelConnUpdater interface {
+	UpdateChannelConn(conn *net.UDPConn) error
+}
+
+// ServiceConnUpdater is a connection which can move its tunnel to the service connection
+// of the new p2p channel once the session is resumed over it.
+type ServiceConnUpdater interface {
+	UpdateServiceConn(conn *net.UDPConn) error
+}
+
 // StateChannel is the channel we receive state change events on
 type StateChannel chan State
 
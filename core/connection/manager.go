@@ -55,6 +55,10 @@ var (
 	ErrInsufficientBalance = errors.New("insufficient balance")
 	// ErrUnlockRequired indicates that the consumer identity has not been unlocked yet
 	ErrUnlockRequired = errors.New("unlock required")
+	// ErrProviderBlocked indicates that the consumer has blocked the provider of the proposal
+	ErrProviderBlocked = errors.New("provider is blocked")
+	// ErrKeyRotationNotSupported indicates that the connection or its provider is not able to rotate session keys
+	ErrKeyRotationNotSupported = errors.New("key rotation is not supported")
 )
 
 // IPCheckConfig contains common params for connection ip check.
@@ -70,10 +74,24 @@ type KeepAliveConfig struct {
 	MaxSendErrCount int
 }
 
+// KeyRotationConfig contains session key rotation options.
+type KeyRotationConfig struct {
+	Interval    time.Duration
+	SendTimeout time.Duration
+}
+
+// ResumeConfig contains session resume options.
+type ResumeConfig struct {
+	CheckInterval time.Duration
+	Timeout       time.Duration
+}
+
 // Config contains common configuration options for connection manager.
 type Config struct {
-	IPCheck   IPCheckConfig
-	KeepAlive KeepAliveConfig
+	IPCheck     IPCheckConfig
+	KeepAlive   KeepAliveConfig
+	KeyRotation KeyRotationConfig
+	Resume      ResumeConfig
 }
 
 // DefaultConfig returns default params.
@@ -88,6 +106,14 @@ func DefaultConfig() Config {
 			SendTimeout:     5 * time.Second,
 			MaxSendErrCount: 5,
 		},
+		KeyRotation: KeyRotationConfig{
+			Interval:    24 * time.Hour,
+			SendTimeout: 20 * time.Second,
+		},
+		Resume: ResumeConfig{
+			CheckInterval: 5 * time.Second,
+			Timeout:       30 * time.Second,
+		},
 	}
 }
 
@@ -124,7 +150,9 @@ type connectionManager struct {
 	statsReportInterval      time.Duration
 	validator                validator
 	p2pDialer                p2p.Dialer
+	signerFactory            identity.SignerFactory
 	timeGetter               TimeGetter
+	localAddrs               func() ([]string, error)
 
 	// These are populated by Connect at runtime.
 	ctx                    context.Context
@@ -152,6 +180,7 @@ func NewManager(
 	statsReportInterval time.Duration,
 	validator validator,
 	p2pDialer p2p.Dialer,
+	signerFactory identity.SignerFactory,
 ) *connectionManager {
 	return &connectionManager{
 		newDialog:                dialogCreator,
@@ -166,7 +195,9 @@ func NewManager(
 		statsReportInterval:      statsReportInterval,
 		validator:                validator,
 		p2pDialer:                p2pDialer,
+		signerFactory:            signerFactory,
 		timeGetter:               time.Now,
+		localAddrs:               localIPv4Addrs,
 	}
 }
 
@@ -195,11 +226,14 @@ func (m *connectionManager) Connect(consumerID, accountantID identity.Identity,
 	providerID := identity.FromAddress(proposal.ProviderID)
 
 	var channel p2p.Channel
-	if contact, err := p2p.ParseContact(proposal.ProviderContacts); err == nil {
-		channel, err = m.createP2PChannel(m.currentCtx(), consumerID, providerID, proposal.ServiceType, contact)
+	var resumableChannel *p2p.SwitchableChannel
+	contact, err := p2p.ParseContact(proposal.ProviderContacts)
+	if err == nil {
+		resumableChannel, err = m.createP2PChannel(m.currentCtx(), consumerID, providerID, proposal.ServiceType, contact)
 		if err != nil {
 			return fmt.Errorf("could not create p2p channel: %w", err)
 		}
+		channel = resumableChannel
 	} else {
 		if stdErrors.Is(err, p2p.ErrContactNotFound) {
 			log.Debug().Msgf("Provider %s doesn't support p2p, will fallback to dialog", providerID.Address)
@@ -268,8 +302,13 @@ func (m *connectionManager) Connect(consumerID, accountantID identity.Identity,
 		return err
 	}
 
+	m.handleProviderDrain(channel, sessionDTO.Session.ID)
 	go m.keepAliveLoop(channel, sessionDTO.Session.ID)
+	go m.keyRotationLoop(connection, channel, consumerID, sessionDTO.Session.ID)
 	go m.checkSessionIP(dialog, channel, consumerID, sessionDTO.Session.ID, originalPublicIP)
+	if resumableChannel != nil {
+		go m.resumeLoop(connection, resumableChannel, consumerID, providerID, proposal.ServiceType, contact, sessionDTO.Session.ID)
+	}
 
 	return err
 }
@@ -401,11 +440,13 @@ func (m *connectionManager) createDialog(consumerID, providerID identity.Identit
 	return dialog, err
 }
 
-func (m *connectionManager) createP2PChannel(ctx context.Context, consumerID, providerID identity.Identity, serviceType string, contactDef p2p.ContactDefinition) (p2p.Channel, error) {
-	channel, err := m.p2pDialer.Dial(ctx, consumerID, providerID, serviceType, contactDef)
+func (m *connectionManager) createP2PChannel(ctx context.Context, consumerID, providerID identity.Identity, serviceType string, contactDef p2p.ContactDefinition) (*p2p.SwitchableChannel, error) {
+	ch, err := m.p2pDialer.Dial(ctx, consumerID, providerID, serviceType, contactDef)
 	if err != nil {
 		return nil, err
 	}
+	// Channel is replaced once the session is resumed after network change.
+	channel := p2p.NewSwitchableChannel(ch)
 	m.addCleanupAfterDisconnect(func() error {
 		log.Trace().Msg("Cleaning: closing P2P communication channel")
 		defer log.Trace().Msg("Cleaning: P2P communication channel DONE")
@@ -796,6 +837,30 @@ func (m *connectionManager) publishStateEvent(state State) {
 	})
 }
 
+func (m *connectionManager) handleProviderDrain(channel p2p.Channel, sessionID session.ID) {
+	if channel == nil {
+		return
+	}
+
+	channel.Handle(p2p.TopicSessionDrain, func(c p2p.Context) error {
+		var msg pb.SessionDrain
+		if err := c.Request().UnmarshalProto(&msg); err != nil {
+			return err
+		}
+
+		deadline := time.Unix(msg.GetDeadline(), 0)
+		log.Warn().Msgf("Provider is shutting down, session %s ends by %s", sessionID, deadline.Format(time.RFC3339))
+		m.setStatus(func(status *Status) {
+			status.ProviderDrainDeadline = deadline
+		})
+		m.eventPublisher.Publish(AppTopicConnectionSession, AppEventConnectionSession{
+			Status:      SessionProviderDrainingStatus,
+			SessionInfo: m.Status(),
+		})
+		return c.OK()
+	})
+}
+
 func (m *connectionManager) keepAliveLoop(channel p2p.Channel, sessionID session.ID) {
 	// TODO: Remove this check once all provider migrates to p2p.
 	if channel == nil {
@@ -832,6 +897,12 @@ func (m *connectionManager) keepAliveLoop(channel p2p.Channel, sessionID session
 			} else {
 				errCount = 0
 			}
+
+			stats := channel.Stats()
+			m.setStatus(func(status *Status) {
+				status.ChannelStats = &stats
+			})
+			m.eventPublisher.Publish(p2p.AppTopicChannelStats, p2p.AppEventChannelStats{SessionID: string(sessionID), Stats: stats})
 		}
 	}
 }
@@ -846,6 +917,66 @@ func (m *connectionManager) sendKeepAlivePing(channel p2p.Channel, sessionID ses
 	return err
 }
 
+func (m *connectionManager) keyRotationLoop(conn Connection, channel p2p.ChannelSender, consumerID identity.Identity, sessionID session.ID) {
+	// TODO: Remove this check once all provider migrates to p2p.
+	if channel == nil || m.config.KeyRotation.Interval <= 0 {
+		return
+	}
+
+	rotator, ok := conn.(KeyRotator)
+	if !ok {
+		return
+	}
+
+	ctx := m.currentCtx()
+	for {
+		select {
+		case <-ctx.Done():
+			log.Debug().Msgf("Stopping session key rotation: %v", ctx.Err())
+			return
+		case <-time.After(m.config.KeyRotation.Interval):
+			err := rotator.RotateKeys(func(config ConsumerConfig) error {
+				return m.sendSessionRekey(ctx, channel, consumerID, sessionID, config)
+			})
+			if stdErrors.Is(err, ErrKeyRotationNotSupported) {
+				log.Info().Msgf("Session keys will not be rotated: %v. SessionID=%s", err, sessionID)
+				return
+			}
+			if err != nil {
+				log.Err(err).Msgf("Failed to rotate session keys. SessionID=%s", sessionID)
+				continue
+			}
+
+			m.setStatus(func(status *Status) {
+				status.KeyRotatedAt = m.timeGetter()
+				status.KeyRotations++
+			})
+			log.Info().Msgf("Session keys rotated. SessionID=%s", sessionID)
+		}
+	}
+}
+
+func (m *connectionManager) sendSessionRekey(ctx context.Context, channel p2p.ChannelSender, consumerID identity.Identity, sessionID session.ID, config ConsumerConfig) error {
+	data, err := json.Marshal(config)
+	if err != nil {
+		return fmt.Errorf("could not marshal session config: %w", err)
+	}
+
+	msg := &pb.SessionRekey{
+		ConsumerID: consumerID.Address,
+		SessionID:  string(sessionID),
+		Config:     data,
+	}
+	log.Debug().Msgf("Sending P2P message to %q for SessionID=%s", p2p.TopicSessionRekey, sessionID)
+	ctx, cancel := context.WithTimeout(ctx, m.config.KeyRotation.SendTimeout)
+	defer cancel()
+	_, err = channel.Send(ctx, p2p.TopicSessionRekey, p2p.ProtoMessage(msg))
+	if err != nil {
+		return fmt.Errorf("could not send session rekey request: %w", err)
+	}
+	return nil
+}
+
 func (m *connectionManager) currentCtx() context.Context {
 	m.ctxLock.RLock()
 	defer m.ctxLock.RUnlock()
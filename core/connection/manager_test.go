@@ -154,6 +154,7 @@ func (tc *testContext) SetupTest() {
 		tc.statsReportInterval,
 		&mockValidator{},
 		tc.mockP2P,
+		func(id identity.Identity) identity.Signer { return &identity.SignerFake{} },
 	)
 	tc.connManager.timeGetter = func() time.Time {
 		return tc.mockTime
@@ -550,6 +551,59 @@ func (tc *testContext) Test_ManagerNotifiesAboutSuccessfulConnection() {
 	assert.Equal(tc.T(), expectedStatusMsg, tc.mockP2P.ch.getSentMsg())
 }
 
+func (tc *testContext) Test_ManagerResumesSessionOnLocalAddressChange() {
+	tc.stubPublisher.Clear()
+	tc.connManager.config.Resume = ResumeConfig{
+		CheckInterval: 10 * time.Millisecond,
+		Timeout:       time.Second,
+	}
+	var addrsLock sync.Mutex
+	addrs := []string{"192.168.1.2"}
+	tc.connManager.localAddrs = func() ([]string, error) {
+		addrsLock.Lock()
+		defer addrsLock.Unlock()
+		return addrs, nil
+	}
+	serviceConnUpdated := make(chan *net.UDPConn, 1)
+	tc.fakeConnectionFactory.mockConnection.onUpdateServiceConn = func(conn *net.UDPConn) {
+		serviceConnUpdated <- conn
+	}
+
+	err := tc.connManager.Connect(consumerID, consumerID, activeProposal, ConnectParams{})
+	assert.NoError(tc.T(), err)
+
+	waitABit()
+	assert.Equal(tc.T(), 0, tc.connManager.Status().Resumes)
+
+	addrsLock.Lock()
+	addrs = []string{"10.0.0.5"}
+	addrsLock.Unlock()
+
+	assert.Eventually(tc.T(), func() bool {
+		return tc.connManager.Status().Resumes == 1
+	}, 2*time.Second, 10*time.Millisecond)
+
+	select {
+	case conn := <-serviceConnUpdated:
+		assert.NotNil(tc.T(), conn)
+	case <-time.After(time.Second):
+		assert.Fail(tc.T(), "service connection of the tunnel was not updated")
+	}
+
+	resume := tc.mockP2P.ch.getResumeMsg()
+	assert.Equal(tc.T(), string(establishedSessionID), resume.GetSessionID())
+	assert.Equal(tc.T(), consumerID.Address, resume.GetConsumerID())
+	assert.NotEmpty(tc.T(), resume.GetSignature())
+
+	var resumedEvent *StubPublisherEvent
+	for _, v := range tc.stubPublisher.GetEventHistory() {
+		if v.calledWithTopic == AppTopicConnectionSession && v.calledWithData.(AppEventConnectionSession).Status == SessionResumedStatus {
+			resumedEvent = &v
+		}
+	}
+	assert.NotNil(tc.T(), resumedEvent)
+}
+
 func TestConnectionManagerSuite(t *testing.T) {
 	suite.Run(t, new(testContext))
 }
@@ -637,6 +691,29 @@ func (mpm *mockPaymentMethod) GetRate() market.PaymentRate {
 	return mpm.rate
 }
 
+func (tc *testContext) Test_ManagerPublishesProviderDrain() {
+	tc.stubPublisher.Clear()
+
+	err := tc.connManager.Connect(consumerID, consumerID, activeProposal, ConnectParams{})
+	assert.NoError(tc.T(), err)
+
+	handler := tc.mockP2P.ch.getHandler(p2p.TopicSessionDrain)
+	assert.NotNil(tc.T(), handler)
+
+	deadline := time.Now().Add(5 * time.Minute).Truncate(time.Second)
+	err = handler(&mockP2PContext{req: p2p.ProtoMessage(&pb.SessionDrain{Deadline: deadline.Unix()})})
+	assert.NoError(tc.T(), err)
+	assert.True(tc.T(), deadline.Equal(tc.connManager.Status().ProviderDrainDeadline))
+
+	var drainEvent *StubPublisherEvent
+	for _, v := range tc.stubPublisher.GetEventHistory() {
+		if v.calledWithTopic == AppTopicConnectionSession && v.calledWithData.(AppEventConnectionSession).Status == SessionProviderDrainingStatus {
+			drainEvent = &v
+		}
+	}
+	assert.NotNil(tc.T(), drainEvent)
+}
+
 type mockP2PDialer struct {
 	ch *mockP2PChannel
 }
@@ -646,8 +723,16 @@ func (m mockP2PDialer) Dial(ctx context.Context, consumerID identity.Identity, p
 }
 
 type mockP2PChannel struct {
-	status connectivity.StatusMessage
-	lock   sync.Mutex
+	status   connectivity.StatusMessage
+	resume   *pb.SessionResume
+	handlers map[string]p2p.HandlerFunc
+	lock     sync.Mutex
+}
+
+func (m *mockP2PChannel) getHandler(topic string) p2p.HandlerFunc {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.handlers[topic]
 }
 
 func (m *mockP2PChannel) Conn() *net.UDPConn {
@@ -660,6 +745,12 @@ func (m *mockP2PChannel) getSentMsg() connectivity.StatusMessage {
 	return m.status
 }
 
+func (m *mockP2PChannel) getResumeMsg() *pb.SessionResume {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.resume
+}
+
 func (m *mockP2PChannel) Send(_ context.Context, topic string, msg *p2p.Message) (*p2p.Message, error) {
 	switch topic {
 	case p2p.TopicSessionCreate:
@@ -682,13 +773,59 @@ func (m *mockP2PChannel) Send(_ context.Context, topic string, msg *p2p.Message)
 
 		return p2p.ProtoMessage(&res), nil
 	case p2p.TopicSessionAcknowledge:
+		return nil, nil
+	case p2p.TopicSessionResume:
+		var res pb.SessionResume
+		msg.UnmarshalProto(&res)
+
+		m.lock.Lock()
+		m.resume = &res
+		m.lock.Unlock()
+
 		return nil, nil
 	}
 
 	return nil, errors.New("unexpected error")
 }
 
+func (m *mockP2PChannel) Publish(ctx context.Context, topic string, msg *p2p.Message) error {
+	return nil
+}
+
+func (m *mockP2PChannel) OpenStream(ctx context.Context, topic string) (p2p.Stream, error) {
+	return nil, errors.New("not implemented")
+}
+
 func (m *mockP2PChannel) Handle(topic string, handler p2p.HandlerFunc) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if m.handlers == nil {
+		m.handlers = make(map[string]p2p.HandlerFunc)
+	}
+	m.handlers[topic] = handler
+}
+
+type mockP2PContext struct {
+	req *p2p.Message
+}
+
+func (m *mockP2PContext) Request() *p2p.Message {
+	return m.req
+}
+
+func (m *mockP2PContext) Error(err error) error {
+	return err
+}
+
+func (m *mockP2PContext) OkWithReply(_ *p2p.Message) error {
+	return nil
+}
+
+func (m *mockP2PContext) OK() error {
+	return nil
+}
+
+func (m *mockP2PChannel) HandleStream(topic string, handler p2p.StreamHandlerFunc) {
 }
 
 func (m *mockP2PChannel) ServiceConn() *net.UDPConn {
@@ -701,6 +838,10 @@ func (m *mockP2PChannel) Close() error {
 	return nil
 }
 
+func (m *mockP2PChannel) Stats() p2p.ChannelStats {
+	return p2p.ChannelStats{}
+}
+
 type mockValidator struct {
 	errorToReturn error
 }
@@ -0,0 +1,187 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package connection
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/p2p"
+	"github.com/mysteriumnetwork/node/pb"
+	"github.com/mysteriumnetwork/node/session"
+	"github.com/rs/zerolog/log"
+)
+
+// resumeLoop watches local network addresses and once any of them is gone (e.g. after switching
+// from Wi-Fi to LTE or getting new DHCP lease) resumes the session over a new p2p channel to the same provider.
+func (m *connectionManager) resumeLoop(conn Connection, channel *p2p.SwitchableChannel, consumerID, providerID identity.Identity, serviceType string, contact p2p.ContactDefinition, sessionID session.ID) {
+	if m.config.Resume.CheckInterval <= 0 {
+		return
+	}
+	updater, ok := conn.(ServiceConnUpdater)
+	if !ok {
+		log.Info().Msgf("Connection of session %s can not be moved to another p2p channel, session will not be resumed on network change", sessionID)
+		return
+	}
+
+	addrs, err := m.localAddrs()
+	if err != nil {
+		log.Err(err).Msg("Could not get local addresses, session will not be resumed on network change")
+		return
+	}
+
+	ctx := m.currentCtx()
+	for {
+		select {
+		case <-ctx.Done():
+			log.Debug().Msgf("Stopping session resume: %v", ctx.Err())
+			return
+		case <-time.After(m.config.Resume.CheckInterval):
+		}
+
+		current, err := m.localAddrs()
+		if err != nil {
+			log.Warn().Err(err).Msg("Could not get local addresses")
+			continue
+		}
+		if !addrsRemoved(addrs, current) {
+			addrs = current
+			continue
+		}
+
+		log.Info().Msgf("Local addresses changed from %v to %v, resuming session %s", addrs, current, sessionID)
+		if err := m.resumeSession(ctx, conn, updater, channel, consumerID, providerID, serviceType, contact, sessionID); err != nil {
+			// Addresses are kept, so resume is retried on next check.
+			log.Err(err).Msgf("Could not resume session %s", sessionID)
+			continue
+		}
+		addrs = current
+	}
+}
+
+// resumeSession re-runs traversal to the provider, moves the session to the new p2p channel
+// and the tunnel to its service connection once provider accepts the resume.
+func (m *connectionManager) resumeSession(ctx context.Context, conn Connection, updater ServiceConnUpdater, channel *p2p.SwitchableChannel, consumerID, providerID identity.Identity, serviceType string, contact p2p.ContactDefinition, sessionID session.ID) error {
+	ctx, cancel := context.WithTimeout(ctx, m.config.Resume.Timeout)
+	defer cancel()
+
+	newChannel, err := m.p2pDialer.Dial(ctx, consumerID, providerID, serviceType, contact)
+	if err != nil {
+		return fmt.Errorf("could not create p2p channel: %w", err)
+	}
+	serviceConn := newChannel.ServiceConn()
+	if serviceConn == nil {
+		newChannel.Close()
+		return errors.New("new p2p channel has no service connection")
+	}
+	if channelUpdater, ok := conn.(ChannelConnUpdater); ok {
+		if err := channelUpdater.UpdateChannelConn(newChannel.Conn()); err != nil {
+			serviceConn.Close()
+			newChannel.Close()
+			return fmt.Errorf("could not update channel connection: %w", err)
+		}
+	}
+
+	// Switch first, so that handlers are in place once provider starts using new channel.
+	oldChannel := channel.Switch(newChannel)
+	if err := m.sendSessionResume(ctx, newChannel, consumerID, sessionID); err != nil {
+		channel.Switch(oldChannel)
+		serviceConn.Close()
+		newChannel.Close()
+		return err
+	}
+	if err := oldChannel.Close(); err != nil {
+		log.Warn().Err(err).Msg("Could not close previous p2p channel")
+	}
+
+	// Provider has moved its side of the tunnel already, so it has to follow.
+	if err := updater.UpdateServiceConn(serviceConn); err != nil {
+		return fmt.Errorf("could not move tunnel to the new service connection: %w", err)
+	}
+
+	m.setStatus(func(status *Status) {
+		status.ResumedAt = m.timeGetter()
+		status.Resumes++
+	})
+	m.eventPublisher.Publish(AppTopicConnectionSession, AppEventConnectionSession{
+		Status:      SessionResumedStatus,
+		SessionInfo: m.Status(),
+	})
+	log.Info().Msgf("Session %s resumed over new p2p channel", sessionID)
+	return nil
+}
+
+func (m *connectionManager) sendSessionResume(ctx context.Context, channel p2p.ChannelSender, consumerID identity.Identity, sessionID session.ID) error {
+	timestamp := m.timeGetter().Unix()
+	signature, err := m.signerFactory(consumerID).Sign(session.ResumeMessage(sessionID, timestamp))
+	if err != nil {
+		return fmt.Errorf("could not sign session resume request: %w", err)
+	}
+
+	msg := &pb.SessionResume{
+		ConsumerID: consumerID.Address,
+		SessionID:  string(sessionID),
+		Timestamp:  timestamp,
+		Signature:  signature.Bytes(),
+	}
+	log.Debug().Msgf("Sending P2P message to %q for SessionID=%s", p2p.TopicSessionResume, sessionID)
+	_, err = channel.Send(ctx, p2p.TopicSessionResume, p2p.ProtoMessage(msg))
+	if err != nil {
+		return fmt.Errorf("could not send session resume request: %w", err)
+	}
+	return nil
+}
+
+// localIPv4Addrs returns IPv4 addresses of local network interfaces used to detect network changes.
+func localIPv4Addrs() ([]string, error) {
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return nil, err
+	}
+
+	var res []string
+	for _, addr := range addrs {
+		ipNet, ok := addr.(*net.IPNet)
+		if !ok || ipNet.IP.To4() == nil || ipNet.IP.IsLoopback() || ipNet.IP.IsLinkLocalUnicast() {
+			continue
+		}
+		res = append(res, ipNet.IP.String())
+	}
+	return res, nil
+}
+
+// addrsRemoved checks whether any of previous addresses is missing from current ones.
+func addrsRemoved(previous, current []string) bool {
+	for _, p := range previous {
+		found := false
+		for _, c := range current {
+			if p == c {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return true
+		}
+	}
+	return false
+}
@@ -20,6 +20,7 @@ package connection
 import (
 	"context"
 	"errors"
+	"net"
 	"sync"
 
 	"github.com/mysteriumnetwork/node/communication"
@@ -127,6 +128,7 @@ func (c *connectionFactoryFake) CreateConnection(serviceType string) (Connection
 		onStartReportStats:  c.mockConnection.onStartReportStats,
 		fakeProcess:         sync.WaitGroup{},
 		stopBlock:           c.mockConnection.stopBlock,
+		onUpdateServiceConn: c.mockConnection.onUpdateServiceConn,
 	}
 
 	return &copy, nil
@@ -141,6 +143,7 @@ type connectionMock struct {
 	onStartReportStats  Statistics
 	fakeProcess         sync.WaitGroup
 	stopBlock           chan struct{}
+	onUpdateServiceConn func(conn *net.UDPConn)
 	sync.RWMutex
 }
 
@@ -171,6 +174,13 @@ func (foc *connectionMock) Start(ctx context.Context, connectionParams ConnectOp
 	return nil
 }
 
+func (foc *connectionMock) UpdateServiceConn(conn *net.UDPConn) error {
+	if foc.onUpdateServiceConn != nil {
+		foc.onUpdateServiceConn(conn)
+	}
+	return nil
+}
+
 func (foc *connectionMock) Wait() error {
 	foc.fakeProcess.Wait()
 	return nil
@@ -0,0 +1,346 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package diagnostics
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/mysteriumnetwork/node/core/state/event"
+	"github.com/mysteriumnetwork/node/firewall/ipset"
+	"github.com/mysteriumnetwork/node/firewall/iptables"
+	"github.com/mysteriumnetwork/node/firewall/nftables"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/identity/registry"
+	"github.com/mysteriumnetwork/node/nat/stun"
+)
+
+const (
+	// MaxBlockAge is an age of the latest block after which ethereum RPC is considered to be out of sync.
+	MaxBlockAge = 10 * time.Minute
+	// ClockSkewWarning is a clock difference with the Mysterium API which is reported as a warning.
+	ClockSkewWarning = time.Minute
+	// ClockSkewFailure is a clock difference with the Mysterium API which is reported as a failure.
+	ClockSkewFailure = 10 * time.Minute
+	// DiskSpaceWarning is a free space in the data directory below which a warning is reported.
+	DiskSpaceWarning = 1 << 30
+	// DiskSpaceFailure is a free space in the data directory below which a failure is reported.
+	DiskSpaceFailure = 100 << 20
+)
+
+// ContextDialer dials network connections, e.g. net.Dialer or a dialer of an upstream proxy.
+type ContextDialer interface {
+	DialContext(ctx context.Context, network, address string) (net.Conn, error)
+}
+
+type httpDoer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// TCPCheck checks if a TCP connection can be established to the given address with the dialer.
+func TCPCheck(name string, dialer ContextDialer, address, hint string) Check {
+	return Check{
+		Name: name,
+		Run: func(ctx context.Context) Result {
+			conn, err := dialer.DialContext(ctx, "tcp", address)
+			if err != nil {
+				return Fail(fmt.Sprintf("%s is unreachable: %v", address, err), hint)
+			}
+			conn.Close()
+			return Pass(fmt.Sprintf("%s is reachable", address))
+		},
+	}
+}
+
+// HTTPCheck checks if an HTTP service at the given address responds without a server error.
+func HTTPCheck(name string, client httpDoer, address, hint string) Check {
+	return Check{
+		Name: name,
+		Run: func(ctx context.Context) Result {
+			res, err := get(ctx, client, address)
+			if err != nil {
+				return Fail(fmt.Sprintf("%s is unreachable: %v", address, err), hint)
+			}
+			if res.StatusCode >= http.StatusInternalServerError {
+				return Fail(fmt.Sprintf("%s responded with %s", address, res.Status), hint)
+			}
+			return Pass(fmt.Sprintf("%s is reachable", address))
+		},
+	}
+}
+
+// ClockSkewCheck compares local clock with the Date header returned by an HTTP service at the given address.
+func ClockSkewCheck(client httpDoer, address string) Check {
+	const hint = "Synchronize the system clock, e.g. enable NTP time synchronization"
+	return Check{
+		Name: "clock",
+		Run: func(ctx context.Context) Result {
+			res, err := get(ctx, client, address)
+			if err != nil {
+				return Warn(fmt.Sprintf("could not get the reference time: %v", err), "Check connectivity to "+address)
+			}
+			remote, err := http.ParseTime(res.Header.Get("Date"))
+			if err != nil {
+				return Warn("reference time is not available", "Check connectivity to "+address)
+			}
+
+			skew := time.Since(remote).Round(time.Second)
+			if skew < 0 {
+				skew = -skew
+			}
+			message := fmt.Sprintf("clock skew is %s", skew)
+			switch {
+			case skew > ClockSkewFailure:
+				return Fail(message, hint)
+			case skew > ClockSkewWarning:
+				return Warn(message, hint)
+			default:
+				return Pass(message)
+			}
+		},
+	}
+}
+
+func get(ctx context.Context, client httpDoer, address string) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodGet, address, nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	res.Body.Close()
+	return res, nil
+}
+
+type feesProvider interface {
+	FetchRegistrationFees() (registry.FeesResponse, error)
+}
+
+// TransactorCheck checks if transactor is able to quote the registration fees.
+func TransactorCheck(fees feesProvider) Check {
+	return Check{
+		Name: "transactor",
+		Run: func(_ context.Context) Result {
+			response, err := fees.FetchRegistrationFees()
+			if err != nil {
+				return Fail(fmt.Sprintf("could not fetch registration fees: %v", err), "Check connectivity to the transactor or the --transactor.address flag")
+			}
+			return Pass(fmt.Sprintf("registration fee is %d", response.Fee))
+		},
+	}
+}
+
+// LatestBlockHeader fetches the header of the latest ethereum block.
+type LatestBlockHeader func(ctx context.Context) (*types.Header, error)
+
+// EthereumCheck checks if ethereum RPC is reachable and in sync.
+func EthereumCheck(latest LatestBlockHeader) Check {
+	return Check{
+		Name: "ethereum",
+		Run: func(ctx context.Context) Result {
+			header, err := latest(ctx)
+			if err != nil {
+				return Fail(fmt.Sprintf("could not fetch the latest block: %v", err), "Check connectivity to the ethereum RPC or the --ether.client.rpc flag")
+			}
+
+			age := time.Since(time.Unix(int64(header.Time), 0)).Round(time.Second)
+			if age > MaxBlockAge {
+				return Warn(fmt.Sprintf("latest block %s is %s old", header.Number, age), "Ethereum RPC node is out of sync, use a different one with the --ether.client.rpc flag")
+			}
+			return Pass(fmt.Sprintf("latest block is %s", header.Number))
+		},
+	}
+}
+
+type natTypeProvider interface {
+	NATType() stun.NATType
+}
+
+type stateProvider interface {
+	GetState() event.State
+}
+
+// NATCheck checks the NAT type and NAT traversal status.
+func NATCheck(detector natTypeProvider, state stateProvider) Check {
+	const hint = "Forward UDP ports to the node or enable UPnP on the router"
+	return Check{
+		Name: "nat",
+		Run: func(_ context.Context) Result {
+			natType := detector.NATType()
+			message := fmt.Sprintf("NAT type is %s", natType)
+
+			result := Pass(message)
+			switch natType {
+			case stun.TypeSymmetric:
+				result = Warn(message+", consumers behind NAT will not be able to connect", hint)
+			case stun.TypeUnknown:
+				result = Warn(message, "Check connectivity to the STUN servers or the --stun.servers flag")
+			}
+
+			status := state.GetState().NATStatus
+			if status.Status == "failure" {
+				result = Warn(fmt.Sprintf("%s, NAT traversal failed: %s", message, status.Error), hint)
+			}
+			return result
+		},
+	}
+}
+
+// FirewallCheck checks if a firewall backend used by the node is available.
+func FirewallCheck() Check {
+	return Check{
+		Name: "firewall",
+		Run: func(_ context.Context) Result {
+			if runtime.GOOS != "linux" {
+				return Pass(fmt.Sprintf("system firewall is used on %s", runtime.GOOS))
+			}
+
+			if nftables.Available() {
+				return Pass("nftables is used: " + version(nftables.Exec("--version")))
+			}
+			iptablesVersion, err := iptables.Exec("--version")
+			if err != nil {
+				return Fail(fmt.Sprintf("neither nftables nor iptables is available: %v", err), "Install nftables or iptables")
+			}
+			if _, err := ipset.Exec(ipset.OpVersion()); err != nil {
+				return Warn("iptables is used, but ipset is not available", "Install ipset, it is required by the incoming traffic firewall")
+			}
+			return Pass("iptables is used: " + version(iptablesVersion, nil))
+		},
+	}
+}
+
+func version(output []string, _ error) string {
+	return strings.TrimSpace(strings.Join(output, " "))
+}
+
+// wireguardModulePath is present when WireGuard kernel module is loaded.
+var wireguardModulePath = "/sys/module/wireguard"
+
+// WireGuardCheck checks if WireGuard kernel module is available.
+func WireGuardCheck() Check {
+	return Check{
+		Name: "wireguard",
+		Run: func(_ context.Context) Result {
+			if runtime.GOOS != "linux" {
+				return Pass("userspace implementation is used")
+			}
+			if _, err := os.Stat(wireguardModulePath); err != nil {
+				return Warn("kernel module is not loaded, userspace implementation is used", "Install WireGuard kernel module for better performance")
+			}
+			return Pass("kernel module is loaded")
+		},
+	}
+}
+
+type binaryChecker interface {
+	Check() error
+	BinaryPath() string
+}
+
+// OpenVPNCheck checks if openvpn binary is usable.
+func OpenVPNCheck(openvpn binaryChecker) Check {
+	return Check{
+		Name: "openvpn",
+		Run: func(_ context.Context) Result {
+			if err := openvpn.Check(); err != nil {
+				return Warn(fmt.Sprintf("openvpn binary %q is not usable: %v", openvpn.BinaryPath(), err), "Install openvpn or point --openvpn.binary flag to it, otherwise openvpn service is not available")
+			}
+			return Pass(fmt.Sprintf("openvpn binary %q is usable", openvpn.BinaryPath()))
+		},
+	}
+}
+
+// DiskSpaceCheck checks available disk space in the given directory.
+func DiskSpaceCheck(dir string) Check {
+	const hint = "Free up disk space, the node stores its database and keystore in the data directory"
+	return Check{
+		Name: "disk",
+		Run: func(_ context.Context) Result {
+			free, err := freeSpace(dir)
+			if err != nil {
+				return Fail(fmt.Sprintf("could not check free space in %s: %v", dir, err), "Check that the data directory exists and is accessible")
+			}
+
+			message := fmt.Sprintf("%d MB free in %s", free>>20, dir)
+			switch {
+			case free < DiskSpaceFailure:
+				return Fail(message, hint)
+			case free < DiskSpaceWarning:
+				return Warn(message, hint)
+			default:
+				return Pass(message)
+			}
+		},
+	}
+}
+
+type identityLister interface {
+	GetIdentities() []identity.Identity
+}
+
+type registrationStatusProvider interface {
+	GetRegistrationStatus(id identity.Identity) (registry.RegistrationStatus, error)
+}
+
+// IdentityCheck checks the registration status of node identities.
+func IdentityCheck(identities identityLister, statuses registrationStatusProvider) Check {
+	const hint = "Register the identity via tequilapi or the cli"
+	return Check{
+		Name: "identity",
+		Run: func(_ context.Context) Result {
+			ids := identities.GetIdentities()
+			if len(ids) == 0 {
+				return Warn("no identities exist", "Create an identity via tequilapi or the cli")
+			}
+
+			result := Pass("")
+			var messages []string
+			for _, id := range ids {
+				status, err := statuses.GetRegistrationStatus(id)
+				if err != nil {
+					result = result.worse(Fail("", "Check connectivity to the ethereum RPC"))
+					messages = append(messages, fmt.Sprintf("%s: could not get the registration status: %v", id.Address, err))
+					continue
+				}
+				if !status.Registered() {
+					result = result.worse(Warn("", hint))
+				}
+				messages = append(messages, fmt.Sprintf("%s: %s", id.Address, status))
+			}
+			result.Message = strings.Join(messages, ", ")
+			return result
+		},
+	}
+}
+
+func (r Result) worse(other Result) Result {
+	if r.Status.Worse(other.Status) != r.Status {
+		return other
+	}
+	return r
+}
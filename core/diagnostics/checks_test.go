@@ -0,0 +1,248 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package diagnostics
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/mysteriumnetwork/node/core/state/event"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/identity/registry"
+	"github.com/mysteriumnetwork/node/nat/stun"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTCPCheck(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	address := listener.Addr().String()
+
+	result := TCPCheck("broker", &net.Dialer{}, address, "hint").Run(context.Background())
+	assert.Equal(t, StatusPass, result.Status)
+
+	listener.Close()
+	result = TCPCheck("broker", &net.Dialer{}, address, "hint").Run(context.Background())
+	assert.Equal(t, StatusFail, result.Status)
+	assert.Equal(t, "hint", result.Hint)
+}
+
+func TestTCPCheck_UsesGivenDialer(t *testing.T) {
+	dialer := &mockDialer{err: errors.New("proxy refused")}
+
+	result := TCPCheck("broker", dialer, "broker.test:4222", "hint").Run(context.Background())
+	assert.Equal(t, StatusFail, result.Status)
+	assert.Contains(t, result.Message, "proxy refused")
+	assert.Equal(t, "broker.test:4222", dialer.address)
+}
+
+func TestHTTPCheck(t *testing.T) {
+	status := http.StatusNotFound
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	result := HTTPCheck("api", http.DefaultClient, server.URL, "hint").Run(context.Background())
+	assert.Equal(t, StatusPass, result.Status)
+
+	status = http.StatusBadGateway
+	result = HTTPCheck("api", http.DefaultClient, server.URL, "hint").Run(context.Background())
+	assert.Equal(t, StatusFail, result.Status)
+	assert.Contains(t, result.Message, "502")
+}
+
+func TestClockSkewCheck(t *testing.T) {
+	tests := map[string]struct {
+		offset time.Duration
+		status Status
+	}{
+		"in sync":       {offset: 0, status: StatusPass},
+		"slightly off":  {offset: -5 * time.Minute, status: StatusWarn},
+		"far in future": {offset: time.Hour, status: StatusFail},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Date", time.Now().Add(test.offset).UTC().Format(http.TimeFormat))
+			}))
+			defer server.Close()
+
+			result := ClockSkewCheck(http.DefaultClient, server.URL).Run(context.Background())
+			assert.Equal(t, test.status, result.Status, result.Message)
+		})
+	}
+}
+
+type mockFeesProvider struct {
+	fees registry.FeesResponse
+	err  error
+}
+
+func (m *mockFeesProvider) FetchRegistrationFees() (registry.FeesResponse, error) {
+	return m.fees, m.err
+}
+
+func TestTransactorCheck(t *testing.T) {
+	result := TransactorCheck(&mockFeesProvider{fees: registry.FeesResponse{Fee: 100}}).Run(context.Background())
+	assert.Equal(t, StatusPass, result.Status)
+	assert.Equal(t, "registration fee is 100", result.Message)
+
+	result = TransactorCheck(&mockFeesProvider{err: errors.New("boom")}).Run(context.Background())
+	assert.Equal(t, StatusFail, result.Status)
+	assert.NotEmpty(t, result.Hint)
+}
+
+type mockNAT struct {
+	natType stun.NATType
+	status  event.NATStatus
+}
+
+func (m *mockNAT) NATType() stun.NATType {
+	return m.natType
+}
+
+func (m *mockNAT) GetState() event.State {
+	return event.State{NATStatus: m.status}
+}
+
+func TestNATCheck(t *testing.T) {
+	tests := map[string]struct {
+		nat    mockNAT
+		status Status
+	}{
+		"public IP":         {nat: mockNAT{natType: stun.TypeNone}, status: StatusPass},
+		"port restricted":   {nat: mockNAT{natType: stun.TypePortRestrictedCone}, status: StatusPass},
+		"symmetric":         {nat: mockNAT{natType: stun.TypeSymmetric}, status: StatusWarn},
+		"traversal failure": {nat: mockNAT{natType: stun.TypeFullCone, status: event.NATStatus{Status: "failure", Error: "timeout"}}, status: StatusWarn},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			result := NATCheck(&test.nat, &test.nat).Run(context.Background())
+			assert.Equal(t, test.status, result.Status, result.Message)
+		})
+	}
+}
+
+func TestWireGuardCheck(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("kernel module is checked on linux only")
+	}
+	defer func(path string) { wireguardModulePath = path }(wireguardModulePath)
+
+	dir, err := ioutil.TempDir("", "diagnostics")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	wireguardModulePath = filepath.Join(dir, "missing")
+	assert.Equal(t, StatusWarn, WireGuardCheck().Run(context.Background()).Status)
+
+	wireguardModulePath = dir
+	assert.Equal(t, StatusPass, WireGuardCheck().Run(context.Background()).Status)
+}
+
+type mockBinary struct {
+	err error
+}
+
+func (m *mockBinary) Check() error {
+	return m.err
+}
+
+func (m *mockBinary) BinaryPath() string {
+	return "openvpn"
+}
+
+func TestOpenVPNCheck(t *testing.T) {
+	assert.Equal(t, StatusPass, OpenVPNCheck(&mockBinary{}).Run(context.Background()).Status)
+
+	result := OpenVPNCheck(&mockBinary{err: errors.New("not found")}).Run(context.Background())
+	assert.Equal(t, StatusWarn, result.Status)
+	assert.Contains(t, result.Message, "not found")
+}
+
+func TestDiskSpaceCheck(t *testing.T) {
+	dir, err := ioutil.TempDir("", "diagnostics")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	result := DiskSpaceCheck(dir).Run(context.Background())
+	assert.NotEqual(t, "", result.Message)
+
+	result = DiskSpaceCheck(filepath.Join(dir, "missing")).Run(context.Background())
+	assert.Equal(t, StatusFail, result.Status)
+}
+
+func TestIdentityCheck(t *testing.T) {
+	id := identity.FromAddress("0x000000000000000000000000000000000000000a")
+
+	tests := map[string]struct {
+		identities []identity.Identity
+		registry   registry.FakeRegistry
+		status     Status
+	}{
+		"no identities": {
+			status: StatusWarn,
+		},
+		"registered": {
+			identities: []identity.Identity{id},
+			registry:   registry.FakeRegistry{RegistrationStatus: registry.RegisteredProvider},
+			status:     StatusPass,
+		},
+		"unregistered": {
+			identities: []identity.Identity{id},
+			registry:   registry.FakeRegistry{RegistrationStatus: registry.Unregistered},
+			status:     StatusWarn,
+		},
+		"status unknown": {
+			identities: []identity.Identity{id},
+			registry:   registry.FakeRegistry{RegistrationCheckError: errors.New("rpc down")},
+			status:     StatusFail,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			identities := identity.NewIdentityManagerFake(test.identities, identity.Identity{})
+			result := IdentityCheck(identities, &test.registry).Run(context.Background())
+			assert.Equal(t, test.status, result.Status, result.Message)
+			assert.NotEmpty(t, result.Message)
+		})
+	}
+}
+
+type mockDialer struct {
+	address string
+	err     error
+}
+
+func (d *mockDialer) DialContext(_ context.Context, _, address string) (net.Conn, error) {
+	d.address = address
+	return nil, d.err
+}
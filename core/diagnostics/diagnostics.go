@@ -0,0 +1,154 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package diagnostics
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Status is an outcome of a single diagnostic check.
+type Status string
+
+const (
+	// StatusPass means that the dependency works as expected.
+	StatusPass Status = "pass"
+	// StatusWarn means that the node works, but in a degraded mode.
+	StatusWarn Status = "warn"
+	// StatusFail means that the dependency is not usable.
+	StatusFail Status = "fail"
+)
+
+func (s Status) severity() int {
+	switch s {
+	case StatusFail:
+		return 2
+	case StatusWarn:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// Worse returns the more severe of the two statuses.
+func (s Status) Worse(other Status) Status {
+	if other.severity() > s.severity() {
+		return other
+	}
+	return s
+}
+
+// Result is an outcome of a single diagnostic check.
+type Result struct {
+	Name     string
+	Status   Status
+	Message  string
+	Hint     string
+	Duration time.Duration
+}
+
+// Pass creates a passing result.
+func Pass(message string) Result {
+	return Result{Status: StatusPass, Message: message}
+}
+
+// Warn creates a warning result with a remediation hint.
+func Warn(message, hint string) Result {
+	return Result{Status: StatusWarn, Message: message, Hint: hint}
+}
+
+// Fail creates a failed result with a remediation hint.
+func Fail(message, hint string) Result {
+	return Result{Status: StatusFail, Message: message, Hint: hint}
+}
+
+// Check is a single named diagnostic.
+type Check struct {
+	Name string
+	Run  func(ctx context.Context) Result
+}
+
+// Report is an outcome of all diagnostic checks.
+type Report struct {
+	Status    Status
+	StartedAt time.Time
+	Results   []Result
+}
+
+// Runner runs diagnostic checks.
+type Runner struct {
+	timeout time.Duration
+	checks  []Check
+}
+
+// DefaultTimeout is a time given for a single check to complete.
+const DefaultTimeout = 10 * time.Second
+
+// NewRunner creates a runner of given checks, each of them limited by the timeout.
+func NewRunner(timeout time.Duration, checks ...Check) *Runner {
+	return &Runner{
+		timeout: timeout,
+		checks:  checks,
+	}
+}
+
+// Run runs all checks concurrently and returns their results in the order the checks were given.
+func (r *Runner) Run(ctx context.Context) Report {
+	report := Report{
+		Status:    StatusPass,
+		StartedAt: time.Now(),
+		Results:   make([]Result, len(r.checks)),
+	}
+
+	var wg sync.WaitGroup
+	for i, check := range r.checks {
+		wg.Add(1)
+		go func(i int, check Check) {
+			defer wg.Done()
+			report.Results[i] = r.runCheck(ctx, check)
+		}(i, check)
+	}
+	wg.Wait()
+
+	for _, result := range report.Results {
+		report.Status = report.Status.Worse(result.Status)
+	}
+	return report
+}
+
+func (r *Runner) runCheck(ctx context.Context, check Check) Result {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	started := time.Now()
+	done := make(chan Result, 1)
+	go func() {
+		done <- check.Run(ctx)
+	}()
+
+	var result Result
+	select {
+	case result = <-done:
+	case <-ctx.Done():
+		result = Fail("check timed out after "+r.timeout.String(), "Check network connectivity of the node")
+	}
+	result.Name = check.Name
+	result.Duration = time.Since(started)
+	return result
+}
@@ -0,0 +1,88 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package diagnostics
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func staticCheck(name string, result Result) Check {
+	return Check{
+		Name: name,
+		Run: func(_ context.Context) Result {
+			return result
+		},
+	}
+}
+
+func TestRunner_Run(t *testing.T) {
+	runner := NewRunner(time.Second,
+		staticCheck("first", Pass("ok")),
+		staticCheck("second", Warn("degraded", "fix it")),
+		staticCheck("third", Pass("ok")),
+	)
+
+	report := runner.Run(context.Background())
+
+	assert.Equal(t, StatusWarn, report.Status)
+	assert.Len(t, report.Results, 3)
+	assert.Equal(t, "first", report.Results[0].Name)
+	assert.Equal(t, "second", report.Results[1].Name)
+	assert.Equal(t, "fix it", report.Results[1].Hint)
+	assert.Equal(t, "third", report.Results[2].Name)
+}
+
+func TestRunner_Run_WorstStatus(t *testing.T) {
+	runner := NewRunner(time.Second,
+		staticCheck("failing", Fail("broken", "fix it")),
+		staticCheck("warning", Warn("degraded", "fix it")),
+	)
+
+	assert.Equal(t, StatusFail, runner.Run(context.Background()).Status)
+	assert.Equal(t, StatusPass, NewRunner(time.Second).Run(context.Background()).Status)
+}
+
+func TestRunner_Run_Timeout(t *testing.T) {
+	blocked := make(chan struct{})
+	defer close(blocked)
+
+	runner := NewRunner(10*time.Millisecond, Check{
+		Name: "stuck",
+		Run: func(_ context.Context) Result {
+			<-blocked
+			return Pass("ok")
+		},
+	})
+
+	report := runner.Run(context.Background())
+
+	assert.Equal(t, StatusFail, report.Status)
+	assert.Equal(t, "stuck", report.Results[0].Name)
+	assert.Contains(t, report.Results[0].Message, "timed out")
+	assert.NotEmpty(t, report.Results[0].Hint)
+}
+
+func TestStatus_Worse(t *testing.T) {
+	assert.Equal(t, StatusWarn, StatusPass.Worse(StatusWarn))
+	assert.Equal(t, StatusFail, StatusWarn.Worse(StatusFail))
+	assert.Equal(t, StatusFail, StatusFail.Worse(StatusPass))
+}
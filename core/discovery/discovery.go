@@ -110,6 +110,20 @@ func (d *Discovery) Start(ownIdentity identity.Identity, proposal market.Service
 	go d.mainDiscoveryLoop()
 }
 
+// UpdateProposal replaces the announced proposal, e.g. when provider contacts change.
+// Already registered proposal is registered again so that the change reaches the broker
+// without waiting for service restart.
+func (d *Discovery) UpdateProposal(proposal market.ServiceProposal) {
+	d.mu.Lock()
+	d.proposal = proposal
+	registered := d.status == PingProposal
+	d.mu.Unlock()
+
+	if registered {
+		go d.reregisterProposal()
+	}
+}
+
 // Wait wait for proposal announcements to stop / unregister
 func (d *Discovery) Wait() {
 	d.proposalAnnouncementStopped.Wait()
@@ -188,30 +202,47 @@ func (d *Discovery) registerIdentity() {
 	d.changeStatus(WaitingForRegistration)
 }
 
+func (d *Discovery) currentProposal() market.ServiceProposal {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.proposal
+}
+
 func (d *Discovery) registerProposal() {
-	err := d.proposalRegistry.RegisterProposal(d.proposal, d.signer)
+	proposal := d.currentProposal()
+	err := d.proposalRegistry.RegisterProposal(proposal, d.signer)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to register proposal, retrying after 1 min")
 		time.Sleep(1 * time.Minute)
 		d.changeStatus(RegisterProposal)
 		return
 	}
-	d.eventBus.Publish(AppTopicProposalAnnounce, d.proposal)
+	d.eventBus.Publish(AppTopicProposalAnnounce, proposal)
 	d.changeStatus(PingProposal)
 }
 
+func (d *Discovery) reregisterProposal() {
+	proposal := d.currentProposal()
+	if err := d.proposalRegistry.RegisterProposal(proposal, d.signer); err != nil {
+		log.Error().Err(err).Msg("Failed to register updated proposal")
+		return
+	}
+	d.eventBus.Publish(AppTopicProposalAnnounce, proposal)
+}
+
 func (d *Discovery) pingProposal() {
 	time.Sleep(d.proposalPingTTL)
-	err := d.proposalRegistry.PingProposal(d.proposal, d.signer)
+	proposal := d.currentProposal()
+	err := d.proposalRegistry.PingProposal(proposal, d.signer)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to ping proposal")
 	}
-	d.eventBus.Publish(AppTopicProposalAnnounce, d.proposal)
+	d.eventBus.Publish(AppTopicProposalAnnounce, proposal)
 	d.changeStatus(PingProposal)
 }
 
 func (d *Discovery) unregisterProposal() {
-	err := d.proposalRegistry.UnregisterProposal(d.proposal, d.signer)
+	err := d.proposalRegistry.UnregisterProposal(d.currentProposal(), d.signer)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to unregister proposal: ")
 		d.changeStatus(UnregisterProposalFailed)
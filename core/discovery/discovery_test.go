@@ -113,6 +113,40 @@ func TestStartStopUnregisterProposal(t *testing.T) {
 	assert.Equal(t, ProposalUnregistered, actualStatus)
 }
 
+func TestUpdateProposalRegistersUpdatedProposal(t *testing.T) {
+	d := discoveryWithMockedDependencies()
+	d.identityRegistry = &identityregistry.FakeRegistry{RegistrationStatus: identityregistry.RegisteredProvider}
+
+	announced := make(chan market.ServiceProposal, 10)
+	err := d.eventBus.Subscribe(AppTopicProposalAnnounce, func(p market.ServiceProposal) {
+		announced <- p
+	})
+	assert.NoError(t, err)
+
+	d.Start(providerID, serviceProposal)
+	defer d.Stop()
+
+	actualStatus := observeStatus(d, PingProposal)
+	assert.Equal(t, PingProposal, actualStatus)
+
+	updated := serviceProposal
+	updated.ProviderContacts = market.ContactList{{Type: "test-contact"}}
+	d.UpdateProposal(updated)
+
+	for {
+		select {
+		case p := <-announced:
+			if len(p.ProviderContacts) == 1 {
+				assert.Equal(t, updated, p)
+				assert.Equal(t, updated, d.currentProposal())
+				return
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("updated proposal was not announced")
+		}
+	}
+}
+
 func observeStatus(d *Discovery, status Status) Status {
 	for {
 		d.mu.RLock()
@@ -0,0 +1,142 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package filediscovery
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/mysteriumnetwork/node/core/discovery/brokerdiscovery"
+	"github.com/mysteriumnetwork/node/core/discovery/proposal"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+// Repository provides proposals from a local JSON file, which contains a list of service proposals.
+type Repository struct {
+	path          string
+	storage       *brokerdiscovery.ProposalStorage
+	checkInterval time.Duration
+
+	stopOnce sync.Once
+	stopChan chan struct{}
+
+	lastModTime time.Time
+	lastSize    int64
+}
+
+// NewRepository constructs a new proposal repository (backed by the static file).
+func NewRepository(path string, storage *brokerdiscovery.ProposalStorage, checkInterval time.Duration) *Repository {
+	return &Repository{
+		path:          path,
+		storage:       storage,
+		checkInterval: checkInterval,
+		stopChan:      make(chan struct{}),
+	}
+}
+
+// Proposal returns a single proposal by its ID.
+func (r *Repository) Proposal(id market.ProposalID) (*market.ServiceProposal, error) {
+	return r.storage.GetProposal(id)
+}
+
+// Proposals returns proposals matching the filter.
+func (r *Repository) Proposals(filter *proposal.Filter) ([]market.ServiceProposal, error) {
+	return r.storage.FindProposals(*filter)
+}
+
+// Start loads proposals from the file and begins watching it for changes.
+func (r *Repository) Start() error {
+	if _, err := r.reloadIfChanged(); err != nil {
+		return err
+	}
+
+	go r.watchLoop()
+
+	return nil
+}
+
+// Stop ends watching the file for changes.
+func (r *Repository) Stop() {
+	r.stopOnce.Do(func() {
+		close(r.stopChan)
+	})
+}
+
+func (r *Repository) watchLoop() {
+	for {
+		select {
+		case <-r.stopChan:
+			return
+		case <-time.After(r.checkInterval):
+			reloaded, err := r.reloadIfChanged()
+			if err != nil {
+				log.Warn().Err(err).Msgf("Failed to reload proposals from %q, keeping the previous ones", r.path)
+			} else if reloaded {
+				log.Info().Msgf("Reloaded proposals from %q", r.path)
+			}
+		}
+	}
+}
+
+func (r *Repository) reloadIfChanged() (bool, error) {
+	info, err := os.Stat(r.path)
+	if err != nil {
+		return false, errors.Wrapf(err, "could not stat proposals file %q", r.path)
+	}
+
+	if info.ModTime().Equal(r.lastModTime) && info.Size() == r.lastSize {
+		return false, nil
+	}
+
+	proposals, err := readProposals(r.path)
+	if err != nil {
+		return false, err
+	}
+
+	r.storage.Set(proposals)
+	r.lastModTime = info.ModTime()
+	r.lastSize = info.Size()
+	return true, nil
+}
+
+func readProposals(path string) ([]market.ServiceProposal, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not read proposals file %q", path)
+	}
+
+	var proposals []market.ServiceProposal
+	if err := json.Unmarshal(data, &proposals); err != nil {
+		return nil, errors.Wrapf(err, "could not parse proposals file %q", path)
+	}
+
+	supported := make([]market.ServiceProposal, 0, len(proposals))
+	for _, p := range proposals {
+		if !p.IsSupported() {
+			log.Warn().Msgf("Skipping unsupported proposal %v from %q", p.UniqueID(), path)
+			continue
+		}
+		supported = append(supported, p)
+	}
+	return supported, nil
+}
@@ -0,0 +1,167 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package filediscovery
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mysteriumnetwork/node/core/discovery"
+	"github.com/mysteriumnetwork/node/core/discovery/brokerdiscovery"
+	"github.com/mysteriumnetwork/node/core/discovery/proposal"
+	"github.com/mysteriumnetwork/node/eventbus"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/money"
+	"github.com/stretchr/testify/assert"
+)
+
+func init() {
+	market.RegisterServiceDefinitionUnserializer(
+		"mock_service",
+		func(rawDefinition *json.RawMessage) (market.ServiceDefinition, error) {
+			return mockServiceDefinition{}, nil
+		},
+	)
+	market.RegisterPaymentMethodUnserializer(
+		"mock_payment",
+		func(rawDefinition *json.RawMessage) (market.PaymentMethod, error) {
+			return mockPaymentMethod{}, nil
+		},
+	)
+	market.RegisterContactUnserializer("mock_contact",
+		func(rawMessage *json.RawMessage) (market.ContactDefinition, error) {
+			return mockContact{}, nil
+		},
+	)
+}
+
+const (
+	proposalsFirst = `[
+		{"provider_id": "0x1", "service_type": "mock_service", "service_definition": {}, "payment_method_type": "mock_payment", "payment_method": {}, "provider_contacts": [{"type": "mock_contact", "definition": {}}]},
+		{"provider_id": "0x2", "service_type": "unknown", "service_definition": {}, "payment_method_type": "mock_payment", "payment_method": {}, "provider_contacts": [{"type": "mock_contact", "definition": {}}]}
+	]`
+	proposalsSecond = `[
+		{"provider_id": "0x3", "service_type": "mock_service", "service_definition": {}, "payment_method_type": "mock_payment", "payment_method": {}, "provider_contacts": [{"type": "mock_contact", "definition": {}}]}
+	]`
+)
+
+func Test_Repository_StartLoadsSupportedProposals(t *testing.T) {
+	path := writeProposals(t, proposalsFirst)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	repo := NewRepository(path, brokerdiscovery.NewStorage(eventbus.New()), time.Millisecond)
+	err := repo.Start()
+	assert.NoError(t, err)
+	defer repo.Stop()
+
+	proposals, err := repo.Proposals(&proposal.Filter{})
+	assert.NoError(t, err)
+	assert.Len(t, proposals, 1)
+	assert.Equal(t, "0x1", proposals[0].ProviderID)
+
+	p, err := repo.Proposal(market.ProposalID{ProviderID: "0x1", ServiceType: "mock_service"})
+	assert.NoError(t, err)
+	assert.Equal(t, "0x1", p.ProviderID)
+}
+
+func Test_Repository_StartFailsWithoutFile(t *testing.T) {
+	repo := NewRepository("/nonexistent/proposals.json", brokerdiscovery.NewStorage(eventbus.New()), time.Millisecond)
+	assert.Error(t, repo.Start())
+}
+
+func Test_Repository_ReloadsChangedFile(t *testing.T) {
+	path := writeProposals(t, proposalsFirst)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	bus := eventbus.New()
+	events := &eventCollector{}
+	assert.NoError(t, bus.Subscribe(discovery.AppTopicProposalAdded, events.add(discovery.AppTopicProposalAdded)))
+	assert.NoError(t, bus.Subscribe(discovery.AppTopicProposalRemoved, events.add(discovery.AppTopicProposalRemoved)))
+
+	repo := NewRepository(path, brokerdiscovery.NewStorage(bus), time.Millisecond)
+	assert.NoError(t, repo.Start())
+	defer repo.Stop()
+
+	assert.NoError(t, ioutil.WriteFile(path, []byte(proposalsSecond), 0600))
+
+	assert.Eventually(t, func() bool {
+		proposals, _ := repo.Proposals(&proposal.Filter{})
+		return len(proposals) == 1 && proposals[0].ProviderID == "0x3"
+	}, 2*time.Second, 10*time.Millisecond)
+	assert.Eventually(t, func() bool {
+		return events.count(discovery.AppTopicProposalAdded) == 2 && events.count(discovery.AppTopicProposalRemoved) == 1
+	}, 2*time.Second, 10*time.Millisecond)
+}
+
+func writeProposals(t *testing.T, content string) string {
+	dir, err := ioutil.TempDir("", "filediscovery")
+	assert.NoError(t, err)
+
+	path := filepath.Join(dir, "proposals.json")
+	assert.NoError(t, ioutil.WriteFile(path, []byte(content), 0600))
+	return path
+}
+
+type eventCollector struct {
+	lock   sync.Mutex
+	events map[string]int
+}
+
+func (ec *eventCollector) add(topic string) func(market.ServiceProposal) {
+	return func(_ market.ServiceProposal) {
+		ec.lock.Lock()
+		defer ec.lock.Unlock()
+		if ec.events == nil {
+			ec.events = make(map[string]int)
+		}
+		ec.events[topic]++
+	}
+}
+
+func (ec *eventCollector) count(topic string) int {
+	ec.lock.Lock()
+	defer ec.lock.Unlock()
+	return ec.events[topic]
+}
+
+type mockServiceDefinition struct{}
+
+func (service mockServiceDefinition) GetLocation() market.Location {
+	return market.Location{}
+}
+
+type mockPaymentMethod struct{}
+
+func (method mockPaymentMethod) GetPrice() money.Money {
+	return money.Money{}
+}
+
+func (method mockPaymentMethod) GetType() string {
+	return "mock"
+}
+
+func (method mockPaymentMethod) GetRate() market.PaymentRate {
+	return market.PaymentRate{PerTime: time.Minute}
+}
+
+type mockContact struct{}
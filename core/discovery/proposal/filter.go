@@ -35,6 +35,7 @@ type Filter struct {
 	UpperGBPriceBound   *uint64
 	LowerGBPriceBound   *uint64
 	ExcludeUnsupported  bool
+	IPv6                bool
 }
 
 // Matches return flag if filter matches given proposal
@@ -58,6 +59,10 @@ func (filter *Filter) Matches(proposal market.ServiceProposal) bool {
 		conditions = append(conditions, reducer.AccessPolicy(filter.AccessPolicyID, filter.AccessPolicySource))
 	}
 
+	if filter.IPv6 {
+		conditions = append(conditions, reducer.IPv6())
+	}
+
 	if filter.UpperTimePriceBound != nil && filter.LowerTimePriceBound != nil {
 		conditions = append(conditions, reducer.PriceMinute(*filter.LowerTimePriceBound, *filter.UpperTimePriceBound))
 	}
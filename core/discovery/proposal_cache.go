@@ -0,0 +1,168 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package discovery
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/mysteriumnetwork/node/core/discovery/proposal"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/pkg/errors"
+)
+
+const proposalCacheBucket = "proposal_cache"
+
+type cacheStorage interface {
+	Store(bucket string, data interface{}) error
+	GetAllFrom(bucket string, data interface{}) error
+	Delete(bucket string, data interface{}) error
+}
+
+type cachedProposal struct {
+	ID        string `storm:"id"`
+	Proposal  market.ServiceProposal
+	UpdatedAt time.Time
+}
+
+// ProposalCache persists the last seen proposals, so that they can be served after a restart
+// or while the discovery sources are unreachable.
+type ProposalCache struct {
+	storage cacheStorage
+	ttl     time.Duration
+	lock    sync.Mutex
+}
+
+// NewProposalCache creates a proposal cache, which forgets proposals not seen for longer than the given ttl.
+func NewProposalCache(storage cacheStorage, ttl time.Duration) *ProposalCache {
+	return &ProposalCache{
+		storage: storage,
+		ttl:     ttl,
+	}
+}
+
+// Store adds the given proposals to the cache or refreshes them.
+func (pc *ProposalCache) Store(proposals []market.ServiceProposal) error {
+	pc.lock.Lock()
+	defer pc.lock.Unlock()
+
+	return pc.store(proposals)
+}
+
+// Replace stores the given proposals and removes all the others from the cache.
+func (pc *ProposalCache) Replace(proposals []market.ServiceProposal) error {
+	pc.lock.Lock()
+	defer pc.lock.Unlock()
+
+	all, err := pc.all()
+	if err != nil {
+		return err
+	}
+
+	keep := make(map[string]struct{}, len(proposals))
+	for _, p := range proposals {
+		keep[cacheKey(p.UniqueID())] = struct{}{}
+	}
+
+	for i := range all {
+		if _, ok := keep[all[i].ID]; ok {
+			continue
+		}
+		if err := pc.storage.Delete(proposalCacheBucket, &all[i]); err != nil {
+			return errors.Wrap(err, "could not remove cached proposal")
+		}
+	}
+
+	return pc.store(proposals)
+}
+
+// Proposal returns a single cached proposal by its ID.
+func (pc *ProposalCache) Proposal(id market.ProposalID) (*market.ServiceProposal, error) {
+	pc.lock.Lock()
+	defer pc.lock.Unlock()
+
+	all, err := pc.all()
+	if err != nil {
+		return nil, err
+	}
+
+	key := cacheKey(id)
+	for _, cp := range all {
+		if cp.ID == key && !pc.expired(cp) {
+			return &cp.Proposal, nil
+		}
+	}
+	return nil, fmt.Errorf("proposal is not cached: %+v", id)
+}
+
+// Proposals returns cached proposals matching the filter.
+func (pc *ProposalCache) Proposals(filter *proposal.Filter) ([]market.ServiceProposal, error) {
+	pc.lock.Lock()
+	defer pc.lock.Unlock()
+
+	all, err := pc.all()
+	if err != nil {
+		return nil, err
+	}
+
+	var result []market.ServiceProposal
+	for i := range all {
+		if pc.expired(all[i]) {
+			if err := pc.storage.Delete(proposalCacheBucket, &all[i]); err != nil {
+				return nil, errors.Wrap(err, "could not remove expired proposal")
+			}
+			continue
+		}
+		if filter.Matches(all[i].Proposal) {
+			result = append(result, all[i].Proposal)
+		}
+	}
+	return result, nil
+}
+
+func (pc *ProposalCache) store(proposals []market.ServiceProposal) error {
+	now := time.Now().UTC()
+	for _, p := range proposals {
+		cp := cachedProposal{
+			ID:        cacheKey(p.UniqueID()),
+			Proposal:  p,
+			UpdatedAt: now,
+		}
+		if err := pc.storage.Store(proposalCacheBucket, &cp); err != nil {
+			return errors.Wrap(err, "could not cache proposal")
+		}
+	}
+	return nil
+}
+
+func (pc *ProposalCache) all() ([]cachedProposal, error) {
+	var all []cachedProposal
+	if err := pc.storage.GetAllFrom(proposalCacheBucket, &all); err != nil {
+		return nil, errors.Wrap(err, "could not get cached proposals")
+	}
+	return all, nil
+}
+
+func (pc *ProposalCache) expired(cp cachedProposal) bool {
+	return time.Now().UTC().After(cp.UpdatedAt.Add(pc.ttl))
+}
+
+func cacheKey(id market.ProposalID) string {
+	return id.ProviderID + "." + id.ServiceType
+}
@@ -0,0 +1,253 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package discovery
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mysteriumnetwork/node/core/discovery/proposal"
+	"github.com/mysteriumnetwork/node/core/storage/boltdb"
+	"github.com/mysteriumnetwork/node/core/storage/boltdb/boltdbtest"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	cachedProposal1 = market.ServiceProposal{ProviderID: "0x1", ServiceType: "noop", ProviderContacts: market.ContactList{{Type: "mock"}}}
+	cachedProposal2 = market.ServiceProposal{ProviderID: "0x2", ServiceType: "noop", ProviderContacts: market.ContactList{{Type: "mock"}}}
+)
+
+func newTestCache(t *testing.T, ttl time.Duration) (*ProposalCache, func()) {
+	dir := boltdbtest.CreateTempDir(t)
+	bolt, err := boltdb.NewStorage(dir)
+	assert.NoError(t, err)
+
+	return NewProposalCache(bolt, ttl), func() {
+		bolt.Close()
+		boltdbtest.RemoveTempDir(t, dir)
+	}
+}
+
+func providerIDs(proposals []market.ServiceProposal) []string {
+	ids := make([]string, 0)
+	for _, p := range proposals {
+		ids = append(ids, p.ProviderID)
+	}
+	return ids
+}
+
+func TestProposalCache_StoreAndReplace(t *testing.T) {
+	cache, cleanup := newTestCache(t, time.Hour)
+	defer cleanup()
+
+	proposals, err := cache.Proposals(&proposal.Filter{})
+	assert.NoError(t, err)
+	assert.Empty(t, proposals)
+
+	assert.NoError(t, cache.Store([]market.ServiceProposal{cachedProposal1}))
+	assert.NoError(t, cache.Store([]market.ServiceProposal{cachedProposal2}))
+	proposals, err = cache.Proposals(&proposal.Filter{})
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"0x1", "0x2"}, providerIDs(proposals))
+
+	proposals, err = cache.Proposals(&proposal.Filter{ProviderID: "0x2"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"0x2"}, providerIDs(proposals))
+
+	assert.NoError(t, cache.Replace([]market.ServiceProposal{cachedProposal2}))
+	proposals, err = cache.Proposals(&proposal.Filter{})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"0x2"}, providerIDs(proposals))
+
+	p, err := cache.Proposal(cachedProposal2.UniqueID())
+	assert.NoError(t, err)
+	assert.Equal(t, "0x2", p.ProviderID)
+
+	_, err = cache.Proposal(cachedProposal1.UniqueID())
+	assert.Error(t, err)
+}
+
+func TestProposalCache_ForgetsExpiredProposals(t *testing.T) {
+	cache, cleanup := newTestCache(t, time.Millisecond)
+	defer cleanup()
+
+	assert.NoError(t, cache.Store([]market.ServiceProposal{cachedProposal1}))
+	time.Sleep(5 * time.Millisecond)
+
+	proposals, err := cache.Proposals(&proposal.Filter{})
+	assert.NoError(t, err)
+	assert.Empty(t, proposals)
+}
+
+func TestRepository_ServesCacheWhenDelegatesFail(t *testing.T) {
+	cache, cleanup := newTestCache(t, time.Hour)
+	defer cleanup()
+
+	delegate := &mockRepository{proposals: []market.ServiceProposal{cachedProposal1}}
+	repo := NewRepository()
+	repo.Add(delegate)
+	repo.UseCache(cache, time.Hour)
+
+	proposals, stale, err := repo.ProposalsWithStatus(&proposal.Filter{})
+	assert.NoError(t, err)
+	assert.False(t, stale)
+	assert.Equal(t, []string{"0x1"}, providerIDs(proposals))
+	assertCached(t, cache, "0x1")
+
+	delegate.setResult(nil, errors.New("discovery is down"))
+	proposals, stale, err = repo.ProposalsWithStatus(&proposal.Filter{})
+	assert.NoError(t, err)
+	assert.True(t, stale)
+	assert.Equal(t, []string{"0x1"}, providerIDs(proposals))
+
+	p, err := repo.Proposal(cachedProposal1.UniqueID())
+	assert.NoError(t, err)
+	assert.Equal(t, "0x1", p.ProviderID)
+}
+
+func TestRepository_PrefersDelegatesOverRefreshedCache(t *testing.T) {
+	cache, cleanup := newTestCache(t, time.Hour)
+	defer cleanup()
+
+	delegate := &mockRepository{proposals: []market.ServiceProposal{cachedProposal1, cachedProposal2}}
+	repo := NewRepository()
+	repo.Add(delegate)
+	repo.UseCache(cache, time.Hour)
+	repo.refresh()
+
+	delegate.setResult([]market.ServiceProposal{cachedProposal1}, nil)
+	proposals, stale, err := repo.ProposalsWithStatus(&proposal.Filter{})
+	assert.NoError(t, err)
+	assert.False(t, stale)
+	assert.Equal(t, []string{"0x1"}, providerIDs(proposals))
+
+	delegate.setResult(nil, errors.New("discovery is down"))
+	proposals, stale, err = repo.ProposalsWithStatus(&proposal.Filter{ProviderID: "0x2"})
+	assert.NoError(t, err)
+	assert.True(t, stale)
+	assert.Equal(t, []string{"0x2"}, providerIDs(proposals))
+}
+
+func TestRepository_MergesPartialResultsWithCache(t *testing.T) {
+	cache, cleanup := newTestCache(t, time.Hour)
+	defer cleanup()
+
+	outdatedProposal1 := cachedProposal1
+	outdatedProposal1.ProviderContacts = market.ContactList{{Type: "outdated"}}
+	assert.NoError(t, cache.Store([]market.ServiceProposal{outdatedProposal1, cachedProposal2}))
+
+	repo := NewRepository()
+	repo.Add(&mockRepository{proposals: []market.ServiceProposal{cachedProposal1}})
+	repo.Add(&mockRepository{err: errors.New("discovery is down")})
+	repo.UseCache(cache, time.Hour)
+
+	proposals, stale, err := repo.ProposalsWithStatus(&proposal.Filter{})
+	assert.NoError(t, err)
+	assert.True(t, stale)
+	assert.ElementsMatch(t, []string{"0x1", "0x2"}, providerIDs(proposals))
+	for _, p := range proposals {
+		assert.Equal(t, "mock", p.ProviderContacts[0].Type)
+	}
+	assertCached(t, cache, "0x1", "0x2")
+}
+
+func TestRepository_PartialResultsWithoutCachedOnesReturnError(t *testing.T) {
+	cache, cleanup := newTestCache(t, time.Hour)
+	defer cleanup()
+
+	repo := NewRepository()
+	repo.Add(&mockRepository{proposals: []market.ServiceProposal{cachedProposal1}})
+	repo.Add(&mockRepository{err: errors.New("discovery is down")})
+	repo.UseCache(cache, time.Hour)
+
+	proposals, stale, err := repo.ProposalsWithStatus(&proposal.Filter{})
+	assert.Error(t, err)
+	assert.False(t, stale)
+	assert.Equal(t, []string{"0x1"}, providerIDs(proposals))
+	assertCached(t, cache, "0x1")
+}
+
+func TestRepository_WithoutCacheReturnsErrors(t *testing.T) {
+	repo := NewRepository()
+	repo.Add(&mockRepository{err: errors.New("discovery is down")})
+
+	_, stale, err := repo.ProposalsWithStatus(&proposal.Filter{})
+	assert.Error(t, err)
+	assert.False(t, stale)
+}
+
+// assertCached waits for the proposals stored in the background to appear in the cache.
+func assertCached(t *testing.T, cache *ProposalCache, providerIDs ...string) {
+	assert.Eventually(t, func() bool {
+		cached, err := cache.Proposals(&proposal.Filter{})
+		if err != nil || len(cached) != len(providerIDs) {
+			return false
+		}
+		for _, id := range providerIDs {
+			found := false
+			for _, p := range cached {
+				found = found || p.ProviderID == id
+			}
+			if !found {
+				return false
+			}
+		}
+		return true
+	}, 2*time.Second, 10*time.Millisecond)
+}
+
+type mockRepository struct {
+	lock      sync.Mutex
+	proposals []market.ServiceProposal
+	err       error
+}
+
+func (mr *mockRepository) setResult(proposals []market.ServiceProposal, err error) {
+	mr.lock.Lock()
+	defer mr.lock.Unlock()
+	mr.proposals, mr.err = proposals, err
+}
+
+func (mr *mockRepository) Proposal(id market.ProposalID) (*market.ServiceProposal, error) {
+	mr.lock.Lock()
+	defer mr.lock.Unlock()
+	if mr.err != nil {
+		return nil, mr.err
+	}
+	for _, p := range mr.proposals {
+		if p.UniqueID() == id {
+			return &p, nil
+		}
+	}
+	return nil, errors.New("not found")
+}
+
+func (mr *mockRepository) Proposals(filter *proposal.Filter) ([]market.ServiceProposal, error) {
+	mr.lock.Lock()
+	defer mr.lock.Unlock()
+	var result []market.ServiceProposal
+	for _, p := range mr.proposals {
+		if filter.Matches(p) {
+			result = append(result, p)
+		}
+	}
+	return result, mr.err
+}
@@ -56,7 +56,7 @@ var (
 	proposalProvider2Streaming = market.ServiceProposal{
 		ProviderID:        provider2,
 		ServiceType:       serviceTypeStreaming,
-		ServiceDefinition: mockService{Location: locationResidential},
+		ServiceDefinition: mockService{Location: locationResidential, IPv6: true},
 		AccessPolicies:    &[]market.AccessPolicy{accessRuleWhitelist, accessRuleBlacklist},
 	}
 	proposalTimeExpensive = market.ServiceProposal{
@@ -153,12 +153,17 @@ func (mpm *mockPaymentMethod) GetRate() market.PaymentRate {
 
 type mockService struct {
 	Location market.Location
+	IPv6     bool
 }
 
 func (service mockService) GetLocation() market.Location {
 	return service.Location
 }
 
+func (service mockService) SupportsIPv6() bool {
+	return service.IPv6
+}
+
 func conditionAlwaysMatch(_ market.ServiceProposal) bool {
 	return true
 }
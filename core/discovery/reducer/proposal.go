@@ -132,6 +132,14 @@ func AccessPolicy(id, source string) func(market.ServiceProposal) bool {
 	}
 }
 
+// IPv6 filters out proposals which do not provide IPv6 egress
+func IPv6() func(market.ServiceProposal) bool {
+	return func(proposal market.ServiceProposal) bool {
+		service, ok := proposal.ServiceDefinition.(market.IPv6Capable)
+		return ok && service.SupportsIPv6()
+	}
+}
+
 // Unsupported filters out unsupported proposals
 func Unsupported() func(market.ServiceProposal) bool {
 	return func(proposal market.ServiceProposal) bool {
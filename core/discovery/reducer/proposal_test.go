@@ -86,6 +86,15 @@ func Test_AccessPolicy_FiltersByIDAndSource(t *testing.T) {
 	assert.True(t, match(proposalProvider2Streaming))
 }
 
+func Test_IPv6(t *testing.T) {
+	match := IPv6()
+
+	assert.False(t, match(proposalEmpty))
+	assert.False(t, match(proposalProvider1Streaming))
+	assert.False(t, match(proposalProvider1Noop))
+	assert.True(t, match(proposalProvider2Streaming))
+}
+
 func Test_PriceMinute_FiltersByPrice(t *testing.T) {
 	match := PriceMinute(100, 1000000)
 
@@ -19,6 +19,7 @@ package discovery
 
 import (
 	"sync"
+	"time"
 
 	"github.com/mysteriumnetwork/node/core/discovery/proposal"
 	"github.com/mysteriumnetwork/node/market"
@@ -29,11 +30,20 @@ import (
 // repository provides proposals from multiple other repositories.
 type repository struct {
 	delegates []proposal.Repository
+
+	cache           *ProposalCache
+	refreshInterval time.Duration
+	refreshLock     sync.Mutex
+	refreshing      bool
+	stopOnce        sync.Once
+	stopChan        chan struct{}
 }
 
 // NewRepository constructs a new composite repository.
 func NewRepository() *repository {
-	return &repository{}
+	return &repository{
+		stopChan: make(chan struct{}),
+	}
 }
 
 // Add adds a delegate repositories from which proposals can be acquired.
@@ -41,6 +51,30 @@ func (c *repository) Add(repository proposal.Repository) {
 	c.delegates = append(c.delegates, repository)
 }
 
+// UseCache enables keeping proposals in the given cache, which is refreshed in the background every refreshInterval.
+// Cached proposals are served only when the delegates fail.
+func (c *repository) UseCache(cache *ProposalCache, refreshInterval time.Duration) {
+	c.cache = cache
+	c.refreshInterval = refreshInterval
+}
+
+// Start begins refreshing the proposal cache in the background.
+func (c *repository) Start() error {
+	if c.cache == nil {
+		return nil
+	}
+
+	go c.refreshLoop()
+	return nil
+}
+
+// Stop ends refreshing the proposal cache.
+func (c *repository) Stop() {
+	c.stopOnce.Do(func() {
+		close(c.stopChan)
+	})
+}
+
 // Proposal returns a single proposal by its ID.
 func (c *repository) Proposal(id market.ProposalID) (*market.ServiceProposal, error) {
 	allErrors := utils.ErrorCollection{}
@@ -53,11 +87,62 @@ func (c *repository) Proposal(id market.ProposalID) (*market.ServiceProposal, er
 		allErrors.Add(err)
 	}
 
+	if c.cache != nil {
+		serviceProposal, err := c.cache.Proposal(id)
+		if err == nil {
+			log.Warn().Err(allErrors.Error()).Msgf("Serving cached proposal %+v", id)
+			return serviceProposal, nil
+		}
+	}
+
 	return nil, allErrors.Error()
 }
 
 // Proposals returns proposals matching the filter.
 func (c *repository) Proposals(filter *proposal.Filter) ([]market.ServiceProposal, error) {
+	proposals, _, err := c.ProposalsWithStatus(filter)
+	return proposals, err
+}
+
+// ProposalsWithStatus returns proposals matching the filter, indicating whether they were served from an outdated cache.
+// Cached proposals are served only when the delegates fail, so the delegates remain the source of truth.
+// Proposals of the delegates which succeeded take precedence over the cached ones.
+func (c *repository) ProposalsWithStatus(filter *proposal.Filter) (proposals []market.ServiceProposal, stale bool, err error) {
+	proposals, err = c.fetch(filter)
+	if c.cache == nil {
+		return proposals, false, err
+	}
+
+	if len(proposals) > 0 {
+		go c.store(proposals)
+	}
+	if err == nil {
+		return proposals, false, nil
+	}
+
+	cached, cacheErr := c.cache.Proposals(filter)
+	if cacheErr != nil {
+		log.Warn().Err(cacheErr).Msg("Failed to read proposal cache")
+		return proposals, false, err
+	}
+
+	merged := mergeProposals(cached, proposals)
+	if len(merged) == len(proposals) {
+		return proposals, false, err
+	}
+
+	log.Warn().Err(err).Msgf("Serving %d cached proposals along with %d live ones", len(merged)-len(proposals), len(proposals))
+	return merged, true, nil
+}
+
+// store keeps the proposals in the cache, it is called in the background to keep cache writes off the request path.
+func (c *repository) store(proposals []market.ServiceProposal) {
+	if err := c.cache.Store(proposals); err != nil {
+		log.Warn().Err(err).Msg("Failed to cache proposals")
+	}
+}
+
+func (c *repository) fetch(filter *proposal.Filter) ([]market.ServiceProposal, error) {
 	log.Debug().Msgf("Retrieving proposals from %d repositories", len(c.delegates))
 	proposals := make([][]market.ServiceProposal, len(c.delegates))
 	errors := make([]error, len(c.delegates))
@@ -72,10 +157,23 @@ func (c *repository) Proposals(filter *proposal.Filter) ([]market.ServiceProposa
 	}
 	wg.Wait()
 
-	uniqueProposals := make(map[market.ProposalID]market.ServiceProposal)
 	for i, repoProposals := range proposals {
 		log.Trace().Msgf("Retrieved %d proposals from repository %d", len(repoProposals), i)
-		for _, p := range repoProposals {
+	}
+	result := mergeProposals(proposals...)
+
+	allErrors := utils.ErrorCollection{}
+	allErrors.Add(errors...)
+
+	log.Err(allErrors.Error()).Msgf("Returning %d unique proposals", len(result))
+	return result, allErrors.Error()
+}
+
+// mergeProposals returns unique proposals of the given lists, proposals of the later lists replace the earlier ones.
+func mergeProposals(lists ...[]market.ServiceProposal) []market.ServiceProposal {
+	uniqueProposals := make(map[market.ProposalID]market.ServiceProposal)
+	for _, list := range lists {
+		for _, p := range list {
 			uniqueProposals[p.UniqueID()] = p
 		}
 	}
@@ -84,10 +182,50 @@ func (c *repository) Proposals(filter *proposal.Filter) ([]market.ServiceProposa
 	for _, val := range uniqueProposals {
 		result = append(result, val)
 	}
+	return result
+}
 
-	allErrors := utils.ErrorCollection{}
-	allErrors.Add(errors...)
+func (c *repository) refreshLoop() {
+	c.refresh()
+	for {
+		select {
+		case <-c.stopChan:
+			return
+		case <-time.After(c.refreshInterval):
+			c.refresh()
+		}
+	}
+}
 
-	log.Err(allErrors.Error()).Msgf("Returning %d unique proposals", len(result))
-	return result, allErrors.Error()
+// refresh fetches all the proposals from the delegates into the cache, unless the refresh is already in progress.
+func (c *repository) refresh() {
+	c.refreshLock.Lock()
+	if c.refreshing {
+		c.refreshLock.Unlock()
+		return
+	}
+	c.refreshing = true
+	c.refreshLock.Unlock()
+
+	defer func() {
+		c.refreshLock.Lock()
+		c.refreshing = false
+		c.refreshLock.Unlock()
+	}()
+
+	proposals, err := c.fetch(&proposal.Filter{ExcludeUnsupported: true})
+	if err != nil {
+		if len(proposals) > 0 {
+			c.store(proposals)
+		}
+		log.Warn().Err(err).Msg("Failed to refresh proposal cache")
+		return
+	}
+
+	if err := c.cache.Replace(proposals); err != nil {
+		log.Warn().Err(err).Msg("Failed to refresh proposal cache")
+		return
+	}
+
+	log.Debug().Msgf("Proposal cache refreshed with %d proposals", len(proposals))
 }
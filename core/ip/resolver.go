@@ -67,14 +67,20 @@ func (r *ResolverImpl) GetOutboundIPAsString() (string, error) {
 	return ip.String(), nil
 }
 
-// GetOutboundIP returns current outbound IP for current system
+// GetOutboundIP returns current outbound IP for current system.
+// If upstream proxy is used, IP of the interface towards the proxy is returned as all traffic leaves through it.
 func (r *ResolverImpl) GetOutboundIP() (net.IP, error) {
 	ipAddress := net.ParseIP(r.bindAddress)
 	localIPAddress := net.UDPAddr{IP: ipAddress}
 
 	dialer := net.Dialer{LocalAddr: &localIPAddress}
 
-	conn, err := dialer.Dial("udp4", checkAddress)
+	address := checkAddress
+	if r.httpClient != nil && r.httpClient.Proxy() != nil {
+		address = r.httpClient.Proxy().Address()
+	}
+
+	conn, err := dialer.Dial("udp4", address)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to determine outbound IP")
 	}
@@ -45,6 +45,6 @@ func (o *oracleResolver) DetectLocation() (location Location, err error) {
 		return Location{}, errors.Wrap(err, "failed to create request")
 	}
 
-	err = o.httpClient.DoRequestAndParseResponse(request, &location)
+	err = o.httpClient.DoRequestAndParseResponse(requests.WithCallClass(request, requests.CallIdempotent), &location)
 	return location, errors.Wrap(err, "failed to execute request")
 }
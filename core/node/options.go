@@ -77,6 +77,8 @@ type Options struct {
 	Payments OptionsPayments
 
 	MobileConsumer bool
+	// MobileProvider bootstraps provider components on mobile node, services are registered by the mobile bindings.
+	MobileProvider bool
 }
 
 // GetOptions retrieves node options from the app configuration.
@@ -103,6 +105,8 @@ func GetOptions() *Options {
 			MysteriumAPIAddress:   config.GetString(config.FlagAPIAddress),
 			BrokerAddress:         config.GetString(config.FlagBrokerAddress),
 			EtherClientRPC:        config.GetString(config.FlagEtherRPC),
+			ProxyURL:              config.GetString(config.FlagProxyURL),
+			NoProxy:               config.GetStringSlice(config.FlagProxyNoProxy),
 		},
 		Discovery: *GetDiscoveryOptions(),
 		MMN: OptionsMMN{
@@ -133,6 +137,10 @@ func GetOptions() *Options {
 			MaxAllowedPaymentPercentile:        config.GetInt(config.FlagPaymentsMaxAccountantFee),
 			BCTimeout:                          config.GetDuration(config.FlagPaymentsBCTimeout),
 			AccountantPromiseSettlingThreshold: config.GetFloat64(config.FlagPaymentsAccountantPromiseSettleThreshold),
+			AccountantPromiseSettlingPolicy:    config.GetString(config.FlagPaymentsAccountantPromiseSettlePolicy),
+			AccountantPromiseSettlingAmount:    config.GetUInt64(config.FlagPaymentsAccountantPromiseSettleAmount),
+			AccountantPromiseSettlingTime:      config.GetString(config.FlagPaymentsAccountantPromiseSettleTime),
+			AccountantPromiseSettlingMaxFee:    config.GetFloat64(config.FlagPaymentsAccountantPromiseSettleMaxFeeRatio),
 			SettlementTimeout:                  config.GetDuration(config.FlagPaymentsAccountantPromiseSettleTimeout),
 			MystSCAddress:                      config.GetString(config.FlagPaymentsMystSCAddress),
 			ConsumerUpperGBPriceBound:          config.GetUInt64(config.FlagPaymentsConsumerPricePerGBUpperBound),
@@ -186,6 +194,8 @@ func GetDiscoveryOptions() *OptionsDiscovery {
 		PingInterval:  config.GetDuration(config.FlagDiscoveryPingInterval),
 		FetchEnabled:  true,
 		FetchInterval: config.GetDuration(config.FlagDiscoveryFetchInterval),
+		FilePath:      config.GetString(config.FlagDiscoveryFile),
+		CacheTTL:      config.GetDuration(config.FlagDiscoveryCacheTTL),
 	}
 }
 
@@ -27,6 +27,8 @@ const (
 	DiscoveryTypeAPI = DiscoveryType("api")
 	// DiscoveryTypeBroker defines type which discovers proposals through Broker (Mysterium Communication)
 	DiscoveryTypeBroker = DiscoveryType("broker")
+	// DiscoveryTypeFile defines type which discovers proposals from a local static file
+	DiscoveryTypeFile = DiscoveryType("file")
 )
 
 // OptionsDiscovery describes possible parameters of discovery configuration
@@ -36,4 +38,6 @@ type OptionsDiscovery struct {
 	PingInterval  time.Duration
 	FetchEnabled  bool
 	FetchInterval time.Duration
+	FilePath      string
+	CacheTTL      time.Duration
 }
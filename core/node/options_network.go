@@ -28,4 +28,8 @@ type OptionsNetwork struct {
 	BrokerAddress       string
 
 	EtherClientRPC string
+
+	// ProxyURL is upstream proxy used for node control plane traffic, hosts from NoProxy are reached directly.
+	ProxyURL string
+	NoProxy  []string
 }
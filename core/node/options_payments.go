@@ -24,6 +24,10 @@ type OptionsPayments struct {
 	MaxAllowedPaymentPercentile        int
 	BCTimeout                          time.Duration
 	AccountantPromiseSettlingThreshold float64
+	AccountantPromiseSettlingPolicy    string
+	AccountantPromiseSettlingAmount    uint64
+	AccountantPromiseSettlingTime      string
+	AccountantPromiseSettlingMaxFee    float64
 	SettlementTimeout                  time.Duration
 	MystSCAddress                      string
 	ConsumerUpperGBPriceBound          uint64
@@ -143,7 +143,7 @@ func (pr *Oracle) fetchPolicyRules(subscription *policySubscription) error {
 	}
 	req.Header.Add("If-None-Match", subscription.eTag)
 
-	res, err := pr.client.Do(req)
+	res, err := pr.client.Do(requests.WithCallClass(req, requests.CallIdempotent))
 	if err != nil {
 		return errors.Wrapf(err, "failed fetch policy rule %s", subscription.policy)
 	}
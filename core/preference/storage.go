@@ -0,0 +1,173 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package preference
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/mysteriumnetwork/node/core/storage"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/pkg/errors"
+)
+
+const providerPreferencesBucket = "provider_preferences"
+
+type persistentStorage interface {
+	GetValue(bucket string, key interface{}, to interface{}) error
+	SetValue(bucket string, key interface{}, to interface{}) error
+}
+
+// ProviderPreferences holds the providers a consumer has marked as favourite or blocked.
+type ProviderPreferences struct {
+	Favourites []string `json:"favourites"`
+	Blocked    []string `json:"blocked"`
+}
+
+// IsFavourite checks if the given provider is marked as favourite.
+func (pp ProviderPreferences) IsFavourite(providerID string) bool {
+	return contains(pp.Favourites, providerID)
+}
+
+// IsBlocked checks if the given provider is blocked.
+func (pp ProviderPreferences) IsBlocked(providerID string) bool {
+	return contains(pp.Blocked, providerID)
+}
+
+// Storage persists provider preferences per consumer identity.
+type Storage struct {
+	bolt persistentStorage
+	lock sync.Mutex
+}
+
+// NewStorage creates a new provider preferences storage.
+func NewStorage(bolt persistentStorage) *Storage {
+	return &Storage{
+		bolt: bolt,
+	}
+}
+
+// Get returns the provider preferences of the given consumer.
+func (s *Storage) Get(consumerID identity.Identity) (ProviderPreferences, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.get(consumerID)
+}
+
+// IsBlocked checks if the given consumer has blocked the provider.
+func (s *Storage) IsBlocked(consumerID identity.Identity, providerID string) (bool, error) {
+	prefs, err := s.Get(consumerID)
+	if err != nil {
+		return false, err
+	}
+	return prefs.IsBlocked(providerID), nil
+}
+
+// AddFavourite marks the provider as favourite. A blocked provider gets unblocked.
+func (s *Storage) AddFavourite(consumerID identity.Identity, providerID string) error {
+	return s.update(consumerID, func(prefs *ProviderPreferences) {
+		prefs.Blocked = remove(prefs.Blocked, providerID)
+		prefs.Favourites = add(prefs.Favourites, providerID)
+	})
+}
+
+// RemoveFavourite removes the provider from favourites.
+func (s *Storage) RemoveFavourite(consumerID identity.Identity, providerID string) error {
+	return s.update(consumerID, func(prefs *ProviderPreferences) {
+		prefs.Favourites = remove(prefs.Favourites, providerID)
+	})
+}
+
+// Block adds the provider to the blocklist. A favourite provider is removed from favourites.
+func (s *Storage) Block(consumerID identity.Identity, providerID string) error {
+	return s.update(consumerID, func(prefs *ProviderPreferences) {
+		prefs.Favourites = remove(prefs.Favourites, providerID)
+		prefs.Blocked = add(prefs.Blocked, providerID)
+	})
+}
+
+// Unblock removes the provider from the blocklist.
+func (s *Storage) Unblock(consumerID identity.Identity, providerID string) error {
+	return s.update(consumerID, func(prefs *ProviderPreferences) {
+		prefs.Blocked = remove(prefs.Blocked, providerID)
+	})
+}
+
+func (s *Storage) update(consumerID identity.Identity, change func(prefs *ProviderPreferences)) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	prefs, err := s.get(consumerID)
+	if err != nil {
+		return err
+	}
+
+	change(&prefs)
+
+	err = s.bolt.SetValue(providerPreferencesBucket, key(consumerID), prefs)
+	return errors.Wrap(err, "could not store provider preferences")
+}
+
+func (s *Storage) get(consumerID identity.Identity) (ProviderPreferences, error) {
+	var prefs ProviderPreferences
+	err := s.bolt.GetValue(providerPreferencesBucket, key(consumerID), &prefs)
+	if err == storage.ErrNotFound {
+		return ProviderPreferences{Favourites: []string{}, Blocked: []string{}}, nil
+	}
+	if err != nil {
+		return prefs, errors.Wrap(err, "could not get provider preferences")
+	}
+	if prefs.Favourites == nil {
+		prefs.Favourites = []string{}
+	}
+	if prefs.Blocked == nil {
+		prefs.Blocked = []string{}
+	}
+	return prefs, nil
+}
+
+func key(consumerID identity.Identity) string {
+	return strings.ToLower(consumerID.Address)
+}
+
+func contains(list []string, providerID string) bool {
+	for _, id := range list {
+		if strings.EqualFold(id, providerID) {
+			return true
+		}
+	}
+	return false
+}
+
+func add(list []string, providerID string) []string {
+	if contains(list, providerID) {
+		return list
+	}
+	return append(list, providerID)
+}
+
+func remove(list []string, providerID string) []string {
+	result := list[:0]
+	for _, id := range list {
+		if !strings.EqualFold(id, providerID) {
+			result = append(result, id)
+		}
+	}
+	return result
+}
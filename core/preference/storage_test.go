@@ -0,0 +1,80 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package preference
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/mysteriumnetwork/node/core/storage/boltdb"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStorage(t *testing.T) {
+	dir, err := ioutil.TempDir("", "providerPreferencesTest")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	bolt, err := boltdb.NewStorage(dir)
+	assert.NoError(t, err)
+	defer bolt.Close()
+
+	storage := NewStorage(bolt)
+	consumer := identity.FromAddress("0xConsumer")
+	other := identity.FromAddress("0xOther")
+
+	prefs, err := storage.Get(consumer)
+	assert.NoError(t, err)
+	assert.Equal(t, ProviderPreferences{Favourites: []string{}, Blocked: []string{}}, prefs)
+
+	assert.NoError(t, storage.AddFavourite(consumer, "0xProvider1"))
+	assert.NoError(t, storage.AddFavourite(consumer, "0xprovider1"))
+	assert.NoError(t, storage.Block(consumer, "0xProvider2"))
+
+	prefs, err = storage.Get(consumer)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"0xProvider1"}, prefs.Favourites)
+	assert.Equal(t, []string{"0xProvider2"}, prefs.Blocked)
+	assert.True(t, prefs.IsFavourite("0xPROVIDER1"))
+
+	blocked, err := storage.IsBlocked(consumer, "0xProvider2")
+	assert.NoError(t, err)
+	assert.True(t, blocked)
+
+	// preferences are kept per identity
+	blocked, err = storage.IsBlocked(other, "0xProvider2")
+	assert.NoError(t, err)
+	assert.False(t, blocked)
+
+	// blocking a favourite removes it from favourites and vice versa
+	assert.NoError(t, storage.Block(consumer, "0xProvider1"))
+	assert.NoError(t, storage.AddFavourite(consumer, "0xProvider2"))
+	prefs, err = storage.Get(consumer)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"0xProvider2"}, prefs.Favourites)
+	assert.Equal(t, []string{"0xProvider1"}, prefs.Blocked)
+
+	assert.NoError(t, storage.Unblock(consumer, "0xProvider1"))
+	assert.NoError(t, storage.RemoveFavourite(consumer, "0xProvider2"))
+	prefs, err = storage.Get(consumer)
+	assert.NoError(t, err)
+	assert.Empty(t, prefs.Favourites)
+	assert.Empty(t, prefs.Blocked)
+}
@@ -0,0 +1,291 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package quality
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mysteriumnetwork/node/consumer/bandwidth"
+	"github.com/mysteriumnetwork/node/core/connection"
+	"github.com/mysteriumnetwork/node/core/storage"
+	"github.com/mysteriumnetwork/node/eventbus"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+const providerQualityBucket = "provider_quality"
+
+// Disconnect reasons recorded in the local quality history.
+const (
+	DisconnectReasonConsumer         = "consumer"
+	DisconnectReasonCanceled         = "canceled"
+	DisconnectReasonConnectionFailed = "connection_failed"
+	DisconnectReasonIPNotChanged     = "ip_not_changed"
+)
+
+const (
+	// neutralScore is given to the providers we have no history with.
+	neutralScore = 0.5
+	// referenceConnectTime is the time to connect which halves the connect time score.
+	referenceConnectTime = 10 * time.Second
+	// referenceThroughput is the download speed in bits per second which gets the full throughput score.
+	referenceThroughput = 10_000_000
+)
+
+type historyStorage interface {
+	GetValue(bucket string, key interface{}, to interface{}) error
+	SetValue(bucket string, key interface{}, to interface{}) error
+}
+
+// ProviderQuality is the local connection history with a single provider.
+type ProviderQuality struct {
+	ProviderID string `json:"provider_id"`
+
+	ConnectSuccess   int           `json:"connect_success"`
+	ConnectFail      int           `json:"connect_fail"`
+	TotalConnectTime time.Duration `json:"total_connect_time"`
+
+	Sessions             int           `json:"sessions"`
+	SessionFailures      int           `json:"session_failures"`
+	TotalSessionDuration time.Duration `json:"total_session_duration"`
+
+	ThroughputSamples int     `json:"throughput_samples"`
+	TotalThroughput   float64 `json:"total_throughput"`
+
+	DisconnectReasons map[string]int `json:"disconnect_reasons"`
+	UpdatedAt         time.Time      `json:"updated_at"`
+}
+
+// AvgConnectTime returns the average time it took to establish a connection.
+func (pq ProviderQuality) AvgConnectTime() time.Duration {
+	if pq.ConnectSuccess == 0 {
+		return 0
+	}
+	return pq.TotalConnectTime / time.Duration(pq.ConnectSuccess)
+}
+
+// AvgSessionDuration returns the average duration of established sessions.
+func (pq ProviderQuality) AvgSessionDuration() time.Duration {
+	if pq.Sessions == 0 {
+		return 0
+	}
+	return pq.TotalSessionDuration / time.Duration(pq.Sessions)
+}
+
+// AvgThroughput returns the average download speed in bits per second.
+func (pq ProviderQuality) AvgThroughput() float64 {
+	if pq.ThroughputSamples == 0 {
+		return 0
+	}
+	return pq.TotalThroughput / float64(pq.ThroughputSamples)
+}
+
+// Score calculates the local quality score between 0 and 1.
+// Success rate weighs the most, followed by session stability, time to connect and throughput.
+func (pq ProviderQuality) Score() float64 {
+	attempts := pq.ConnectSuccess + pq.ConnectFail
+	if attempts == 0 {
+		return neutralScore
+	}
+
+	// Laplace smoothing keeps a single attempt from deciding the score.
+	successRate := float64(pq.ConnectSuccess+1) / float64(attempts+2)
+
+	stability := neutralScore
+	if pq.Sessions > 0 {
+		stability = float64(pq.Sessions-pq.SessionFailures) / float64(pq.Sessions)
+	}
+
+	connectTime := neutralScore
+	if pq.ConnectSuccess > 0 {
+		connectTime = 1 / (1 + float64(pq.AvgConnectTime())/float64(referenceConnectTime))
+	}
+
+	throughput := neutralScore
+	if pq.ThroughputSamples > 0 {
+		throughput = pq.AvgThroughput() / referenceThroughput
+		if throughput > 1 {
+			throughput = 1
+		}
+	}
+
+	return 0.5*successRate + 0.2*stability + 0.15*connectTime + 0.15*throughput
+}
+
+type connectionAttempt struct {
+	providerID  string
+	startedAt   time.Time
+	connectedAt time.Time
+	reason      string
+	throughput  []float64
+}
+
+// History keeps the local quality record of providers, independently of the remote quality service.
+type History struct {
+	storage historyStorage
+	now     func() time.Time
+
+	lock    sync.Mutex
+	current *connectionAttempt
+}
+
+// NewHistory creates a local quality history.
+func NewHistory(storage historyStorage) *History {
+	return &History{
+		storage: storage,
+		now:     time.Now,
+	}
+}
+
+// Subscribe subscribes to connection events, which are recorded to the history.
+// State events are consumed synchronously, since a single attempt is tracked from Connecting to NotConnected.
+func (h *History) Subscribe(bus eventbus.Subscriber) error {
+	if err := bus.Subscribe(connection.AppTopicConnectionState, h.consumeStateEvent); err != nil {
+		return err
+	}
+	return bus.SubscribeAsync(bandwidth.AppTopicConnectionThroughput, h.consumeThroughputEvent)
+}
+
+// ProviderQuality returns the local quality history of the given provider.
+func (h *History) ProviderQuality(providerID string) (ProviderQuality, error) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	return h.get(providerID)
+}
+
+// Score returns the local quality score of the given provider.
+func (h *History) Score(providerID string) float64 {
+	pq, err := h.ProviderQuality(providerID)
+	if err != nil {
+		log.Warn().Err(err).Msgf("Failed to get quality history of provider %s", providerID)
+		return neutralScore
+	}
+	return pq.Score()
+}
+
+func (h *History) consumeStateEvent(e connection.AppEventConnectionState) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	switch e.State {
+	case connection.Connecting:
+		h.current = &connectionAttempt{
+			providerID: e.SessionInfo.Proposal.ProviderID,
+			startedAt:  e.SessionInfo.StartedAt,
+		}
+		if h.current.startedAt.IsZero() {
+			h.current.startedAt = h.now()
+		}
+	case connection.Connected:
+		if h.current != nil && h.current.connectedAt.IsZero() {
+			h.current.connectedAt = h.now()
+		}
+	case connection.StateConnectionFailed:
+		h.setReason(DisconnectReasonConnectionFailed)
+	case connection.StateIPNotChanged:
+		h.setReason(DisconnectReasonIPNotChanged)
+	case connection.Canceled:
+		h.setReason(DisconnectReasonCanceled)
+	case connection.NotConnected:
+		if h.current == nil {
+			return
+		}
+		if err := h.record(*h.current); err != nil {
+			log.Error().Err(err).Msg("Failed to record provider quality")
+		}
+		h.current = nil
+	}
+}
+
+func (h *History) consumeThroughputEvent(e bandwidth.AppEventConnectionThroughput) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	if h.current == nil || h.current.connectedAt.IsZero() {
+		return
+	}
+	h.current.throughput = append(h.current.throughput, float64(e.Throughput.Down))
+}
+
+func (h *History) setReason(reason string) {
+	if h.current != nil && h.current.reason == "" {
+		h.current.reason = reason
+	}
+}
+
+func (h *History) record(attempt connectionAttempt) error {
+	if attempt.providerID == "" {
+		return nil
+	}
+
+	pq, err := h.get(attempt.providerID)
+	if err != nil {
+		return err
+	}
+
+	if attempt.connectedAt.IsZero() {
+		if attempt.reason == DisconnectReasonCanceled {
+			// Cancelled by the consumer, it says nothing about the provider.
+			return nil
+		}
+		pq.ConnectFail++
+	} else {
+		pq.ConnectSuccess++
+		pq.TotalConnectTime += attempt.connectedAt.Sub(attempt.startedAt)
+		pq.Sessions++
+		pq.TotalSessionDuration += h.now().Sub(attempt.connectedAt)
+		if attempt.reason == DisconnectReasonConnectionFailed || attempt.reason == DisconnectReasonIPNotChanged {
+			pq.SessionFailures++
+		}
+		for _, t := range attempt.throughput {
+			pq.ThroughputSamples++
+			pq.TotalThroughput += t
+		}
+	}
+
+	reason := attempt.reason
+	if reason == "" || reason == DisconnectReasonCanceled {
+		reason = DisconnectReasonConsumer
+	}
+	pq.DisconnectReasons[reason]++
+	pq.UpdatedAt = h.now().UTC()
+
+	err = h.storage.SetValue(providerQualityBucket, key(attempt.providerID), pq)
+	return errors.Wrap(err, "could not store provider quality")
+}
+
+func (h *History) get(providerID string) (ProviderQuality, error) {
+	pq := ProviderQuality{}
+	err := h.storage.GetValue(providerQualityBucket, key(providerID), &pq)
+	if err != nil && err != storage.ErrNotFound {
+		return pq, errors.Wrap(err, "could not get provider quality")
+	}
+
+	pq.ProviderID = providerID
+	if pq.DisconnectReasons == nil {
+		pq.DisconnectReasons = make(map[string]int)
+	}
+	return pq, nil
+}
+
+func key(providerID string) string {
+	return strings.ToLower(providerID)
+}
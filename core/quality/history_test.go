@@ -0,0 +1,98 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package quality
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/mysteriumnetwork/node/consumer/bandwidth"
+	"github.com/mysteriumnetwork/node/core/connection"
+	"github.com/mysteriumnetwork/node/core/storage/boltdb"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHistory_RecordsConnections(t *testing.T) {
+	dir, err := ioutil.TempDir("", "qualityHistoryTest")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	bolt, err := boltdb.NewStorage(dir)
+	assert.NoError(t, err)
+	defer bolt.Close()
+
+	start := time.Date(2020, 5, 1, 12, 0, 0, 0, time.UTC)
+	now := start
+	history := NewHistory(bolt)
+	history.now = func() time.Time { return now }
+
+	status := connection.Status{
+		StartedAt: start,
+		Proposal:  market.ServiceProposal{ProviderID: "0xProvider"},
+	}
+	state := func(s connection.State) {
+		history.consumeStateEvent(connection.AppEventConnectionState{State: s, SessionInfo: status})
+	}
+
+	// successful session
+	state(connection.Connecting)
+	now = start.Add(2 * time.Second)
+	state(connection.Connected)
+	state(connection.Connected)
+	history.consumeThroughputEvent(bandwidth.AppEventConnectionThroughput{Throughput: bandwidth.Throughput{Down: 4_000_000}})
+	history.consumeThroughputEvent(bandwidth.AppEventConnectionThroughput{Throughput: bandwidth.Throughput{Down: 6_000_000}})
+	now = start.Add(time.Minute)
+	state(connection.Disconnecting)
+	state(connection.NotConnected)
+
+	// failed connection
+	state(connection.Connecting)
+	state(connection.StateConnectionFailed)
+	state(connection.Canceled)
+	state(connection.NotConnected)
+
+	// cancelled by consumer before connecting
+	state(connection.Connecting)
+	state(connection.Canceled)
+	state(connection.NotConnected)
+
+	pq, err := history.ProviderQuality("0xPROVIDER")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, pq.ConnectSuccess)
+	assert.Equal(t, 1, pq.ConnectFail)
+	assert.Equal(t, 2*time.Second, pq.AvgConnectTime())
+	assert.Equal(t, 1, pq.Sessions)
+	assert.Equal(t, 58*time.Second, pq.AvgSessionDuration())
+	assert.Equal(t, 5_000_000.0, pq.AvgThroughput())
+	assert.Equal(t, map[string]int{DisconnectReasonConsumer: 1, DisconnectReasonConnectionFailed: 1}, pq.DisconnectReasons)
+
+	assert.InDelta(t, 0.5*0.5+0.2*1+0.15*(1/1.2)+0.15*0.5, history.Score("0xProvider"), 0.0001)
+	assert.Equal(t, neutralScore, history.Score("0xUnknown"))
+}
+
+func TestProviderQuality_Score(t *testing.T) {
+	good := ProviderQuality{ConnectSuccess: 10, TotalConnectTime: 10 * time.Second, Sessions: 10, ThroughputSamples: 1, TotalThroughput: 20_000_000}
+	bad := ProviderQuality{ConnectSuccess: 1, ConnectFail: 9, TotalConnectTime: 30 * time.Second, Sessions: 1, SessionFailures: 1}
+
+	assert.Greater(t, good.Score(), neutralScore)
+	assert.Less(t, bad.Score(), neutralScore)
+	assert.LessOrEqual(t, good.Score(), 1.0)
+}
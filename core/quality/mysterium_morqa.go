@@ -41,13 +41,9 @@ const (
 
 // MysteriumMORQA HTTP client for Mysterium Quality Oracle - MORQA
 type MysteriumMORQA struct {
-	// http    HTTPClient
+	http    *requests.HTTPClient
 	baseURL string
 
-	client        *http.Client
-	clientMu      sync.Mutex
-	clientFactory func() *http.Client
-
 	batch    metrics.Batch
 	eventsMu sync.Mutex
 	events   chan *metrics.Event
@@ -56,21 +52,13 @@ type MysteriumMORQA struct {
 }
 
 // NewMorqaClient creates Mysterium Morqa client with a real communication
-func NewMorqaClient(srcIP, baseURL string, timeout time.Duration) *MysteriumMORQA {
-	morqa := &MysteriumMORQA{
+func NewMorqaClient(srcIP, baseURL string, timeout time.Duration, proxy *requests.Proxy) *MysteriumMORQA {
+	return &MysteriumMORQA{
+		http:    requests.NewHTTPClientWithProxy(srcIP, timeout, proxy),
 		baseURL: baseURL,
 		events:  make(chan *metrics.Event, maxBatchMetricsToKeep),
 		stop:    make(chan struct{}),
-		clientFactory: func() *http.Client {
-			return &http.Client{
-				Timeout:   timeout,
-				Transport: requests.GetDefaultTransport(srcIP),
-			}
-		},
 	}
-	morqa.client = morqa.clientFactory()
-
-	return morqa
 }
 
 // Start starts sending batch metrics to the Morqa server.
@@ -133,7 +121,7 @@ func (m *MysteriumMORQA) sendMetrics() error {
 
 	request.Close = true
 
-	response, err := m.client.Do(request)
+	response, err := m.http.Do(request)
 	if err != nil {
 		return err
 	}
@@ -157,7 +145,7 @@ func (m *MysteriumMORQA) ProposalsMetrics() []ConnectMetric {
 		return nil
 	}
 
-	response, err := m.client.Do(request)
+	response, err := m.http.Do(request)
 	if err != nil {
 		log.Warn().Err(err).Msg("Failed to request or parse proposals metrics")
 		return nil
@@ -181,20 +169,7 @@ func (m *MysteriumMORQA) SendMetric(event *metrics.Event) error {
 
 // Reconnect creates new instance of underlying HTTP client.
 func (m *MysteriumMORQA) Reconnect() {
-	m.clientMu.Lock()
-	defer m.clientMu.Unlock()
-	m.client.CloseIdleConnections()
-	m.client = m.clientFactory()
-}
-
-func (m *MysteriumMORQA) resolveClient() *http.Client {
-	m.clientMu.Lock()
-	defer m.clientMu.Unlock()
-	if m.client != nil {
-		return m.client
-	}
-	m.client = m.clientFactory()
-	return m.client
+	m.http.Reconnect()
 }
 
 func (m *MysteriumMORQA) newRequest(method, path string, body []byte) (*http.Request, error) {
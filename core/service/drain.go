@@ -0,0 +1,127 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/p2p"
+	"github.com/mysteriumnetwork/node/pb"
+	"github.com/mysteriumnetwork/node/session"
+	"github.com/mysteriumnetwork/node/utils"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	drainPollInterval  = time.Second
+	drainNotifyTimeout = 10 * time.Second
+)
+
+type sessionLister interface {
+	GetAll() []session.Session
+}
+
+// SettleFunc settles earnings of the given provider with the accountant.
+type SettleFunc func(providerID identity.Identity) error
+
+// Drainer gracefully stops providing services: it stops announcing proposals, rejects new sessions,
+// notifies connected consumers and waits for the running sessions to end before settling the earnings.
+type Drainer struct {
+	services     *Manager
+	sessions     sessionLister
+	drain        *session.Drain
+	settle       SettleFunc
+	pollInterval time.Duration
+	once         sync.Once
+}
+
+// NewDrainer creates drainer of the services started by the given manager.
+func NewDrainer(services *Manager, sessions sessionLister, drain *session.Drain, settle SettleFunc) *Drainer {
+	return &Drainer{
+		services:     services,
+		sessions:     sessions,
+		drain:        drain,
+		settle:       settle,
+		pollInterval: drainPollInterval,
+	}
+}
+
+// Drain blocks until the running sessions end or the timeout passes, then settles the earnings.
+// Services are left running so that the caller can stop them, only the first call has an effect.
+func (d *Drainer) Drain(timeout time.Duration) (err error) {
+	d.once.Do(func() {
+		err = d.run(timeout)
+	})
+	return err
+}
+
+func (d *Drainer) run(timeout time.Duration) error {
+	instances := d.services.servicePool.running()
+	if len(instances) == 0 {
+		return nil
+	}
+
+	deadline := time.Now().Add(timeout)
+	log.Info().Msgf("Draining %d service(s), waiting for sessions to end until %s", len(instances), deadline.Format(time.RFC3339))
+	d.drain.Start()
+
+	providers := make(map[string]identity.Identity)
+	for _, instance := range instances {
+		providerID := instance.Proposal().ProviderID
+		providers[providerID] = identity.FromAddress(providerID)
+		if instance.discovery != nil {
+			instance.discovery.Stop()
+		}
+	}
+
+	notice := p2p.ProtoMessage(&pb.SessionDrain{Deadline: deadline.Unix()})
+	ctx, cancel := context.WithTimeout(context.Background(), drainNotifyTimeout)
+	for _, instance := range instances {
+		instance.notifyP2PChannels(ctx, p2p.TopicSessionDrain, notice)
+	}
+	cancel()
+
+	d.waitSessions(deadline)
+
+	errSettle := utils.ErrorCollection{}
+	for _, provider := range providers {
+		if err := d.settle(provider); err != nil {
+			log.Error().Err(err).Msgf("Could not settle earnings of provider %s", provider.Address)
+			errSettle.Add(err)
+		}
+	}
+	return errSettle.Errorf("Some earnings were not settled: %v", ". ")
+}
+
+func (d *Drainer) waitSessions(deadline time.Time) {
+	for {
+		remaining := len(d.sessions.GetAll())
+		if remaining == 0 {
+			log.Info().Msg("All sessions ended")
+			return
+		}
+		if time.Now().After(deadline) {
+			log.Warn().Msgf("Drain timeout reached, %d session(s) are still running", remaining)
+			return
+		}
+		time.Sleep(d.pollInterval)
+	}
+}
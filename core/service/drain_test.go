@@ -0,0 +1,161 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/mocks"
+	"github.com/mysteriumnetwork/node/p2p"
+	"github.com/mysteriumnetwork/node/pb"
+	"github.com/mysteriumnetwork/node/session"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDrainer_Drain(t *testing.T) {
+	discovery := &drainDiscovery{}
+	channel := &drainChannel{}
+	manager := drainManager(&Instance{
+		id:          "service-1",
+		proposal:    market.ServiceProposal{ProviderID: "0x1"},
+		discovery:   discovery,
+		p2pChannels: []p2p.Channel{channel},
+	})
+	sessions := &drainSessions{remaining: 2}
+	drain := session.NewDrain()
+	var settled []identity.Identity
+	drainer := NewDrainer(manager, sessions, drain, func(providerID identity.Identity) error {
+		settled = append(settled, providerID)
+		return nil
+	})
+	drainer.pollInterval = time.Millisecond
+
+	err := drainer.Drain(time.Minute)
+
+	assert.NoError(t, err)
+	assert.True(t, drain.Draining())
+	assert.True(t, discovery.stopped)
+	assert.Equal(t, []string{p2p.TopicSessionDrain}, channel.topics)
+	assert.Equal(t, 0, sessions.remaining)
+	assert.Equal(t, []identity.Identity{identity.FromAddress("0x1")}, settled)
+}
+
+func TestDrainer_Drain_Timeout(t *testing.T) {
+	manager := drainManager(&Instance{
+		id:       "service-1",
+		proposal: market.ServiceProposal{ProviderID: "0x1"},
+	})
+	sessions := &drainSessions{remaining: 1000}
+	drainer := NewDrainer(manager, sessions, session.NewDrain(), func(identity.Identity) error {
+		return errors.New("accountant unavailable")
+	})
+	drainer.pollInterval = time.Millisecond
+
+	err := drainer.Drain(10 * time.Millisecond)
+
+	assert.EqualError(t, err, "Some earnings were not settled: accountant unavailable")
+	assert.True(t, sessions.remaining > 0)
+}
+
+func TestDrainer_Drain_NoServices(t *testing.T) {
+	drain := session.NewDrain()
+	drainer := NewDrainer(drainManager(), &drainSessions{}, drain, func(identity.Identity) error {
+		assert.Fail(t, "nothing should be settled")
+		return nil
+	})
+
+	assert.NoError(t, drainer.Drain(time.Minute))
+	assert.False(t, drain.Draining())
+}
+
+func TestDrainer_Drain_OnlyOnce(t *testing.T) {
+	manager := drainManager(&Instance{
+		id:       "service-1",
+		proposal: market.ServiceProposal{ProviderID: "0x1"},
+	})
+	var settleCount int
+	drainer := NewDrainer(manager, &drainSessions{}, session.NewDrain(), func(identity.Identity) error {
+		settleCount++
+		return nil
+	})
+
+	assert.NoError(t, drainer.Drain(time.Minute))
+	assert.NoError(t, drainer.Drain(time.Minute))
+	assert.Equal(t, 1, settleCount)
+}
+
+func drainManager(instances ...*Instance) *Manager {
+	pool := NewPool(mocks.NewEventBus())
+	for _, instance := range instances {
+		pool.Add(instance)
+	}
+	return &Manager{servicePool: pool}
+}
+
+type drainDiscovery struct {
+	stopped bool
+}
+
+func (d *drainDiscovery) Start(identity.Identity, market.ServiceProposal) {}
+
+func (d *drainDiscovery) UpdateProposal(market.ServiceProposal) {}
+
+func (d *drainDiscovery) Stop() {
+	d.stopped = true
+}
+
+func (d *drainDiscovery) Wait() {}
+
+type drainChannel struct {
+	p2p.Channel
+	mu     sync.Mutex
+	topics []string
+}
+
+func (c *drainChannel) Send(_ context.Context, topic string, msg *p2p.Message) (*p2p.Message, error) {
+	var notice pb.SessionDrain
+	if err := msg.UnmarshalProto(&notice); err != nil {
+		return nil, err
+	}
+	if notice.Deadline <= time.Now().Unix() {
+		return nil, errors.New("deadline is in the past")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.topics = append(c.topics, topic)
+	return nil, nil
+}
+
+type drainSessions struct {
+	remaining int
+}
+
+func (s *drainSessions) GetAll() []session.Session {
+	if s.remaining == 0 {
+		return nil
+	}
+	s.remaining--
+	return []session.Session{{}}
+}
@@ -18,15 +18,19 @@
 package service
 
 import (
+	"encoding/json"
 	"fmt"
+	"net"
 	"time"
 
 	"github.com/gofrs/uuid"
 	"github.com/mysteriumnetwork/node/communication"
 	"github.com/mysteriumnetwork/node/core/policy"
 	"github.com/mysteriumnetwork/node/core/service/servicestate"
+	"github.com/mysteriumnetwork/node/eventbus"
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/nat/stun"
 	"github.com/mysteriumnetwork/node/p2p"
 	"github.com/mysteriumnetwork/node/session"
 	"github.com/mysteriumnetwork/node/session/connectivity"
@@ -43,6 +47,10 @@ var (
 	ErrUnsupportedAccessPolicy = errors.New("unsupported access policy")
 )
 
+// p2pChannelCloseDelay is a time given for the p2p channel to finish sending the last message
+// before it is closed after session destroy.
+var p2pChannelCloseDelay = 10 * time.Second
+
 // Service interface represents pluggable Mysterium service
 type Service interface {
 	Serve(instance *Instance) error
@@ -50,6 +58,17 @@ type Service interface {
 	session.ConfigProvider
 }
 
+// KeyRotator is a service able to rotate the keys of a running session without dropping it.
+type KeyRotator interface {
+	RotateKeys(sessionID string, config json.RawMessage) error
+}
+
+// ServiceConnUpdater is a service able to move the tunnel of a running session to the service connection
+// of the new p2p channel once the session is resumed over it.
+type ServiceConnUpdater interface {
+	UpdateServiceConn(sessionID string, conn *net.UDPConn) error
+}
+
 // DialogWaiterFactory initiates communication channel which waits for incoming dialogs
 type DialogWaiterFactory func(providerID identity.Identity, serviceType string, policies *policy.Repository) (communication.DialogWaiter, error)
 
@@ -62,6 +81,7 @@ type DiscoveryFactory func() Discovery
 // Discovery registers the service to the discovery api periodically
 type Discovery interface {
 	Start(ownIdentity identity.Identity, proposal market.ServiceProposal)
+	UpdateProposal(proposal market.ServiceProposal)
 	Stop()
 	Wait()
 }
@@ -172,9 +192,15 @@ func (manager *Manager) Start(providerID identity.Identity, serviceType string,
 		subscribeSessionCreate(mng, ch, service)
 		subscribeSessionStatus(mng, ch, manager.statusStorage)
 		subscribeSessionAcknowledge(mng, ch)
+		if rotator, ok := service.(KeyRotator); ok {
+			subscribeSessionRekey(mng, ch, rotator)
+		}
+		if updater, ok := service.(ServiceConnUpdater); ok {
+			subscribeSessionResume(mng, ch, updater, instance.closeP2PChannel)
+		}
 		subscribeSessionDestroy(mng, ch, func() {
 			// Give some time for channel to finish sending last message.
-			time.Sleep(10 * time.Second)
+			time.Sleep(p2pChannelCloseDelay)
 			instance.closeP2PChannel(ch)
 		})
 	}
@@ -204,6 +230,29 @@ func (manager *Manager) Start(providerID identity.Identity, serviceType string,
 	return id, nil
 }
 
+// Subscribe subscribes manager to the events it needs to keep running services up to date.
+func (manager *Manager) Subscribe(bus eventbus.Subscriber) error {
+	return bus.SubscribeAsync(stun.AppTopicNATTypeDetected, manager.handleNATTypeDetected)
+}
+
+// handleNATTypeDetected re-announces proposals of the running services, their p2p contact
+// advertises the NAT type which is only known after the detection finishes.
+func (manager *Manager) handleNATTypeDetected(e stun.AppEventNATTypeDetected) {
+	if e.Error != nil {
+		return
+	}
+
+	for _, instance := range manager.servicePool.running() {
+		proposal := instance.Proposal()
+		proposal.SetProviderContacts(
+			identity.FromAddress(proposal.ProviderID),
+			market.ContactList{instance.dialogWaiter.GetContact(), manager.p2pListener.GetContact()},
+		)
+		instance.setProposal(proposal)
+		instance.discovery.UpdateProposal(proposal)
+	}
+}
+
 func generateID() (ID, error) {
 	uid, err := uuid.NewV4()
 	if err != nil {
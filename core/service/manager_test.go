@@ -18,18 +18,29 @@
 package service
 
 import (
+	"context"
 	"errors"
+	"net"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/mysteriumnetwork/node/core/policy"
 	"github.com/mysteriumnetwork/node/core/service/servicestate"
+	"github.com/mysteriumnetwork/node/eventbus"
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/market"
 	"github.com/mysteriumnetwork/node/mocks"
+	"github.com/mysteriumnetwork/node/nat/event"
+	"github.com/mysteriumnetwork/node/nat/stun"
+	"github.com/mysteriumnetwork/node/nat/traversal"
 	"github.com/mysteriumnetwork/node/p2p"
+	"github.com/mysteriumnetwork/node/pb"
 	"github.com/mysteriumnetwork/node/requests"
+	"github.com/mysteriumnetwork/node/session"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
 )
 
 var (
@@ -146,13 +157,234 @@ func TestManager_StopSendsEvent_SucceedsAndPublishesEvent(t *testing.T) {
 	assert.True(t, matchFound)
 }
 
+func TestManager_NATTypeDetectionUpdatesProposalContacts(t *testing.T) {
+	registry := NewRegistry()
+	mockCopy := *serviceMock
+	mockCopy.mockProcess = make(chan struct{})
+	registry.Register(serviceType, func(options Options) (Service, market.ServiceProposal, error) {
+		return &mockCopy, proposalMock, nil
+	})
+
+	discovery := mockDiscovery{}
+	listener := &mockP2PListener{}
+	bus := eventbus.New()
+	manager := NewManager(
+		registry,
+		MockDialogWaiterFactory,
+		MockDialogHandlerFactory,
+		MockDiscoveryFactoryFunc(&discovery),
+		bus,
+		mockPolicyOracle,
+		listener, nil, nil,
+	)
+	require.NoError(t, manager.Subscribe(bus))
+
+	id, err := manager.Start(identity.FromAddress("0x1"), serviceType, nil, struct{}{}, nil)
+	require.NoError(t, err)
+	defer manager.Stop(id)
+
+	natTypeOf := func(proposal market.ServiceProposal) string {
+		for _, contact := range proposal.ProviderContacts {
+			if def, ok := contact.Definition.(p2p.ContactDefinition); ok {
+				return def.NATType
+			}
+		}
+		return ""
+	}
+	assert.Equal(t, "", natTypeOf(manager.Service(id).Proposal()))
+
+	listener.natType = stun.TypeFullCone
+	bus.Publish(stun.AppTopicNATTypeDetected, stun.AppEventNATTypeDetected{Type: stun.TypeFullCone})
+
+	assert.Eventually(t, func() bool {
+		return natTypeOf(discovery.announcedProposal()) == string(stun.TypeFullCone)
+	}, 2*time.Second, 10*time.Millisecond)
+	assert.Equal(t, string(stun.TypeFullCone), natTypeOf(manager.Service(id).Proposal()))
+	assert.Equal(t, "0x1", manager.Service(id).Proposal().ProviderID)
+}
+
+func TestManager_DestroyingResumedSessionClosesNewChannel(t *testing.T) {
+	defer func(delay time.Duration) { p2pChannelCloseDelay = delay }(p2pChannelCloseDelay)
+	p2pChannelCloseDelay = 0
+
+	ks := identity.NewKeystoreFilesystem("dir", identity.NewMockKeystore(identity.MockKeys), identity.MockDecryptFunc)
+	consumer := identity.FromAddress("0x53a835143c0ef3bbcbfa796d7eb738ca7dd28f68")
+	require.NoError(t, identity.NewIdentityManager(ks, eventbus.New()).Unlock(consumer.Address, ""))
+	signer := identity.NewSigner(ks, consumer)
+
+	registry := NewRegistry()
+	service := &resumableService{serviceFake: serviceFake{mockProcess: make(chan struct{})}}
+	registry.Register(serviceType, func(options Options) (Service, market.ServiceProposal, error) {
+		return service, proposalMock, nil
+	})
+	storage := session.NewStorageMemory()
+	newSessionManager := func(proposal market.ServiceProposal, serviceID string, ch p2p.Channel) *session.Manager {
+		return session.NewManager(proposal, storage, mockPaymentEngineFactory, traversal.NewNoopPinger(),
+			&mockNATEventGetter{}, serviceID, mocks.NewEventBus(), p2p.NewSwitchableChannel(ch), nil, session.DefaultConfig())
+	}
+	listener := &mockP2PListener{}
+	manager := NewManager(
+		registry,
+		MockDialogWaiterFactory,
+		MockDialogHandlerFactory,
+		MockDiscoveryFactoryFunc(&mockDiscovery{}),
+		mocks.NewEventBus(),
+		mockPolicyOracle,
+		listener, newSessionManager, nil,
+	)
+	id, err := manager.Start(identity.FromAddress(proposalMock.ProviderID), serviceType, nil, struct{}{}, nil)
+	require.NoError(t, err)
+	defer manager.Stop(id)
+	instance := manager.servicePool.Instance(id)
+
+	serviceConn, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.ParseIP("127.0.0.1")})
+	require.NoError(t, err)
+	defer serviceConn.Close()
+	oldChannel, newChannel := &mockChannel{}, &mockChannel{serviceConn: serviceConn}
+	listener.channelHandler(oldChannel)
+	listener.channelHandler(newChannel)
+
+	sess, err := session.NewSession()
+	require.NoError(t, err)
+	err = newSessionManager(proposalMock, string(id), oldChannel).Start(sess, consumer, session.ConsumerInfo{IssuerID: consumer}, proposalMock.ID, nil, nil)
+	require.NoError(t, err)
+
+	now := time.Now().Unix()
+	signature, err := signer.Sign(session.ResumeMessage(sess.ID, now))
+	require.NoError(t, err)
+	err = newChannel.handle(p2p.TopicSessionResume, &pb.SessionResume{
+		ConsumerID: consumer.Address,
+		SessionID:  string(sess.ID),
+		Timestamp:  now,
+		Signature:  signature.Bytes(),
+	})
+	require.NoError(t, err)
+	assert.True(t, oldChannel.isClosed())
+	assert.Equal(t, serviceConn, service.updatedConn)
+	assert.Equal(t, []p2p.Channel{newChannel}, trackedP2PChannels(instance))
+
+	err = newChannel.handle(p2p.TopicSessionDestroy, &pb.SessionInfo{ConsumerID: consumer.Address, SessionID: string(sess.ID)})
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		return newChannel.isClosed() && len(trackedP2PChannels(instance)) == 0
+	}, 2*time.Second, 10*time.Millisecond)
+}
+
+func trackedP2PChannels(instance *Instance) []p2p.Channel {
+	instance.p2pChannelsLock.Lock()
+	defer instance.p2pChannelsLock.Unlock()
+	return append([]p2p.Channel(nil), instance.p2pChannels...)
+}
+
 type mockP2PListener struct {
+	channelHandler func(ch p2p.Channel)
+	natType        stun.NATType
 }
 
-func (m mockP2PListener) GetContact() market.Contact {
-	return market.Contact{}
+func (m *mockP2PListener) GetContact() market.Contact {
+	if m.natType == "" {
+		return market.Contact{}
+	}
+	return market.Contact{
+		Type:       p2p.ContactTypeV1,
+		Definition: p2p.ContactDefinition{NATType: string(m.natType)},
+	}
+}
+
+func (m *mockP2PListener) Listen(providerID identity.Identity, serviceType string, channelHandler func(ch p2p.Channel)) error {
+	m.channelHandler = channelHandler
+	return nil
+}
+
+type resumableService struct {
+	serviceFake
+	updatedConn *net.UDPConn
+}
+
+func (s *resumableService) UpdateServiceConn(_ string, conn *net.UDPConn) error {
+	s.updatedConn = conn
+	return nil
+}
+
+func mockPaymentEngineFactory(_, _, _ identity.Identity, _ string) (session.PaymentEngine, error) {
+	return &mockPaymentEngine{}, nil
+}
+
+type mockPaymentEngine struct{}
+
+func (m *mockPaymentEngine) Start() error { return nil }
+
+func (m *mockPaymentEngine) Stop() {}
+
+type mockNATEventGetter struct{}
+
+func (m *mockNATEventGetter) LastEvent() *event.Event {
+	return &event.Event{}
+}
+
+type mockChannel struct {
+	p2p.Channel
+	mu          sync.Mutex
+	handlers    map[string]p2p.HandlerFunc
+	serviceConn *net.UDPConn
+	closed      bool
+}
+
+func (m *mockChannel) Send(_ context.Context, _ string, _ *p2p.Message) (*p2p.Message, error) {
+	return &p2p.Message{}, nil
+}
+
+func (m *mockChannel) Handle(topic string, handler p2p.HandlerFunc) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.handlers == nil {
+		m.handlers = make(map[string]p2p.HandlerFunc)
+	}
+	m.handlers[topic] = handler
+}
+
+func (m *mockChannel) HandleStream(_ string, _ p2p.StreamHandlerFunc) {}
+
+func (m *mockChannel) ServiceConn() *net.UDPConn {
+	return m.serviceConn
+}
+
+func (m *mockChannel) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.closed = true
+	return nil
+}
+
+func (m *mockChannel) isClosed() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.closed
+}
+
+func (m *mockChannel) handle(topic string, msg proto.Message) error {
+	m.mu.Lock()
+	handler := m.handlers[topic]
+	m.mu.Unlock()
+	return handler(&mockContext{req: p2p.ProtoMessage(msg)})
+}
+
+type mockContext struct {
+	req *p2p.Message
+}
+
+func (c *mockContext) Request() *p2p.Message {
+	return c.req
+}
+
+func (c *mockContext) Error(err error) error {
+	return err
+}
+
+func (c *mockContext) OkWithReply(_ *p2p.Message) error {
+	return nil
 }
 
-func (m mockP2PListener) Listen(providerID identity.Identity, serviceType string, channelHandler func(ch p2p.Channel)) error {
+func (c *mockContext) OK() error {
 	return nil
 }
@@ -18,6 +18,7 @@
 package service
 
 import (
+	"context"
 	"sync"
 
 	"github.com/mysteriumnetwork/node/communication"
@@ -115,6 +116,17 @@ func (p *Pool) List() map[ID]*Instance {
 	return p.instances
 }
 
+func (p *Pool) running() []*Instance {
+	p.Lock()
+	defer p.Unlock()
+
+	instances := make([]*Instance, 0, len(p.instances))
+	for _, instance := range p.instances {
+		instances = append(instances, instance)
+	}
+	return instances
+}
+
 // Instance returns service instance by the requested id.
 func (p *Pool) Instance(id ID) *Instance {
 	p.Lock()
@@ -150,6 +162,7 @@ type Instance struct {
 	stateLock       sync.RWMutex
 	options         Options
 	service         RunnableService
+	proposalLock    sync.RWMutex
 	proposal        market.ServiceProposal
 	policies        *policy.Repository
 	dialogWaiter    communication.DialogWaiter
@@ -166,9 +179,17 @@ func (i *Instance) Options() Options {
 
 // Proposal returns service proposal of the running service instance.
 func (i *Instance) Proposal() market.ServiceProposal {
+	i.proposalLock.RLock()
+	defer i.proposalLock.RUnlock()
 	return i.proposal
 }
 
+func (i *Instance) setProposal(proposal market.ServiceProposal) {
+	i.proposalLock.Lock()
+	defer i.proposalLock.Unlock()
+	i.proposal = proposal
+}
+
 // Policies returns service policies of the running service instance.
 func (i *Instance) Policies() *policy.Repository {
 	return i.policies
@@ -196,20 +217,41 @@ func (i *Instance) addP2PChannel(ch p2p.Channel) {
 	i.p2pChannels = append(i.p2pChannels, ch)
 }
 
+// closeP2PChannel closes the channel and stops tracking it.
 func (i *Instance) closeP2PChannel(ch p2p.Channel) {
 	i.p2pChannelsLock.Lock()
 	defer i.p2pChannelsLock.Unlock()
 
 	for index, channel := range i.p2pChannels {
 		if channel == ch {
-			// Close and delete channel.
-			if err := channel.Close(); err != nil {
-				log.Err(err).Msg("Could not close p2p channel")
-			}
 			i.p2pChannels = append(i.p2pChannels[:index], i.p2pChannels[index+1:]...)
-			return
+			break
 		}
 	}
+	if err := ch.Close(); err != nil {
+		log.Err(err).Msg("Could not close p2p channel")
+	}
+}
+
+// notifyP2PChannels sends the message to consumers connected over p2p channels, errors are only logged
+// as consumers running older versions do not handle every topic.
+func (i *Instance) notifyP2PChannels(ctx context.Context, topic string, msg *p2p.Message) {
+	i.p2pChannelsLock.Lock()
+	channels := make([]p2p.Channel, len(i.p2pChannels))
+	copy(channels, i.p2pChannels)
+	i.p2pChannelsLock.Unlock()
+
+	var wg sync.WaitGroup
+	for _, channel := range channels {
+		wg.Add(1)
+		go func(channel p2p.Channel) {
+			defer wg.Done()
+			if _, err := channel.Send(ctx, topic, msg); err != nil {
+				log.Debug().Err(err).Msgf("Could not send P2P message to %q", topic)
+			}
+		}(channel)
+	}
+	wg.Wait()
 }
 
 func (i *Instance) stop() error {
@@ -236,10 +278,11 @@ func (i *Instance) stop() error {
 
 // toEvent returns an event representation of the instance
 func (i *Instance) toEvent() servicestate.AppEventServiceStatus {
+	proposal := i.Proposal()
 	return servicestate.AppEventServiceStatus{
 		ID:         string(i.id),
-		ProviderID: i.proposal.ProviderID,
-		Type:       i.proposal.ServiceType,
+		ProviderID: proposal.ProviderID,
+		Type:       proposal.ServiceType,
 		Status:     string(i.state),
 	}
 }
@@ -96,12 +96,28 @@ func MockDialogHandlerFactory(market.ServiceProposal, session.ConfigProvider, st
 }
 
 type mockDiscovery struct {
-	wg sync.WaitGroup
+	wg       sync.WaitGroup
+	mu       sync.Mutex
+	proposal market.ServiceProposal
 }
 
 func (mds *mockDiscovery) Start(ownIdentity identity.Identity, proposal market.ServiceProposal) {
 	mds.wg.Add(1)
+	mds.UpdateProposal(proposal)
 }
+
+func (mds *mockDiscovery) UpdateProposal(proposal market.ServiceProposal) {
+	mds.mu.Lock()
+	defer mds.mu.Unlock()
+	mds.proposal = proposal
+}
+
+func (mds *mockDiscovery) announcedProposal() market.ServiceProposal {
+	mds.mu.Lock()
+	defer mds.mu.Unlock()
+	return mds.proposal
+}
+
 func (mds *mockDiscovery) Stop() {
 	mds.wg.Done()
 }
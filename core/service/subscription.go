@@ -20,6 +20,7 @@ package service
 import (
 	"encoding/json"
 	"fmt"
+	"net"
 	"time"
 
 	"github.com/mysteriumnetwork/node/identity"
@@ -147,3 +148,47 @@ func subscribeSessionAcknowledge(mng *session.Manager, ch p2p.ChannelHandler) {
 		return c.OK()
 	})
 }
+
+func subscribeSessionRekey(mng *session.Manager, ch p2p.ChannelHandler, rotator KeyRotator) {
+	ch.Handle(p2p.TopicSessionRekey, func(c p2p.Context) error {
+		var sr pb.SessionRekey
+		if err := c.Request().UnmarshalProto(&sr); err != nil {
+			return err
+		}
+		log.Debug().Msgf("Received P2P message for %q with SessionID=%s", p2p.TopicSessionRekey, sr.GetSessionID())
+		consumerID := identity.FromAddress(sr.GetConsumerID())
+		sessionID := sr.GetSessionID()
+
+		err := mng.RotateKeys(consumerID, sessionID, func() error {
+			return rotator.RotateKeys(sessionID, sr.GetConfig())
+		})
+		if err != nil {
+			return fmt.Errorf("cannot rotate keys of session %s: %w", sessionID, err)
+		}
+
+		return c.OK()
+	})
+}
+
+func subscribeSessionResume(mng *session.Manager, ch p2p.ChannelHandler, updater ServiceConnUpdater, closeChannel func(p2p.Channel)) {
+	ch.Handle(p2p.TopicSessionResume, func(c p2p.Context) error {
+		var sr pb.SessionResume
+		if err := c.Request().UnmarshalProto(&sr); err != nil {
+			return err
+		}
+		log.Debug().Msgf("Received P2P message for %q with SessionID=%s", p2p.TopicSessionResume, sr.GetSessionID())
+
+		consumerID := identity.FromAddress(sr.GetConsumerID())
+		sessionID := sr.GetSessionID()
+
+		previous, err := mng.Resume(consumerID, sessionID, sr.GetTimestamp(), sr.GetSignature(), func(conn *net.UDPConn) error {
+			return updater.UpdateServiceConn(sessionID, conn)
+		})
+		if err != nil {
+			return fmt.Errorf("cannot resume session %s: %w", sessionID, err)
+		}
+		closeChannel(previous)
+
+		return c.OK()
+	})
+}
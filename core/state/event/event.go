@@ -28,6 +28,8 @@ import (
 	"github.com/mysteriumnetwork/node/identity/registry"
 	"github.com/mysteriumnetwork/node/market"
 	"github.com/mysteriumnetwork/node/money"
+	"github.com/mysteriumnetwork/node/p2p"
+	pingpongEvent "github.com/mysteriumnetwork/node/session/pingpong/event"
 	"github.com/mysteriumnetwork/payments/crypto"
 )
 
@@ -51,6 +53,7 @@ type Identity struct {
 	Balance            uint64
 	Earnings           uint64
 	EarningsTotal      uint64
+	SettlementPolicy   pingpongEvent.SettlementPolicy
 }
 
 // Connection represents consumer connection state.
@@ -120,4 +123,49 @@ type ServiceSession struct {
 	ServiceType string `json:"service_type"`
 	// example: 500000
 	TokensEarned uint64 `json:"tokens_earned"`
+	// last known p2p channel statistics, omitted until the first keep alive
+	ChannelStats *ChannelStats `json:"channel_stats,omitempty"`
+}
+
+// ChannelStats represents p2p channel transport statistics of the session.
+// swagger:model ChannelStatsDTO
+type ChannelStats struct {
+	// smoothed round trip time in milliseconds
+	// example: 42
+	RTT int64 `json:"rtt_ms"`
+	// ratio of retransmitted segments to all sent segments
+	// example: 0.01
+	Loss float64 `json:"loss"`
+	// example: 12
+	Retransmits uint64 `json:"retransmits"`
+	// example: 1200
+	SegmentsSent uint64 `json:"segments_sent"`
+	// example: 12345
+	BytesSent uint64 `json:"bytes_sent"`
+	// example: 23451
+	BytesReceived uint64 `json:"bytes_received"`
+	// time of the last peer address change, omitted if address did not change
+	// example: 2019-06-06T11:04:43.910035Z
+	PeerAddrChangedAt *time.Time `json:"peer_addr_changed_at,omitempty"`
+}
+
+// NewChannelStats maps p2p channel statistics, it returns nil if statistics are not known.
+func NewChannelStats(stats *p2p.ChannelStats) *ChannelStats {
+	if stats == nil {
+		return nil
+	}
+
+	result := &ChannelStats{
+		RTT:           stats.RTT.Milliseconds(),
+		Loss:          stats.Loss,
+		Retransmits:   stats.Retransmits,
+		SegmentsSent:  stats.SegmentsSent,
+		BytesSent:     stats.BytesSent,
+		BytesReceived: stats.BytesReceived,
+	}
+	if !stats.PeerAddrChangedAt.IsZero() {
+		changedAt := stats.PeerAddrChangedAt
+		result.PeerAddrChangedAt = &changedAt
+	}
+	return result
 }
@@ -163,6 +163,7 @@ func (k *Keeper) fetchIdentities() []stateEvent.Identity {
 			Balance:            k.deps.BalanceProvider.GetBalance(id),
 			Earnings:           earnings.UnsettledBalance,
 			EarningsTotal:      earnings.LifetimeBalance,
+			SettlementPolicy:   earnings.SettlementPolicy,
 		}
 		identities[idx] = stateIdentity
 	}
@@ -325,6 +326,7 @@ func (k *Keeper) updateSessionState(e interface{}) {
 			TokensEarned: sessions[i].TokensEarned,
 			ServiceID:    sessions[i].ServiceID,
 			ServiceType:  sessions[i].ServiceType,
+			ChannelStats: stateEvent.NewChannelStats(sessions[i].ChannelStats),
 		}
 
 		// each new session counts as an additional attempt, mark them for further use
@@ -448,6 +450,7 @@ func (k *Keeper) consumeEarningsChangedEvent(e interface{}) {
 	}
 	id.Earnings = evt.Current.UnsettledBalance
 	id.EarningsTotal = evt.Current.LifetimeBalance
+	id.SettlementPolicy = evt.Current.SettlementPolicy
 	go k.announceStateChanges(nil)
 }
 
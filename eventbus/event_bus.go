@@ -19,8 +19,7 @@ package eventbus
 
 import (
 	asaskevichEventBus "github.com/asaskevich/EventBus"
-	"github.com/rs/zerolog"
-	"github.com/rs/zerolog/log"
+	"github.com/mysteriumnetwork/node/logconfig"
 )
 
 // EventBus allows subscribing and publishing data by topic
@@ -57,28 +56,29 @@ func (simplifiedBus simplifiedEventBus) SubscribeAsync(topic string, fn interfac
 	return simplifiedBus.bus.SubscribeAsync(topic, fn, false)
 }
 
-var logLevelsByTopic = map[string]zerolog.Level{
-	"ProposalAdded":               zerolog.Disabled,
-	"ProposalUpdated":             zerolog.Disabled,
-	"ProposalRemoved":             zerolog.Disabled,
-	"proposalEvent":               zerolog.Disabled,
-	"Statistics":                  zerolog.Disabled,
-	"Throughput":                  zerolog.Disabled,
-	"State change":                zerolog.TraceLevel,
-	"Session data transferred":    zerolog.TraceLevel,
-	"Session change":              zerolog.TraceLevel,
-	"accountant_promise_received": zerolog.TraceLevel,
+// loggersByTopic sets component loggers of frequently published topics.
+var loggersByTopic = map[string]*logconfig.Logger{
+	"ProposalAdded":               proposalsLog,
+	"ProposalUpdated":             proposalsLog,
+	"ProposalRemoved":             proposalsLog,
+	"proposalEvent":               proposalsLog,
+	"Statistics":                  statisticsLog,
+	"Throughput":                  statisticsLog,
+	"State change":                sessionLog,
+	"Session data transferred":    sessionLog,
+	"Session change":              sessionLog,
+	"accountant_promise_received": sessionLog,
 }
 
-func levelFor(topic string) zerolog.Level {
-	if level, exist := logLevelsByTopic[topic]; exist {
-		return level
+func loggerFor(topic string) *logconfig.Logger {
+	if logger, exist := loggersByTopic[topic]; exist {
+		return logger
 	}
-	return zerolog.DebugLevel
+	return log
 }
 
 func (simplifiedBus simplifiedEventBus) Publish(topic string, data interface{}) {
-	log.WithLevel(levelFor(topic)).Msgf("Published topic=%q event=%+v", topic, data)
+	loggerFor(topic).Debug().Msgf("Published topic=%q event=%+v", topic, data)
 	simplifiedBus.bus.Publish(topic, data)
 }
 
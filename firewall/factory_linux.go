@@ -19,8 +19,26 @@
 
 package firewall
 
+import (
+	"net"
+
+	"github.com/mysteriumnetwork/node/firewall/nftables"
+	"github.com/rs/zerolog/log"
+)
+
 // NewOutgoingTrafficFirewall creates firewall instance for outgoing traffic.
+// nftables is used if it is available, iptables otherwise.
 func NewOutgoingTrafficFirewall() OutgoingTrafficFirewall {
+	if nftables.Available() {
+		log.Info().Msg("Using nftables for the outgoing traffic firewall")
+		return &outgoingFirewallNftables{
+			referenceTracker: make(map[string]refCount),
+			elementRefs:      make(map[string]int),
+			trafficLockScope: none,
+			lookupIP:         net.LookupIP,
+		}
+	}
+
 	return &outgoingFirewallIptables{
 		referenceTracker: make(map[string]refCount),
 		trafficLockScope: none,
@@ -29,9 +47,16 @@ func NewOutgoingTrafficFirewall() OutgoingTrafficFirewall {
 
 // NewIncomingTrafficFirewall creates firewall instance for incoming traffic.
 func NewIncomingTrafficFirewall(enabled bool) IncomingTrafficFirewall {
-	if enabled {
-		return &incomingFirewallIptables{}
+	if !enabled {
+		return &incomingFirewallNoop{}
 	}
 
-	return &incomingFirewallNoop{}
+	if nftables.Available() {
+		log.Info().Msg("Using nftables for the incoming traffic firewall")
+		return &incomingFirewallNftables{
+			hosts:    make(map[string]int),
+			lookupIP: net.LookupIP,
+		}
+	}
+	return &incomingFirewallIptables{}
 }
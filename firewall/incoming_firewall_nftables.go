@@ -0,0 +1,153 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package firewall
+
+import (
+	"net"
+	"net/url"
+	"sync"
+
+	"github.com/mysteriumnetwork/node/firewall/nftables"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	nftIncomingFirewallTable     = "myst_provider_firewall"
+	nftIncomingBlockedNetworks   = "blocked_networks"
+	nftIncomingAllowedIPs        = "allowed_ips"
+	nftIncomingAllowedHosts      = "allowed_hosts"
+	nftIncomingAllowedIPsTimeout = "24h"
+)
+
+// incomingFirewallNftables allows incoming traffic blocking in IP granularity using a dedicated nftables table.
+type incomingFirewallNftables struct {
+	lock     sync.Mutex
+	hosts    map[string]int
+	lookupIP func(host string) ([]net.IP, error)
+}
+
+func (ibn *incomingFirewallNftables) Setup() error {
+	if err := checkNftablesVersion(); err != nil {
+		return err
+	}
+
+	// Table of previous runs is replaced, just in case
+	return nftables.NewTransaction().
+		ResetTable("inet", nftIncomingFirewallTable).
+		Add("add set inet %s %s { type ipv4_addr; flags interval; }", nftIncomingFirewallTable, nftIncomingBlockedNetworks).
+		Add("add set inet %s %s { type ipv4_addr; flags timeout; timeout %s; }", nftIncomingFirewallTable, nftIncomingAllowedIPs, nftIncomingAllowedIPsTimeout).
+		Add("add set inet %s %s { type ipv4_addr; }", nftIncomingFirewallTable, nftIncomingAllowedHosts).
+		Add("add chain inet %s forward { type filter hook forward priority 0; policy accept; }", nftIncomingFirewallTable).
+		// Packets going to firewall with these destination IPs are whitelisted
+		Add("add rule inet %s forward ip saddr @%s ip daddr @%s accept", nftIncomingFirewallTable, nftIncomingBlockedNetworks, nftIncomingAllowedIPs).
+		Add("add rule inet %s forward ip saddr @%s ip daddr @%s accept", nftIncomingFirewallTable, nftIncomingBlockedNetworks, nftIncomingAllowedHosts).
+		// By default all packets going to firewall are rejected
+		Add("add rule inet %s forward ip saddr @%s reject", nftIncomingFirewallTable, nftIncomingBlockedNetworks).
+		Apply()
+}
+
+func (ibn *incomingFirewallNftables) Teardown() {
+	if err := nftables.NewTransaction().DeleteTable("inet", nftIncomingFirewallTable).Apply(); err != nil {
+		log.Warn().Err(err).Msg("Error cleaning up nftables rules, you might want to do it yourself")
+	}
+}
+
+func (ibn *incomingFirewallNftables) BlockIncomingTraffic(network net.IPNet) (IncomingRuleRemove, error) {
+	network.IP = network.IP.Mask(network.Mask)
+	return ibn.elementWithRemoval(nftIncomingBlockedNetworks, network.String())
+}
+
+// AllowURLAccess adds URL based exception.
+func (ibn *incomingFirewallNftables) AllowURLAccess(rawURLs ...string) (IncomingRuleRemove, error) {
+	var ruleRemovers []IncomingRuleRemove
+	removeAll := func() error {
+		for _, ruleRemover := range ruleRemovers {
+			if err := ruleRemover(); err != nil {
+				log.Warn().Err(err).Msg("Error removing allowed host")
+			}
+		}
+		return nil
+	}
+
+	for _, rawURL := range rawURLs {
+		parsed, err := url.Parse(rawURL)
+		if err != nil {
+			removeAll()
+			return nil, err
+		}
+
+		ips, err := resolveIPv4(ibn.lookupIP, parsed.Hostname())
+		if err != nil {
+			removeAll()
+			return nil, err
+		}
+		for _, ip := range ips {
+			remover, err := ibn.allowHost(ip.String())
+			if err != nil {
+				removeAll()
+				return nil, err
+			}
+			ruleRemovers = append(ruleRemovers, remover)
+		}
+	}
+	return removeAll, nil
+}
+
+func (ibn *incomingFirewallNftables) AllowIPAccess(ip net.IP) (IncomingRuleRemove, error) {
+	return ibn.elementWithRemoval(nftIncomingAllowedIPs, ip.String())
+}
+
+// allowHost adds the host IP to the set, several URLs may resolve to the same IP so it is removed with the last reference only.
+func (ibn *incomingFirewallNftables) allowHost(ip string) (IncomingRuleRemove, error) {
+	ibn.lock.Lock()
+	defer ibn.lock.Unlock()
+
+	if ibn.hosts[ip] == 0 {
+		if err := nftables.NewTransaction().Add("add element inet %s %s { %s }", nftIncomingFirewallTable, nftIncomingAllowedHosts, ip).Apply(); err != nil {
+			return nil, err
+		}
+	}
+	ibn.hosts[ip]++
+
+	var once sync.Once
+	return func() (err error) {
+		once.Do(func() {
+			ibn.lock.Lock()
+			defer ibn.lock.Unlock()
+
+			ibn.hosts[ip]--
+			if ibn.hosts[ip] > 0 {
+				return
+			}
+			delete(ibn.hosts, ip)
+			err = nftables.NewTransaction().Add("delete element inet %s %s { %s }", nftIncomingFirewallTable, nftIncomingAllowedHosts, ip).Apply()
+		})
+		return err
+	}, nil
+}
+
+func (ibn *incomingFirewallNftables) elementWithRemoval(set, element string) (IncomingRuleRemove, error) {
+	if err := nftables.NewTransaction().Add("add element inet %s %s { %s }", nftIncomingFirewallTable, set, element).Apply(); err != nil {
+		return nil, err
+	}
+	return func() error {
+		return nftables.NewTransaction().Add("delete element inet %s %s { %s }", nftIncomingFirewallTable, set, element).Apply()
+	}, nil
+}
+
+var _ IncomingTrafficFirewall = &incomingFirewallNftables{}
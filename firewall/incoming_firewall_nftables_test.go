@@ -0,0 +1,92 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package firewall
+
+import (
+	"net"
+	"testing"
+
+	"github.com/mysteriumnetwork/node/firewall/nftables"
+	"github.com/stretchr/testify/assert"
+)
+
+func newIncomingFirewallNftablesStub() (*incomingFirewallNftables, *nftablesExecMock) {
+	mockedExec := &nftablesExecMock{
+		mocks: map[string]nftablesExecResult{},
+	}
+	nftables.Exec = mockedExec.Exec
+	nftables.ApplyScript = mockedExec.ApplyScript
+
+	fw := &incomingFirewallNftables{
+		hosts: make(map[string]int),
+		lookupIP: func(host string) ([]net.IP, error) {
+			return []net.IP{net.ParseIP("2.2.2.2")}, nil
+		},
+	}
+	return fw, mockedExec
+}
+
+func Test_incomingFirewallNftables_Setup(t *testing.T) {
+	fw, mockedExec := newIncomingFirewallNftablesStub()
+
+	assert.NoError(t, fw.Setup())
+	assert.True(t, mockedExec.VerifyCalledWithArgs("--version"))
+	assert.True(t, mockedExec.VerifyApplied("add set inet myst_provider_firewall allowed_ips { type ipv4_addr; flags timeout; timeout 24h; }"))
+	assert.True(t, mockedExec.VerifyApplied("add rule inet myst_provider_firewall forward ip saddr @blocked_networks ip daddr @allowed_ips accept"))
+	assert.True(t, mockedExec.VerifyApplied("add rule inet myst_provider_firewall forward ip saddr @blocked_networks reject"))
+}
+
+func Test_incomingFirewallNftables_BlockIncomingTraffic(t *testing.T) {
+	fw, mockedExec := newIncomingFirewallNftablesStub()
+
+	_, network, _ := net.ParseCIDR("10.8.0.1/24")
+	removeRule, err := fw.BlockIncomingTraffic(*network)
+	assert.NoError(t, err)
+	assert.True(t, mockedExec.VerifyApplied("add element inet myst_provider_firewall blocked_networks { 10.8.0.0/24 }"))
+
+	assert.NoError(t, removeRule())
+	assert.True(t, mockedExec.VerifyApplied("delete element inet myst_provider_firewall blocked_networks { 10.8.0.0/24 }"))
+}
+
+func Test_incomingFirewallNftables_AllowIPAccess(t *testing.T) {
+	fw, mockedExec := newIncomingFirewallNftablesStub()
+
+	removeRule, err := fw.AllowIPAccess(net.IP{1, 2, 3, 4})
+	assert.NoError(t, err)
+	assert.True(t, mockedExec.VerifyApplied("add element inet myst_provider_firewall allowed_ips { 1.2.3.4 }"))
+
+	assert.NoError(t, removeRule())
+	assert.True(t, mockedExec.VerifyApplied("delete element inet myst_provider_firewall allowed_ips { 1.2.3.4 }"))
+}
+
+func Test_incomingFirewallNftables_AllowURLAccessIsRemovedWithLastReference(t *testing.T) {
+	fw, mockedExec := newIncomingFirewallNftablesStub()
+
+	removeFirst, err := fw.AllowURLAccess("http://url1")
+	assert.NoError(t, err)
+	removeSecond, err := fw.AllowURLAccess("http://url2")
+	assert.NoError(t, err)
+	assert.Len(t, mockedExec.scripts, 1)
+	assert.True(t, mockedExec.VerifyApplied("add element inet myst_provider_firewall allowed_hosts { 2.2.2.2 }"))
+
+	assert.NoError(t, removeFirst())
+	assert.Len(t, mockedExec.scripts, 1)
+
+	assert.NoError(t, removeSecond())
+	assert.True(t, mockedExec.VerifyApplied("delete element inet myst_provider_firewall allowed_hosts { 2.2.2.2 }"))
+}
@@ -0,0 +1,126 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package nftables
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/mysteriumnetwork/node/firewall/iptables"
+	"github.com/mysteriumnetwork/node/utils/cmdutil"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+// Exec runs nft with the given args.
+var Exec = defaultExec
+
+// ApplyScript applies the given nft script as a single atomic transaction.
+var ApplyScript = defaultApplyScript
+
+func defaultExec(args ...string) ([]string, error) {
+	args = append([]string{"sudo", "nft"}, args...)
+	output, err := cmdutil.ExecOutput(args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "nft cmd error")
+	}
+	return splitLines(output)
+}
+
+func defaultApplyScript(script string) error {
+	cmd := exec.Command("sudo", "nft", "-f", "-")
+	cmd.Stdin = strings.NewReader(script)
+	out, err := cmd.CombinedOutput()
+	log.Debug().Msgf("nft script:\n%s\noutput:\n%s", script, out)
+	if err != nil {
+		return errors.Errorf("nft script error: %v output: %s", err, out)
+	}
+	return nil
+}
+
+func splitLines(output string) ([]string, error) {
+	outputScanner := bufio.NewScanner(bytes.NewBufferString(output))
+	var lines []string
+	for outputScanner.Scan() {
+		lines = append(lines, outputScanner.Text())
+	}
+	return lines, outputScanner.Err()
+}
+
+// Available checks if nftables should be used for the rules management.
+// It is the case if nft works and iptables is either missing or is the nftables based one,
+// mixing nftables with the legacy iptables rules is not reliable.
+func Available() bool {
+	if _, err := Exec("--version"); err != nil {
+		return false
+	}
+
+	output, err := iptables.Exec("--version")
+	if err != nil {
+		return true
+	}
+	return strings.Contains(strings.Join(output, " "), "nf_tables")
+}
+
+// Transaction is a list of nft commands applied atomically, either all of them take effect or none.
+type Transaction struct {
+	commands []string
+}
+
+// NewTransaction creates an empty transaction.
+func NewTransaction() *Transaction {
+	return &Transaction{}
+}
+
+// Add appends the command to the transaction.
+func (t *Transaction) Add(format string, args ...interface{}) *Transaction {
+	t.commands = append(t.commands, fmt.Sprintf(format, args...))
+	return t
+}
+
+// ResetTable appends the commands which replace the table, leftovers of the previous run included, with an empty one.
+func (t *Transaction) ResetTable(family, table string) *Transaction {
+	return t.DeleteTable(family, table).Add("add table %s %s", family, table)
+}
+
+// DeleteTable appends the commands which delete the table along with all its content, if it exists.
+func (t *Transaction) DeleteTable(family, table string) *Transaction {
+	// Adding an existing table is a no-op, so deleting never fails.
+	return t.Add("add table %s %s", family, table).Add("delete table %s %s", family, table)
+}
+
+// Empty checks if the transaction has no commands.
+func (t *Transaction) Empty() bool {
+	return len(t.commands) == 0
+}
+
+// String returns the transaction as an nft script.
+func (t *Transaction) String() string {
+	return strings.Join(t.commands, "\n") + "\n"
+}
+
+// Apply applies all the commands of the transaction atomically.
+func (t *Transaction) Apply() error {
+	if t.Empty() {
+		return nil
+	}
+	return ApplyScript(t.String())
+}
@@ -0,0 +1,48 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package nftables
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransaction(t *testing.T) {
+	var applied []string
+	ApplyScript = func(script string) error {
+		applied = append(applied, script)
+		return nil
+	}
+	defer func() { ApplyScript = defaultApplyScript }()
+
+	err := NewTransaction().
+		ResetTable("inet", "myst").
+		Add("add set inet %s %s { type ipv4_addr; }", "myst", "allowed").
+		Apply()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"add table inet myst\n" +
+			"delete table inet myst\n" +
+			"add table inet myst\n" +
+			"add set inet myst allowed { type ipv4_addr; }\n",
+	}, applied)
+
+	assert.NoError(t, NewTransaction().Apply())
+	assert.Len(t, applied, 1)
+}
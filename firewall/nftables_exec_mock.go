@@ -0,0 +1,67 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package firewall
+
+import (
+	"strings"
+)
+
+type nftablesExecResult struct {
+	called bool
+	output []string
+	err    error
+}
+
+type nftablesExecMock struct {
+	mocks     map[string]nftablesExecResult
+	scripts   []string
+	scriptErr error
+}
+
+func (nem *nftablesExecMock) Exec(args ...string) ([]string, error) {
+	key := argsToKey(args...)
+	res := nem.mocks[key]
+	res.called = true
+	nem.mocks[key] = res
+	return res.output, res.err
+}
+
+func (nem *nftablesExecMock) ApplyScript(script string) error {
+	if nem.scriptErr != nil {
+		return nem.scriptErr
+	}
+	nem.scripts = append(nem.scripts, script)
+	return nil
+}
+
+func (nem *nftablesExecMock) VerifyCalledWithArgs(args ...string) bool {
+	key := argsToKey(args...)
+	return nem.mocks[key].called
+}
+
+// VerifyApplied checks if the command was applied as a part of some transaction.
+func (nem *nftablesExecMock) VerifyApplied(command string) bool {
+	for _, script := range nem.scripts {
+		for _, line := range strings.Split(script, "\n") {
+			if line == command {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -174,10 +174,15 @@ func (obi *outgoingFirewallIptables) cleanupStaleRules() error {
 }
 
 func (obi *outgoingFirewallIptables) trackingReferenceCall(ref string, actualCall func() (OutgoingRuleRemove, error)) (OutgoingRuleRemove, error) {
-	obi.lock.Lock()
-	defer obi.lock.Unlock()
+	return trackingReferenceCall(&obi.lock, obi.referenceTracker, ref, actualCall)
+}
+
+// trackingReferenceCall makes the actual call only for the first reference, the returned removal takes effect once.
+func trackingReferenceCall(lock *sync.Mutex, tracker map[string]refCount, ref string, actualCall func() (OutgoingRuleRemove, error)) (OutgoingRuleRemove, error) {
+	lock.Lock()
+	defer lock.Unlock()
 
-	refCount := obi.referenceTracker[ref]
+	refCount := tracker[ref]
 	if refCount.count == 0 {
 		removeRule, err := actualCall()
 		if err != nil {
@@ -186,23 +191,23 @@ func (obi *outgoingFirewallIptables) trackingReferenceCall(ref string, actualCal
 		refCount.f = removeRule
 
 		refCount.count++
-		obi.referenceTracker[ref] = refCount
+		tracker[ref] = refCount
 	}
 
-	return obi.decreaseRefCall(ref), nil
+	return decreaseRefCall(lock, tracker, ref), nil
 }
 
-func (obi *outgoingFirewallIptables) decreaseRefCall(ref string) OutgoingRuleRemove {
+func decreaseRefCall(lock *sync.Mutex, tracker map[string]refCount, ref string) OutgoingRuleRemove {
 	return func() {
-		obi.lock.Lock()
-		defer obi.lock.Unlock()
+		lock.Lock()
+		defer lock.Unlock()
 
-		refCount := obi.referenceTracker[ref]
+		refCount := tracker[ref]
 		if refCount.count == 1 {
 			refCount.f()
 
 			refCount.count--
-			obi.referenceTracker[ref] = refCount
+			tracker[ref] = refCount
 		}
 	}
 }
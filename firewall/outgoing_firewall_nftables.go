@@ -0,0 +1,233 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package firewall
+
+import (
+	"net"
+	"net/url"
+	"sync"
+
+	"github.com/mysteriumnetwork/node/firewall/nftables"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	nftKillSwitchTable   = "myst_consumer_kill_switch"
+	nftKillSwitchChain   = "kill_switch"
+	nftKillSwitchSources = "blocked_sources"
+	nftKillSwitchAllowed = "allowed_destinations"
+)
+
+// outgoingFirewallNftables is the kill switch implementation using a dedicated nftables table.
+// Blocked sources and allowed destinations are the elements of the table sets, so no rules are changed after setup.
+// The same element can be added by several rules (e.g. a host resolving to an allowed IP), so elements are
+// reference counted per set and deleted once no rule refers to them.
+type outgoingFirewallNftables struct {
+	lock             sync.Mutex
+	trafficLockScope Scope
+	referenceTracker map[string]refCount
+	elementRefs      map[string]int
+	lookupIP         func(host string) ([]net.IP, error)
+}
+
+// Setup tries to setup all changes made by setup and leave system in the state before setup.
+func (obn *outgoingFirewallNftables) Setup() error {
+	if err := checkNftablesVersion(); err != nil {
+		return err
+	}
+
+	return nftables.NewTransaction().
+		ResetTable("inet", nftKillSwitchTable).
+		Add("add set inet %s %s { type ipv4_addr; }", nftKillSwitchTable, nftKillSwitchSources).
+		Add("add set inet %s %s { type ipv4_addr; }", nftKillSwitchTable, nftKillSwitchAllowed).
+		Add("add chain inet %s output { type filter hook output priority 0; policy accept; }", nftKillSwitchTable).
+		Add("add chain inet %s %s", nftKillSwitchTable, nftKillSwitchChain).
+		// Take kill switch chain into effect for packets of the blocked sources
+		Add("add rule inet %s output ip saddr @%s jump %s", nftKillSwitchTable, nftKillSwitchSources, nftKillSwitchChain).
+		// TODO for now always allow outgoing DNS traffic, BUT it should be exposed as separate firewall call
+		Add("add rule inet %s %s udp dport 53 accept", nftKillSwitchTable, nftKillSwitchChain).
+		Add("add rule inet %s %s tcp dport 53 accept", nftKillSwitchTable, nftKillSwitchChain).
+		Add("add rule inet %s %s ip daddr @%s accept", nftKillSwitchTable, nftKillSwitchChain, nftKillSwitchAllowed).
+		// By default all new connections going to kill switch chain are rejected
+		Add("add rule inet %s %s ct state new reject", nftKillSwitchTable, nftKillSwitchChain).
+		Apply()
+}
+
+// Teardown tries to cleanup all changes made by setup and leave system in the state before setup.
+func (obn *outgoingFirewallNftables) Teardown() {
+	if err := nftables.NewTransaction().DeleteTable("inet", nftKillSwitchTable).Apply(); err != nil {
+		log.Warn().Err(err).Msg("Error cleaning up nftables rules, you might want to do it yourself")
+	}
+}
+
+// BlockOutgoingTraffic effectively disallows any outgoing traffic from consumer node with specified scope.
+func (obn *outgoingFirewallNftables) BlockOutgoingTraffic(scope Scope, outboundIP string) (OutgoingRuleRemove, error) {
+	if obn.trafficLockScope == Global {
+		// nothing can override global lock
+		return func() {}, nil
+	}
+	obn.trafficLockScope = scope
+	return trackingReferenceCall(&obn.lock, obn.referenceTracker, "block-traffic", func() (OutgoingRuleRemove, error) {
+		return obn.addElementsWithRemoval(nftKillSwitchSources, []net.IP{net.ParseIP(outboundIP)})
+	})
+}
+
+// AllowIPAccess adds exception to blocked traffic for specified URL (host part is usually taken).
+func (obn *outgoingFirewallNftables) AllowIPAccess(ip string) (OutgoingRuleRemove, error) {
+	return trackingReferenceCall(&obn.lock, obn.referenceTracker, "allow:"+ip, func() (OutgoingRuleRemove, error) {
+		ips, err := resolveIPv4(obn.lookupIP, ip)
+		if err != nil {
+			return nil, err
+		}
+		return obn.addElementsWithRemoval(nftKillSwitchAllowed, ips)
+	})
+}
+
+// AllowURLAccess adds URL based exception.
+func (obn *outgoingFirewallNftables) AllowURLAccess(rawURLs ...string) (OutgoingRuleRemove, error) {
+	var ruleRemovers []func()
+	removeAll := func() {
+		for _, ruleRemover := range ruleRemovers {
+			ruleRemover()
+		}
+	}
+	for _, rawURL := range rawURLs {
+		parsed, err := url.Parse(rawURL)
+		if err != nil {
+			removeAll()
+			return nil, err
+		}
+
+		remover, err := obn.AllowIPAccess(parsed.Hostname())
+		if err != nil {
+			removeAll()
+			return nil, err
+		}
+		ruleRemovers = append(ruleRemovers, remover)
+	}
+	return removeAll, nil
+}
+
+// addElementsWithRemoval adds the IPs to the set, the returned removal deletes only the IPs no other rule refers to.
+// It is called under the lock of the reference tracker.
+func (obn *outgoingFirewallNftables) addElementsWithRemoval(set string, ips []net.IP) (OutgoingRuleRemove, error) {
+	ips = uniqueIPs(ips)
+
+	var added []net.IP
+	for _, ip := range ips {
+		if obn.elementRefs[elementRef(set, ip)] == 0 {
+			added = append(added, ip)
+		}
+	}
+	if len(added) > 0 {
+		if err := nftables.NewTransaction().Add("add element inet %s %s { %s }", nftKillSwitchTable, set, joinIPs(added)).Apply(); err != nil {
+			return nil, err
+		}
+	}
+	for _, ip := range ips {
+		obn.elementRefs[elementRef(set, ip)]++
+	}
+
+	return func() {
+		var removed []net.IP
+		for _, ip := range ips {
+			ref := elementRef(set, ip)
+			obn.elementRefs[ref]--
+			if obn.elementRefs[ref] <= 0 {
+				delete(obn.elementRefs, ref)
+				removed = append(removed, ip)
+			}
+		}
+		if len(removed) == 0 {
+			return
+		}
+
+		elements := joinIPs(removed)
+		if err := nftables.NewTransaction().Add("delete element inet %s %s { %s }", nftKillSwitchTable, set, elements).Apply(); err != nil {
+			log.Warn().Err(err).Msgf("Error deleting %s from nftables set %s, you might wanna do it yourself", elements, set)
+		}
+	}, nil
+}
+
+func elementRef(set string, ip net.IP) string {
+	return set + "/" + ip.String()
+}
+
+func uniqueIPs(ips []net.IP) []net.IP {
+	seen := make(map[string]struct{}, len(ips))
+	var unique []net.IP
+	for _, ip := range ips {
+		if _, ok := seen[ip.String()]; ok {
+			continue
+		}
+		seen[ip.String()] = struct{}{}
+		unique = append(unique, ip)
+	}
+	return unique
+}
+
+func checkNftablesVersion() error {
+	output, err := nftables.Exec("--version")
+	if err != nil {
+		return err
+	}
+	for _, line := range output {
+		log.Info().Msg("[version check] " + line)
+	}
+	return nil
+}
+
+// resolveIPv4 returns IPv4 addresses of the host, nftables sets can not hold host names.
+func resolveIPv4(lookupIP func(host string) ([]net.IP, error), host string) ([]net.IP, error) {
+	if ip := net.ParseIP(host); ip != nil {
+		if ip.To4() == nil {
+			return nil, errors.Errorf("IPv6 address %s is not supported", host)
+		}
+		return []net.IP{ip}, nil
+	}
+
+	resolved, err := lookupIP(host)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not resolve %s", host)
+	}
+
+	var ips []net.IP
+	for _, ip := range resolved {
+		if ip.To4() != nil {
+			ips = append(ips, ip)
+		}
+	}
+	if len(ips) == 0 {
+		return nil, errors.Errorf("no IPv4 addresses resolved for %s", host)
+	}
+	return ips, nil
+}
+
+func joinIPs(ips []net.IP) string {
+	elements := ""
+	for i, ip := range ips {
+		if i > 0 {
+			elements += ", "
+		}
+		elements += ip.String()
+	}
+	return elements
+}
+
+var _ OutgoingTrafficFirewall = &outgoingFirewallNftables{}
@@ -0,0 +1,104 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package firewall
+
+import (
+	"net"
+	"testing"
+
+	"github.com/mysteriumnetwork/node/firewall/nftables"
+	"github.com/stretchr/testify/assert"
+)
+
+func newOutgoingFirewallNftablesStub() (*outgoingFirewallNftables, *nftablesExecMock) {
+	mockedExec := &nftablesExecMock{
+		mocks: map[string]nftablesExecResult{},
+	}
+	nftables.Exec = mockedExec.Exec
+	nftables.ApplyScript = mockedExec.ApplyScript
+
+	fw := &outgoingFirewallNftables{
+		referenceTracker: make(map[string]refCount),
+		elementRefs:      make(map[string]int),
+		lookupIP: func(host string) ([]net.IP, error) {
+			return []net.IP{net.ParseIP("2.2.2.2"), net.ParseIP("::1")}, nil
+		},
+	}
+	return fw, mockedExec
+}
+
+func Test_outgoingFirewallNftables_SetupIsSuccessful(t *testing.T) {
+	fw, mockedExec := newOutgoingFirewallNftablesStub()
+
+	assert.NoError(t, fw.Setup())
+	assert.True(t, mockedExec.VerifyCalledWithArgs("--version"))
+	assert.Len(t, mockedExec.scripts, 1)
+	assert.True(t, mockedExec.VerifyApplied("delete table inet myst_consumer_kill_switch"))
+	assert.True(t, mockedExec.VerifyApplied("add rule inet myst_consumer_kill_switch output ip saddr @blocked_sources jump kill_switch"))
+	assert.True(t, mockedExec.VerifyApplied("add rule inet myst_consumer_kill_switch kill_switch ct state new reject"))
+}
+
+func Test_outgoingFirewallNftables_BlocksAllOutgoingTraffic(t *testing.T) {
+	fw, mockedExec := newOutgoingFirewallNftablesStub()
+
+	removeRuleFunc, err := fw.BlockOutgoingTraffic("test-scope", "1.1.1.1")
+	assert.NoError(t, err)
+	assert.True(t, mockedExec.VerifyApplied("add element inet myst_consumer_kill_switch blocked_sources { 1.1.1.1 }"))
+
+	removeRuleFunc()
+	assert.True(t, mockedExec.VerifyApplied("delete element inet myst_consumer_kill_switch blocked_sources { 1.1.1.1 }"))
+}
+
+func Test_outgoingFirewallNftables_HostsAreResolved(t *testing.T) {
+	fw, mockedExec := newOutgoingFirewallNftablesStub()
+
+	removeRules, err := fw.AllowURLAccess("http://url1", "http://3.3.3.3:500/ignoredpath")
+	assert.NoError(t, err)
+	assert.True(t, mockedExec.VerifyApplied("add element inet myst_consumer_kill_switch allowed_destinations { 2.2.2.2 }"))
+	assert.True(t, mockedExec.VerifyApplied("add element inet myst_consumer_kill_switch allowed_destinations { 3.3.3.3 }"))
+	assert.Equal(t, 1, fw.referenceTracker["allow:url1"].count)
+
+	removeRules()
+	assert.True(t, mockedExec.VerifyApplied("delete element inet myst_consumer_kill_switch allowed_destinations { 2.2.2.2 }"))
+	assert.True(t, mockedExec.VerifyApplied("delete element inet myst_consumer_kill_switch allowed_destinations { 3.3.3.3 }"))
+	assert.Equal(t, 0, fw.referenceTracker["allow:url1"].count)
+}
+
+func Test_outgoingFirewallNftables_SharedElementIsDeletedByLastRule(t *testing.T) {
+	fw, mockedExec := newOutgoingFirewallNftablesStub()
+
+	removeHost, err := fw.AllowIPAccess("url1")
+	assert.NoError(t, err)
+	removeIP, err := fw.AllowIPAccess("2.2.2.2")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"add element inet myst_consumer_kill_switch allowed_destinations { 2.2.2.2 }\n"}, mockedExec.scripts)
+
+	removeHost()
+	assert.False(t, mockedExec.VerifyApplied("delete element inet myst_consumer_kill_switch allowed_destinations { 2.2.2.2 }"))
+
+	removeIP()
+	assert.True(t, mockedExec.VerifyApplied("delete element inet myst_consumer_kill_switch allowed_destinations { 2.2.2.2 }"))
+	assert.Empty(t, fw.elementRefs)
+}
+
+func Test_outgoingFirewallNftables_Teardown(t *testing.T) {
+	fw, mockedExec := newOutgoingFirewallNftablesStub()
+
+	fw.Teardown()
+	assert.Equal(t, []string{"add table inet myst_consumer_kill_switch\ndelete table inet myst_consumer_kill_switch\n"}, mockedExec.scripts)
+}
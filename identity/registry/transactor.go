@@ -127,7 +127,7 @@ func (t *Transactor) FetchRegistrationFees() (FeesResponse, error) {
 		return f, errors.Wrap(err, "failed to fetch transactor fees")
 	}
 
-	err = t.httpClient.DoRequestAndParseResponse(req, &f)
+	err = t.httpClient.DoRequestAndParseResponse(requests.WithCallClass(req, requests.CallIdempotent), &f)
 	return f, err
 }
 
@@ -140,7 +140,7 @@ func (t *Transactor) FetchSettleFees() (FeesResponse, error) {
 		return f, errors.Wrap(err, "failed to fetch transactor fees")
 	}
 
-	err = t.httpClient.DoRequestAndParseResponse(req, &f)
+	err = t.httpClient.DoRequestAndParseResponse(requests.WithCallClass(req, requests.CallIdempotent), &f)
 	return f, err
 }
 
@@ -163,7 +163,7 @@ func (t *Transactor) TopUp(id string) error {
 	// This is left as a synchronous call on purpose.
 	t.publisher.Publish(AppTopicTransactorTopUp, id)
 
-	return t.httpClient.DoRequest(req)
+	return t.httpClient.DoRequest(requests.WithCallClass(req, requests.CallNonIdempotent))
 }
 
 // SettleAndRebalance requests the transactor to settle and rebalance the given channel
@@ -181,7 +181,7 @@ func (t *Transactor) SettleAndRebalance(accountantID string, promise pc.Promise)
 	if err != nil {
 		return errors.Wrap(err, "failed to create TopUp request")
 	}
-	return t.httpClient.DoRequest(req)
+	return t.httpClient.DoRequest(requests.WithCallClass(req, requests.CallNonIdempotent))
 }
 
 // RegisterIdentity instructs Transactor to register identity on behalf of a client identified by 'id'
@@ -205,7 +205,7 @@ func (t *Transactor) RegisterIdentity(id string, regReqDTO *IdentityRegistration
 	// We need to notify registry before returning.
 	t.publisher.Publish(AppTopicTransactorRegistration, regReq)
 
-	return t.httpClient.DoRequest(req)
+	return t.httpClient.DoRequest(requests.WithCallClass(req, requests.CallNonIdempotent))
 }
 
 func (t *Transactor) fillIdentityRegistrationRequest(id string, regReqDTO IdentityRegistrationRequestDTO) (IdentityRegistrationRequest, error) {
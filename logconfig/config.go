@@ -77,7 +77,7 @@ func Configure(opts *LogOptions) {
 			log.Err(err).Msg("Failed to cleanup obsolete logs")
 		}
 	}
-	log.Logger = log.Logger.Level(opts.LogLevel)
+	levels.SetDefaultLevel(opts.LogLevel, 0)
 }
 
 func consoleWriter() io.Writer {
@@ -88,16 +88,18 @@ func consoleWriter() io.Writer {
 }
 
 func makeLogger(w io.Writer) zerolog.Logger {
-	return log.Output(w).
-		Level(zerolog.DebugLevel).
+	return zerolog.New(io.MultiWriter(w, tail)).
+		Level(zerolog.TraceLevel).
 		With().
 		Caller().
 		Timestamp().
 		Logger()
 }
 
+// setGlobalLogger sets the root logger of components and the global logger, which is filtered by the default level.
 func setGlobalLogger(logger *zerolog.Logger) {
-	log.Logger = *logger
+	rootLogger.Store(logger)
+	log.Logger = logger.Hook(defaultLevelHook{})
 	stdlog.SetFlags(0)
 	stdlog.SetOutput(log.Logger)
 }
@@ -0,0 +1,231 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package logconfig
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// ErrUnknownComponent is returned when changing log level of a component which has no logger.
+var ErrUnknownComponent = errors.New("unknown log component")
+
+// levelInherit marks a component logger which uses the default log level.
+const levelInherit = int32(-128)
+
+// ComponentLevel describes log level override of a component.
+type ComponentLevel struct {
+	Component string
+	Level     zerolog.Level
+	// ExpiresAt is the time when the override is reverted, zero if it is permanent.
+	ExpiresAt time.Time
+}
+
+// LevelState describes current log levels.
+type LevelState struct {
+	Default zerolog.Level
+	// DefaultExpiresAt is the time when the default level is reverted, zero if it is permanent.
+	DefaultExpiresAt time.Time
+	Overrides        []ComponentLevel
+	Components       []string
+}
+
+// LevelRegistry keeps the default log level and component level overrides which can be changed at runtime.
+type LevelRegistry struct {
+	mu           sync.Mutex
+	defaultLevel int32
+	defaultTimer *revertTimer
+	loggers      map[string]*Logger
+	timers       map[string]*revertTimer
+}
+
+type revertTimer struct {
+	timer     *time.Timer
+	expiresAt time.Time
+}
+
+var levels = &LevelRegistry{
+	defaultLevel: int32(zerolog.DebugLevel),
+	loggers:      make(map[string]*Logger),
+	timers:       make(map[string]*revertTimer),
+}
+
+// LogLevels returns the registry of node log levels.
+func LogLevels() *LevelRegistry {
+	return levels
+}
+
+// DefaultLevel returns the log level of messages logged without a component.
+func (r *LevelRegistry) DefaultLevel() zerolog.Level {
+	return zerolog.Level(atomic.LoadInt32(&r.defaultLevel))
+}
+
+// SetDefaultLevel changes the log level of messages logged without a component.
+// When ttl is positive, the previous level is restored after it passes.
+func (r *LevelRegistry) SetDefaultLevel(level zerolog.Level, ttl time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	previous := zerolog.Level(atomic.LoadInt32(&r.defaultLevel))
+	if r.defaultTimer != nil {
+		r.defaultTimer.timer.Stop()
+		r.defaultTimer = nil
+	}
+	atomic.StoreInt32(&r.defaultLevel, int32(level))
+	if ttl > 0 {
+		r.defaultTimer = r.revertAfter(ttl, func(timer *revertTimer) {
+			if r.defaultTimer != timer {
+				return
+			}
+			r.defaultTimer = nil
+			atomic.StoreInt32(&r.defaultLevel, int32(previous))
+			log.Info().Msgf("Default log level reverted to %s", previous)
+		})
+	}
+	r.updateGlobalLevel()
+}
+
+// SetComponentLevel overrides the log level of the component.
+// When ttl is positive, the previous level is restored after it passes.
+func (r *LevelRegistry) SetComponentLevel(component string, level zerolog.Level, ttl time.Duration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	logger, ok := r.loggers[component]
+	if !ok {
+		return errors.Wrap(ErrUnknownComponent, component)
+	}
+	previous := atomic.LoadInt32(&logger.level)
+	r.stopTimer(component)
+	atomic.StoreInt32(&logger.level, int32(level))
+	if ttl > 0 {
+		r.timers[component] = r.revertAfter(ttl, func(timer *revertTimer) {
+			if r.timers[component] != timer {
+				return
+			}
+			delete(r.timers, component)
+			atomic.StoreInt32(&logger.level, previous)
+			log.Info().Msgf("Log level of %s reverted to %s", component, logger.Level())
+		})
+	}
+	r.updateGlobalLevel()
+	return nil
+}
+
+// ResetComponentLevel restores the log level the component logger was created with.
+func (r *LevelRegistry) ResetComponentLevel(component string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	logger, ok := r.loggers[component]
+	if !ok {
+		return errors.Wrap(ErrUnknownComponent, component)
+	}
+	r.stopTimer(component)
+	atomic.StoreInt32(&logger.level, logger.defaultLevel)
+	r.updateGlobalLevel()
+	return nil
+}
+
+// State returns the default log level, component overrides and all known components.
+func (r *LevelRegistry) State() LevelState {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	result := LevelState{
+		Default:    zerolog.Level(atomic.LoadInt32(&r.defaultLevel)),
+		Overrides:  make([]ComponentLevel, 0),
+		Components: make([]string, 0, len(r.loggers)),
+	}
+	if r.defaultTimer != nil {
+		result.DefaultExpiresAt = r.defaultTimer.expiresAt
+	}
+	for component, logger := range r.loggers {
+		result.Components = append(result.Components, component)
+
+		level := atomic.LoadInt32(&logger.level)
+		if level == levelInherit {
+			continue
+		}
+		override := ComponentLevel{Component: component, Level: zerolog.Level(level)}
+		if timer, ok := r.timers[component]; ok {
+			override.ExpiresAt = timer.expiresAt
+		}
+		result.Overrides = append(result.Overrides, override)
+	}
+	sort.Strings(result.Components)
+	sort.Slice(result.Overrides, func(i, j int) bool {
+		return result.Overrides[i].Component < result.Overrides[j].Component
+	})
+	return result
+}
+
+func (r *LevelRegistry) register(logger *Logger) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.loggers[logger.component] = logger
+}
+
+// revertAfter schedules the revert, which is skipped if the timer was replaced in the meantime.
+func (r *LevelRegistry) revertAfter(ttl time.Duration, revert func(timer *revertTimer)) *revertTimer {
+	timer := &revertTimer{expiresAt: time.Now().Add(ttl)}
+	timer.timer = time.AfterFunc(ttl, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		revert(timer)
+		r.updateGlobalLevel()
+	})
+	return timer
+}
+
+func (r *LevelRegistry) stopTimer(component string) {
+	if timer, ok := r.timers[component]; ok {
+		timer.timer.Stop()
+		delete(r.timers, component)
+	}
+}
+
+// updateGlobalLevel lowers zerolog global level to the most verbose level in use,
+// so that messages nobody is interested in are dropped before they are built.
+func (r *LevelRegistry) updateGlobalLevel() {
+	lowest := atomic.LoadInt32(&r.defaultLevel)
+	for _, logger := range r.loggers {
+		if level := atomic.LoadInt32(&logger.level); level != levelInherit && level < lowest {
+			lowest = level
+		}
+	}
+	zerolog.SetGlobalLevel(zerolog.Level(lowest))
+}
+
+// defaultLevelHook drops messages of the global logger which are below the default level.
+type defaultLevelHook struct{}
+
+// Run discards the event if its level is below the default level (zerolog hook).
+func (defaultLevelHook) Run(e *zerolog.Event, level zerolog.Level, _ string) {
+	if level != zerolog.NoLevel && level < levels.DefaultLevel() {
+		e.Discard()
+	}
+}
@@ -0,0 +1,158 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package logconfig
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogger_FollowsDefaultLevelUnlessOverridden(t *testing.T) {
+	var buf bytes.Buffer
+	restore := useTestLogger(&buf)
+	defer restore()
+
+	componentLog := NewLogger("test/override")
+	levels.SetDefaultLevel(zerolog.InfoLevel, 0)
+
+	componentLog.Debug().Msg("component debug hidden")
+	componentLog.Info().Msg("component info shown")
+	log.Debug().Msg("global debug hidden")
+
+	require.NoError(t, levels.SetComponentLevel("test/override", zerolog.DebugLevel, 0))
+	componentLog.Debug().Msg("component debug shown")
+	log.Debug().Msg("global debug still hidden")
+
+	require.NoError(t, levels.ResetComponentLevel("test/override"))
+	componentLog.Debug().Msg("component debug hidden again")
+
+	output := buf.String()
+	assert.Contains(t, output, `"component":"test/override","message":"component info shown"`)
+	assert.Contains(t, output, "component debug shown")
+	assert.NotContains(t, output, "hidden")
+}
+
+func TestLogger_WithLevelIgnoresDefaultLevel(t *testing.T) {
+	var buf bytes.Buffer
+	restore := useTestLogger(&buf)
+	defer restore()
+
+	componentLog := NewLoggerWithLevel("test/quiet", zerolog.Disabled)
+	componentLog.Info().Msg("component info hidden")
+
+	require.NoError(t, levels.SetComponentLevel("test/quiet", zerolog.DebugLevel, 0))
+	componentLog.Debug().Msg("component debug shown")
+
+	require.NoError(t, levels.ResetComponentLevel("test/quiet"))
+	assert.Equal(t, zerolog.Disabled, componentLog.Level())
+	componentLog.Info().Msg("component info hidden again")
+
+	output := buf.String()
+	assert.Contains(t, output, "component debug shown")
+	assert.NotContains(t, output, "hidden")
+}
+
+func TestLogger_RebuildsCachedLoggerOnLevelChange(t *testing.T) {
+	var buf bytes.Buffer
+	restore := useTestLogger(&buf)
+	defer restore()
+
+	componentLog := NewLogger("test/cache")
+	logger := componentLog.logger()
+	assert.Same(t, logger, componentLog.logger())
+
+	require.NoError(t, levels.SetComponentLevel("test/cache", zerolog.WarnLevel, 0))
+	defer levels.ResetComponentLevel("test/cache")
+	assert.NotSame(t, logger, componentLog.logger())
+	assert.Equal(t, zerolog.WarnLevel, componentLog.logger().GetLevel())
+}
+
+func TestLevelRegistry_RevertsAfterTTL(t *testing.T) {
+	componentLog := NewLogger("test/ttl")
+	levels.SetDefaultLevel(zerolog.InfoLevel, 0)
+	defer levels.SetDefaultLevel(zerolog.DebugLevel, 0)
+
+	require.NoError(t, levels.SetComponentLevel("test/ttl", zerolog.TraceLevel, 20*time.Millisecond))
+	levels.SetDefaultLevel(zerolog.WarnLevel, 20*time.Millisecond)
+
+	state := levels.State()
+	assert.Equal(t, zerolog.WarnLevel, state.Default)
+	assert.False(t, state.DefaultExpiresAt.IsZero())
+	assert.Contains(t, state.Components, "test/ttl")
+	index := indexOfOverride(state, "test/ttl")
+	require.NotEqual(t, -1, index)
+	assert.Equal(t, zerolog.TraceLevel, state.Overrides[index].Level)
+	assert.False(t, state.Overrides[index].ExpiresAt.IsZero())
+	assert.Equal(t, zerolog.TraceLevel, zerolog.GlobalLevel())
+
+	assert.Eventually(t, func() bool {
+		return componentLog.Level() == zerolog.InfoLevel && levels.DefaultLevel() == zerolog.InfoLevel
+	}, time.Second, 5*time.Millisecond)
+	assert.Equal(t, zerolog.InfoLevel, zerolog.GlobalLevel())
+	assert.Equal(t, -1, indexOfOverride(levels.State(), "test/ttl"))
+}
+
+func TestLevelRegistry_ReplacingOverrideCancelsRevert(t *testing.T) {
+	componentLog := NewLogger("test/replace")
+
+	require.NoError(t, levels.SetComponentLevel("test/replace", zerolog.TraceLevel, 20*time.Millisecond))
+	require.NoError(t, levels.SetComponentLevel("test/replace", zerolog.ErrorLevel, 0))
+	defer levels.ResetComponentLevel("test/replace")
+
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, zerolog.ErrorLevel, componentLog.Level())
+}
+
+func TestLevelRegistry_UnknownComponent(t *testing.T) {
+	err := levels.SetComponentLevel("test/unknown", zerolog.TraceLevel, 0)
+	assert.Error(t, err)
+	assert.Equal(t, ErrUnknownComponent, errors.Cause(err))
+
+	err = levels.ResetComponentLevel("test/unknown")
+	assert.Equal(t, ErrUnknownComponent, errors.Cause(err))
+}
+
+func indexOfOverride(state LevelState, component string) int {
+	for i, override := range state.Overrides {
+		if override.Component == component {
+			return i
+		}
+	}
+	return -1
+}
+
+func useTestLogger(buf *bytes.Buffer) func() {
+	original := log.Logger
+	originalRoot, _ := rootLogger.Load().(*zerolog.Logger)
+
+	logger := zerolog.New(buf)
+	setGlobalLogger(&logger)
+
+	return func() {
+		levels.SetDefaultLevel(zerolog.DebugLevel, 0)
+		log.Logger = original
+		rootLogger.Store(originalRoot)
+	}
+}
@@ -0,0 +1,131 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package logconfig
+
+import (
+	"sync/atomic"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// rootLogger holds the global logger without default level filtering (*zerolog.Logger),
+// component loggers are derived from it.
+var rootLogger atomic.Value
+
+// Logger is a named logger of a node component (e.g. p2p, pingpong).
+// Its level follows the default level unless it is overridden at runtime.
+type Logger struct {
+	component    string
+	level        int32
+	defaultLevel int32
+	cache        atomic.Value
+}
+
+type cachedLogger struct {
+	root   *zerolog.Logger
+	level  zerolog.Level
+	logger zerolog.Logger
+}
+
+// NewLogger creates a logger of the named component.
+// It is meant to be assigned to a package level variable named log, replacing the zerolog/log import.
+func NewLogger(component string) *Logger {
+	return newLogger(component, levelInherit)
+}
+
+// NewLoggerWithLevel creates a logger of the named component which uses the given level
+// instead of the default level until it is overridden at runtime, e.g. for very verbose components.
+func NewLoggerWithLevel(component string, level zerolog.Level) *Logger {
+	return newLogger(component, int32(level))
+}
+
+func newLogger(component string, level int32) *Logger {
+	logger := &Logger{component: component, level: level, defaultLevel: level}
+	levels.register(logger)
+	return logger
+}
+
+// Component returns the name of the component.
+func (l *Logger) Component() string {
+	return l.component
+}
+
+// Level returns the current log level of the component.
+func (l *Logger) Level() zerolog.Level {
+	if level := atomic.LoadInt32(&l.level); level != levelInherit {
+		return zerolog.Level(level)
+	}
+	return levels.DefaultLevel()
+}
+
+// Trace starts a new message with trace level.
+func (l *Logger) Trace() *zerolog.Event {
+	return l.logger().Trace()
+}
+
+// Debug starts a new message with debug level.
+func (l *Logger) Debug() *zerolog.Event {
+	return l.logger().Debug()
+}
+
+// Info starts a new message with info level.
+func (l *Logger) Info() *zerolog.Event {
+	return l.logger().Info()
+}
+
+// Warn starts a new message with warn level.
+func (l *Logger) Warn() *zerolog.Event {
+	return l.logger().Warn()
+}
+
+// Error starts a new message with error level.
+func (l *Logger) Error() *zerolog.Event {
+	return l.logger().Error()
+}
+
+// Err starts a new message with error level with err as a field if not nil or with info level if err is nil.
+func (l *Logger) Err(err error) *zerolog.Event {
+	return l.logger().Err(err)
+}
+
+// WithLevel starts a new message with level.
+func (l *Logger) WithLevel(level zerolog.Level) *zerolog.Event {
+	return l.logger().WithLevel(level)
+}
+
+func (l *Logger) logger() *zerolog.Logger {
+	root, _ := rootLogger.Load().(*zerolog.Logger)
+	if root == nil {
+		// Logging was not configured (e.g. in tests), follow the global logger as is.
+		logger := log.Logger.With().Str("component", l.component).Logger().Level(l.Level())
+		return &logger
+	}
+
+	level := l.Level()
+	cached, ok := l.cache.Load().(*cachedLogger)
+	if !ok || cached.root != root || cached.level != level {
+		cached = &cachedLogger{
+			root:   root,
+			level:  level,
+			logger: root.With().Str("component", l.component).Logger().Level(level),
+		}
+		l.cache.Store(cached)
+	}
+	return &cached.logger
+}
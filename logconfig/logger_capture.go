@@ -18,6 +18,8 @@
 package logconfig
 
 import (
+	"sync"
+
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 )
@@ -54,3 +56,93 @@ func (l *LogCapturer) Run(e *zerolog.Event, level zerolog.Level, message string)
 func (l *LogCapturer) Messages() []string {
 	return l.logs
 }
+
+const (
+	tailSize             = 1000
+	tailSubscriberBuffer = 256
+)
+
+// tail captures output of the global logger.
+var tail = NewLogTail(tailSize)
+
+// Tail returns the captured tail of the node log.
+func Tail() *LogTail {
+	return tail
+}
+
+// LogTail captures the most recent log lines (JSON) in memory and streams new ones to subscribers.
+// Typical use case is watching the log of a running node remotely.
+type LogTail struct {
+	mu          sync.Mutex
+	lines       [][]byte
+	next        int
+	subscribers map[chan []byte]struct{}
+}
+
+// NewLogTail creates a LogTail keeping up to size lines.
+func NewLogTail(size int) *LogTail {
+	return &LogTail{
+		lines:       make([][]byte, 0, size),
+		subscribers: make(map[chan []byte]struct{}),
+	}
+}
+
+// Write captures a log line, it never fails (io.Writer).
+func (t *LogTail) Write(p []byte) (int, error) {
+	line := make([]byte, len(p))
+	copy(line, p)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if len(t.lines) < cap(t.lines) {
+		t.lines = append(t.lines, line)
+	} else {
+		t.lines[t.next] = line
+		t.next = (t.next + 1) % len(t.lines)
+	}
+
+	for subscriber := range t.subscribers {
+		select {
+		case subscriber <- line:
+		default:
+			// Slow subscribers miss lines rather than block logging.
+		}
+	}
+	return len(p), nil
+}
+
+// Lines returns captured lines, oldest first.
+func (t *LogTail) Lines() [][]byte {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.recent()
+}
+
+// Subscribe returns captured lines and a channel of lines logged from now on.
+// Unsubscribe must be called when lines are not needed anymore.
+func (t *LogTail) Subscribe() (recent [][]byte, lines <-chan []byte, unsubscribe func()) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	subscriber := make(chan []byte, tailSubscriberBuffer)
+	t.subscribers[subscriber] = struct{}{}
+
+	var once sync.Once
+	unsubscribe = func() {
+		once.Do(func() {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+
+			delete(t.subscribers, subscriber)
+		})
+	}
+	return t.recent(), subscriber, unsubscribe
+}
+
+func (t *LogTail) recent() [][]byte {
+	result := make([][]byte, 0, len(t.lines))
+	result = append(result, t.lines[t.next:]...)
+	return append(result, t.lines[:t.next]...)
+}
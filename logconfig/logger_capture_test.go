@@ -0,0 +1,62 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package logconfig
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogTail_KeepsRecentLines(t *testing.T) {
+	tail := NewLogTail(2)
+
+	for _, line := range []string{"first\n", "second\n", "third\n"} {
+		n, err := tail.Write([]byte(line))
+		require.NoError(t, err)
+		assert.Equal(t, len(line), n)
+	}
+
+	assert.Equal(t, [][]byte{[]byte("second\n"), []byte("third\n")}, tail.Lines())
+}
+
+func TestLogTail_StreamsNewLines(t *testing.T) {
+	tail := NewLogTail(10)
+	_, _ = tail.Write([]byte("before\n"))
+
+	recent, lines, unsubscribe := tail.Subscribe()
+	assert.Equal(t, [][]byte{[]byte("before\n")}, recent)
+
+	buf := []byte("after\n")
+	_, _ = tail.Write(buf)
+	buf[0] = 'X'
+
+	select {
+	case line := <-lines:
+		assert.Equal(t, "after\n", string(line))
+	case <-time.After(time.Second):
+		t.Fatal("line was not streamed")
+	}
+
+	unsubscribe()
+	unsubscribe()
+	_, _ = tail.Write([]byte("unsubscribed\n"))
+	assert.Len(t, lines, 0)
+}
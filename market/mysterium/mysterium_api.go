@@ -58,7 +58,7 @@ func (mApi *MysteriumAPI) IdentityExists(id identity.Identity, signer identity.S
 	if err != nil {
 		return false, err
 	}
-	res, err := mApi.httpClient.Do(req)
+	res, err := mApi.httpClient.Do(requests.WithCallClass(req, requests.CallIdempotent))
 	if err != nil {
 		return false, err
 	}
@@ -74,7 +74,7 @@ func (mApi *MysteriumAPI) RegisterIdentity(id identity.Identity, signer identity
 		return err
 	}
 
-	err = mApi.httpClient.DoRequest(req)
+	err = mApi.httpClient.DoRequest(requests.WithCallClass(req, requests.CallNonIdempotent))
 	if err == nil {
 		log.Info().Msg("Identity registered: " + id.Address)
 	}
@@ -90,7 +90,7 @@ func (mApi *MysteriumAPI) GetPayoutInfo(id identity.Identity, signer identity.Si
 	}
 
 	var payoutInfoResponse PayoutInfoResponse
-	err = mApi.httpClient.DoRequestAndParseResponse(req, &payoutInfoResponse)
+	err = mApi.httpClient.DoRequestAndParseResponse(requests.WithCallClass(req, requests.CallIdempotent), &payoutInfoResponse)
 	if err != nil {
 		return nil, err
 	}
@@ -109,7 +109,7 @@ func (mApi *MysteriumAPI) UpdatePayoutInfo(id identity.Identity, ethAddress stri
 		return err
 	}
 
-	err = mApi.httpClient.DoRequest(req)
+	err = mApi.httpClient.DoRequest(requests.WithCallClass(req, requests.CallIdempotent))
 	if err == nil {
 		log.Info().Msg("Payout address registered: " + ethAddress)
 	}
@@ -127,7 +127,7 @@ func (mApi *MysteriumAPI) UpdateReferralInfo(id identity.Identity, referralCode
 		return err
 	}
 
-	err = mApi.httpClient.DoRequest(req)
+	err = mApi.httpClient.DoRequest(requests.WithCallClass(req, requests.CallIdempotent))
 	if err == nil {
 		log.Info().Msg("Referral code submitted for: " + id.Address)
 	}
@@ -145,7 +145,7 @@ func (mApi *MysteriumAPI) UpdateEmail(id identity.Identity, email string, signer
 		return err
 	}
 
-	err = mApi.httpClient.DoRequest(req)
+	err = mApi.httpClient.DoRequest(requests.WithCallClass(req, requests.CallIdempotent))
 	if err == nil {
 		log.Info().Msg("Email submitted for: " + id.Address)
 	}
@@ -161,7 +161,7 @@ func (mApi *MysteriumAPI) RegisterProposal(proposal market.ServiceProposal, sign
 		return err
 	}
 
-	err = mApi.httpClient.DoRequest(req)
+	err = mApi.httpClient.DoRequest(requests.WithCallClass(req, requests.CallNonIdempotent))
 	if err == nil {
 		log.Info().Msgf("Proposal registered for node: %s service type: %s", proposal.ProviderID, proposal.ServiceType)
 	}
@@ -179,7 +179,7 @@ func (mApi *MysteriumAPI) UnregisterProposal(proposal market.ServiceProposal, si
 		return err
 	}
 
-	err = mApi.httpClient.DoRequest(req)
+	err = mApi.httpClient.DoRequest(requests.WithCallClass(req, requests.CallNonIdempotent))
 
 	if err == nil {
 		log.Info().Msg("Proposal unregistered for node: " + proposal.ProviderID)
@@ -198,7 +198,7 @@ func (mApi *MysteriumAPI) PingProposal(proposal market.ServiceProposal, signer i
 		return err
 	}
 
-	err = mApi.httpClient.DoRequest(req)
+	err = mApi.httpClient.DoRequest(requests.WithCallClass(req, requests.CallNonIdempotent))
 	if err == nil {
 		log.Info().Msgf("Proposal pinged for node: %s service type: %s", proposal.ProviderID, proposal.ServiceType)
 	}
@@ -241,7 +241,7 @@ func (mApi *MysteriumAPI) QueryProposals(query ProposalsQuery) ([]market.Service
 	}
 	req.Header.Add("If-None-Match", mApi.getLatestProposalsEtag())
 
-	res, err := mApi.httpClient.Do(req)
+	res, err := mApi.httpClient.Do(requests.WithCallClass(req, requests.CallIdempotent))
 	if err != nil {
 		return nil, errors.Wrap(err, "cannot fetch proposals")
 	}
@@ -301,7 +301,7 @@ func (mApi *MysteriumAPI) SendSessionStats(sessionID session.ID, sessionStats Se
 		return err
 	}
 
-	err = mApi.httpClient.DoRequest(req)
+	err = mApi.httpClient.DoRequest(requests.WithCallClass(req, requests.CallNonIdempotent))
 	if err == nil {
 		log.Info().Msg("Session stats sent: " + string(sessionID))
 	}
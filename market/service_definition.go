@@ -28,6 +28,11 @@ type ServiceDefinition interface {
 	GetLocation() Location
 }
 
+// IPv6Capable is implemented by the service definitions which tell whether the service provides IPv6 egress.
+type IPv6Capable interface {
+	SupportsIPv6() bool
+}
+
 // UnsupportedServiceDefinition represents unknown or unsupported service definition returned by deserializer
 type UnsupportedServiceDefinition struct {
 }
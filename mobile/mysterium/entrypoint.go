@@ -29,6 +29,7 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/mysteriumnetwork/node/cmd"
+	"github.com/mysteriumnetwork/node/config"
 	"github.com/mysteriumnetwork/node/core/connection"
 	"github.com/mysteriumnetwork/node/core/discovery"
 	"github.com/mysteriumnetwork/node/core/discovery/proposal"
@@ -69,6 +70,9 @@ type MobileNode struct {
 	consumerBalanceTracker       *pingpong.ConsumerBalanceTracker
 	registryAddress              string
 	channelImplementationAddress string
+	provider                     *providerMode
+	sessionStorage               sessionStorage
+	stateKeeper                  stateKeeper
 }
 
 // MobileNodeOptions contains common mobile node options.
@@ -89,6 +93,8 @@ type MobileNodeOptions struct {
 	AccountantEndpointAddress       string
 	AccountantID                    string
 	MystSCAddress                   string
+	// ProviderEnabled bootstraps provider components, services are started with StartProvider.
+	ProviderEnabled bool
 }
 
 // DefaultNodeOptions returns default options.
@@ -198,6 +204,12 @@ func NewNode(appPath string, options *MobileNodeOptions) (*MobileNode, error) {
 			ConsumerUpperGBPriceBound:          7000000,
 		},
 		MobileConsumer: true,
+		MobileProvider: options.ProviderEnabled,
+	}
+
+	if options.ProviderEnabled {
+		config.Current.SetDefault(config.FlagAccessPolicyAddress.Name, config.FlagAccessPolicyAddress.Value)
+		config.Current.SetDefault(config.FlagAccessPolicyFetchInterval.Name, config.FlagAccessPolicyFetchInterval.Value)
 	}
 
 	err := di.Bootstrap(nodeOptions)
@@ -224,6 +236,8 @@ func NewNode(appPath string, options *MobileNodeOptions) (*MobileNode, error) {
 		identityChannelCalculator:    di.ChannelAddressCalculator,
 		channelImplementationAddress: nodeOptions.Transactor.ChannelImplementation,
 		registryAddress:              nodeOptions.Transactor.RegistryAddress,
+		sessionStorage:               di.SessionStorage,
+		stateKeeper:                  di.StateKeeper,
 		proposalsManager: newProposalsManager(
 			di.ProposalRepository,
 			di.MysteriumAPI,
@@ -237,6 +251,13 @@ func NewNode(appPath string, options *MobileNodeOptions) (*MobileNode, error) {
 			},
 		),
 	}
+	if options.ProviderEnabled {
+		mobileNode.provider = &providerMode{
+			services:       di.ServicesManager,
+			earnings:       di.AccountantPromiseSettler,
+			registerDevice: di.RegisterWireguardService,
+		}
+	}
 	return mobileNode, nil
 }
 
@@ -383,6 +404,11 @@ type ConnectRequest struct {
 	ProviderID        string
 	ServiceType       string
 	DisableKillSwitch bool
+	// DNSOption is one of "auto", "provider", "system" or a comma separated list of DNS server IPs.
+	// Defaults to "auto".
+	DNSOption string
+	// AccountantID overrides accountant configured for the node.
+	AccountantID string
 }
 
 // ConnectResponse represents connect response with optional error code and message.
@@ -392,6 +418,7 @@ type ConnectResponse struct {
 }
 
 const (
+	connectErrInvalidParams       = "InvalidParams"
 	connectErrInvalidProposal     = "InvalidProposal"
 	connectErrInsufficientBalance = "InsufficientBalance"
 	connectErrUnknown             = "Unknown"
@@ -399,6 +426,19 @@ const (
 
 // Connect connects to given provider.
 func (mb *MobileNode) Connect(req *ConnectRequest) *ConnectResponse {
+	connectOptions, err := toConnectParams(req)
+	if err != nil {
+		return &ConnectResponse{
+			ErrorCode:    connectErrInvalidParams,
+			ErrorMessage: err.Error(),
+		}
+	}
+
+	accountant := mb.accountant
+	if req.AccountantID != "" {
+		accountant = identity.FromAddress(req.AccountantID)
+	}
+
 	proposal, err := mb.proposalsManager.repository.Proposal(market.ProposalID{
 		ProviderID:  req.ProviderID,
 		ServiceType: req.ServiceType,
@@ -410,11 +450,7 @@ func (mb *MobileNode) Connect(req *ConnectRequest) *ConnectResponse {
 		}
 	}
 
-	connectOptions := connection.ConnectParams{
-		DisableKillSwitch: req.DisableKillSwitch,
-		DNS:               connection.DNSOptionAuto,
-	}
-	if err := mb.connectionManager.Connect(identity.FromAddress(req.IdentityAddress), mb.accountant, *proposal, connectOptions); err != nil {
+	if err := mb.connectionManager.Connect(identity.FromAddress(req.IdentityAddress), accountant, *proposal, connectOptions); err != nil {
 		if err == connection.ErrInsufficientBalance {
 			return &ConnectResponse{
 				ErrorCode: connectErrInsufficientBalance,
@@ -428,6 +464,21 @@ func (mb *MobileNode) Connect(req *ConnectRequest) *ConnectResponse {
 	return &ConnectResponse{}
 }
 
+func toConnectParams(req *ConnectRequest) (connection.ConnectParams, error) {
+	dns := connection.DNSOptionAuto
+	if req.DNSOption != "" {
+		opt, err := connection.NewDNSOption(req.DNSOption)
+		if err != nil {
+			return connection.ConnectParams{}, err
+		}
+		dns = opt
+	}
+	return connection.ConnectParams{
+		DisableKillSwitch: req.DisableKillSwitch,
+		DNS:               dns,
+	}, nil
+}
+
 // Disconnect disconnects or cancels current connection.
 func (mb *MobileNode) Disconnect() error {
 	if err := mb.connectionManager.Disconnect(); err != nil {
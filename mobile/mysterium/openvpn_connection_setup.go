@@ -21,6 +21,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net"
 	"sync"
 	"time"
 
@@ -218,6 +219,18 @@ func (c *openvpnConnection) Start(ctx context.Context, options connection.Connec
 	return nil
 }
 
+// UpdateChannelConn excludes new p2p channel traffic from VPN tunnel after the session is resumed.
+func (c *openvpnConnection) UpdateChannelConn(conn *net.UDPConn) error {
+	channelSocket, err := peekLookAtSocketFd4From(conn)
+	if err != nil {
+		return fmt.Errorf("could not get channel socket: %w", err)
+	}
+	if !c.tunnelSetup.SocketProtect(channelSocket) {
+		return errors.New("could not protect p2p socket")
+	}
+	return nil
+}
+
 func (c *openvpnConnection) Stop() {
 	c.stopOnce.Do(func() {
 		if c.session != nil {
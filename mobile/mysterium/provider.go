@@ -0,0 +1,211 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package mysterium
+
+import (
+	"sync"
+
+	"github.com/mysteriumnetwork/node/core/service"
+	"github.com/mysteriumnetwork/node/core/service/servicestate"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/services/wireguard"
+	wireguard_service "github.com/mysteriumnetwork/node/services/wireguard/service"
+	"github.com/mysteriumnetwork/node/session/pingpong"
+	"github.com/mysteriumnetwork/node/session/pingpong/event"
+	"github.com/pkg/errors"
+)
+
+type providerServices interface {
+	Start(providerID identity.Identity, serviceType string, policyIDs []string, options service.Options, pm market.PaymentMethod) (service.ID, error)
+	Stop(id service.ID) error
+	Service(id service.ID) *service.Instance
+}
+
+type earningsProvider interface {
+	GetEarnings(id identity.Identity) event.Earnings
+}
+
+// providerMode runs wireguard service of the mobile node.
+type providerMode struct {
+	services       providerServices
+	earnings       earningsProvider
+	registerDevice func(deviceFactory func() (wireguard_service.MultiPeerDevice, error))
+
+	mu          sync.Mutex
+	tunnelSetup WireguardTunnelSetup
+	serviceID   service.ID
+}
+
+// StartProviderRequest represents provider start request.
+type StartProviderRequest struct {
+	IdentityAddress string
+	// PricePerMinute is a price of a minute of the service in MYST.
+	PricePerMinute float64
+	// PricePerGB is a price of a gigabyte of the traffic in MYST.
+	PricePerGB float64
+}
+
+// GetProviderStatusResponse represents provider status response.
+type GetProviderStatusResponse struct {
+	ServiceID string
+	Status    string
+}
+
+// ServiceStatusChangeCallback represents provider service status callback.
+type ServiceStatusChangeCallback interface {
+	OnChange(serviceID string, status string)
+}
+
+// EarningsChangeCallback represents provider earnings callback.
+type EarningsChangeCallback interface {
+	OnChange(identityAddress string, lifetimeBalance int64, unsettledBalance int64)
+}
+
+// GetEarningsRequest represents provider earnings request.
+type GetEarningsRequest struct {
+	IdentityAddress string
+}
+
+// GetEarningsResponse represents provider earnings response.
+type GetEarningsResponse struct {
+	LifetimeBalance  int64
+	UnsettledBalance int64
+}
+
+var errProviderModeDisabled = errors.New("provider mode is not enabled, set ProviderEnabled node option")
+
+// OverrideWireguardProvider sets up wireguard service to run on top of the single TUN device
+// established by the given tunnel setup, e.g. the one backed by Android VpnService.
+//
+// The node terminates consumer tunnels only. Decrypted consumer packets are written to the established
+// descriptor with the consumer address from the service subnet (10.182.0.0/16 by default) as the source,
+// and packets read from it are sent to the consumer owning their destination address. Android does not
+// forward packets written to a VpnService interface, so the embedding application must provide the egress:
+// Establish must return a descriptor served by a userspace network stack of the application (e.g. one end
+// of a SOCK_SEQPACKET socket pair), which opens consumer connections from its own sockets, protected
+// with VpnService.protect, and writes the replies back addressed to the consumer.
+func (mb *MobileNode) OverrideWireguardProvider(tunnelSetup WireguardTunnelSetup) error {
+	if mb.provider == nil {
+		return errProviderModeDisabled
+	}
+
+	mb.provider.mu.Lock()
+	defer mb.provider.mu.Unlock()
+
+	wireguard.Bootstrap()
+	mb.provider.tunnelSetup = tunnelSetup
+	mb.provider.registerDevice(func() (wireguard_service.MultiPeerDevice, error) {
+		return newWireguardProviderDevice(tunnelSetup), nil
+	})
+	return nil
+}
+
+// StartProvider starts wireguard service provided by the given identity.
+func (mb *MobileNode) StartProvider(req *StartProviderRequest) error {
+	if mb.provider == nil {
+		return errProviderModeDisabled
+	}
+
+	mb.provider.mu.Lock()
+	defer mb.provider.mu.Unlock()
+
+	if mb.provider.tunnelSetup == nil {
+		return errors.New("wireguard provider tunnel setup is not set")
+	}
+	if mb.provider.serviceID != "" {
+		return errors.Errorf("provider is already running service %s", mb.provider.serviceID)
+	}
+
+	// All the sessions share a single device, Android allows only one VpnService interface.
+	options := wireguard_service.DefaultOptions
+	options.MultiPeer = true
+	pm := pingpong.NewPaymentMethod(req.PricePerGB, req.PricePerMinute)
+	id, err := mb.provider.services.Start(identity.FromAddress(req.IdentityAddress), wireguard.ServiceType, nil, options, pm)
+	if err != nil {
+		return errors.Wrap(err, "could not start provider service")
+	}
+	mb.provider.serviceID = id
+	return nil
+}
+
+// StopProvider stops running provider service.
+func (mb *MobileNode) StopProvider() error {
+	if mb.provider == nil {
+		return errProviderModeDisabled
+	}
+
+	mb.provider.mu.Lock()
+	defer mb.provider.mu.Unlock()
+
+	if mb.provider.serviceID == "" {
+		return nil
+	}
+	if err := mb.provider.services.Stop(mb.provider.serviceID); err != nil {
+		return errors.Wrap(err, "could not stop provider service")
+	}
+	mb.provider.serviceID = ""
+	return nil
+}
+
+// GetProviderStatus returns status of the provider service.
+func (mb *MobileNode) GetProviderStatus() *GetProviderStatusResponse {
+	status := &GetProviderStatusResponse{Status: string(servicestate.NotRunning)}
+	if mb.provider == nil {
+		return status
+	}
+
+	mb.provider.mu.Lock()
+	defer mb.provider.mu.Unlock()
+
+	if mb.provider.serviceID == "" {
+		return status
+	}
+	status.ServiceID = string(mb.provider.serviceID)
+	if instance := mb.provider.services.Service(mb.provider.serviceID); instance != nil {
+		status.Status = string(instance.State())
+	}
+	return status
+}
+
+// RegisterServiceStatusChangeCallback registers callback which is called on provider service status change.
+func (mb *MobileNode) RegisterServiceStatusChangeCallback(cb ServiceStatusChangeCallback) {
+	_ = mb.eventBus.SubscribeAsync(servicestate.AppTopicServiceStatus, func(e servicestate.AppEventServiceStatus) {
+		cb.OnChange(e.ID, e.Status)
+	})
+}
+
+// RegisterEarningsChangeCallback registers callback which is called on provider earnings change.
+func (mb *MobileNode) RegisterEarningsChangeCallback(cb EarningsChangeCallback) {
+	_ = mb.eventBus.SubscribeAsync(event.AppTopicEarningsChanged, func(e event.AppEventEarningsChanged) {
+		cb.OnChange(e.Identity.Address, int64(e.Current.LifetimeBalance), int64(e.Current.UnsettledBalance))
+	})
+}
+
+// GetEarnings returns provider earnings of the given identity.
+func (mb *MobileNode) GetEarnings(req *GetEarningsRequest) (*GetEarningsResponse, error) {
+	if mb.provider == nil {
+		return nil, errProviderModeDisabled
+	}
+
+	earnings := mb.provider.earnings.GetEarnings(identity.FromAddress(req.IdentityAddress))
+	return &GetEarningsResponse{
+		LifetimeBalance:  int64(earnings.LifetimeBalance),
+		UnsettledBalance: int64(earnings.UnsettledBalance),
+	}, nil
+}
@@ -0,0 +1,214 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package mysterium
+
+import (
+	"net"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/mysteriumnetwork/node/core/service"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/services/wireguard"
+	"github.com/mysteriumnetwork/node/services/wireguard/key"
+	"github.com/mysteriumnetwork/node/services/wireguard/resources"
+	wireguard_service "github.com/mysteriumnetwork/node/services/wireguard/service"
+	"github.com/mysteriumnetwork/node/session/pingpong/event"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.zx2c4.com/wireguard/device"
+	"golang.zx2c4.com/wireguard/tun"
+)
+
+func TestMobileNode_ProviderLifecycle(t *testing.T) {
+	services := &mockProviderServices{}
+	var deviceFactory func() (wireguard_service.MultiPeerDevice, error)
+	mb := &MobileNode{provider: &providerMode{
+		services: services,
+		registerDevice: func(factory func() (wireguard_service.MultiPeerDevice, error)) {
+			deviceFactory = factory
+		},
+	}}
+
+	err := mb.StartProvider(&StartProviderRequest{IdentityAddress: "0x1"})
+	assert.EqualError(t, err, "wireguard provider tunnel setup is not set")
+
+	require.NoError(t, mb.OverrideWireguardProvider(&mockWireguardTunnelSetup{}))
+	require.NotNil(t, deviceFactory)
+	device, err := deviceFactory()
+	require.NoError(t, err)
+	assert.IsType(t, &wireguardProviderDevice{}, device)
+
+	err = mb.StartProvider(&StartProviderRequest{IdentityAddress: "0x1", PricePerMinute: 0.1, PricePerGB: 0.5})
+	require.NoError(t, err)
+	assert.Equal(t, identity.FromAddress("0x1"), services.providerID)
+	assert.Equal(t, wireguard.ServiceType, services.serviceType)
+	assert.True(t, services.options.(wireguard_service.Options).MultiPeer)
+	assert.Equal(t, &GetProviderStatusResponse{ServiceID: "service-1", Status: "NotRunning"}, mb.GetProviderStatus())
+
+	err = mb.StartProvider(&StartProviderRequest{IdentityAddress: "0x1"})
+	assert.EqualError(t, err, "provider is already running service service-1")
+
+	require.NoError(t, mb.StopProvider())
+	assert.Equal(t, service.ID("service-1"), services.stopped)
+	assert.Equal(t, &GetProviderStatusResponse{Status: "NotRunning"}, mb.GetProviderStatus())
+}
+
+func TestMobileNode_ProviderModeDisabled(t *testing.T) {
+	mb := &MobileNode{}
+
+	assert.Equal(t, errProviderModeDisabled, mb.StartProvider(&StartProviderRequest{}))
+	assert.Equal(t, errProviderModeDisabled, mb.StopProvider())
+	assert.Equal(t, errProviderModeDisabled, mb.OverrideWireguardProvider(&mockWireguardTunnelSetup{}))
+	assert.Equal(t, &GetProviderStatusResponse{Status: "NotRunning"}, mb.GetProviderStatus())
+}
+
+func TestMobileNode_GetEarnings(t *testing.T) {
+	mb := &MobileNode{provider: &providerMode{
+		earnings: &mockEarningsProvider{earnings: event.Earnings{LifetimeBalance: 100, UnsettledBalance: 40}},
+	}}
+
+	earnings, err := mb.GetEarnings(&GetEarningsRequest{IdentityAddress: "0x1"})
+	require.NoError(t, err)
+	assert.Equal(t, &GetEarningsResponse{LifetimeBalance: 100, UnsettledBalance: 40}, earnings)
+}
+
+func TestWireguardProviderDevice_Peers(t *testing.T) {
+	d := newWireguardProviderDevice(&mockWireguardTunnelSetup{})
+	d.privateKey = "MHiL0gkPWvJ+WR6XOPp6hM2JpWqP8i/FjXb8Gd/IRFM="
+	d.ipAddr = net.IPNet{IP: net.ParseIP("10.182.0.1").To4(), Mask: net.CIDRMask(24, 32)}
+	d.endpoint = net.UDPAddr{IP: net.ParseIP("1.2.3.4"), Port: 52820}
+	pool, err := resources.NewIPPool(net.IPNet{IP: net.ParseIP("10.182.0.0").To4(), Mask: net.CIDRMask(24, 32)})
+	require.NoError(t, err)
+	d.pool = pool
+	d.device = device.NewDevice(newMockTun(), device.NewLogger(device.LogLevelError, "[test]"))
+	defer d.Stop()
+
+	consumer1, consumer2, consumer3 := newPublicKey(t), newPublicKey(t), newPublicKey(t)
+
+	config1, err := d.AddPeer(consumer1, "", nil)
+	require.NoError(t, err)
+	config2, err := d.AddPeer(consumer2, "", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "10.182.0.2/24", config1.Consumer.IPAddress.String())
+	assert.Equal(t, "10.182.0.3/24", config2.Consumer.IPAddress.String())
+	assert.Equal(t, "1.2.3.4:52820", config2.Provider.Endpoint.String())
+	assert.True(t, config2.Provider.SharedEndpoint)
+
+	_, err = d.AddPeer(consumer1, "", nil)
+	assert.EqualError(t, err, "peer already exists")
+
+	require.NoError(t, d.ReplacePeer(consumer1, consumer3, ""))
+	_, err = d.PeerStats(consumer1)
+	assert.Error(t, err)
+	_, err = d.PeerStats(consumer3)
+	assert.NoError(t, err)
+
+	require.NoError(t, d.RemovePeer(consumer2))
+	_, err = d.PeerStats(consumer2)
+	assert.Error(t, err)
+	assert.Equal(t, map[string]net.IP{consumer3: net.ParseIP("10.182.0.2").To4()}, d.peers)
+}
+
+func TestWireguardProviderDevice_StartValidatesConfig(t *testing.T) {
+	d := newWireguardProviderDevice(&mockWireguardTunnelSetup{})
+
+	assert.EqualError(t, d.Start(wireguard.ProviderModeConfig{ListenPort: 52820}), "public IP is required")
+	assert.EqualError(t, d.Start(wireguard.ProviderModeConfig{PublicIP: "1.2.3.4"}), "listen port is required")
+}
+
+func newPublicKey(t *testing.T) string {
+	privateKey, err := key.GeneratePrivateKey()
+	require.NoError(t, err)
+	publicKey, err := key.PrivateKeyToPublicKey(privateKey)
+	require.NoError(t, err)
+	return publicKey
+}
+
+type mockProviderServices struct {
+	providerID  identity.Identity
+	serviceType string
+	options     service.Options
+	stopped     service.ID
+}
+
+func (m *mockProviderServices) Start(providerID identity.Identity, serviceType string, _ []string, options service.Options, _ market.PaymentMethod) (service.ID, error) {
+	m.providerID = providerID
+	m.serviceType = serviceType
+	m.options = options
+	return "service-1", nil
+}
+
+func (m *mockProviderServices) Stop(id service.ID) error {
+	m.stopped = id
+	return nil
+}
+
+func (m *mockProviderServices) Service(_ service.ID) *service.Instance {
+	return nil
+}
+
+type mockEarningsProvider struct {
+	earnings event.Earnings
+}
+
+func (m *mockEarningsProvider) GetEarnings(_ identity.Identity) event.Earnings {
+	return m.earnings
+}
+
+type mockWireguardTunnelSetup struct{}
+
+func (m *mockWireguardTunnelSetup) NewTunnel()                   {}
+func (m *mockWireguardTunnelSetup) AddTunnelAddress(string, int) {}
+func (m *mockWireguardTunnelSetup) AddRoute(string, int)         {}
+func (m *mockWireguardTunnelSetup) AddDNS(string)                {}
+func (m *mockWireguardTunnelSetup) SetBlocking(bool)             {}
+func (m *mockWireguardTunnelSetup) Establish() (int, error)      { return 0, nil }
+func (m *mockWireguardTunnelSetup) SetMTU(int)                   {}
+func (m *mockWireguardTunnelSetup) Protect(int) error            { return nil }
+func (m *mockWireguardTunnelSetup) SetSessionName(string)        {}
+
+type mockTun struct {
+	events chan tun.Event
+	closed chan struct{}
+	once   sync.Once
+}
+
+func newMockTun() *mockTun {
+	return &mockTun{events: make(chan tun.Event), closed: make(chan struct{})}
+}
+
+func (m *mockTun) File() *os.File { return nil }
+func (m *mockTun) Read([]byte, int) (int, error) {
+	<-m.closed
+	return 0, os.ErrClosed
+}
+func (m *mockTun) Write(buf []byte, offset int) (int, error) { return len(buf) - offset, nil }
+func (m *mockTun) Flush() error                              { return nil }
+func (m *mockTun) MTU() (int, error)                         { return androidTunMtu, nil }
+func (m *mockTun) Name() (string, error)                     { return "tun0", nil }
+func (m *mockTun) Events() chan tun.Event                    { return m.events }
+func (m *mockTun) Close() error {
+	m.once.Do(func() {
+		close(m.closed)
+		close(m.events)
+	})
+	return nil
+}
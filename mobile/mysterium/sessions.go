@@ -0,0 +1,138 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package mysterium
+
+import (
+	"encoding/json"
+
+	"github.com/mysteriumnetwork/node/consumer/session"
+	stateEvent "github.com/mysteriumnetwork/node/core/state/event"
+	"github.com/mysteriumnetwork/node/datasize"
+	"github.com/pkg/errors"
+)
+
+const defaultSessionsPageSize = 50
+
+type sessionStorage interface {
+	GetPage(page, pageSize int) ([]session.History, int, error)
+}
+
+type stateKeeper interface {
+	GetState() stateEvent.State
+}
+
+// ListSessionsRequest represents paged session history request.
+type ListSessionsRequest struct {
+	// Page is a page number starting from 1.
+	Page int
+	// PageSize is a number of sessions per page, defaults to 50.
+	PageSize int
+}
+
+type listSessionsResponse struct {
+	Sessions []sessionDTO `json:"sessions"`
+	Paging   pagingDTO    `json:"paging"`
+}
+
+type sessionDTO struct {
+	ID              string `json:"id"`
+	ProviderID      string `json:"providerId"`
+	ServiceType     string `json:"serviceType"`
+	ProviderCountry string `json:"providerCountry"`
+	Started         int64  `json:"started"`
+	Duration        int64  `json:"duration"`
+	BytesReceived   uint64 `json:"bytesReceived"`
+	BytesSent       uint64 `json:"bytesSent"`
+	TokensSpent     uint64 `json:"tokensSpent"`
+	Status          string `json:"status"`
+}
+
+type pagingDTO struct {
+	Page       int `json:"page"`
+	PageSize   int `json:"pageSize"`
+	TotalItems int `json:"totalItems"`
+	TotalPages int `json:"totalPages"`
+}
+
+// ListSessions returns a page of consumer session history, newest sessions first.
+// Sessions are returned as JSON byte array since go mobile does not support complex slices.
+func (mb *MobileNode) ListSessions(req *ListSessionsRequest) ([]byte, error) {
+	page, pageSize := req.Page, req.PageSize
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = defaultSessionsPageSize
+	}
+
+	sessions, total, err := mb.sessionStorage.GetPage(page, pageSize)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get session history")
+	}
+
+	res := listSessionsResponse{
+		Sessions: make([]sessionDTO, len(sessions)),
+		Paging: pagingDTO{
+			Page:       page,
+			PageSize:   pageSize,
+			TotalItems: total,
+			TotalPages: (total + pageSize - 1) / pageSize,
+		},
+	}
+	for i, s := range sessions {
+		res.Sessions[i] = sessionDTO{
+			ID:              string(s.SessionID),
+			ProviderID:      s.ProviderID.Address,
+			ServiceType:     s.ServiceType,
+			ProviderCountry: s.ProviderCountry,
+			Started:         s.Started.Unix(),
+			Duration:        int64(s.GetDuration().Seconds()),
+			BytesReceived:   s.DataStats.BytesReceived,
+			BytesSent:       s.DataStats.BytesSent,
+			TokensSpent:     s.Invoice.AgreementTotal,
+			Status:          s.Status,
+		}
+	}
+	return json.Marshal(res)
+}
+
+// GetConnectionStatisticsResponse represents current connection statistics.
+type GetConnectionStatisticsResponse struct {
+	// Duration is a connection duration in seconds.
+	Duration      int64
+	BytesReceived int64
+	BytesSent     int64
+	// ThroughputReceived is a download speed in bits per second.
+	ThroughputReceived int64
+	// ThroughputSent is an upload speed in bits per second.
+	ThroughputSent int64
+	TokensSpent    int64
+}
+
+// GetConnectionStatistics returns statistics of the current connection.
+func (mb *MobileNode) GetConnectionStatistics() *GetConnectionStatisticsResponse {
+	conn := mb.stateKeeper.GetState().Connection
+	return &GetConnectionStatisticsResponse{
+		Duration:           int64(conn.Session.Duration().Seconds()),
+		BytesReceived:      int64(conn.Statistics.BytesReceived),
+		BytesSent:          int64(conn.Statistics.BytesSent),
+		ThroughputReceived: int64(datasize.BitSize(conn.Throughput.Down).Bits()),
+		ThroughputSent:     int64(datasize.BitSize(conn.Throughput.Up).Bits()),
+		TokensSpent:        int64(conn.Invoice.AgreementTotal),
+	}
+}
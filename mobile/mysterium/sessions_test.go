@@ -0,0 +1,135 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package mysterium
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mysteriumnetwork/node/consumer/bandwidth"
+	"github.com/mysteriumnetwork/node/consumer/session"
+	"github.com/mysteriumnetwork/node/core/connection"
+	stateEvent "github.com/mysteriumnetwork/node/core/state/event"
+	"github.com/mysteriumnetwork/node/datasize"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/payments/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMobileNode_ListSessions(t *testing.T) {
+	started := time.Date(2020, 4, 1, 10, 0, 0, 0, time.UTC)
+	storage := &mockSessionStorage{
+		sessions: []session.History{
+			{
+				SessionID:       "session-1",
+				ProviderID:      identity.FromAddress("0x1"),
+				ServiceType:     "wireguard",
+				ProviderCountry: "LT",
+				Started:         started,
+				Updated:         started.Add(time.Minute),
+				Status:          session.SessionStatusCompleted,
+				DataStats:       connection.Statistics{BytesReceived: 20, BytesSent: 10},
+				Invoice:         crypto.Invoice{AgreementTotal: 300},
+			},
+		},
+		total: 3,
+	}
+	mb := &MobileNode{sessionStorage: storage}
+
+	res, err := mb.ListSessions(&ListSessionsRequest{Page: 2, PageSize: 2})
+	require.NoError(t, err)
+	assert.Equal(t, 2, storage.page)
+	assert.Equal(t, 2, storage.pageSize)
+	assert.JSONEq(t, `{
+		"sessions": [{
+			"id": "session-1",
+			"providerId": "0x1",
+			"serviceType": "wireguard",
+			"providerCountry": "LT",
+			"started": 1585735200,
+			"duration": 60,
+			"bytesReceived": 20,
+			"bytesSent": 10,
+			"tokensSpent": 300,
+			"status": "Completed"
+		}],
+		"paging": {"page": 2, "pageSize": 2, "totalItems": 3, "totalPages": 2}
+	}`, string(res))
+
+	_, err = mb.ListSessions(&ListSessionsRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, 1, storage.page)
+	assert.Equal(t, defaultSessionsPageSize, storage.pageSize)
+
+	storage.err = errors.New("storage failure")
+	_, err = mb.ListSessions(&ListSessionsRequest{})
+	assert.EqualError(t, err, "could not get session history: storage failure")
+}
+
+func TestMobileNode_GetConnectionStatistics(t *testing.T) {
+	mb := &MobileNode{stateKeeper: &mockStateKeeper{state: stateEvent.State{
+		Connection: stateEvent.Connection{
+			Statistics: connection.Statistics{BytesReceived: 2048, BytesSent: 1024},
+			Throughput: bandwidth.Throughput{Up: datasize.BitSpeed(8), Down: datasize.BitSpeed(16)},
+			Invoice:    crypto.Invoice{AgreementTotal: 500},
+		},
+	}}}
+
+	assert.Equal(t, &GetConnectionStatisticsResponse{
+		BytesReceived:      2048,
+		BytesSent:          1024,
+		ThroughputReceived: 16,
+		ThroughputSent:     8,
+		TokensSpent:        500,
+	}, mb.GetConnectionStatistics())
+}
+
+func TestToConnectParams(t *testing.T) {
+	params, err := toConnectParams(&ConnectRequest{DisableKillSwitch: true})
+	require.NoError(t, err)
+	assert.Equal(t, connection.ConnectParams{DisableKillSwitch: true, DNS: connection.DNSOptionAuto}, params)
+
+	params, err = toConnectParams(&ConnectRequest{DNSOption: "1.1.1.1,8.8.8.8"})
+	require.NoError(t, err)
+	assert.Equal(t, connection.DNSOption("1.1.1.1,8.8.8.8"), params.DNS)
+
+	_, err = toConnectParams(&ConnectRequest{DNSOption: "invalid"})
+	assert.EqualError(t, err, "invalid IP address provided as a DNS option: invalid")
+}
+
+type mockSessionStorage struct {
+	sessions       []session.History
+	total          int
+	err            error
+	page, pageSize int
+}
+
+func (m *mockSessionStorage) GetPage(page, pageSize int) ([]session.History, int, error) {
+	m.page, m.pageSize = page, pageSize
+	return m.sessions, m.total, m.err
+}
+
+type mockStateKeeper struct {
+	state stateEvent.State
+}
+
+func (m *mockStateKeeper) GetState() stateEvent.State {
+	return m.state
+}
@@ -89,6 +89,7 @@ type wireguardConnection struct {
 	stateCh         chan connection.State
 	opts            wireGuardOptions
 	privateKey      string
+	config          wireguard.ServiceConfig
 	device          wireguardDevice
 	ipResolver      ip.Resolver
 	natPinger       natPinger
@@ -96,6 +97,7 @@ type wireguardConnection struct {
 }
 
 var _ connection.Connection = &wireguardConnection{}
+var _ connection.ServiceConnUpdater = &wireguardConnection{}
 
 func (c *wireguardConnection) State() <-chan connection.State {
 	return c.stateCh
@@ -131,7 +133,13 @@ func (c *wireguardConnection) Start(ctx context.Context, options connection.Conn
 	if options.ProviderNATConn != nil {
 		options.ProviderNATConn.Close()
 		config.LocalPort = options.ProviderNATConn.LocalAddr().(*net.UDPAddr).Port
-		config.Provider.Endpoint.Port = options.ProviderNATConn.RemoteAddr().(*net.UDPAddr).Port
+		if !config.Provider.SharedEndpoint {
+			if remoteIP := options.ProviderNATConn.RemoteAddr().(*net.UDPAddr).IP; !remoteIP.IsLoopback() {
+				// Peer connection may go via relay, so its remote address is used instead of the provider one.
+				config.Provider.Endpoint.IP = remoteIP
+			}
+			config.Provider.Endpoint.Port = options.ProviderNATConn.RemoteAddr().(*net.UDPAddr).Port
+		}
 	} else if len(config.Ports) > 0 { // TODO this backward compatibility block needs to be removed once we migrate to the p2p communication.
 		ip := config.Provider.Endpoint.IP.String()
 		lPort, rPort, err := c.natPinger.PingProvider(ctx, ip, c.ports, config.Ports, 0)
@@ -143,6 +151,7 @@ func (c *wireguardConnection) Start(ctx context.Context, options connection.Conn
 		config.Provider.Endpoint.Port = rPort
 	}
 
+	c.config = config
 	if err := c.device.Start(c.privateKey, config, options.ChannelConn); err != nil {
 		return errors.Wrap(err, "could not start device")
 	}
@@ -156,6 +165,32 @@ func (c *wireguardConnection) Start(ctx context.Context, options connection.Conn
 	return nil
 }
 
+// UpdateChannelConn excludes new p2p channel traffic from VPN tunnel after the session is resumed.
+func (c *wireguardConnection) UpdateChannelConn(conn *net.UDPConn) error {
+	return c.device.ProtectChannelConn(conn)
+}
+
+// UpdateServiceConn moves the tunnel to the service connection of the new p2p channel after the session is resumed.
+func (c *wireguardConnection) UpdateServiceConn(conn *net.UDPConn) error {
+	conn.Close()
+
+	endpoint := c.config.Provider.Endpoint
+	if !c.config.Provider.SharedEndpoint {
+		if remoteIP := conn.RemoteAddr().(*net.UDPAddr).IP; !remoteIP.IsLoopback() {
+			endpoint.IP = remoteIP
+		}
+		endpoint.Port = conn.RemoteAddr().(*net.UDPAddr).Port
+	}
+	localPort := conn.LocalAddr().(*net.UDPAddr).Port
+
+	if err := c.device.UpdateEndpoint(localPort, c.config.Provider.PublicKey, endpoint); err != nil {
+		return errors.Wrap(err, "could not update device endpoint")
+	}
+	c.config.LocalPort = localPort
+	c.config.Provider.Endpoint = endpoint
+	return nil
+}
+
 func (c *wireguardConnection) Wait() error {
 	<-c.done
 	return nil
@@ -215,6 +250,8 @@ func (c *wireguardConnection) isNoopPinger() bool {
 
 type wireguardDevice interface {
 	Start(privateKey string, config wireguard.ServiceConfig, channelConn *net.UDPConn) error
+	ProtectChannelConn(channelConn *net.UDPConn) error
+	UpdateEndpoint(listenPort int, providerPublicKey string, providerEndpoint net.UDPAddr) error
 	Stop()
 	Stats() (*wireguard.Stats, error)
 }
@@ -254,19 +291,46 @@ func (w *wireguardDeviceImpl) Start(privateKey string, config wireguard.ServiceC
 
 	// Exclude p2p channel traffic from VPN tunnel.
 	if channelConn != nil {
-		channelSocket, err := peekLookAtSocketFd4From(channelConn)
-		if err != nil {
-			return fmt.Errorf("could not get channel socket: %w", err)
-		}
-		err = w.tunnelSetup.Protect(channelSocket)
-		if err != nil {
-			return fmt.Errorf("could not protect p2p socket: %w", err)
-		}
+		return w.ProtectChannelConn(channelConn)
 	}
 
 	return nil
 }
 
+func (w *wireguardDeviceImpl) ProtectChannelConn(channelConn *net.UDPConn) error {
+	channelSocket, err := peekLookAtSocketFd4From(channelConn)
+	if err != nil {
+		return fmt.Errorf("could not get channel socket: %w", err)
+	}
+	if err := w.tunnelSetup.Protect(channelSocket); err != nil {
+		return fmt.Errorf("could not protect p2p socket: %w", err)
+	}
+	return nil
+}
+
+// UpdateEndpoint moves the device to the new listen port and provider endpoint, the new socket is protected from the tunnel.
+func (w *wireguardDeviceImpl) UpdateEndpoint(listenPort int, providerPublicKey string, providerEndpoint net.UDPAddr) error {
+	if w.device == nil {
+		return errors.New("device is not started")
+	}
+
+	peer := wireguard.Peer{
+		Endpoint:               &providerEndpoint,
+		PublicKey:              providerPublicKey,
+		KeepAlivePeriodSeconds: 18,
+	}
+	config := fmt.Sprintf("listen_port=%d\n", listenPort) + peer.Encode()
+	if err := w.device.IpcSetOperation(bufio.NewReader(strings.NewReader(config))); err != nil {
+		return errors.Wrap(err, "could not set device config")
+	}
+
+	socket, err := peekLookAtSocketFd4(w.device)
+	if err != nil {
+		return errors.Wrap(err, "could not get socket")
+	}
+	return w.tunnelSetup.Protect(socket)
+}
+
 func (w *wireguardDeviceImpl) Stop() {
 	if w.device != nil {
 		w.device.Close()
@@ -305,6 +369,9 @@ func (w *wireguardDeviceImpl) applyConfig(devApi *device.Device, privateKey stri
 		// All traffic through this peer (unfortunately 0.0.0.0/0 didn't work as it was treated as ipv6)
 		AllowedIPs: []string{"0.0.0.0/1", "128.0.0.0/1"},
 	}
+	if config.Consumer.IPAddress6.IP != nil {
+		peer.AllowedIPs = append(peer.AllowedIPs, "::/1", "8000::/1")
+	}
 	if err := devApi.IpcSetOperation(bufio.NewReader(strings.NewReader(peer.Encode()))); err != nil {
 		return err
 	}
@@ -317,6 +384,11 @@ func (w *wireguardDeviceImpl) newTunnDevice(wgTunnSetup WireguardTunnelSetup, co
 	wgTunnSetup.NewTunnel()
 	wgTunnSetup.SetSessionName("wg-tun-session")
 	wgTunnSetup.AddTunnelAddress(consumerIP.IP.String(), prefixLen)
+	consumerIP6 := config.Consumer.IPAddress6
+	if consumerIP6.IP != nil {
+		prefixLen6, _ := consumerIP6.Mask.Size()
+		wgTunnSetup.AddTunnelAddress(consumerIP6.IP.String(), prefixLen6)
+	}
 	wgTunnSetup.SetMTU(androidTunMtu)
 	wgTunnSetup.SetBlocking(true)
 
@@ -332,6 +404,10 @@ func (w *wireguardDeviceImpl) newTunnDevice(wgTunnSetup WireguardTunnelSetup, co
 	// Route all traffic through tunnel
 	wgTunnSetup.AddRoute("0.0.0.0", 1)
 	wgTunnSetup.AddRoute("128.0.0.0", 1)
+	if consumerIP6.IP != nil {
+		wgTunnSetup.AddRoute("::", 1)
+		wgTunnSetup.AddRoute("8000::", 1)
+	}
 
 	// Provider requests to delay consumer connection since it might be in a process of setting up NAT traversal for given consumer
 	if config.Consumer.ConnectDelay > 0 {
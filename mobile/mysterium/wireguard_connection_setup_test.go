@@ -107,16 +107,19 @@ func newServiceConfig() wg.ServiceConfig {
 		LocalPort:  51000,
 		RemotePort: 51001,
 		Provider: struct {
-			PublicKey string
-			Endpoint  net.UDPAddr
+			PublicKey      string
+			Endpoint       net.UDPAddr
+			SharedEndpoint bool
 		}{
 			PublicKey: "wg1",
 			Endpoint:  *endpoint,
 		},
 		Consumer: struct {
 			IPAddress    net.IPNet
+			IPAddress6   net.IPNet
 			DNSIPs       string
 			ConnectDelay int
+			PresharedKey bool
 		}{
 			IPAddress: net.IPNet{
 				IP:   net.IPv4(127, 0, 0, 1),
@@ -135,6 +138,14 @@ func (m mockWireGuardDevice) Start(_ string, _ wg.ServiceConfig, _ *net.UDPConn)
 	return nil
 }
 
+func (m mockWireGuardDevice) ProtectChannelConn(_ *net.UDPConn) error {
+	return nil
+}
+
+func (m mockWireGuardDevice) UpdateEndpoint(_ int, _ string, _ net.UDPAddr) error {
+	return nil
+}
+
 func (m mockWireGuardDevice) Stop() {
 }
 
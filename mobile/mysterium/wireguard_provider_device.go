@@ -0,0 +1,334 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package mysterium
+
+import (
+	"bufio"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+
+	"github.com/mysteriumnetwork/node/services/wireguard"
+	"github.com/mysteriumnetwork/node/services/wireguard/key"
+	"github.com/mysteriumnetwork/node/services/wireguard/resources"
+	wireguard_service "github.com/mysteriumnetwork/node/services/wireguard/service"
+	"github.com/rs/zerolog/log"
+	"golang.zx2c4.com/wireguard/device"
+	"golang.zx2c4.com/wireguard/tun"
+)
+
+// newWireguardProviderDevice creates userspace wireguard device running in provider mode
+// on top of the TUN device established by the tunnel setup, e.g. the one of Android VpnService.
+//
+// Android allows a single VpnService interface only, so the device is shared by all the sessions
+// of the service and every consumer is added as a separate peer with its own address from the service subnet.
+// The device terminates consumer tunnels only, see OverrideWireguardProvider for the traffic forwarding.
+func newWireguardProviderDevice(tunnelSetup WireguardTunnelSetup) *wireguardProviderDevice {
+	return &wireguardProviderDevice{
+		tunnelSetup: tunnelSetup,
+		newTun:      newProviderTunDevice,
+		peers:       make(map[string]net.IP),
+	}
+}
+
+type wireguardProviderDevice struct {
+	tunnelSetup WireguardTunnelSetup
+	newTun      func(tunnelSetup WireguardTunnelSetup, ipAddr, ipAddr6 net.IPNet) (tun.Device, error)
+
+	mu         sync.Mutex
+	device     *device.Device
+	iface      string
+	privateKey string
+	ipAddr     net.IPNet
+	ipAddr6    net.IPNet
+	endpoint   net.UDPAddr
+	pool       *resources.IPPool
+	peers      map[string]net.IP
+}
+
+var _ wireguard_service.MultiPeerDevice = &wireguardProviderDevice{}
+
+// Start establishes TUN device for the whole network of the given config and starts userspace wireguard device on top of it.
+func (d *wireguardProviderDevice) Start(config wireguard.ProviderModeConfig) (err error) {
+	if config.PublicIP == "" {
+		return errors.New("public IP is required")
+	}
+	if config.ListenPort == 0 {
+		return errors.New("listen port is required")
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.pool, err = resources.NewIPPool(config.Network)
+	if err != nil {
+		return fmt.Errorf("could not create peer IP pool: %w", err)
+	}
+	d.privateKey, err = key.GeneratePrivateKey()
+	if err != nil {
+		return fmt.Errorf("could not generate private key: %w", err)
+	}
+	d.ipAddr = net.IPNet{IP: d.pool.ProviderIP(), Mask: config.Network.Mask}
+	if config.Network6.IP != nil {
+		d.ipAddr6 = net.IPNet{IP: resources.IPv6FromIPv4(config.Network6, d.ipAddr.IP), Mask: config.Network6.Mask}
+	}
+	d.endpoint = net.UDPAddr{IP: net.ParseIP(config.PublicIP), Port: config.ListenPort}
+
+	tunDevice, err := d.newTun(d.tunnelSetup, d.ipAddr, d.ipAddr6)
+	if err != nil {
+		return fmt.Errorf("could not create tunnel device: %w", err)
+	}
+	if d.iface, err = tunDevice.Name(); err != nil {
+		log.Warn().Err(err).Msg("Could not get provider tunnel name")
+	}
+
+	d.device = device.NewDevice(tunDevice, device.NewLogger(device.LogLevelDebug, "[userspace-wg-provider]"))
+	defer func() {
+		if err != nil {
+			d.device.Close()
+			d.device = nil
+		}
+	}()
+
+	deviceConfig := wireguard.DeviceConfig{
+		PrivateKey: d.privateKey,
+		ListenPort: d.endpoint.Port,
+	}
+	if err := d.setDeviceConfig(deviceConfig.Encode()); err != nil {
+		return fmt.Errorf("could not configure device: %w", err)
+	}
+
+	socket, err := peekLookAtSocketFd4(d.device)
+	if err != nil {
+		return fmt.Errorf("could not get socket: %w", err)
+	}
+	if err := d.tunnelSetup.Protect(socket); err != nil {
+		return fmt.Errorf("could not protect socket: %w", err)
+	}
+	return nil
+}
+
+// InterfaceName returns the name of the TUN device.
+func (d *wireguardProviderDevice) InterfaceName() string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.iface
+}
+
+// Network returns the device network, the address being the provider one.
+func (d *wireguardProviderDevice) Network() net.IPNet {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.ipAddr
+}
+
+// Network6 returns the device IPv6 network, it is empty if IPv6 is not enabled.
+func (d *wireguardProviderDevice) Network6() net.IPNet {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.ipAddr6
+}
+
+// AddPeer allocates an address for the consumer, adds it as a device peer and returns the session config.
+func (d *wireguardProviderDevice) AddPeer(publicKey, presharedKey string, endpoint *net.UDPAddr) (wireguard.ServiceConfig, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if _, ok := d.peers[publicKey]; ok {
+		return wireguard.ServiceConfig{}, errors.New("peer already exists")
+	}
+
+	providerPublicKey, err := key.PrivateKeyToPublicKey(d.privateKey)
+	if err != nil {
+		return wireguard.ServiceConfig{}, err
+	}
+
+	ip, err := d.pool.Allocate()
+	if err != nil {
+		return wireguard.ServiceConfig{}, fmt.Errorf("could not allocate peer IP: %w", err)
+	}
+
+	peer := wireguard.Peer{
+		PublicKey:    publicKey,
+		PresharedKey: presharedKey,
+		Endpoint:     endpoint,
+		AllowedIPs:   d.peerAllowedIPs(ip),
+	}
+	if err := d.setDeviceConfig(peer.Encode()); err != nil {
+		if err := d.pool.Release(ip); err != nil {
+			log.Warn().Err(err).Msg("Failed to release peer IP")
+		}
+		return wireguard.ServiceConfig{}, fmt.Errorf("could not add peer: %w", err)
+	}
+	d.peers[publicKey] = ip
+
+	var config wireguard.ServiceConfig
+	config.Provider.PublicKey = providerPublicKey
+	config.Provider.Endpoint = d.endpoint
+	config.Provider.SharedEndpoint = true
+	config.Consumer.IPAddress = net.IPNet{IP: ip, Mask: d.ipAddr.Mask}
+	if d.ipAddr6.IP != nil {
+		config.Consumer.IPAddress6 = net.IPNet{IP: resources.IPv6FromIPv4(d.ipAddr6, ip), Mask: d.ipAddr6.Mask}
+	}
+	return config, nil
+}
+
+// ReplacePeer replaces the consumer peer with the one using new keys, keeping the consumer address.
+func (d *wireguardProviderDevice) ReplacePeer(publicKey, newPublicKey, newPresharedKey string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	ip, ok := d.peers[publicKey]
+	if !ok {
+		return errors.New("peer not found")
+	}
+	if _, ok := d.peers[newPublicKey]; ok {
+		return errors.New("peer already exists")
+	}
+
+	// Allowed IP is moved to the new peer once it is added, so the tunnel keeps working.
+	peer := wireguard.Peer{
+		PublicKey:    newPublicKey,
+		PresharedKey: newPresharedKey,
+		AllowedIPs:   d.peerAllowedIPs(ip),
+	}
+	if err := d.setDeviceConfig(peer.Encode()); err != nil {
+		return fmt.Errorf("could not add peer: %w", err)
+	}
+	d.peers[newPublicKey] = ip
+	delete(d.peers, publicKey)
+
+	return d.removeDevicePeer(publicKey)
+}
+
+// RemovePeer removes the consumer peer from the device and releases its address.
+func (d *wireguardProviderDevice) RemovePeer(publicKey string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	ip, ok := d.peers[publicKey]
+	if !ok {
+		return errors.New("peer not found")
+	}
+	delete(d.peers, publicKey)
+
+	if err := d.pool.Release(ip); err != nil {
+		log.Warn().Err(err).Msg("Failed to release peer IP")
+	}
+	return d.removeDevicePeer(publicKey)
+}
+
+// PeerStats returns traffic statistics of the given consumer peer.
+func (d *wireguardProviderDevice) PeerStats(publicKey string) (*wireguard.Stats, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.device == nil {
+		return nil, errors.New("device is not started")
+	}
+	deviceState, err := wireguard.ParseUserspaceDevice(d.device.IpcGetOperation)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse userspace wg device state: %w", err)
+	}
+	stats, ok := wireguard.ParseDevicePeersStats(deviceState)[publicKey]
+	if !ok {
+		return nil, fmt.Errorf("no stats for peer %s", publicKey)
+	}
+	return &stats, nil
+}
+
+// Stop closes wireguard device together with its TUN device.
+func (d *wireguardProviderDevice) Stop() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.device != nil {
+		d.device.Close()
+		d.device = nil
+	}
+	return nil
+}
+
+// peerAllowedIPs returns the addresses routed to the peer, IPv6 one being derived from the IPv4.
+func (d *wireguardProviderDevice) peerAllowedIPs(ip net.IP) []string {
+	allowedIPs := []string{ip.String() + "/32"}
+	if d.ipAddr6.IP != nil {
+		allowedIPs = append(allowedIPs, resources.IPv6FromIPv4(d.ipAddr6, ip).String()+"/128")
+	}
+	return allowedIPs
+}
+
+func (d *wireguardProviderDevice) removeDevicePeer(publicKey string) error {
+	if d.device == nil {
+		return errors.New("device is not started")
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(publicKey)
+	if err != nil {
+		return err
+	}
+	var peerKey device.NoisePublicKey
+	if len(decoded) != len(peerKey) {
+		return errors.New("unexpected key size")
+	}
+	copy(peerKey[:], decoded)
+
+	d.device.RemovePeer(peerKey)
+	return nil
+}
+
+func (d *wireguardProviderDevice) setDeviceConfig(config string) error {
+	if d.device == nil {
+		return errors.New("device is not started")
+	}
+	if err := d.device.IpcSetOperation(bufio.NewReader(strings.NewReader(config))); err != nil {
+		return fmt.Errorf("could not set device config: %w", err)
+	}
+	d.device.Up()
+	return nil
+}
+
+// newProviderTunDevice establishes the single TUN device of the provider, routing the whole service subnet to it.
+func newProviderTunDevice(tunnelSetup WireguardTunnelSetup, ipAddr, ipAddr6 net.IPNet) (tun.Device, error) {
+	tunnelSetup.NewTunnel()
+	tunnelSetup.SetSessionName("wg-provider")
+
+	prefixLen, _ := ipAddr.Mask.Size()
+	tunnelSetup.AddTunnelAddress(ipAddr.IP.String(), prefixLen)
+	network := ipAddr.IP.Mask(ipAddr.Mask)
+	tunnelSetup.AddRoute(network.String(), prefixLen)
+	if ipAddr6.IP != nil {
+		prefixLen6, _ := ipAddr6.Mask.Size()
+		tunnelSetup.AddTunnelAddress(ipAddr6.IP.String(), prefixLen6)
+		tunnelSetup.AddRoute(ipAddr6.IP.Mask(ipAddr6.Mask).String(), prefixLen6)
+	}
+	tunnelSetup.SetMTU(androidTunMtu)
+	tunnelSetup.SetBlocking(true)
+
+	fd, err := tunnelSetup.Establish()
+	if err != nil {
+		return nil, err
+	}
+	return newDeviceFromFd(fd)
+}
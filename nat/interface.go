@@ -31,6 +31,7 @@ type NATService interface {
 // Options params to setup firewall/NAT rules.
 type Options struct {
 	VPNNetwork        net.IPNet
+	VPNNetwork6       net.IPNet
 	ProviderExtIP     net.IP
 	EnableDNSRedirect bool
 	DNSIP             net.IP
@@ -0,0 +1,443 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package mapping
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackpal/gateway"
+	"github.com/mysteriumnetwork/node/eventbus"
+	"github.com/mysteriumnetwork/node/nat/event"
+	"github.com/rs/zerolog/log"
+)
+
+// PCPStageName is used to indicate PCP port mapping NAT traversal stage
+const PCPStageName = "pcp_port_mapping"
+
+// DefaultPCPConfig returns default PCP port mapping config.
+func DefaultPCPConfig() *PCPConfig {
+	return &PCPConfig{
+		Gateway:      gateway.DiscoverGateway,
+		Port:         5351,
+		MapLifetime:  20 * time.Minute,
+		RetryTimeout: 250 * time.Millisecond,
+		Retries:      4,
+		SkipInterval: 10 * time.Minute,
+	}
+}
+
+// PCPConfig represents PCP port mapping config.
+type PCPConfig struct {
+	// Gateway returns IP of the gateway running PCP server.
+	Gateway func() (net.IP, error)
+	// Port is PCP server port of the gateway.
+	Port int
+	// MapLifetime is a requested lifetime of port mapping, mapping is renewed in the half of the assigned lifetime.
+	MapLifetime time.Duration
+	// RetryTimeout is a timeout of the first request attempt, it is doubled for every retry.
+	RetryTimeout time.Duration
+	// Retries is a number of request attempts.
+	Retries int
+	// SkipInterval is an interval for which gateway is not queried again after failed mapping.
+	SkipInterval time.Duration
+}
+
+// NewPCPPortMapper returns port mapper instance which maps ports using router's PCP (RFC 6887) server.
+func NewPCPPortMapper(config *PCPConfig, publisher eventbus.Publisher) PortMapper {
+	return &pcpPortMapper{
+		config:    config,
+		publisher: publisher,
+		mappings:  make(map[*pcpMapping]struct{}),
+	}
+}
+
+type pcpPortMapper struct {
+	config    *PCPConfig
+	publisher eventbus.Publisher
+
+	mu           sync.Mutex
+	client       *pcpClient
+	skipUntil    time.Time
+	mappingsLock sync.Mutex
+	mappings     map[*pcpMapping]struct{}
+}
+
+type pcpMapping struct {
+	request pcpMapRequest
+	renew   chan struct{}
+	stop    chan struct{}
+}
+
+func (p *pcpPortMapper) Map(protocol string, port int, name string) (release func(), ok bool) {
+	client, err := p.gatewayClient()
+	if err != nil {
+		log.Info().Err(err).Msg("PCP port mapping skipped")
+		p.notify(err)
+		return nil, false
+	}
+
+	m, err := newPCPMapping(protocol, port, p.config.MapLifetime)
+	if err != nil {
+		log.Warn().Err(err).Msgf("Couldn't add PCP port mapping for port %d", port)
+		p.notify(err)
+		return nil, false
+	}
+
+	resp, err := p.request(client, m.request, nil)
+	if err == nil && int(resp.externalPort) != port {
+		p.deleteMapping(client, m.request)
+		err = fmt.Errorf("PCP server assigned external port %d instead of %d", resp.externalPort, port)
+	}
+	p.notify(err)
+	if err != nil {
+		log.Warn().Err(err).Msgf("Couldn't add PCP port mapping for port %d", port)
+		p.skip()
+		return nil, false
+	}
+	log.Info().Msgf("Mapped network port %d to %s:%d using PCP (%s)", port, resp.externalIP, resp.externalPort, name)
+
+	p.mappingsLock.Lock()
+	p.mappings[m] = struct{}{}
+	p.mappingsLock.Unlock()
+
+	go p.renewLoop(client, m, time.Duration(resp.lifetime)*time.Second/2)
+
+	return func() {
+		p.mappingsLock.Lock()
+		delete(p.mappings, m)
+		p.mappingsLock.Unlock()
+
+		close(m.stop)
+		p.deleteMapping(client, m.request)
+	}, true
+}
+
+func newPCPMapping(protocol string, port int, lifetime time.Duration) (*pcpMapping, error) {
+	proto, ok := pcpProtocols[strings.ToUpper(protocol)]
+	if !ok {
+		return nil, fmt.Errorf("unsupported protocol %q", protocol)
+	}
+
+	m := &pcpMapping{
+		request: pcpMapRequest{
+			protocol:     proto,
+			internalPort: uint16(port),
+			externalPort: uint16(port),
+			lifetime:     uint32(lifetime / time.Second),
+		},
+		renew: make(chan struct{}, 1),
+		stop:  make(chan struct{}),
+	}
+	if _, err := rand.Read(m.request.nonce[:]); err != nil {
+		return nil, fmt.Errorf("could not generate mapping nonce: %w", err)
+	}
+	return m, nil
+}
+
+func (p *pcpPortMapper) gatewayClient() (*pcpClient, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if time.Now().Before(p.skipUntil) {
+		return nil, errors.New("previous PCP port mapping failed")
+	}
+
+	ip, err := p.config.Gateway()
+	if err != nil {
+		return nil, fmt.Errorf("could not discover gateway: %w", err)
+	}
+
+	addr := &net.UDPAddr{IP: ip, Port: p.config.Port}
+	if p.client == nil || p.client.gateway.String() != addr.String() {
+		p.client = newPCPClient(addr, p.config.RetryTimeout, p.config.Retries)
+	}
+	return p.client, nil
+}
+
+func (p *pcpPortMapper) skip() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.skipUntil = time.Now().Add(p.config.SkipInterval)
+}
+
+// renewLoop renews mapping in the half of its lifetime and
+// immediately when other mapping detects that PCP server has lost its state.
+func (p *pcpPortMapper) renewLoop(client *pcpClient, m *pcpMapping, wait time.Duration) {
+	for {
+		select {
+		case <-m.stop:
+			return
+		case <-m.renew:
+		case <-time.After(wait):
+		}
+
+		resp, err := p.request(client, m.request, m)
+		p.notify(err)
+		if err != nil {
+			log.Warn().Err(err).Msgf("Couldn't renew PCP port mapping for port %d", m.request.internalPort)
+			if wait /= 2; wait < p.config.RetryTimeout {
+				wait = p.config.RetryTimeout
+			}
+			continue
+		}
+		wait = time.Duration(resp.lifetime) * time.Second / 2
+	}
+}
+
+func (p *pcpPortMapper) request(client *pcpClient, req pcpMapRequest, current *pcpMapping) (*pcpMapResponse, error) {
+	resp, restarted, err := client.mapPort(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if restarted {
+		log.Warn().Msgf("PCP server %s restart detected, renewing port mappings", client.gateway)
+		p.mappingsLock.Lock()
+		for m := range p.mappings {
+			if m == current {
+				continue
+			}
+			select {
+			case m.renew <- struct{}{}:
+			default:
+			}
+		}
+		p.mappingsLock.Unlock()
+	}
+	return resp, nil
+}
+
+func (p *pcpPortMapper) deleteMapping(client *pcpClient, req pcpMapRequest) {
+	log.Debug().Msgf("Deleting PCP port mapping for port: %d", req.internalPort)
+	req.lifetime = 0
+	if _, _, err := client.mapPort(req); err != nil {
+		log.Warn().Err(err).Msg("Couldn't delete PCP port mapping")
+	}
+}
+
+func (p *pcpPortMapper) notify(err error) {
+	if err != nil {
+		p.publisher.Publish(event.AppTopicTraversal, event.BuildFailureEvent(PCPStageName, err))
+	} else {
+		p.publisher.Publish(event.AppTopicTraversal, event.BuildSuccessfulEvent(PCPStageName))
+	}
+}
+
+const (
+	pcpVersion        = 2
+	pcpOpcodeMap      = 1
+	pcpResponseBit    = 0x80
+	pcpHeaderSize     = 24
+	pcpMapPayloadSize = 36
+	pcpMaxMessageSize = 1100
+	pcpResultSuccess  = 0
+)
+
+var pcpProtocols = map[string]byte{
+	"TCP": 6,
+	"UDP": 17,
+}
+
+var pcpResultCodes = map[byte]string{
+	1:  "UNSUPP_VERSION",
+	2:  "NOT_AUTHORIZED",
+	3:  "MALFORMED_REQUEST",
+	4:  "UNSUPP_OPCODE",
+	5:  "UNSUPP_OPTION",
+	6:  "MALFORMED_OPTION",
+	7:  "NETWORK_FAILURE",
+	8:  "NO_RESOURCES",
+	9:  "UNSUPP_PROTOCOL",
+	10: "USER_EX_QUOTA",
+	11: "CANNOT_PROVIDE_EXTERNAL",
+	12: "ADDRESS_MISMATCH",
+	13: "EXCESSIVE_REMOTE_PEERS",
+}
+
+// pcpMapRequest is a PCP MAP opcode request, zero lifetime deletes the mapping.
+type pcpMapRequest struct {
+	nonce        [12]byte
+	protocol     byte
+	internalPort uint16
+	externalPort uint16
+	lifetime     uint32
+}
+
+func (r pcpMapRequest) encode(clientIP net.IP) []byte {
+	b := make([]byte, pcpHeaderSize+pcpMapPayloadSize)
+	b[0] = pcpVersion
+	b[1] = pcpOpcodeMap
+	binary.BigEndian.PutUint32(b[4:8], r.lifetime)
+	copy(b[8:24], clientIP.To16())
+	copy(b[24:36], r.nonce[:])
+	b[36] = r.protocol
+	binary.BigEndian.PutUint16(b[40:42], r.internalPort)
+	binary.BigEndian.PutUint16(b[42:44], r.externalPort)
+	// Any external IPv4 address is suggested.
+	copy(b[44:60], net.IPv4zero.To16())
+	return b
+}
+
+// pcpMapResponse is a PCP MAP opcode response.
+type pcpMapResponse struct {
+	resultCode   byte
+	lifetime     uint32
+	epoch        uint32
+	nonce        [12]byte
+	protocol     byte
+	internalPort uint16
+	externalPort uint16
+	externalIP   net.IP
+}
+
+func decodePCPMapResponse(b []byte) (*pcpMapResponse, error) {
+	if len(b) < pcpHeaderSize+pcpMapPayloadSize {
+		return nil, fmt.Errorf("PCP response is too short: %d bytes", len(b))
+	}
+	if b[0] != pcpVersion {
+		return nil, fmt.Errorf("unsupported PCP version %d", b[0])
+	}
+	if b[1] != pcpResponseBit|pcpOpcodeMap {
+		return nil, fmt.Errorf("unexpected PCP opcode %d", b[1])
+	}
+
+	resp := &pcpMapResponse{
+		resultCode:   b[3],
+		lifetime:     binary.BigEndian.Uint32(b[4:8]),
+		epoch:        binary.BigEndian.Uint32(b[8:12]),
+		protocol:     b[36],
+		internalPort: binary.BigEndian.Uint16(b[40:42]),
+		externalPort: binary.BigEndian.Uint16(b[42:44]),
+		externalIP:   net.IP(append([]byte(nil), b[44:60]...)),
+	}
+	copy(resp.nonce[:], b[24:36])
+	return resp, nil
+}
+
+// pcpClient sends requests to a single PCP server and tracks its epoch.
+type pcpClient struct {
+	gateway      *net.UDPAddr
+	retryTimeout time.Duration
+	retries      int
+
+	mu         sync.Mutex
+	epochKnown bool
+	epoch      uint32
+	epochTime  time.Time
+}
+
+func newPCPClient(gateway *net.UDPAddr, retryTimeout time.Duration, retries int) *pcpClient {
+	return &pcpClient{
+		gateway:      gateway,
+		retryTimeout: retryTimeout,
+		retries:      retries,
+	}
+}
+
+// mapPort sends MAP request and returns the response and whether the server lost its state since the previous response.
+func (c *pcpClient) mapPort(req pcpMapRequest) (resp *pcpMapResponse, restarted bool, err error) {
+	conn, err := net.DialUDP("udp", nil, c.gateway)
+	if err != nil {
+		return nil, false, fmt.Errorf("could not dial PCP server: %w", err)
+	}
+	defer conn.Close()
+
+	packet := req.encode(conn.LocalAddr().(*net.UDPAddr).IP)
+	buf := make([]byte, pcpMaxMessageSize)
+	timeout := c.retryTimeout
+	for i := 0; i < c.retries; i++ {
+		if _, err := conn.Write(packet); err != nil {
+			return nil, false, fmt.Errorf("could not send PCP request: %w", err)
+		}
+
+		if err := conn.SetReadDeadline(time.Now().Add(timeout)); err != nil {
+			return nil, false, err
+		}
+		resp, err := c.readResponse(conn, buf, req)
+		if err == nil {
+			return resp, c.checkEpoch(resp.epoch), nil
+		}
+		if netErr, ok := err.(net.Error); !ok || !netErr.Timeout() {
+			return nil, false, err
+		}
+		timeout *= 2
+	}
+
+	return nil, false, fmt.Errorf("no PCP response from %s", c.gateway)
+}
+
+func (c *pcpClient) readResponse(conn *net.UDPConn, buf []byte, req pcpMapRequest) (*pcpMapResponse, error) {
+	for {
+		n, err := conn.Read(buf)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := decodePCPMapResponse(buf[:n])
+		if err != nil {
+			log.Debug().Err(err).Msg("Skipping invalid PCP response")
+			continue
+		}
+		if resp.nonce != req.nonce || resp.protocol != req.protocol || resp.internalPort != req.internalPort {
+			continue
+		}
+		if resp.resultCode != pcpResultSuccess {
+			return nil, fmt.Errorf("PCP request failed with %s", pcpResultName(resp.resultCode))
+		}
+		return resp, nil
+	}
+}
+
+// checkEpoch validates server epoch as described in RFC 6887 section 8.5
+// and reports if the server lost its state and mappings have to be recreated.
+func (c *pcpClient) checkEpoch(epoch uint32) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	restarted := false
+	if c.epochKnown {
+		serverDelta := int64(epoch) - int64(c.epoch)
+		clientDelta := int64(now.Sub(c.epochTime) / time.Second)
+		if serverDelta < -1 ||
+			clientDelta+2 < serverDelta-serverDelta/16 ||
+			serverDelta+2 < clientDelta-clientDelta/16 {
+			restarted = true
+		}
+	}
+
+	c.epochKnown = true
+	c.epoch = epoch
+	c.epochTime = now
+	return restarted
+}
+
+func pcpResultName(code byte) string {
+	if name, ok := pcpResultCodes[code]; ok {
+		return name
+	}
+	return fmt.Sprintf("result code %d", code)
+}
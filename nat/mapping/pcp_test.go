@@ -0,0 +1,254 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package mapping
+
+import (
+	"encoding/binary"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mysteriumnetwork/node/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPCPMap_MapsAndDeletesPort(t *testing.T) {
+	server := newFakePCPServer(t)
+	defer server.stop()
+	portMapper := NewPCPPortMapper(server.config(), mocks.NewEventBus())
+
+	release, ok := portMapper.Map("UDP", 51334, "Test")
+	require.True(t, ok)
+
+	requests := server.requestsFor(51334)
+	require.Len(t, requests, 1)
+	assert.Equal(t, byte(17), requests[0].protocol)
+	assert.Equal(t, uint16(51334), requests[0].externalPort)
+	assert.Equal(t, uint32(1200), requests[0].lifetime)
+
+	release()
+	requests = server.requestsFor(51334)
+	require.Len(t, requests, 2)
+	assert.Equal(t, uint32(0), requests[1].lifetime)
+	assert.Equal(t, requests[0].nonce, requests[1].nonce)
+}
+
+func TestPCPMap_SkipsGatewayAfterFailure(t *testing.T) {
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	require.NoError(t, err)
+	port := conn.LocalAddr().(*net.UDPAddr).Port
+	conn.Close()
+
+	lookups := 0
+	config := &PCPConfig{
+		Gateway: func() (net.IP, error) {
+			lookups++
+			return net.IPv4(127, 0, 0, 1), nil
+		},
+		Port:         port,
+		MapLifetime:  time.Minute,
+		RetryTimeout: 10 * time.Millisecond,
+		Retries:      2,
+		SkipInterval: time.Minute,
+	}
+	portMapper := NewPCPPortMapper(config, mocks.NewEventBus())
+
+	release, ok := portMapper.Map("UDP", 51334, "Test")
+	assert.False(t, ok)
+	assert.Nil(t, release)
+
+	_, ok = portMapper.Map("UDP", 51335, "Test")
+	assert.False(t, ok)
+	assert.Equal(t, 1, lookups)
+}
+
+func TestPCPMap_FailsWhenExternalPortDiffers(t *testing.T) {
+	server := newFakePCPServer(t)
+	defer server.stop()
+	server.setExternalPort(51334, 40000)
+	portMapper := NewPCPPortMapper(server.config(), mocks.NewEventBus())
+
+	_, ok := portMapper.Map("UDP", 51334, "Test")
+	assert.False(t, ok)
+
+	requests := server.requestsFor(51334)
+	require.Len(t, requests, 2)
+	assert.Equal(t, uint32(0), requests[1].lifetime)
+}
+
+func TestPCPMap_RenewsMappingsOnServerRestart(t *testing.T) {
+	server := newFakePCPServer(t)
+	defer server.stop()
+	server.setLifetime(51334, 1)
+	portMapper := NewPCPPortMapper(server.config(), mocks.NewEventBus())
+
+	release, ok := portMapper.Map("UDP", 51334, "Short lease")
+	require.True(t, ok)
+	defer release()
+	release, ok = portMapper.Map("UDP", 51335, "Long lease")
+	require.True(t, ok)
+	defer release()
+
+	server.restart()
+
+	assert.Eventually(t, func() bool {
+		return len(server.requestsFor(51335)) >= 2
+	}, 3*time.Second, 10*time.Millisecond)
+}
+
+func TestPortMapper_FallsBackToPCP(t *testing.T) {
+	server := newFakePCPServer(t)
+	defer server.stop()
+	router := &mockRouter{uPnPEnabled: false}
+	config := &Config{
+		MapInterface: router,
+		PCP:          server.config(),
+	}
+	portMapper := NewPortMapper(config, mocks.NewEventBus())
+
+	release, ok := portMapper.Map("UDP", 51334, "Test")
+	require.True(t, ok)
+	defer release()
+
+	assert.Equal(t, mapping{}, router.addedMapping())
+	assert.Len(t, server.requestsFor(51334), 1)
+}
+
+func TestPCPClient_CheckEpoch(t *testing.T) {
+	client := newPCPClient(&net.UDPAddr{}, time.Second, 1)
+
+	assert.False(t, client.checkEpoch(1000))
+	assert.False(t, client.checkEpoch(1000))
+	assert.True(t, client.checkEpoch(10))
+	assert.False(t, client.checkEpoch(11))
+	assert.True(t, client.checkEpoch(5000))
+}
+
+type fakePCPServer struct {
+	conn *net.UDPConn
+
+	mu            sync.Mutex
+	epochStart    time.Time
+	requests      map[uint16][]pcpMapRequest
+	lifetimes     map[uint16]uint32
+	externalPorts map[uint16]uint16
+}
+
+func newFakePCPServer(t *testing.T) *fakePCPServer {
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	require.NoError(t, err)
+
+	s := &fakePCPServer{
+		conn:          conn,
+		epochStart:    time.Now().Add(-time.Hour),
+		requests:      make(map[uint16][]pcpMapRequest),
+		lifetimes:     make(map[uint16]uint32),
+		externalPorts: make(map[uint16]uint16),
+	}
+	go s.serve()
+	return s
+}
+
+func (s *fakePCPServer) config() *PCPConfig {
+	return &PCPConfig{
+		Gateway:      func() (net.IP, error) { return net.IPv4(127, 0, 0, 1), nil },
+		Port:         s.conn.LocalAddr().(*net.UDPAddr).Port,
+		MapLifetime:  20 * time.Minute,
+		RetryTimeout: 100 * time.Millisecond,
+		Retries:      3,
+		SkipInterval: time.Minute,
+	}
+}
+
+func (s *fakePCPServer) serve() {
+	buf := make([]byte, pcpMaxMessageSize)
+	for {
+		n, addr, err := s.conn.ReadFromUDP(buf)
+		if err != nil {
+			return
+		}
+		if n != pcpHeaderSize+pcpMapPayloadSize {
+			continue
+		}
+
+		req := pcpMapRequest{
+			protocol:     buf[36],
+			internalPort: binary.BigEndian.Uint16(buf[40:42]),
+			externalPort: binary.BigEndian.Uint16(buf[42:44]),
+			lifetime:     binary.BigEndian.Uint32(buf[4:8]),
+		}
+		copy(req.nonce[:], buf[24:36])
+
+		s.mu.Lock()
+		s.requests[req.internalPort] = append(s.requests[req.internalPort], req)
+		lifetime := req.lifetime
+		if l, ok := s.lifetimes[req.internalPort]; ok && lifetime > 0 {
+			lifetime = l
+		}
+		externalPort := req.externalPort
+		if p, ok := s.externalPorts[req.internalPort]; ok {
+			externalPort = p
+		}
+		epoch := uint32(time.Since(s.epochStart) / time.Second)
+		s.mu.Unlock()
+
+		resp := make([]byte, n)
+		copy(resp, buf[:n])
+		resp[1] = pcpResponseBit | pcpOpcodeMap
+		resp[3] = pcpResultSuccess
+		binary.BigEndian.PutUint32(resp[4:8], lifetime)
+		binary.BigEndian.PutUint32(resp[8:12], epoch)
+		binary.BigEndian.PutUint16(resp[42:44], externalPort)
+		copy(resp[44:60], net.IPv4(1, 2, 3, 4).To16())
+		s.conn.WriteToUDP(resp, addr)
+	}
+}
+
+func (s *fakePCPServer) requestsFor(port uint16) []pcpMapRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]pcpMapRequest(nil), s.requests[port]...)
+}
+
+func (s *fakePCPServer) setLifetime(port uint16, lifetime uint32) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lifetimes[port] = lifetime
+}
+
+func (s *fakePCPServer) setExternalPort(port, externalPort uint16) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.externalPorts[port] = externalPort
+}
+
+func (s *fakePCPServer) restart() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.epochStart = time.Now()
+}
+
+func (s *fakePCPServer) stop() {
+	s.conn.Close()
+}
@@ -37,6 +37,7 @@ func DefaultConfig() *Config {
 		MapInterface:      portmap.Any(),
 		MapLifetime:       20 * time.Minute,
 		MapUpdateInterval: 15 * time.Minute,
+		PCP:               DefaultPCPConfig(),
 	}
 }
 
@@ -45,9 +46,12 @@ type Config struct {
 	MapInterface      portmap.Interface
 	MapLifetime       time.Duration
 	MapUpdateInterval time.Duration
+	// PCP enables PCP port mapping when uPnP and NAT-PMP fail, nil disables it.
+	PCP *PCPConfig
 }
 
-// PortMapper tries to map port using router's uPnP or NAT-PMP depending on given config map interface.
+// PortMapper tries to map port using router's uPnP or NAT-PMP depending on given config map interface
+// falling back to PCP if it is enabled.
 type PortMapper interface {
 	// Map maps port for given protocol. It returns release func which
 	// must be called when port no longer needed and ok which is true if
@@ -57,18 +61,34 @@ type PortMapper interface {
 
 // NewPortMapper returns port mapper instance.
 func NewPortMapper(config *Config, publisher eventbus.Publisher) PortMapper {
+	var pcp PortMapper
+	if config.PCP != nil {
+		pcp = NewPCPPortMapper(config.PCP, publisher)
+	}
+
 	return &portMapper{
 		config:    config,
 		publisher: publisher,
+		pcp:       pcp,
 	}
 }
 
 type portMapper struct {
 	config    *Config
 	publisher eventbus.Publisher
+	pcp       PortMapper
 }
 
 func (p *portMapper) Map(protocol string, port int, name string) (release func(), ok bool) {
+	release, ok = p.mapPort(protocol, port, name)
+	if !ok && p.pcp != nil {
+		log.Info().Msgf("Trying PCP port mapping for port %d", port)
+		return p.pcp.Map(protocol, port, name)
+	}
+	return release, ok
+}
+
+func (p *portMapper) mapPort(protocol string, port int, name string) (release func(), ok bool) {
 	if !p.routerIPPublic() {
 		err := errors.New("failed to find router public IP")
 		log.Info().Err(err).Msg("Port mapping is useless, skipping it.")
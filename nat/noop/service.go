@@ -0,0 +1,51 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package noop
+
+import "github.com/mysteriumnetwork/node/nat"
+
+// NATService doesn't set up any forwarding rules, it is used when traffic is forwarded outside of the node,
+// e.g. by the mobile application which owns the TUN device.
+type NATService struct{}
+
+// NewService returns a new noop NAT service.
+func NewService() *NATService {
+	return &NATService{}
+}
+
+var _ nat.NATService = &NATService{}
+
+// Enable does nothing.
+func (s *NATService) Enable() error {
+	return nil
+}
+
+// Setup does nothing.
+func (s *NATService) Setup(_ nat.Options) ([]interface{}, error) {
+	return nil, nil
+}
+
+// Del does nothing.
+func (s *NATService) Del(_ []interface{}) error {
+	return nil
+}
+
+// Disable does nothing.
+func (s *NATService) Disable() error {
+	return nil
+}
@@ -29,9 +29,12 @@ import (
 )
 
 type serviceIPTables struct {
-	mu        sync.Mutex
-	rules     []iptables.Rule
-	ipForward serviceIPForward
+	mu         sync.Mutex
+	rules      []iptables.Rule
+	ipForward  serviceIPForward
+	ipForward6 serviceIPForward
+	// ipv6 tells if IPv6 forwarding was enabled for the IPv6 enabled tunnels.
+	ipv6 bool
 }
 
 const (
@@ -60,6 +63,14 @@ func (svc *serviceIPTables) Setup(opts Options) (appliedRules []interface{}, err
 		}
 	}()
 
+	if opts.VPNNetwork6.IP != nil && !svc.ipv6 {
+		// Note that enabling IPv6 forwarding makes Linux ignore router advertisements, unless accept_ra is set to 2.
+		if err := svc.ipForward6.Enable(); err != nil {
+			return nil, errors.Wrap(err, "could not enable IPv6 forwarding")
+		}
+		svc.ipv6 = true
+	}
+
 	for _, rule := range makeIPTablesRules(opts) {
 		if err := svc.applyRule(rule); err != nil {
 			return nil, err
@@ -100,11 +111,15 @@ func (svc *serviceIPTables) Enable() error {
 // Disable disables NAT service and deletes all rules.
 func (svc *serviceIPTables) Disable() error {
 	svc.ipForward.Disable()
+	if svc.ipv6 {
+		svc.ipForward6.Disable()
+		svc.ipv6 = false
+	}
 	return svc.Del(untypedIptRules(svc.rules))
 }
 
 func (svc *serviceIPTables) applyRule(rule iptables.Rule) error {
-	if err := iptablesExec(rule.ApplyArgs()...); err != nil {
+	if err := iptablesExec(rule.IsIPv6(), rule.ApplyArgs()...); err != nil {
 		return err
 	}
 	svc.rules = append(svc.rules, rule)
@@ -112,7 +127,7 @@ func (svc *serviceIPTables) applyRule(rule iptables.Rule) error {
 }
 
 func (svc *serviceIPTables) removeRule(rule iptables.Rule) error {
-	if err := iptablesExec(rule.RemoveArgs()...); err != nil {
+	if err := iptablesExec(rule.IsIPv6(), rule.RemoveArgs()...); err != nil {
 		return err
 	}
 	for i := range svc.rules {
@@ -161,13 +176,56 @@ func makeIPTablesRules(opts Options) (rules []iptables.Rule) {
 		"--table", "nat")
 	rules = append(rules, rule)
 
+	if opts.VPNNetwork6.IP != nil {
+		rules = append(rules, makeIP6TablesRules(opts)...)
+	}
+
+	return rules
+}
+
+// makeIP6TablesRules makes NAT66 and forwarding rules for the IPv6 network of the tunnel.
+func makeIP6TablesRules(opts Options) (rules []iptables.Rule) {
+	vpnNetwork := opts.VPNNetwork6.String()
+
+	// Protect private networks rule
+	for _, ipNet := range protectedNetworks() {
+		if ipNet.IP.To4() != nil {
+			continue
+		}
+		rule := iptables.AppendTo(chainForward).RuleSpec(
+			"--source", vpnNetwork, "--destination", ipNet.String(),
+			"--jump", "DROP").IPv6()
+		rules = append(rules, rule)
+	}
+
+	// Forwarding rules, the default ip6tables forward policy is often DROP
+	rule := iptables.AppendTo(chainForward).RuleSpec("--source", vpnNetwork,
+		"--jump", "ACCEPT").IPv6()
+	rules = append(rules, rule)
+
+	rule = iptables.AppendTo(chainForward).RuleSpec("--destination", vpnNetwork,
+		"--match", "conntrack", "--ctstate", "RELATED,ESTABLISHED",
+		"--jump", "ACCEPT").IPv6()
+	rules = append(rules, rule)
+
+	// NAT66 forwarding rule, tunnel uses unique local addresses which are not routed in the internet
+	rule = iptables.AppendTo(chainPostRouting).RuleSpec("--source", vpnNetwork, "!", "--destination", vpnNetwork,
+		"--jump", "MASQUERADE",
+		"--table", "nat").IPv6()
+	rules = append(rules, rule)
+
 	return rules
 }
 
-func iptablesExec(args ...string) error {
-	args = append([]string{"/sbin/iptables"}, args...)
+func iptablesExec(ipv6 bool, args ...string) error {
+	binary := "/sbin/iptables"
+	if ipv6 {
+		binary = "/sbin/ip6tables"
+	}
+
+	args = append([]string{binary}, args...)
 	if err := cmdutil.SudoExec(args...); err != nil {
-		return errors.Wrap(err, "error calling IPTables")
+		return errors.Wrapf(err, "error calling %s", binary)
 	}
 	return nil
 }
@@ -0,0 +1,62 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package nat
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_makeIPTablesRules_IPv6(t *testing.T) {
+	_, network, _ := net.ParseCIDR("10.182.0.0/24")
+	_, network6, _ := net.ParseCIDR("fd00:182::/64")
+
+	rules := makeIPTablesRules(Options{
+		VPNNetwork:    *network,
+		VPNNetwork6:   *network6,
+		ProviderExtIP: net.ParseIP("1.2.3.4"),
+	})
+
+	assert.Len(t, rules, 4)
+	assert.False(t, rules[0].IsIPv6())
+	assert.Equal(t,
+		[]string{"-A", "POSTROUTING", "--source", "10.182.0.0/24", "!", "--destination", "10.182.0.0/24", "--jump", "SNAT", "--to", "1.2.3.4", "--table", "nat"},
+		rules[0].ApplyArgs(),
+	)
+	for _, rule := range rules[1:] {
+		assert.True(t, rule.IsIPv6())
+	}
+	assert.Equal(t,
+		[]string{"-A", "POSTROUTING", "--source", "fd00:182::/64", "!", "--destination", "fd00:182::/64", "--jump", "MASQUERADE", "--table", "nat"},
+		rules[3].ApplyArgs(),
+	)
+}
+
+func Test_makeIPTablesRules_IPv4Only(t *testing.T) {
+	_, network, _ := net.ParseCIDR("10.182.0.0/24")
+
+	rules := makeIPTablesRules(Options{
+		VPNNetwork:    *network,
+		ProviderExtIP: net.ParseIP("1.2.3.4"),
+	})
+
+	assert.Len(t, rules, 1)
+	assert.False(t, rules[0].IsIPv6())
+}
@@ -0,0 +1,261 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package nat
+
+import (
+	"net"
+	"strings"
+	"sync"
+
+	"github.com/mysteriumnetwork/node/firewall/nftables"
+	"github.com/mysteriumnetwork/node/utils"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+const nftNATTable = "myst_nat"
+
+// serviceNftables sets up NAT/Firewall rules in dedicated nftables tables.
+// Every VPN network gets its own chains, which are reached through the verdict maps keyed by the source network,
+// so rules of a network are added and removed atomically without touching the others.
+type serviceNftables struct {
+	mu         sync.Mutex
+	networks   map[string]nftNetwork
+	ipForward  serviceIPForward
+	ipForward6 serviceIPForward
+	// ipv6 tells if IPv6 forwarding was enabled for the IPv6 enabled tunnels.
+	ipv6 bool
+	// tables tells if the tables were created.
+	tables bool
+}
+
+// nftNetwork is the set of chains of a single VPN network, used as the applied rule.
+type nftNetwork struct {
+	id     string
+	family string
+	chains []nftNetworkChain
+}
+
+type nftNetworkChain struct {
+	name    string
+	vmap    string
+	network string
+}
+
+// Setup sets NAT/Firewall rules for the given NATOptions.
+func (svc *serviceNftables) Setup(opts Options) (appliedRules []interface{}, err error) {
+	log.Info().Msg("Setting up NAT/Firewall rules")
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+
+	tx := nftables.NewTransaction()
+	if !svc.tables {
+		svc.addTables(tx)
+	}
+
+	if opts.VPNNetwork6.IP != nil && !svc.ipv6 {
+		// Note that enabling IPv6 forwarding makes Linux ignore router advertisements, unless accept_ra is set to 2.
+		if err := svc.ipForward6.Enable(); err != nil {
+			return nil, errors.Wrap(err, "could not enable IPv6 forwarding")
+		}
+		svc.ipv6 = true
+	}
+
+	networks := []nftNetwork{makeNftNetwork(tx, opts)}
+	if opts.VPNNetwork6.IP != nil {
+		networks = append(networks, makeNftNetwork6(tx, opts))
+	}
+
+	if err := tx.Apply(); err != nil {
+		return nil, errors.Wrap(err, "could not apply nftables rules")
+	}
+	svc.tables = true
+
+	for _, network := range networks {
+		svc.networks[network.family+network.id] = network
+		appliedRules = append(appliedRules, network)
+	}
+	log.Info().Msg("Setting up NAT/Firewall rules... done")
+	return appliedRules, nil
+}
+
+// Del removes given NAT/Firewall rules that were previously set up.
+func (svc *serviceNftables) Del(rules []interface{}) error {
+	log.Info().Msg("Deleting NAT/Firewall rules")
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+
+	errs := utils.ErrorCollection{}
+	for _, rule := range rules {
+		network := rule.(nftNetwork)
+		if err := svc.deleteNetwork(network); err != nil {
+			errs.Add(err)
+		}
+	}
+	err := errs.Error()
+	log.Info().Err(err).Msg("Deleting NAT/Firewall rules... done")
+	return err
+}
+
+// Enable enables NAT service.
+func (svc *serviceNftables) Enable() error {
+	err := svc.ipForward.Enable()
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to enable IP forwarding")
+	}
+	return err
+}
+
+// Disable disables NAT service and deletes all rules.
+func (svc *serviceNftables) Disable() error {
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+
+	svc.ipForward.Disable()
+	if svc.ipv6 {
+		svc.ipForward6.Disable()
+		svc.ipv6 = false
+	}
+
+	svc.networks = make(map[string]nftNetwork)
+	svc.tables = false
+	return nftables.NewTransaction().
+		DeleteTable("ip", nftNATTable).
+		DeleteTable("ip6", nftNATTable).
+		Apply()
+}
+
+func (svc *serviceNftables) deleteNetwork(network nftNetwork) error {
+	if _, ok := svc.networks[network.family+network.id]; !ok {
+		return nil
+	}
+
+	tx := nftables.NewTransaction()
+	for _, chain := range network.chains {
+		tx.Add("delete element %s %s %s { %s }", network.family, nftNATTable, chain.vmap, chain.network)
+		tx.Add("flush chain %s %s %s", network.family, nftNATTable, chain.name)
+		tx.Add("delete chain %s %s %s", network.family, nftNATTable, chain.name)
+	}
+	if err := tx.Apply(); err != nil {
+		return errors.Wrapf(err, "could not delete nftables rules of %s", network.id)
+	}
+	delete(svc.networks, network.family+network.id)
+	return nil
+}
+
+// addTables adds the commands creating IPv4 and IPv6 tables, replacing the ones left by previous runs.
+func (svc *serviceNftables) addTables(tx *nftables.Transaction) {
+	for _, family := range []string{"ip", "ip6"} {
+		addrType := "ipv4_addr"
+		if family == "ip6" {
+			addrType = "ipv6_addr"
+		}
+
+		tx.ResetTable(family, nftNATTable)
+		for _, vmap := range []string{"dns_networks", "nat_networks", "forward_networks", "reply_networks"} {
+			tx.Add("add map %s %s %s { type %s : verdict; flags interval; }", family, nftNATTable, vmap, addrType)
+		}
+		tx.Add("add chain %s %s prerouting { type nat hook prerouting priority -100; }", family, nftNATTable)
+		tx.Add("add chain %s %s postrouting { type nat hook postrouting priority 100; }", family, nftNATTable)
+		tx.Add("add chain %s %s forward { type filter hook forward priority 0; policy accept; }", family, nftNATTable)
+		tx.Add("add rule %s %s prerouting %s saddr vmap @dns_networks", family, nftNATTable, family)
+		tx.Add("add rule %s %s postrouting %s saddr vmap @nat_networks", family, nftNATTable, family)
+		tx.Add("add rule %s %s forward %s saddr vmap @forward_networks", family, nftNATTable, family)
+		tx.Add("add rule %s %s forward %s daddr vmap @reply_networks", family, nftNATTable, family)
+	}
+}
+
+func makeNftNetwork(tx *nftables.Transaction, opts Options) nftNetwork {
+	vpnNetwork := nftNetworkString(opts.VPNNetwork)
+	network := nftNetwork{id: nftChainID(vpnNetwork), family: "ip"}
+
+	if opts.EnableDNSRedirect {
+		// DNS port redirect rules
+		chain := network.addChain(tx, "dns", vpnNetwork)
+		for _, proto := range []string{"udp", "tcp"} {
+			tx.Add("add rule ip %s %s ip daddr %s %s dport 53 redirect to :%d", nftNATTable, chain, opts.DNSIP, proto, opts.DNSPort)
+		}
+	}
+
+	// Protect private networks rule
+	if protected := protectedNetworksOf(false); len(protected) > 0 {
+		chain := network.addChain(tx, "forward", vpnNetwork)
+		tx.Add("add rule ip %s %s ip daddr { %s } drop", nftNATTable, chain, strings.Join(protected, ", "))
+	}
+
+	// NAT forwarding rule
+	chain := network.addChain(tx, "nat", vpnNetwork)
+	tx.Add("add rule ip %s %s ip daddr != %s snat to %s", nftNATTable, chain, vpnNetwork, opts.ProviderExtIP)
+
+	return network
+}
+
+// makeNftNetwork6 makes NAT66 and forwarding rules for the IPv6 network of the tunnel.
+func makeNftNetwork6(tx *nftables.Transaction, opts Options) nftNetwork {
+	vpnNetwork := nftNetworkString(opts.VPNNetwork6)
+	network := nftNetwork{id: nftChainID(vpnNetwork), family: "ip6"}
+
+	// Protect private networks rule
+	chain := network.addChain(tx, "forward", vpnNetwork)
+	if protected := protectedNetworksOf(true); len(protected) > 0 {
+		tx.Add("add rule ip6 %s %s ip6 daddr { %s } drop", nftNATTable, chain, strings.Join(protected, ", "))
+	}
+
+	// Forwarding rules, the default ip6tables forward policy is often DROP
+	tx.Add("add rule ip6 %s %s accept", nftNATTable, chain)
+	chain = network.addChain(tx, "reply", vpnNetwork)
+	tx.Add("add rule ip6 %s %s ct state related,established accept", nftNATTable, chain)
+
+	// NAT66 forwarding rule, tunnel uses unique local addresses which are not routed in the internet
+	chain = network.addChain(tx, "nat", vpnNetwork)
+	tx.Add("add rule ip6 %s %s ip6 daddr != %s masquerade", nftNATTable, chain, vpnNetwork)
+
+	return network
+}
+
+// addChain adds the commands creating a chain of the network, which is jumped to from the given kind of verdict map.
+func (n *nftNetwork) addChain(tx *nftables.Transaction, kind, vpnNetwork string) string {
+	chain := nftNetworkChain{
+		name:    kind + "_" + n.id,
+		vmap:    kind + "_networks",
+		network: vpnNetwork,
+	}
+	tx.Add("add chain %s %s %s", n.family, nftNATTable, chain.name)
+	tx.Add("add element %s %s %s { %s : jump %s }", n.family, nftNATTable, chain.vmap, vpnNetwork, chain.name)
+	n.chains = append(n.chains, chain)
+	return chain.name
+}
+
+func protectedNetworksOf(ipv6 bool) (networks []string) {
+	for _, ipNet := range protectedNetworks() {
+		if (ipNet.IP.To4() == nil) == ipv6 {
+			networks = append(networks, ipNet.String())
+		}
+	}
+	return networks
+}
+
+// nftNetworkString returns the network without the host bits, which nftables does not accept.
+func nftNetworkString(network net.IPNet) string {
+	network.IP = network.IP.Mask(network.Mask)
+	return network.String()
+}
+
+func nftChainID(network string) string {
+	return strings.NewReplacer(".", "_", ":", "_", "/", "_").Replace(network)
+}
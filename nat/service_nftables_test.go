@@ -0,0 +1,156 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package nat
+
+import (
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/mysteriumnetwork/node/firewall/nftables"
+	"github.com/stretchr/testify/assert"
+)
+
+func newNftablesServiceStub(scripts *[]string) *serviceNftables {
+	nftables.ApplyScript = func(script string) error {
+		*scripts = append(*scripts, script)
+		return nil
+	}
+
+	forward := serviceIPForward{
+		CommandFactory: (&mockCommandFactory{MockCommand: &mockCommand{OutputRes: []byte("1")}}).Create,
+		CommandRead:    []string{"doesnt", "matter"},
+		CommandEnable:  []string{"doesnt", "matter"},
+		CommandDisable: []string{"doesnt", "matter"},
+	}
+	return &serviceNftables{
+		networks:   make(map[string]nftNetwork),
+		ipForward:  forward,
+		ipForward6: forward,
+	}
+}
+
+func Test_serviceNftables_Setup(t *testing.T) {
+	var scripts []string
+	svc := newNftablesServiceStub(&scripts)
+	_, network, _ := net.ParseCIDR("10.182.0.1/24")
+
+	rules, err := svc.Setup(Options{
+		VPNNetwork:        *network,
+		DNSIP:             net.ParseIP("10.182.0.1"),
+		DNSPort:           5353,
+		EnableDNSRedirect: true,
+		ProviderExtIP:     net.ParseIP("1.2.3.4"),
+	})
+	assert.NoError(t, err)
+	assert.Len(t, rules, 1)
+	assert.Len(t, scripts, 1)
+
+	commands := strings.Split(scripts[0], "\n")
+	assert.Contains(t, commands, "add table ip myst_nat")
+	assert.Contains(t, commands, "delete table ip myst_nat")
+	assert.Contains(t, commands, "add rule ip myst_nat postrouting ip saddr vmap @nat_networks")
+	assert.Contains(t, commands, "add element ip myst_nat nat_networks { 10.182.0.0/24 : jump nat_10_182_0_0_24 }")
+	assert.Contains(t, commands, "add rule ip myst_nat nat_10_182_0_0_24 ip daddr != 10.182.0.0/24 snat to 1.2.3.4")
+	assert.Contains(t, commands, "add rule ip myst_nat dns_10_182_0_0_24 ip daddr 10.182.0.1 udp dport 53 redirect to :5353")
+
+	// Tables are created once, the next networks only add their chains.
+	_, network2, _ := net.ParseCIDR("10.183.0.0/24")
+	_, err = svc.Setup(Options{VPNNetwork: *network2, ProviderExtIP: net.ParseIP("1.2.3.4")})
+	assert.NoError(t, err)
+	assert.Len(t, scripts, 2)
+	assert.NotContains(t, scripts[1], "table")
+	assert.NotContains(t, scripts[1], "dns_")
+}
+
+func Test_serviceNftables_SetupIPv6(t *testing.T) {
+	var scripts []string
+	svc := newNftablesServiceStub(&scripts)
+	_, network, _ := net.ParseCIDR("10.182.0.0/24")
+	_, network6, _ := net.ParseCIDR("fd00:182::/64")
+
+	rules, err := svc.Setup(Options{
+		VPNNetwork:    *network,
+		VPNNetwork6:   *network6,
+		ProviderExtIP: net.ParseIP("1.2.3.4"),
+	})
+	assert.NoError(t, err)
+	assert.Len(t, rules, 2)
+	assert.True(t, svc.ipv6)
+
+	commands := strings.Split(scripts[0], "\n")
+	assert.Contains(t, commands, "add element ip6 myst_nat nat_networks { fd00:182::/64 : jump nat_fd00_182___64 }")
+	assert.Contains(t, commands, "add rule ip6 myst_nat nat_fd00_182___64 ip6 daddr != fd00:182::/64 masquerade")
+}
+
+func Test_serviceNftables_SetupIPv6ForwardRules(t *testing.T) {
+	var scripts []string
+	svc := newNftablesServiceStub(&scripts)
+	_, network, _ := net.ParseCIDR("10.182.0.0/24")
+	_, network6, _ := net.ParseCIDR("fd00:182::/64")
+
+	_, err := svc.Setup(Options{
+		VPNNetwork:    *network,
+		VPNNetwork6:   *network6,
+		ProviderExtIP: net.ParseIP("1.2.3.4"),
+	})
+	assert.NoError(t, err)
+
+	commands := strings.Split(scripts[0], "\n")
+	assert.Contains(t, commands, "add rule ip6 myst_nat forward ip6 saddr vmap @forward_networks")
+	assert.Contains(t, commands, "add rule ip6 myst_nat forward ip6 daddr vmap @reply_networks")
+	assert.Contains(t, commands, "add element ip6 myst_nat forward_networks { fd00:182::/64 : jump forward_fd00_182___64 }")
+	assert.Contains(t, commands, "add rule ip6 myst_nat forward_fd00_182___64 accept")
+	assert.Contains(t, commands, "add element ip6 myst_nat reply_networks { fd00:182::/64 : jump reply_fd00_182___64 }")
+	assert.Contains(t, commands, "add rule ip6 myst_nat reply_fd00_182___64 ct state related,established accept")
+}
+
+func Test_serviceNftables_Del(t *testing.T) {
+	var scripts []string
+	svc := newNftablesServiceStub(&scripts)
+	_, network, _ := net.ParseCIDR("10.182.0.0/24")
+
+	rules, err := svc.Setup(Options{VPNNetwork: *network, ProviderExtIP: net.ParseIP("1.2.3.4")})
+	assert.NoError(t, err)
+
+	assert.NoError(t, svc.Del(rules))
+	assert.Len(t, scripts, 2)
+	assert.Equal(t,
+		"delete element ip myst_nat nat_networks { 10.182.0.0/24 }\n"+
+			"flush chain ip myst_nat nat_10_182_0_0_24\n"+
+			"delete chain ip myst_nat nat_10_182_0_0_24\n",
+		scripts[1],
+	)
+	assert.Empty(t, svc.networks)
+
+	// Rules already deleted are skipped.
+	assert.NoError(t, svc.Del(rules))
+	assert.Len(t, scripts, 2)
+}
+
+func Test_serviceNftables_Disable(t *testing.T) {
+	var scripts []string
+	svc := newNftablesServiceStub(&scripts)
+
+	assert.NoError(t, svc.Disable())
+	assert.Equal(t,
+		"add table ip myst_nat\ndelete table ip myst_nat\nadd table ip6 myst_nat\ndelete table ip6 myst_nat\n",
+		scripts[0],
+	)
+	assert.False(t, svc.tables)
+}
@@ -119,6 +119,10 @@ func makePfctlRules(opts Options) (rules []string, err error) {
 		rules = append(rules, rule)
 	}
 
+	if opts.VPNNetwork6.IP != nil {
+		log.Warn().Msg("IPv6 NAT is not supported by pfctl, tunnel IPv6 traffic will not be forwarded")
+	}
+
 	// NAT forwarding rule
 	rule := fmt.Sprintf("nat on %s inet from %s to any -> %s",
 		externalIface,
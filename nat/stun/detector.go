@@ -0,0 +1,326 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package stun
+
+import (
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/mysteriumnetwork/node/eventbus"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+// NATType is the type of NAT the node is behind.
+type NATType string
+
+// NAT types as classified by RFC 3489.
+const (
+	TypeUnknown            NATType = "unknown"
+	TypeNone               NATType = "none"
+	TypeFullCone           NATType = "full_cone"
+	TypeRestrictedCone     NATType = "restricted_cone"
+	TypePortRestrictedCone NATType = "port_restricted_cone"
+	TypeSymmetric          NATType = "symmetric"
+)
+
+// AppTopicNATTypeDetected is the topic NAT type detection results are published on.
+const AppTopicNATTypeDetected = "NATTypeDetected"
+
+// AppEventNATTypeDetected is published when NAT type detection finishes.
+type AppEventNATTypeDetected struct {
+	Type  NATType
+	Error error
+}
+
+// Punchable tells if NAT hole punching can succeed between the peers behind the given NAT types.
+// Symmetric NAT allocates a new port for every destination, so the peer has to accept packets from any port.
+func Punchable(a, b NATType) bool {
+	if a == TypeSymmetric {
+		return b != TypeSymmetric && b != TypePortRestrictedCone
+	}
+	if b == TypeSymmetric {
+		return a != TypePortRestrictedCone
+	}
+	return true
+}
+
+const (
+	probeAttempts = 3
+	probeTimeout  = 700 * time.Millisecond
+)
+
+// response is the result of a single STUN probe.
+type response struct {
+	mappedAddr *net.UDPAddr
+	otherAddr  *net.UDPAddr
+}
+
+type probeFunc func(conn *net.UDPConn, server *net.UDPAddr, change changeRequest) (*response, error)
+
+// networkCheckInterval is how often local addresses are checked to re-detect NAT type after network change.
+const networkCheckInterval = time.Minute
+
+// Detector detects NAT type using STUN probes against the configured servers.
+type Detector struct {
+	servers   []string
+	publisher eventbus.Publisher
+	probe     probeFunc
+	addrs     func() ([]string, error)
+
+	detectMu sync.Mutex
+
+	mu      sync.RWMutex
+	natType NATType
+
+	stopOnce sync.Once
+	stop     chan struct{}
+}
+
+// NewDetector creates NAT type detector using the given STUN servers.
+func NewDetector(servers []string, publisher eventbus.Publisher) *Detector {
+	return &Detector{
+		servers:   servers,
+		publisher: publisher,
+		probe:     probe,
+		addrs:     localIPv4Addrs,
+		natType:   TypeUnknown,
+		stop:      make(chan struct{}),
+	}
+}
+
+// Start runs NAT type detection in the background and runs it again once local addresses change.
+func (d *Detector) Start() {
+	go d.detectLoop()
+}
+
+// Stop stops watching for network changes.
+func (d *Detector) Stop() {
+	d.stopOnce.Do(func() {
+		close(d.stop)
+	})
+}
+
+// NATType returns the last detected NAT type, it is unknown until the detection finishes.
+func (d *Detector) NATType() NATType {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	return d.natType
+}
+
+// Detect runs NAT type detection again and returns the result.
+func (d *Detector) Detect() (NATType, error) {
+	d.detectMu.Lock()
+	defer d.detectMu.Unlock()
+
+	natType, err := d.classify()
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to detect NAT type")
+	} else {
+		log.Info().Msgf("Detected NAT type: %s", natType)
+	}
+
+	d.mu.Lock()
+	d.natType = natType
+	d.mu.Unlock()
+
+	if d.publisher != nil {
+		d.publisher.Publish(AppTopicNATTypeDetected, AppEventNATTypeDetected{Type: natType, Error: err})
+	}
+	return natType, err
+}
+
+func (d *Detector) detectLoop() {
+	addrs, err := d.addrs()
+	if err != nil {
+		log.Warn().Err(err).Msg("Could not get local addresses, NAT type will not be re-detected on network change")
+	}
+	d.Detect()
+
+	for {
+		select {
+		case <-d.stop:
+			return
+		case <-time.After(networkCheckInterval):
+		}
+
+		current, err := d.addrs()
+		if err != nil {
+			log.Warn().Err(err).Msg("Could not get local addresses")
+			continue
+		}
+		if sameAddrs(addrs, current) {
+			continue
+		}
+
+		log.Info().Msgf("Local addresses changed from %v to %v, detecting NAT type again", addrs, current)
+		addrs = current
+		d.Detect()
+	}
+}
+
+// classify runs the tests of RFC 3489 from a single local port:
+// the mapped address is compared with the local one, then server is asked to respond from the other IP and port,
+// the mapping to the other server address is compared with the first one and the server is asked to respond from the other port.
+func (d *Detector) classify() (NATType, error) {
+	conn, err := net.ListenUDP("udp4", nil)
+	if err != nil {
+		return TypeUnknown, errors.Wrap(err, "could not create UDP conn")
+	}
+	defer conn.Close()
+
+	server, res, err := d.firstResponding(conn)
+	if err != nil {
+		return TypeUnknown, err
+	}
+	if isLocalAddr(res.mappedAddr, conn.LocalAddr().(*net.UDPAddr)) {
+		return TypeNone, nil
+	}
+	if res.otherAddr == nil {
+		return TypeUnknown, errors.Errorf("STUN server %s does not support change requests", server)
+	}
+
+	if _, err := d.probe(conn, server, changeIP|changePort); err == nil {
+		return TypeFullCone, nil
+	}
+
+	otherRes, err := d.probe(conn, res.otherAddr, changeNone)
+	if err != nil {
+		return TypeUnknown, errors.Wrap(err, "could not probe the other STUN server address")
+	}
+	if otherRes.mappedAddr.String() != res.mappedAddr.String() {
+		return TypeSymmetric, nil
+	}
+
+	if _, err := d.probe(conn, server, changePort); err == nil {
+		return TypeRestrictedCone, nil
+	}
+	return TypePortRestrictedCone, nil
+}
+
+func (d *Detector) firstResponding(conn *net.UDPConn) (*net.UDPAddr, *response, error) {
+	for _, server := range d.servers {
+		if server == "" {
+			continue
+		}
+		addr, err := net.ResolveUDPAddr("udp4", server)
+		if err != nil {
+			log.Warn().Err(err).Msgf("Could not resolve STUN server %s", server)
+			continue
+		}
+		res, err := d.probe(conn, addr, changeNone)
+		if err != nil {
+			log.Warn().Err(err).Msgf("STUN server %s did not respond", server)
+			continue
+		}
+		return addr, res, nil
+	}
+	return nil, nil, errors.New("no STUN server responded")
+}
+
+// probe sends binding request to the server and waits for the response, retrying on timeout.
+func probe(conn *net.UDPConn, server *net.UDPAddr, change changeRequest) (*response, error) {
+	id, err := newTransactionID()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not generate transaction ID")
+	}
+	req := message{msgType: typeBindingRequest, transactionID: id, change: change}
+
+	buf := make([]byte, 1500)
+	for i := 0; i < probeAttempts; i++ {
+		if _, err := conn.WriteToUDP(req.encode(), server); err != nil {
+			return nil, errors.Wrap(err, "could not send binding request")
+		}
+
+		conn.SetReadDeadline(time.Now().Add(probeTimeout))
+		for {
+			n, _, err := conn.ReadFromUDP(buf)
+			if err != nil {
+				break
+			}
+			res, err := decodeMessage(buf[:n])
+			if err != nil || res.msgType != typeBindingResponse || res.transactionID != id || res.mappedAddr == nil {
+				// Late responses of the previous probes are ignored.
+				continue
+			}
+			return &response{mappedAddr: res.mappedAddr, otherAddr: res.otherAddr}, nil
+		}
+	}
+	return nil, errors.New("binding request timed out")
+}
+
+func isLocalAddr(mapped, local *net.UDPAddr) bool {
+	if mapped.Port != local.Port {
+		return false
+	}
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return false
+	}
+	for _, addr := range addrs {
+		if ipNet, ok := addr.(*net.IPNet); ok && ipNet.IP.Equal(mapped.IP) {
+			return true
+		}
+	}
+	return false
+}
+
+// localIPv4Addrs returns IPv4 addresses of local network interfaces used to detect network changes.
+// Point-to-point interfaces are skipped, these are VPN tunnels (including the WireGuard ones of the node)
+// which do not change the NAT the node is behind.
+func localIPv4Addrs() ([]string, error) {
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return nil, err
+	}
+
+	var res []string
+	for _, iface := range ifaces {
+		if iface.Flags&net.FlagUp == 0 || iface.Flags&net.FlagPointToPoint != 0 {
+			continue
+		}
+		addrs, err := iface.Addrs()
+		if err != nil {
+			return nil, err
+		}
+		for _, addr := range addrs {
+			ipNet, ok := addr.(*net.IPNet)
+			if !ok || ipNet.IP.To4() == nil || ipNet.IP.IsLoopback() || ipNet.IP.IsLinkLocalUnicast() {
+				continue
+			}
+			res = append(res, ipNet.IP.String())
+		}
+	}
+	sort.Strings(res)
+	return res, nil
+}
+
+func sameAddrs(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,200 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package stun
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/mysteriumnetwork/node/mocks"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetector_DetectsNoNATWhenMappedAddressIsLocal(t *testing.T) {
+	bus := mocks.NewEventBus()
+	detector := NewDetector([]string{"127.0.0.1:1", "127.0.0.1:3478"}, bus)
+	detector.probe = func(conn *net.UDPConn, to *net.UDPAddr, _ changeRequest) (*response, error) {
+		if to.Port == 1 {
+			return nil, errors.New("timeout")
+		}
+		local := conn.LocalAddr().(*net.UDPAddr)
+		return &response{mappedAddr: &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: local.Port}}, nil
+	}
+
+	natType, err := detector.Detect()
+	assert.NoError(t, err)
+	assert.Equal(t, TypeNone, natType)
+	assert.Equal(t, TypeNone, detector.NATType())
+	assert.Equal(t, AppEventNATTypeDetected{Type: TypeNone}, bus.Pop())
+}
+
+func TestDetector_StartDetectsInBackground(t *testing.T) {
+	detected := make(chan struct{})
+	detector := NewDetector([]string{"1.1.1.1:3478"}, nil)
+	detector.addrs = func() ([]string, error) {
+		return []string{"192.168.1.2"}, nil
+	}
+	detector.probe = func(_ *net.UDPConn, _ *net.UDPAddr, _ changeRequest) (*response, error) {
+		<-detected
+		return &response{
+			mappedAddr: &net.UDPAddr{IP: net.ParseIP("2.2.2.2"), Port: 1000},
+			otherAddr:  &net.UDPAddr{IP: net.ParseIP("1.1.1.2"), Port: 3479},
+		}, nil
+	}
+
+	detector.Start()
+	defer detector.Stop()
+	assert.Equal(t, TypeUnknown, detector.NATType())
+
+	close(detected)
+	assert.Eventually(t, func() bool {
+		return detector.NATType() == TypeFullCone
+	}, 2*time.Second, 10*time.Millisecond)
+}
+
+func TestDetector_ReturnsUnknownWhenNoServerResponds(t *testing.T) {
+	detector := NewDetector(nil, nil)
+
+	natType, err := detector.Detect()
+	assert.EqualError(t, err, "no STUN server responded")
+	assert.Equal(t, TypeUnknown, natType)
+	assert.Equal(t, TypeUnknown, detector.NATType())
+}
+
+func TestDetector_ClassifiesNATTypes(t *testing.T) {
+	server := &net.UDPAddr{IP: net.ParseIP("1.1.1.1"), Port: 3478}
+	other := &net.UDPAddr{IP: net.ParseIP("1.1.1.2"), Port: 3479}
+	mapped := &net.UDPAddr{IP: net.ParseIP("2.2.2.2"), Port: 1000}
+	mappedOther := &net.UDPAddr{IP: net.ParseIP("2.2.2.2"), Port: 1001}
+
+	tests := []struct {
+		name     string
+		natType  NATType
+		response func(to *net.UDPAddr, change changeRequest) (*response, error)
+	}{
+		{
+			name:    "full cone",
+			natType: TypeFullCone,
+			response: func(to *net.UDPAddr, change changeRequest) (*response, error) {
+				return &response{mappedAddr: mapped, otherAddr: other}, nil
+			},
+		},
+		{
+			name:    "restricted cone",
+			natType: TypeRestrictedCone,
+			response: func(to *net.UDPAddr, change changeRequest) (*response, error) {
+				if change&changeIP != 0 {
+					return nil, errors.New("timeout")
+				}
+				return &response{mappedAddr: mapped, otherAddr: other}, nil
+			},
+		},
+		{
+			name:    "port restricted cone",
+			natType: TypePortRestrictedCone,
+			response: func(to *net.UDPAddr, change changeRequest) (*response, error) {
+				if change != changeNone {
+					return nil, errors.New("timeout")
+				}
+				return &response{mappedAddr: mapped, otherAddr: other}, nil
+			},
+		},
+		{
+			name:    "symmetric",
+			natType: TypeSymmetric,
+			response: func(to *net.UDPAddr, change changeRequest) (*response, error) {
+				if change != changeNone {
+					return nil, errors.New("timeout")
+				}
+				if to == other {
+					return &response{mappedAddr: mappedOther, otherAddr: server}, nil
+				}
+				return &response{mappedAddr: mapped, otherAddr: other}, nil
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			detector := NewDetector([]string{server.String()}, nil)
+			detector.probe = func(_ *net.UDPConn, to *net.UDPAddr, change changeRequest) (*response, error) {
+				return test.response(to, change)
+			}
+
+			natType, err := detector.Detect()
+			assert.NoError(t, err)
+			assert.Equal(t, test.natType, natType)
+			assert.Equal(t, test.natType, detector.NATType())
+		})
+	}
+}
+
+func TestServer_RespondsFromChangedAddress(t *testing.T) {
+	server := NewServer("127.0.0.1", "127.0.0.2")
+	if err := server.Start(); err != nil {
+		// Only some systems route the whole 127.0.0.0/8 to the loopback interface.
+		t.Skipf("Secondary loopback address is not available: %v", err)
+	}
+	defer server.Stop()
+
+	conn, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.ParseIP("127.0.0.1")})
+	require.NoError(t, err)
+	defer conn.Close()
+
+	res, err := probe(conn, server.Addr(), changeNone)
+	require.NoError(t, err)
+	assert.Equal(t, conn.LocalAddr().String(), res.mappedAddr.String())
+	assert.Equal(t, "127.0.0.2", res.otherAddr.IP.String())
+	assert.NotEqual(t, server.Addr().Port, res.otherAddr.Port)
+
+	// Responses from the other address are accepted since the conn is not connected.
+	_, err = probe(conn, server.Addr(), changeIP|changePort)
+	assert.NoError(t, err)
+}
+
+func TestPunchable(t *testing.T) {
+	assert.True(t, Punchable(TypeFullCone, TypeSymmetric))
+	assert.True(t, Punchable(TypeRestrictedCone, TypeSymmetric))
+	assert.True(t, Punchable(TypePortRestrictedCone, TypePortRestrictedCone))
+	assert.True(t, Punchable(TypeUnknown, TypeSymmetric))
+	assert.False(t, Punchable(TypeSymmetric, TypeSymmetric))
+	assert.False(t, Punchable(TypeSymmetric, TypePortRestrictedCone))
+	assert.False(t, Punchable(TypePortRestrictedCone, TypeSymmetric))
+}
+
+func TestMessage_EncodeDecode(t *testing.T) {
+	id, err := newTransactionID()
+	require.NoError(t, err)
+	msg := message{
+		msgType:       typeBindingResponse,
+		transactionID: id,
+		change:        changeIP,
+		mappedAddr:    &net.UDPAddr{IP: net.ParseIP("1.2.3.4").To4(), Port: 1234},
+		otherAddr:     &net.UDPAddr{IP: net.ParseIP("5.6.7.8").To4(), Port: 5678},
+	}
+
+	decoded, err := decodeMessage(msg.encode())
+	require.NoError(t, err)
+	assert.Equal(t, msg, decoded)
+
+	_, err = decodeMessage([]byte("not a STUN message at all"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,191 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package stun
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"net"
+
+	"github.com/pkg/errors"
+)
+
+// STUN message types and attributes used for the NAT type detection, see RFC 5389 and RFC 5780.
+const (
+	headerSize  = 20
+	magicCookie = 0x2112A442
+
+	typeBindingRequest  = 0x0001
+	typeBindingResponse = 0x0101
+
+	attrMappedAddress    = 0x0001
+	attrChangeRequest    = 0x0003
+	attrChangedAddress   = 0x0005
+	attrXORMappedAddress = 0x0020
+	attrOtherAddress     = 0x802c
+
+	familyIPv4 = 0x01
+)
+
+// changeRequest asks server to respond from the other IP and/or port.
+type changeRequest uint32
+
+const (
+	changeNone changeRequest = 0
+	changePort changeRequest = 0x02
+	changeIP   changeRequest = 0x04
+)
+
+type transactionID [12]byte
+
+func newTransactionID() (id transactionID, err error) {
+	_, err = rand.Read(id[:])
+	return id, err
+}
+
+// message is STUN binding request or response with the attributes used by the detector.
+type message struct {
+	msgType       uint16
+	transactionID transactionID
+	change        changeRequest
+	mappedAddr    *net.UDPAddr
+	otherAddr     *net.UDPAddr
+}
+
+func (m message) encode() []byte {
+	var attrs bytes.Buffer
+	if m.change != changeNone {
+		writeAttr(&attrs, attrChangeRequest, uint32ToBytes(uint32(m.change)))
+	}
+	if m.mappedAddr != nil {
+		writeAttr(&attrs, attrMappedAddress, encodeAddr(m.mappedAddr, nil))
+		writeAttr(&attrs, attrXORMappedAddress, encodeAddr(m.mappedAddr, &m.transactionID))
+	}
+	if m.otherAddr != nil {
+		writeAttr(&attrs, attrOtherAddress, encodeAddr(m.otherAddr, nil))
+	}
+
+	b := make([]byte, headerSize, headerSize+attrs.Len())
+	binary.BigEndian.PutUint16(b[0:2], m.msgType)
+	binary.BigEndian.PutUint16(b[2:4], uint16(attrs.Len()))
+	binary.BigEndian.PutUint32(b[4:8], magicCookie)
+	copy(b[8:20], m.transactionID[:])
+	return append(b, attrs.Bytes()...)
+}
+
+func decodeMessage(b []byte) (message, error) {
+	var m message
+	if len(b) < headerSize {
+		return m, errors.New("STUN message is too short")
+	}
+	if binary.BigEndian.Uint32(b[4:8]) != magicCookie {
+		return m, errors.New("not a STUN message")
+	}
+	m.msgType = binary.BigEndian.Uint16(b[0:2])
+	copy(m.transactionID[:], b[8:20])
+
+	length := int(binary.BigEndian.Uint16(b[2:4]))
+	if len(b) < headerSize+length {
+		return m, errors.New("STUN message is truncated")
+	}
+	attrs := b[headerSize : headerSize+length]
+	for len(attrs) >= 4 {
+		attrType := binary.BigEndian.Uint16(attrs[0:2])
+		attrLen := int(binary.BigEndian.Uint16(attrs[2:4]))
+		if len(attrs) < 4+attrLen {
+			return m, errors.New("STUN attribute is truncated")
+		}
+		value := attrs[4 : 4+attrLen]
+
+		switch attrType {
+		case attrChangeRequest:
+			if attrLen == 4 {
+				m.change = changeRequest(binary.BigEndian.Uint32(value))
+			}
+		case attrXORMappedAddress:
+			m.mappedAddr = decodeAddr(value, &m.transactionID)
+		case attrMappedAddress:
+			if m.mappedAddr == nil {
+				m.mappedAddr = decodeAddr(value, nil)
+			}
+		case attrOtherAddress, attrChangedAddress:
+			m.otherAddr = decodeAddr(value, nil)
+		}
+
+		// Attributes are padded to 4 bytes.
+		next := 4 + (attrLen+3)/4*4
+		if next > len(attrs) {
+			break
+		}
+		attrs = attrs[next:]
+	}
+	return m, nil
+}
+
+func writeAttr(buf *bytes.Buffer, attrType uint16, value []byte) {
+	header := make([]byte, 4)
+	binary.BigEndian.PutUint16(header[0:2], attrType)
+	binary.BigEndian.PutUint16(header[2:4], uint16(len(value)))
+	buf.Write(header)
+	buf.Write(value)
+	buf.Write(make([]byte, (4-len(value)%4)%4))
+}
+
+// encodeAddr encodes IPv4 address attribute, XOR-ed with the magic cookie if transaction ID is given.
+func encodeAddr(addr *net.UDPAddr, xor *transactionID) []byte {
+	b := make([]byte, 8)
+	b[1] = familyIPv4
+	port := uint16(addr.Port)
+	ip := addr.IP.To4()
+	if xor != nil {
+		port ^= magicCookie >> 16
+		ip = xorIP(ip)
+	}
+	binary.BigEndian.PutUint16(b[2:4], port)
+	copy(b[4:8], ip)
+	return b
+}
+
+func decodeAddr(b []byte, xor *transactionID) *net.UDPAddr {
+	if len(b) < 8 || b[1] != familyIPv4 {
+		return nil
+	}
+	port := binary.BigEndian.Uint16(b[2:4])
+	ip := net.IP(append([]byte{}, b[4:8]...))
+	if xor != nil {
+		port ^= magicCookie >> 16
+		ip = xorIP(ip)
+	}
+	return &net.UDPAddr{IP: ip, Port: int(port)}
+}
+
+func xorIP(ip net.IP) net.IP {
+	cookie := uint32ToBytes(magicCookie)
+	res := make(net.IP, net.IPv4len)
+	for i := range res {
+		res[i] = ip[i] ^ cookie[i]
+	}
+	return res
+}
+
+func uint32ToBytes(v uint32) []byte {
+	b := make([]byte, 4)
+	binary.BigEndian.PutUint32(b, v)
+	return b
+}
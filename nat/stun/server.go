@@ -0,0 +1,121 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package stun
+
+import (
+	"net"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+// Server is a minimal STUN server answering binding requests, including the ones asking to respond
+// from the other IP and port. It listens on two IPs and two ports, it is used as a local stand-in of public servers.
+type Server struct {
+	ips   [2]string
+	conns [2][2]*net.UDPConn
+	wg    sync.WaitGroup
+	once  sync.Once
+}
+
+// NewServer creates new STUN server listening on the given primary and secondary IPs.
+func NewServer(primaryIP, secondaryIP string) *Server {
+	return &Server{ips: [2]string{primaryIP, secondaryIP}}
+}
+
+// Start starts listening, the ports are chosen by the system.
+func (s *Server) Start() error {
+	ports := [2]int{}
+	for i, ip := range s.ips {
+		for j := range ports {
+			conn, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.ParseIP(ip), Port: ports[j]})
+			if err != nil {
+				s.Stop()
+				return errors.Wrapf(err, "could not listen on %s", ip)
+			}
+			// The other IP uses the same ports as the primary one.
+			ports[j] = conn.LocalAddr().(*net.UDPAddr).Port
+			s.conns[i][j] = conn
+		}
+	}
+
+	for i := range s.conns {
+		for j := range s.conns[i] {
+			s.wg.Add(1)
+			go s.serve(i, j)
+		}
+	}
+	return nil
+}
+
+// Addr returns the primary server address.
+func (s *Server) Addr() *net.UDPAddr {
+	return s.conns[0][0].LocalAddr().(*net.UDPAddr)
+}
+
+// Stop stops the server.
+func (s *Server) Stop() {
+	s.once.Do(func() {
+		for i := range s.conns {
+			for j := range s.conns[i] {
+				if s.conns[i][j] != nil {
+					s.conns[i][j].Close()
+				}
+			}
+		}
+		s.wg.Wait()
+	})
+}
+
+func (s *Server) serve(ipIdx, portIdx int) {
+	defer s.wg.Done()
+
+	conn := s.conns[ipIdx][portIdx]
+	buf := make([]byte, 1500)
+	for {
+		n, addr, err := conn.ReadFromUDP(buf)
+		if err != nil {
+			return
+		}
+
+		req, err := decodeMessage(buf[:n])
+		if err != nil || req.msgType != typeBindingRequest {
+			continue
+		}
+
+		fromIP, fromPort := ipIdx, portIdx
+		if req.change&changeIP != 0 {
+			fromIP = 1 - ipIdx
+		}
+		if req.change&changePort != 0 {
+			fromPort = 1 - portIdx
+		}
+		from := s.conns[fromIP][fromPort]
+
+		res := message{
+			msgType:       typeBindingResponse,
+			transactionID: req.transactionID,
+			mappedAddr:    addr,
+			otherAddr:     s.conns[1-ipIdx][1-portIdx].LocalAddr().(*net.UDPAddr),
+		}
+		if _, err := from.WriteToUDP(res.encode(), addr); err != nil {
+			log.Debug().Err(err).Msg("STUN server failed to send response")
+		}
+	}
+}
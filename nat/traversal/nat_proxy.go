@@ -21,8 +21,6 @@ import (
 	"fmt"
 	"io"
 	"net"
-
-	"github.com/rs/zerolog/log"
 )
 
 const bufferLen = 2048 * 1024
@@ -30,7 +30,6 @@ import (
 	"github.com/mysteriumnetwork/node/core/port"
 	"github.com/mysteriumnetwork/node/eventbus"
 	"github.com/mysteriumnetwork/node/nat/event"
-	"github.com/rs/zerolog/log"
 	"golang.org/x/net/ipv4"
 )
 
@@ -28,7 +28,6 @@ import (
 	"strings"
 	"sync"
 
-	"github.com/rs/zerolog/log"
 	"github.com/xtaci/kcp-go/v5"
 	"golang.org/x/crypto/nacl/box"
 )
@@ -53,12 +52,21 @@ const (
 type ChannelSender interface {
 	// Send sends message to given topic. Peer listening to topic will receive message.
 	Send(ctx context.Context, topic string, msg *Message) (*Message, error)
+
+	// Publish sends message to given topic without waiting for peer reply.
+	Publish(ctx context.Context, topic string, msg *Message) error
+
+	// OpenStream opens bidirectional byte stream to given topic.
+	OpenStream(ctx context.Context, topic string) (Stream, error)
 }
 
 // ChannelHandler is used to handle messages.
 type ChannelHandler interface {
 	// Handle registers handler for given topic which handles peer request.
 	Handle(topic string, handler HandlerFunc)
+
+	// HandleStream registers handler for given topic which handles streams opened by peer.
+	HandleStream(topic string, handler StreamHandlerFunc)
 }
 
 // Channel represents p2p communication channel which can send and receive messages over encrypted and reliable UDP transport.
@@ -72,6 +80,9 @@ type Channel interface {
 	// Conn returns underlying channel's UDP connection.
 	Conn() *net.UDPConn
 
+	// Stats returns channel transport statistics.
+	Stats() ChannelStats
+
 	// Close closes p2p communication channel.
 	Close() error
 }
@@ -147,6 +158,13 @@ type channel struct {
 	streams      map[uint64]*stream
 	nextStreamID uint64
 
+	// streamHandlers are responsible for handling byte streams opened by peer.
+	streamHandlers map[string]StreamHandlerFunc
+
+	// byteStreams holds opened byte streams, both local and opened by peer.
+	byteStreams      map[streamKey]*byteStream
+	nextByteStreamID uint64
+
 	// privateKey is channel's private key. For now it's here just to be able to recreate the same channel for unit tests.
 	privateKey PrivateKey
 
@@ -163,6 +181,12 @@ type channel struct {
 	// upnpPortsRelease should be called to close mapped upnp ports when channel is closed.
 	upnpPortsRelease []func()
 
+	// relayRuleRemove should be called to remove the relay firewall rule when channel is closed.
+	relayRuleRemove func()
+
+	// stats collects channel transport statistics.
+	stats *channelStats
+
 	// stop is used to stop all running goroutines.
 	stop chan struct{}
 }
@@ -184,7 +208,8 @@ func newChannel(remoteConn *net.UDPConn, privateKey PrivateKey, peerPubKey Publi
 	}
 
 	// Setup KCP session. It will write to proxy conn only.
-	udpSession, sessAddr, err := listenUDPSession(proxyConn.LocalAddr(), privateKey, peerPubKey)
+	stats := newChannelStats()
+	udpSession, sessAddr, err := listenUDPSession(proxyConn.LocalAddr(), privateKey, peerPubKey, stats)
 	if err != nil {
 		return nil, fmt.Errorf("could not create KCP UDP session: %w", err)
 	}
@@ -208,12 +233,15 @@ func newChannel(remoteConn *net.UDPConn, privateKey PrivateKey, peerPubKey Publi
 		tr:               &tr,
 		topicHandlers:    make(map[string]HandlerFunc),
 		streams:          make(map[uint64]*stream),
+		streamHandlers:   make(map[string]StreamHandlerFunc),
+		byteStreams:      make(map[streamKey]*byteStream),
 		privateKey:       privateKey,
 		peer:             &peer,
 		localSessionAddr: sessAddr,
 		serviceConn:      nil,
 		stop:             make(chan struct{}, 1),
 		sendQueue:        make(chan *transportMsg, 100),
+		stats:            stats,
 	}
 
 	go c.remoteReadLoop()
@@ -250,10 +278,12 @@ func (c *channel) remoteReadLoop() {
 				if addr.Port != latestPeerAddr.Port {
 					log.Debug().Msgf("Peer port changed from %d to %d", latestPeerAddr.Port, addr.Port)
 					c.peer.updateAddr(addr)
+					c.stats.peerAddrChanged()
 					latestPeerAddr = addr
 				}
 			}
 		}
+		c.stats.addReceived(n)
 
 		_, err = c.tr.proxyConn.WriteToUDP(buf[:n], c.localSessionAddr)
 		if err != nil {
@@ -291,6 +321,7 @@ func (c *channel) remoteSendLoop() {
 			}
 			return
 		}
+		c.stats.addSent(n)
 	}
 }
 
@@ -315,11 +346,16 @@ func (c *channel) localReadLoop() {
 			fmt.Printf("recv from %s: %+v\n", c.tr.session.RemoteAddr(), msg)
 		}
 
-		// If message contains topic it means that peer is making a request
-		// and waits for response.
-		if msg.topic != "" {
+		switch {
+		case msg.msgType == msgTypePublish:
+			go c.handlePublish(&msg)
+		case msg.msgType != msgTypeRequestReply:
+			c.handleStreamMsg(&msg)
+		case msg.topic != "":
+			// If message contains topic it means that peer is making a request
+			// and waits for response.
 			go c.handleRequest(&msg)
-		} else {
+		default:
 			// In other case we treat it as a reply for peer to our request.
 			go c.handleReply(&msg)
 		}
@@ -398,6 +434,25 @@ func (c *channel) handleRequest(msg *transportMsg) {
 	c.sendQueue <- &resMsg
 }
 
+// handlePublish handles incoming published message, handler reply is not sent to peer.
+func (c *channel) handlePublish(msg *transportMsg) {
+	c.mu.RLock()
+	handler, ok := c.topicHandlers[msg.topic]
+	c.mu.RUnlock()
+
+	if !ok {
+		log.Error().Msgf("Handler %q not found for published message", msg.topic)
+		return
+	}
+
+	ctx := defaultContext{req: &Message{Data: msg.data}}
+	if err := handler(&ctx); err != nil {
+		log.Err(err).Msgf("Handler %q internal error", msg.topic)
+	} else if ctx.publicError != nil {
+		log.Err(ctx.publicError).Msgf("Handler %q public error", msg.topic)
+	}
+}
+
 // ServiceConn returns UDP connection which can be used for services.
 func (c *channel) ServiceConn() *net.UDPConn {
 	return c.serviceConn
@@ -406,14 +461,19 @@ func (c *channel) ServiceConn() *net.UDPConn {
 // Close closes channel.
 func (c *channel) Close() error {
 	c.mu.Lock()
-	defer c.mu.Unlock()
-
 	var closeErr error
+	var byteStreams []*byteStream
 	c.once.Do(func() {
 		close(c.stop)
+		for _, s := range c.byteStreams {
+			byteStreams = append(byteStreams, s)
+		}
 		for _, release := range c.upnpPortsRelease {
 			release()
 		}
+		if c.relayRuleRemove != nil {
+			c.relayRuleRemove()
+		}
 
 		if err := c.tr.remoteConn.Close(); err != nil {
 			closeErr = fmt.Errorf("could not close remote conn: %w", err)
@@ -427,7 +487,11 @@ func (c *channel) Close() error {
 			closeErr = fmt.Errorf("could not close p2p transport session: %w", err)
 		}
 	})
+	c.mu.Unlock()
 
+	for _, s := range byteStreams {
+		s.remoteClose()
+	}
 	return closeErr
 }
 
@@ -436,6 +500,11 @@ func (c *channel) Conn() *net.UDPConn {
 	return c.tr.remoteConn
 }
 
+// Stats returns channel transport statistics.
+func (c *channel) Stats() ChannelStats {
+	return c.stats.snapshot()
+}
+
 // Send sends message to given topic. Peer listening to topic will receive message.
 func (c *channel) Send(ctx context.Context, topic string, msg *Message) (*Message, error) {
 	reply, err := c.sendRequest(ctx, topic, msg)
@@ -445,6 +514,18 @@ func (c *channel) Send(ctx context.Context, topic string, msg *Message) (*Messag
 	return reply, nil
 }
 
+// Publish sends message to given topic without waiting for peer reply.
+func (c *channel) Publish(ctx context.Context, topic string, msg *Message) error {
+	select {
+	case <-ctx.Done():
+		return fmt.Errorf("timeout publishing to %q: %w", topic, ErrSendTimeout)
+	case <-c.stop:
+		return ErrStreamClosed
+	case c.sendQueue <- &transportMsg{msgType: msgTypePublish, topic: topic, data: msg.Data}:
+		return nil
+	}
+}
+
 // Handle registers handler for given topic which handles peer request.
 func (c *channel) Handle(topic string, handler HandlerFunc) {
 	c.mu.Lock()
@@ -511,6 +592,13 @@ func (c *channel) setUpnpPortsRelease(release []func()) {
 	c.upnpPortsRelease = release
 }
 
+func (c *channel) setRelayRuleRemove(remove func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.relayRuleRemove = remove
+}
+
 func reopenConn(conn *net.UDPConn) (*net.UDPConn, error) {
 	// conn first must be closed to prevent use of WriteTo with pre-connected connection error.
 	conn.Close()
@@ -521,12 +609,13 @@ func reopenConn(conn *net.UDPConn) (*net.UDPConn, error) {
 	return conn, nil
 }
 
-func listenUDPSession(proxyAddr net.Addr, privateKey PrivateKey, peerPubKey PublicKey) (sess *kcp.UDPSession, localAddr *net.UDPAddr, err error) {
+func listenUDPSession(proxyAddr net.Addr, privateKey PrivateKey, peerPubKey PublicKey, stats *channelStats) (sess *kcp.UDPSession, localAddr *net.UDPAddr, err error) {
 	blockCrypt, err := newBlockCrypt(privateKey, peerPubKey)
 	if err != nil {
 		err = fmt.Errorf("could not create block crypt: %w", err)
 		return
 	}
+	blockCrypt = &statsBlockCrypt{BlockCrypt: blockCrypt, stats: stats}
 	localConn, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.ParseIP("127.0.0.1")})
 	if err != nil {
 		err = fmt.Errorf("could not create UDP conn: %w", err)
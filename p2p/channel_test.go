@@ -19,7 +19,11 @@ package p2p
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/base64"
 	"errors"
+	"io"
+	"io/ioutil"
 	"net"
 	"sync"
 	"testing"
@@ -209,6 +213,152 @@ func TestChannel_Send_Timeout(t *testing.T) {
 	})
 }
 
+func TestChannel_Publish(t *testing.T) {
+	provider, consumer, err := createTestChannels()
+	require.NoError(t, err)
+	defer provider.Close()
+	defer consumer.Close()
+
+	received := make(chan string, 1)
+	provider.Handle("publish", func(c Context) error {
+		received <- string(c.Request().Data)
+		return c.OkWithReply(&Message{Data: []byte("ignored")})
+	})
+
+	err = consumer.Publish(context.Background(), "publish", &Message{Data: []byte("hello")})
+	require.NoError(t, err)
+
+	select {
+	case v := <-received:
+		assert.Equal(t, "hello", v)
+	case <-time.After(time.Second):
+		t.Fatal("did not receive published message")
+	}
+}
+
+func TestChannel_Stream(t *testing.T) {
+	provider, consumer, err := createTestChannels()
+	require.NoError(t, err)
+	defer provider.Close()
+	defer consumer.Close()
+
+	// Data larger than stream window checks that writer waits for reader.
+	data := make([]byte, streamWindowSize+123)
+	_, err = rand.Read(data)
+	require.NoError(t, err)
+
+	t.Run("Test upload to peer", func(t *testing.T) {
+		received := make(chan []byte, 1)
+		provider.HandleStream("upload", func(s Stream) {
+			defer s.Close()
+			b, err := ioutil.ReadAll(s)
+			assert.NoError(t, err)
+			received <- b
+		})
+
+		s, err := consumer.OpenStream(context.Background(), "upload")
+		require.NoError(t, err)
+		assert.Equal(t, "upload", s.Topic())
+		n, err := s.Write(data)
+		require.NoError(t, err)
+		assert.Equal(t, len(data), n)
+		require.NoError(t, s.Close())
+
+		select {
+		case b := <-received:
+			assert.Equal(t, data, b)
+		case <-time.After(5 * time.Second):
+			t.Fatal("did not receive stream data")
+		}
+	})
+
+	t.Run("Test download from peer", func(t *testing.T) {
+		provider.HandleStream("download", func(s Stream) {
+			defer s.Close()
+			_, err := s.Write(data)
+			assert.NoError(t, err)
+		})
+
+		s, err := consumer.OpenStream(context.Background(), "download")
+		require.NoError(t, err)
+		defer s.Close()
+		b, err := ioutil.ReadAll(s)
+		require.NoError(t, err)
+		assert.Equal(t, data, b)
+
+		_, err = s.Write([]byte("late"))
+		assert.True(t, errors.Is(err, ErrStreamClosed))
+	})
+
+	t.Run("Test stream handler not found", func(t *testing.T) {
+		_, err := consumer.OpenStream(context.Background(), "unknown")
+		assert.True(t, errors.Is(err, ErrHandlerNotFound))
+	})
+
+	t.Run("Test stream is closed with channel", func(t *testing.T) {
+		opened := make(chan struct{})
+		provider.HandleStream("idle", func(s Stream) {
+			close(opened)
+		})
+
+		s, err := consumer.OpenStream(context.Background(), "idle")
+		require.NoError(t, err)
+		<-opened
+		consumer.Close()
+
+		_, err = s.Read(make([]byte, 1))
+		assert.Equal(t, io.EOF, err)
+	})
+}
+
+func TestByteStream_ClosesWhenPeerExceedsWindow(t *testing.T) {
+	ch := &channel{
+		stop:        make(chan struct{}),
+		sendQueue:   make(chan *transportMsg, 1),
+		byteStreams: make(map[streamKey]*byteStream),
+	}
+	s := newByteStream(ch, streamKey{id: 1}, "overflow")
+	ch.byteStreams[s.key] = s
+
+	data := &transportMsg{id: 1, msgType: msgTypeStreamData}
+	data.data = []byte(base64.StdEncoding.EncodeToString(make([]byte, streamWindowSize)))
+	s.handleMsg(data)
+	data.data = []byte(base64.StdEncoding.EncodeToString([]byte{1}))
+	s.handleMsg(data)
+
+	select {
+	case msg := <-ch.sendQueue:
+		assert.Equal(t, uint64(msgTypeStreamClose), msg.msgType)
+	case <-time.After(time.Second):
+		t.Fatal("stream was not closed")
+	}
+	_, err := s.Read(make([]byte, 1))
+	assert.Equal(t, ErrStreamClosed, err)
+}
+
+func TestByteStream_DropsRepliesForOpenedStream(t *testing.T) {
+	ch := &channel{
+		stop:        make(chan struct{}),
+		byteStreams: make(map[streamKey]*byteStream),
+	}
+	s := newByteStream(ch, streamKey{id: 1, local: true}, "reply")
+
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 3; i++ {
+			s.handleMsg(&transportMsg{id: 1, msgType: msgTypeStreamOpenReply, statusCode: statusCodeOK})
+		}
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("duplicate open replies blocked the stream")
+	}
+	assert.Len(t, s.openCh, 1)
+}
+
 func TestChannel_Send_To_When_Peer_Starts_Later(t *testing.T) {
 	provider, consumer, err := createTestChannels()
 	require.NoError(t, err)
@@ -26,6 +26,7 @@ import (
 	"github.com/mysteriumnetwork/node/communication/nats"
 	"github.com/mysteriumnetwork/node/core/port"
 	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/nat/stun"
 	"github.com/mysteriumnetwork/node/pb"
 
 	"google.golang.org/protobuf/proto"
@@ -46,6 +47,10 @@ type natConsumerPinger interface {
 	PingProviderPeer(ctx context.Context, ip string, localPorts, remotePorts []int, initialTTL int, n int) (conns []*net.UDPConn, err error)
 }
 
+type natTypeProvider interface {
+	NATType() stun.NATType
+}
+
 type natProviderPinger interface {
 	PingConsumerPeer(ctx context.Context, ip string, localPorts, remotePorts []int, initialTTL int, n int) (conns []*net.UDPConn, err error)
 }
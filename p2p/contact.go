@@ -35,9 +35,12 @@ const (
 	ContactTypeV1 = "nats/p2p/v1"
 )
 
-// ContactDefinition represents p2p contact which contains NATS broker addresses for connection.
+// ContactDefinition represents p2p contact which contains NATS broker addresses for connection,
+// relay addresses used when NAT hole punching fails and provider NAT type to predict if punching can succeed.
 type ContactDefinition struct {
 	BrokerAddresses []string `json:"broker_addresses"`
+	RelayAddresses  []string `json:"relay_addresses,omitempty"`
+	NATType         string   `json:"nat_type,omitempty"`
 }
 
 // ParseContact tries to parse p2p contact from given contacts list.
@@ -31,9 +31,9 @@ import (
 	"github.com/mysteriumnetwork/node/core/ip"
 	"github.com/mysteriumnetwork/node/core/port"
 	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/nat/stun"
 	"github.com/mysteriumnetwork/node/pb"
 
-	"github.com/rs/zerolog/log"
 	"google.golang.org/protobuf/proto"
 )
 
@@ -45,8 +45,10 @@ type Dialer interface {
 }
 
 // NewDialer creates new p2p communication dialer which is used on consumer side.
-func NewDialer(broker brokerConnector, signer identity.SignerFactory, verifier identity.Verifier, ipResolver ip.Resolver, consumerPinger natConsumerPinger, portPool port.ServicePortSupplier) Dialer {
+func NewDialer(broker brokerConnector, signer identity.SignerFactory, verifier identity.Verifier, ipResolver ip.Resolver, consumerPinger natConsumerPinger, portPool port.ServicePortSupplier, relayConfig RelayConfig, natType natTypeProvider) Dialer {
 	return &dialer{
+		natType:        natType,
+		relayConfig:    relayConfig,
 		broker:         broker,
 		ipResolver:     ipResolver,
 		signer:         signer,
@@ -64,6 +66,8 @@ type dialer struct {
 	signer         identity.SignerFactory
 	verifier       identity.Verifier
 	ipResolver     ip.Resolver
+	relayConfig    RelayConfig
+	natType        natTypeProvider
 }
 
 // Dial exchanges p2p configuration via broker, performs NAT pinging if needed
@@ -95,6 +99,7 @@ func (m *dialer) Dial(ctx context.Context, consumerID, providerID identity.Ident
 	}
 
 	var conn1, conn2 *net.UDPConn
+	var removeRelayRule firewall.OutgoingRuleRemove
 	if len(config.peerPorts) == requiredConnCount {
 		log.Debug().Msg("Skipping provider ping")
 		conn1, err = net.DialUDP("udp4", &net.UDPAddr{Port: config.localPorts[0]}, &net.UDPAddr{IP: net.ParseIP(config.peerIP()), Port: config.peerPorts[0]})
@@ -106,10 +111,16 @@ func (m *dialer) Dial(ctx context.Context, consumerID, providerID identity.Ident
 			return nil, fmt.Errorf("could not create UDP conn for service: %w", err)
 		}
 	} else {
-		log.Debug().Msgf("Pinging provider %s with IP %s using ports %v:%v", providerID.Address, config.peerIP(), config.localPorts, config.peerPorts)
-		conns, err := m.consumerPinger.PingProviderPeer(ctx, config.peerIP(), config.localPorts, config.peerPorts, consumerInitialTTL, requiredConnCount)
+		conns, err := m.pingProvider(ctx, providerID, config, stun.NATType(contactDef.NATType))
 		if err != nil {
-			return nil, fmt.Errorf("could not ping peer: %w", err)
+			if len(contactDef.RelayAddresses) == 0 {
+				return nil, fmt.Errorf("could not ping peer: %w", err)
+			}
+			log.Warn().Err(err).Msgf("Could not ping provider %s, falling back to relay", providerID.Address)
+			conns, removeRelayRule, err = dialRelay(ctx, m.portPool, contactDef.RelayAddresses, config.privateKey, config.peerPubKey)
+			if err != nil {
+				return nil, fmt.Errorf("could not connect peer via relay: %w", err)
+			}
 		}
 		conn1 = conns[0]
 		conn2 = conns[1]
@@ -120,17 +131,38 @@ func (m *dialer) Dial(ctx context.Context, consumerID, providerID identity.Ident
 	case <-peerReady:
 		log.Debug().Msg("Received handlers ready message from provider")
 	case <-ctx.Done():
+		if removeRelayRule != nil {
+			removeRelayRule()
+		}
 		return nil, errors.New("timeout while performing configuration exchange")
 	}
 
 	channel, err := newChannel(conn1, config.privateKey, config.peerPubKey)
 	if err != nil {
+		if removeRelayRule != nil {
+			removeRelayRule()
+		}
 		return nil, fmt.Errorf("could not create p2p channel: %w", err)
 	}
 	channel.setServiceConn(conn2)
+	if removeRelayRule != nil {
+		channel.setRelayRuleRemove(removeRelayRule)
+	}
 	return channel, nil
 }
 
+func (m *dialer) pingProvider(ctx context.Context, providerID identity.Identity, config *p2pConnectConfig, providerNATType stun.NATType) ([]*net.UDPConn, error) {
+	if m.relayConfig.Force {
+		return nil, errRelayForced
+	}
+	if natType := m.natType.NATType(); !stun.Punchable(natType, providerNATType) {
+		return nil, fmt.Errorf("NAT hole punching skipped, consumer NAT %s and provider NAT %s are not traversable", natType, providerNATType)
+	}
+
+	log.Debug().Msgf("Pinging provider %s with IP %s using ports %v:%v", providerID.Address, config.peerIP(), config.localPorts, config.peerPorts)
+	return m.consumerPinger.PingProviderPeer(ctx, config.peerIP(), config.localPorts, config.peerPorts, consumerInitialTTL, requiredConnCount)
+}
+
 func (m *dialer) exchangeConfig(ctx context.Context, brokerConn nats.Connection, providerID identity.Identity, serviceType string, consumerID identity.Identity) (*p2pConnectConfig, error) {
 	pubKey, privateKey, err := GenerateKey()
 	if err != nil {
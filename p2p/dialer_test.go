@@ -19,6 +19,7 @@ package p2p
 
 import (
 	"context"
+	"errors"
 	"io/ioutil"
 	"net"
 	"os"
@@ -29,6 +30,8 @@ import (
 	"github.com/mysteriumnetwork/node/core/ip"
 	"github.com/mysteriumnetwork/node/core/port"
 	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/nat/stun"
+	"github.com/mysteriumnetwork/node/p2p/relay"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -51,7 +54,7 @@ func TestDialer_Exchange_And_Communication_When_Provider_With_PublicIP(t *testin
 	ipResolver := ip.NewResolverMock("127.0.0.1")
 
 	t.Run("Test provider listens to peer", func(t *testing.T) {
-		channelListener := NewListener(brokerConn, signerFactory, verifier, ipResolver, providerPinger, portPool, mockPortMapper)
+		channelListener := NewListener(brokerConn, signerFactory, verifier, ipResolver, providerPinger, portPool, mockPortMapper, RelayConfig{}, &mockNATType{})
 		err := channelListener.Listen(providerID, "wireguard", func(ch Channel) {
 			ch.Handle("test", func(c Context) error {
 				return c.OkWithReply(&Message{Data: []byte("pong")})
@@ -61,7 +64,7 @@ func TestDialer_Exchange_And_Communication_When_Provider_With_PublicIP(t *testin
 	})
 
 	t.Run("Test consumer dialer creates new ready to use channel", func(t *testing.T) {
-		channelDialer := NewDialer(mockBroker, signerFactory, verifier, ipResolver, consumerPinger, portPool)
+		channelDialer := NewDialer(mockBroker, signerFactory, verifier, ipResolver, consumerPinger, portPool, RelayConfig{}, &mockNATType{})
 
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
@@ -103,7 +106,7 @@ func TestDialer_Exchange_And_Communication_When_Provider_Behind_NAT(t *testing.T
 	ipResolver := ip.NewResolverMockMultiple("127.0.0.1", "1.1.1.1")
 
 	t.Run("Test provider listens to peer", func(t *testing.T) {
-		channelListener := NewListener(brokerConn, signerFactory, verifier, ipResolver, providerPinger, portPool, mockPortMapper)
+		channelListener := NewListener(brokerConn, signerFactory, verifier, ipResolver, providerPinger, portPool, mockPortMapper, RelayConfig{}, &mockNATType{})
 		err = channelListener.Listen(providerID, "wireguard", func(ch Channel) {
 			ch.Handle("test", func(c Context) error {
 				return c.OkWithReply(&Message{Data: []byte("pong")})
@@ -113,7 +116,7 @@ func TestDialer_Exchange_And_Communication_When_Provider_Behind_NAT(t *testing.T
 	})
 
 	t.Run("Test consumer dialer creates new ready to use channel", func(t *testing.T) {
-		channelDialer := NewDialer(mockBroker, signerFactory, verifier, ipResolver, consumerPinger, portPool)
+		channelDialer := NewDialer(mockBroker, signerFactory, verifier, ipResolver, consumerPinger, portPool, RelayConfig{}, &mockNATType{})
 
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
@@ -145,7 +148,7 @@ func TestDialer_Exchange_And_Communication_When_Provider_Behind_NAT_With_Upnp_En
 	ipResolver := ip.NewResolverMockMultiple("127.0.0.1", "0.0.0.0")
 
 	t.Run("Test provider listens to peer", func(t *testing.T) {
-		channelListener := NewListener(brokerConn, signerFactory, verifier, ipResolver, providerPinger, portPool, mockPortMapper)
+		channelListener := NewListener(brokerConn, signerFactory, verifier, ipResolver, providerPinger, portPool, mockPortMapper, RelayConfig{}, &mockNATType{})
 		err := channelListener.Listen(providerID, "wireguard", func(ch Channel) {
 			ch.Handle("test", func(c Context) error {
 				return c.OkWithReply(&Message{Data: []byte("pong")})
@@ -155,7 +158,7 @@ func TestDialer_Exchange_And_Communication_When_Provider_Behind_NAT_With_Upnp_En
 	})
 
 	t.Run("Test consumer dialer creates new ready to use channel", func(t *testing.T) {
-		channelDialer := NewDialer(mockBroker, signerFactory, verifier, ipResolver, consumerPinger, portPool)
+		channelDialer := NewDialer(mockBroker, signerFactory, verifier, ipResolver, consumerPinger, portPool, RelayConfig{}, &mockNATType{})
 
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
@@ -168,6 +171,72 @@ func TestDialer_Exchange_And_Communication_When_Provider_Behind_NAT_With_Upnp_En
 	})
 }
 
+func TestDialer_Exchange_And_Communication_Via_Relay_When_Ping_Fails(t *testing.T) {
+	consumerID, providerID, ks, cleanup := createTestIdentities(t)
+	defer cleanup()
+
+	signerFactory := func(id identity.Identity) identity.Signer {
+		return identity.NewSigner(ks, identity.FromAddress(id.Address))
+	}
+	verifier := identity.NewVerifierSigned()
+	brokerConn := nats.StartConnectionMock()
+	defer brokerConn.Close()
+	mockBroker := &mockBroker{conn: brokerConn}
+	portPool := port.NewPool()
+	mockPortMapper := &mockPortMapper{}
+	providerPinger := &mockProviderNATPinger{err: errors.New("ping timeout")}
+	consumerPinger := &mockConsumerNATPinger{err: errors.New("ping timeout")}
+	// Simulate behind NAT behaviour with different IP's.
+	ipResolver := ip.NewResolverMockMultiple("127.0.0.1", "1.1.1.1")
+
+	relayServer := relay.NewServer("127.0.0.1:0", relay.DefaultIdleTimeout)
+	require.NoError(t, relayServer.Start())
+	defer relayServer.Stop()
+	relayConfig := RelayConfig{Addresses: []string{relayServer.Addr().String()}}
+
+	channelListener := NewListener(brokerConn, signerFactory, verifier, ipResolver, providerPinger, portPool, mockPortMapper, relayConfig, &mockNATType{})
+
+	t.Run("Test provider listens to peer", func(t *testing.T) {
+		err := channelListener.Listen(providerID, "wireguard", func(ch Channel) {
+			ch.Handle("test", func(c Context) error {
+				return c.OkWithReply(&Message{Data: []byte("pong")})
+			})
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("Test consumer dialer creates new ready to use channel via relay", func(t *testing.T) {
+		channelDialer := NewDialer(mockBroker, signerFactory, verifier, ipResolver, consumerPinger, portPool, RelayConfig{}, &mockNATType{})
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		contactDef := channelListener.GetContact().Definition.(ContactDefinition)
+		assert.Equal(t, relayConfig.Addresses, contactDef.RelayAddresses)
+		consumerChannel, err := channelDialer.Dial(ctx, consumerID, providerID, "wireguard", contactDef)
+		require.NoError(t, err)
+		assert.Equal(t, relayServer.Addr().String(), consumerChannel.ServiceConn().RemoteAddr().String())
+
+		res, err := consumerChannel.Send(context.Background(), "test", &Message{Data: []byte("ping")})
+		require.NoError(t, err)
+		assert.Equal(t, "pong", string(res.Data))
+	})
+}
+
+func TestDialer_PingProvider_Skipped_When_NAT_Is_Not_Punchable(t *testing.T) {
+	d := &dialer{
+		consumerPinger: &mockConsumerNATPinger{},
+		natType:        &mockNATType{natType: stun.TypeSymmetric},
+	}
+	config := &p2pConnectConfig{}
+
+	_, err := d.pingProvider(context.Background(), identity.FromAddress("0x1"), config, stun.TypeSymmetric)
+	assert.Error(t, err)
+
+	d.natType = &mockNATType{natType: stun.TypeFullCone}
+	_, err = d.pingProvider(context.Background(), identity.FromAddress("0x1"), config, stun.TypeSymmetric)
+	assert.NoError(t, err)
+}
+
 func createTestIdentities(t *testing.T) (consumerID identity.Identity, providerID identity.Identity, ks *identity.Keystore, cleanup func()) {
 	dir, err := ioutil.TempDir("", "p2pDialerTest")
 	assert.NoError(t, err)
@@ -187,18 +256,31 @@ func createTestIdentities(t *testing.T) (consumerID identity.Identity, providerI
 
 type mockConsumerNATPinger struct {
 	conns []*net.UDPConn
+	err   error
 }
 
 func (m *mockConsumerNATPinger) PingProviderPeer(ctx context.Context, ip string, localPorts, remotePorts []int, initialTTL int, n int) (conns []*net.UDPConn, err error) {
-	return m.conns, nil
+	return m.conns, m.err
 }
 
 type mockProviderNATPinger struct {
 	conns []*net.UDPConn
+	err   error
 }
 
 func (m *mockProviderNATPinger) PingConsumerPeer(ctx context.Context, ip string, localPorts, remotePorts []int, initialTTL int, n int) (conns []*net.UDPConn, err error) {
-	return m.conns, nil
+	return m.conns, m.err
+}
+
+type mockNATType struct {
+	natType stun.NATType
+}
+
+func (m *mockNATType) NATType() stun.NATType {
+	if m.natType == "" {
+		return stun.TypeUnknown
+	}
+	return m.natType
 }
 
 type mockBroker struct {
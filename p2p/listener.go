@@ -27,13 +27,13 @@ import (
 	"github.com/mysteriumnetwork/node/communication/nats"
 	"github.com/mysteriumnetwork/node/core/ip"
 	"github.com/mysteriumnetwork/node/core/port"
+	"github.com/mysteriumnetwork/node/firewall"
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/market"
 	"github.com/mysteriumnetwork/node/nat/mapping"
 	"github.com/mysteriumnetwork/node/pb"
 
 	nats_lib "github.com/nats-io/go-nats"
-	"github.com/rs/zerolog/log"
 	"google.golang.org/protobuf/proto"
 )
 
@@ -49,8 +49,10 @@ type Listener interface {
 }
 
 // NewListener creates new p2p communication listener which is used on provider side.
-func NewListener(brokerConn nats.Connection, signer identity.SignerFactory, verifier identity.Verifier, ipResolver ip.Resolver, providerPinger natProviderPinger, portPool port.ServicePortSupplier, portMapper mapping.PortMapper) Listener {
+func NewListener(brokerConn nats.Connection, signer identity.SignerFactory, verifier identity.Verifier, ipResolver ip.Resolver, providerPinger natProviderPinger, portPool port.ServicePortSupplier, portMapper mapping.PortMapper, relayConfig RelayConfig, natType natTypeProvider) Listener {
 	return &listener{
+		natType:        natType,
+		relayConfig:    relayConfig,
 		brokerConn:     brokerConn,
 		pendingConfigs: map[PublicKey]p2pConnectConfig{},
 		ipResolver:     ipResolver,
@@ -71,6 +73,8 @@ type listener struct {
 	verifier       identity.Verifier
 	ipResolver     ip.Resolver
 	portMapper     mapping.PortMapper
+	relayConfig    RelayConfig
+	natType        natTypeProvider
 
 	// Keys holds pendingConfigs temporary configs for provider side since it
 	// need to handle key exchange in two steps.
@@ -98,8 +102,13 @@ func (c *p2pConnectConfig) peerIP() string {
 
 func (m *listener) GetContact() market.Contact {
 	return market.Contact{
-		Type:       ContactTypeV1,
-		Definition: ContactDefinition{BrokerAddresses: m.brokerConn.Servers()}}
+		Type: ContactTypeV1,
+		Definition: ContactDefinition{
+			BrokerAddresses: m.brokerConn.Servers(),
+			RelayAddresses:  m.relayConfig.Addresses,
+			NATType:         string(m.natType.NATType()),
+		},
+	}
 }
 
 // Listen listens for incoming peer connections to establish new p2p channels. Establishes p2p channel and passes it
@@ -134,6 +143,7 @@ func (m *listener) Listen(providerID identity.Identity, serviceType string, chan
 		}(msg.Reply)
 
 		var conn1, conn2 *net.UDPConn
+		var removeRelayRule firewall.OutgoingRuleRemove
 		if len(config.peerPorts) == requiredConnCount {
 			log.Debug().Msg("Skipping consumer ping")
 			conn1, err = net.DialUDP("udp4", &net.UDPAddr{Port: config.localPorts[0]}, &net.UDPAddr{IP: net.ParseIP(config.peerIP()), Port: config.peerPorts[0]})
@@ -147,22 +157,37 @@ func (m *listener) Listen(providerID identity.Identity, serviceType string, chan
 				return
 			}
 		} else {
-			log.Debug().Msgf("Pinging consumer with IP %s using ports %v:%v", config.peerIP(), config.localPorts, config.peerPorts)
-			conns, err := m.providerPinger.PingConsumerPeer(context.Background(), config.peerIP(), config.localPorts, config.peerPorts, providerInitialTTL, requiredConnCount)
+			conns, err := m.pingConsumer(config)
 			if err != nil {
-				log.Err(err).Msg("Could not ping peer")
-				return
+				if len(m.relayConfig.Addresses) == 0 {
+					log.Err(err).Msg("Could not ping peer")
+					return
+				}
+				log.Warn().Err(err).Msg("Could not ping consumer, falling back to relay")
+				ctx, cancel := context.WithTimeout(context.Background(), providerRelayTimeout)
+				conns, removeRelayRule, err = dialRelay(ctx, m.portPool, m.relayConfig.Addresses, config.privateKey, config.peerPubKey)
+				cancel()
+				if err != nil {
+					log.Err(err).Msg("Could not connect peer via relay")
+					return
+				}
 			}
 			conn1 = conns[0]
 			conn2 = conns[1]
 		}
 		channel, err := newChannel(conn1, config.privateKey, config.peerPubKey)
 		if err != nil {
+			if removeRelayRule != nil {
+				removeRelayRule()
+			}
 			log.Err(err).Msg("Could not create channel")
 			return
 		}
 		channel.setServiceConn(conn2)
 		channel.setUpnpPortsRelease(config.upnpPortsRelease)
+		if removeRelayRule != nil {
+			channel.setRelayRuleRemove(removeRelayRule)
+		}
 
 		channelHandlers(channel)
 
@@ -177,6 +202,15 @@ func (m *listener) Listen(providerID identity.Identity, serviceType string, chan
 	return err
 }
 
+func (m *listener) pingConsumer(config *p2pConnectConfig) ([]*net.UDPConn, error) {
+	if m.relayConfig.Force {
+		return nil, errRelayForced
+	}
+
+	log.Debug().Msgf("Pinging consumer with IP %s using ports %v:%v", config.peerIP(), config.localPorts, config.peerPorts)
+	return m.providerPinger.PingConsumerPeer(context.Background(), config.peerIP(), config.localPorts, config.peerPorts, providerInitialTTL, requiredConnCount)
+}
+
 func (m *listener) providerStartConfigExchange(signerID identity.Identity, msg *nats_lib.Msg, outboundIP string) error {
 	pubKey, privateKey, err := GenerateKey()
 	if err != nil {
@@ -23,7 +23,6 @@ import (
 	"net/textproto"
 	"strconv"
 
-	"github.com/rs/zerolog/log"
 	"google.golang.org/protobuf/proto"
 )
 
@@ -46,6 +45,12 @@ const (
 	TopicSessionStatus = "p2p-session-connectivity-status"
 	// TopicSessionDestroy is a session destroy endpoint for p2p communication.
 	TopicSessionDestroy = "p2p-session-destroy"
+	// TopicSessionRekey is a session key rotation endpoint for p2p communication.
+	TopicSessionRekey = "p2p-session-rekey"
+	// TopicSessionResume is a session resume endpoint for p2p communication.
+	TopicSessionResume = "p2p-session-resume"
+	// TopicSessionDrain is a notification that provider is shutting down and does not accept new sessions.
+	TopicSessionDrain = "p2p-session-drain"
 
 	// TopicPaymentMessage is a payment messages endpoint for p2p communication.
 	TopicPaymentMessage = "p2p-payment-message"
@@ -76,9 +81,11 @@ func ProtoMessage(m proto.Message) *Message {
 }
 
 const (
-	headerFieldRequestID = "Request-ID"
-	headerFieldTopic     = "Topic"
-	headerStatusCode     = "Status-Code"
+	headerFieldRequestID       = "Request-ID"
+	headerFieldTopic           = "Topic"
+	headerStatusCode           = "Status-Code"
+	headerFieldMessageType     = "Message-Type"
+	headerFieldStreamInitiator = "Stream-Initiator"
 
 	statusCodeOK                 = 1
 	statusCodePublicErr          = 2
@@ -86,12 +93,27 @@ const (
 	statusCodeHandlerNotFoundErr = 4
 )
 
+// Message types. Request and reply messages don't have a type
+// to stay compatible with peers which doesn't support other types.
+const (
+	msgTypeRequestReply    = 0
+	msgTypePublish         = 1
+	msgTypeStreamOpen      = 2
+	msgTypeStreamOpenReply = 3
+	msgTypeStreamData      = 4
+	msgTypeStreamWindow    = 5
+	msgTypeStreamClose     = 6
+)
+
 // transportMsg is internal structure for sending and receiving messages.
 type transportMsg struct {
 	// Header fields.
 	id         uint64
 	statusCode uint64
 	topic      string
+	msgType    uint64
+	// initiator is set for stream messages sent by the peer which opened the stream.
+	initiator bool
 
 	// Data field.
 	data []byte
@@ -114,6 +136,13 @@ func (m *transportMsg) readFrom(conn *textproto.Reader) error {
 	}
 	m.statusCode = statusCode
 	m.topic = header.Get(headerFieldTopic)
+	if msgType := header.Get(headerFieldMessageType); msgType != "" {
+		m.msgType, err = strconv.ParseUint(msgType, 10, 64)
+		if err != nil {
+			return fmt.Errorf("could not parse message type: %w", err)
+		}
+	}
+	m.initiator = header.Get(headerFieldStreamInitiator) == "1"
 
 	// Read data.
 	data, err := conn.ReadDotBytes()
@@ -132,6 +161,12 @@ func (m *transportMsg) writeTo(conn *textproto.Writer) error {
 	header.WriteString(fmt.Sprintf("%s:%d\r\n", headerFieldRequestID, m.id))
 	header.WriteString(fmt.Sprintf("%s:%s\r\n", headerFieldTopic, m.topic))
 	header.WriteString(fmt.Sprintf("%s:%d\r\n", headerStatusCode, m.statusCode))
+	if m.msgType != msgTypeRequestReply {
+		header.WriteString(fmt.Sprintf("%s:%d\r\n", headerFieldMessageType, m.msgType))
+	}
+	if m.initiator {
+		header.WriteString(fmt.Sprintf("%s:1\r\n", headerFieldStreamInitiator))
+	}
 	header.WriteByte('\n')
 	w.Write(header.Bytes())
 	w.Write(m.data)
@@ -0,0 +1,127 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package p2p
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/mysteriumnetwork/node/core/port"
+	"github.com/mysteriumnetwork/node/firewall"
+	"github.com/mysteriumnetwork/node/p2p/relay"
+	"golang.org/x/crypto/nacl/box"
+)
+
+// providerRelayTimeout is the time provider waits for consumer on relay.
+// It must be longer than NAT pinging timeout, so the consumer which punches holes without success can still join.
+const providerRelayTimeout = 30 * time.Second
+
+var errRelayForced = errors.New("NAT hole punching skipped, relay is forced")
+
+// RelayConfig configures relay fallback when NAT hole punching fails.
+type RelayConfig struct {
+	// Addresses are relay servers advertised in provider contact, consumers use the ones of the provider.
+	Addresses []string
+	// Force skips NAT hole punching and always uses relays, it is useful for testing.
+	Force bool
+}
+
+// dialRelay binds peer connections on the first available relay. Both peers try relays in the same order,
+// so they meet on the same one. Fresh local ports are used since the pinging ones may still be busy.
+// Returned firewall rule remover should be called once the connections are no longer used.
+func dialRelay(ctx context.Context, portPool port.ServicePortSupplier, relays []string, privateKey PrivateKey, peerPubKey PublicKey) ([]*net.UDPConn, firewall.OutgoingRuleRemove, error) {
+	var sharedKey [32]byte
+	box.Precompute(&sharedKey, (*[32]byte)(&peerPubKey), (*[32]byte)(&privateKey))
+
+	for _, relayAddr := range relays {
+		addr, err := net.ResolveUDPAddr("udp4", relayAddr)
+		if err != nil {
+			log.Warn().Err(err).Msgf("Could not resolve relay address %s", relayAddr)
+			continue
+		}
+		removeRule, err := firewall.AllowIPAccess(addr.IP.String())
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not add relay IP firewall rule: %w", err)
+		}
+
+		localPorts, err := acquireLocalPorts(portPool, requiredConnCount)
+		if err != nil {
+			removeRule()
+			return nil, nil, fmt.Errorf("could not acquire local ports: %w", err)
+		}
+
+		log.Info().Msgf("Connecting peer via relay %s", relayAddr)
+		conns, err := bindRelay(ctx, addr.String(), localPorts, sharedKey)
+		if err != nil {
+			removeRule()
+			if errors.Is(err, relay.ErrUnavailable) {
+				log.Warn().Msgf("Relay %s is unavailable", relayAddr)
+				continue
+			}
+			return nil, nil, err
+		}
+		return conns, removeRule, nil
+	}
+	return nil, nil, errors.New("no relay available")
+}
+
+func bindRelay(ctx context.Context, relayAddr string, localPorts []int, sharedKey [32]byte) ([]*net.UDPConn, error) {
+	type result struct {
+		conn *net.UDPConn
+		err  error
+	}
+
+	// Stop binding the other connections once one of them fails.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make([]chan result, len(localPorts))
+	for i, localPort := range localPorts {
+		results[i] = make(chan result, 1)
+		go func(i, localPort int) {
+			conn, err := relay.Bind(ctx, localPort, relayAddr, relay.Token(sharedKey, i))
+			if err != nil {
+				cancel()
+			}
+			results[i] <- result{conn: conn, err: err}
+		}(i, localPort)
+	}
+
+	var conns []*net.UDPConn
+	var err error
+	for _, ch := range results {
+		res := <-ch
+		if res.err != nil {
+			if err == nil || errors.Is(res.err, relay.ErrUnavailable) {
+				err = res.err
+			}
+			continue
+		}
+		conns = append(conns, res.conn)
+	}
+	if err != nil {
+		for _, conn := range conns {
+			conn.Close()
+		}
+		return nil, err
+	}
+	return conns, nil
+}
@@ -0,0 +1,96 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package relay
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	bindInterval = 200 * time.Millisecond
+	// aliveTimeout is the time to wait for the relay to answer the first bind request.
+	aliveTimeout = 2 * time.Second
+)
+
+// ErrUnavailable is returned when relay server does not answer the bind requests.
+var ErrUnavailable = errors.New("relay is unavailable")
+
+// Bind binds local UDP port to the relay session of the given token and waits until the peer binds to it too.
+// Returned connection sends to the relay, which forwards the packets to the peer.
+func Bind(ctx context.Context, localPort int, relayAddr, token string) (*net.UDPConn, error) {
+	raddr, err := net.ResolveUDPAddr("udp4", relayAddr)
+	if err != nil {
+		return nil, fmt.Errorf("could not resolve relay address: %w", err)
+	}
+	conn, err := net.DialUDP("udp4", &net.UDPAddr{Port: localPort}, raddr)
+	if err != nil {
+		return nil, fmt.Errorf("could not create UDP conn for relay: %w", err)
+	}
+
+	if err := waitPeer(ctx, conn, token); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
+
+func waitPeer(ctx context.Context, conn *net.UDPConn, token string) error {
+	started := time.Now()
+	alive := false
+	buf := make([]byte, len(msgReady))
+
+	for {
+		if _, err := conn.Write(bindMsg(token)); err != nil {
+			log.Debug().Err(err).Msg("Failed to send relay bind request")
+		}
+
+		// Read the answers until the next bind request is due.
+		next := time.Now().Add(bindInterval)
+		conn.SetReadDeadline(next)
+		for {
+			n, err := conn.Read(buf)
+			if err != nil {
+				break
+			}
+			switch string(buf[:n]) {
+			case msgReady:
+				conn.SetReadDeadline(time.Time{})
+				return nil
+			case msgWait:
+				alive = true
+			}
+		}
+
+		if !alive && time.Since(started) > aliveTimeout {
+			return ErrUnavailable
+		}
+
+		// Read fails immediately if relay port is unreachable, don't flood it with requests.
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Until(next)):
+		}
+	}
+}
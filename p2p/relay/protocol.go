@@ -0,0 +1,67 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Package relay forwards p2p and service UDP traffic between the peers unable to connect directly.
+//
+// Relay fallback can be tried locally with three processes, the relay must be addressed by LAN IP:
+//
+//	myst relay --p2p.relay.port 4589
+//	myst --p2p.relay.addresses 192.168.1.2:4589 --p2p.relay.force service wireguard
+//	myst --p2p.relay.force daemon
+package relay
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+)
+
+// Relay control messages are plain text packets starting with the protocol prefix,
+// all the other packets are forwarded between the peers as is.
+const (
+	msgPrefix = "MYSTRELAY/1 "
+	msgBind   = msgPrefix + "BIND "
+	msgWait   = msgPrefix + "WAIT"
+	msgReady  = msgPrefix + "READY"
+)
+
+// Token returns relay token for the peers connection with the given index.
+// Token is derived from the secret key shared by the peers, so nobody else can bind to their session.
+func Token(sharedKey [32]byte, index int) string {
+	h := sha256.New()
+	h.Write([]byte(msgPrefix))
+	h.Write(sharedKey[:])
+	fmt.Fprintf(h, "%d", index)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func isControlMsg(packet []byte) bool {
+	return bytes.HasPrefix(packet, []byte(msgPrefix))
+}
+
+func bindMsg(token string) []byte {
+	return []byte(msgBind + token)
+}
+
+func parseBindMsg(packet []byte) (token string, ok bool) {
+	if !bytes.HasPrefix(packet, []byte(msgBind)) {
+		return "", false
+	}
+	token = string(packet[len(msgBind):])
+	return token, token != ""
+}
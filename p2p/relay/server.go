@@ -0,0 +1,279 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package relay
+
+import (
+	"net"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// DefaultIdleTimeout is the time after which relay session without any traffic is removed.
+const DefaultIdleTimeout = 2 * time.Minute
+
+const (
+	// pendingTimeout is the time after which session not paired with the second peer is removed.
+	pendingTimeout = 30 * time.Second
+	// maxPendingSessions limits the number of unpaired sessions relay keeps in total.
+	maxPendingSessions = 10000
+	// maxPendingPerSource limits the number of unpaired sessions created from the same IP address.
+	maxPendingPerSource = 32
+)
+
+// Server forwards UDP packets between the pairs of peers which bound to the same token.
+// Packets are already encrypted by the peers, relay only knows their addresses.
+type Server struct {
+	addr        string
+	idleTimeout time.Duration
+
+	pendingTimeout      time.Duration
+	maxPending          int
+	maxPendingPerSource int
+
+	conn *net.UDPConn
+
+	mu       sync.Mutex
+	sessions map[string]*session
+	peers    map[string]*session
+	// pending counts unpaired sessions by the IP address of the peer which created them.
+	pending      map[string]int
+	pendingTotal int
+
+	stop chan struct{}
+	once sync.Once
+}
+
+type session struct {
+	token    string
+	source   string
+	peers    []*net.UDPAddr
+	created  time.Time
+	lastSeen time.Time
+}
+
+func (s *session) paired() bool {
+	return len(s.peers) == 2
+}
+
+func (s *session) has(addr *net.UDPAddr) bool {
+	for _, peer := range s.peers {
+		if peer.String() == addr.String() {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *session) other(addr *net.UDPAddr) *net.UDPAddr {
+	for _, peer := range s.peers {
+		if peer.String() != addr.String() {
+			return peer
+		}
+	}
+	return nil
+}
+
+// NewServer creates new relay server listening on the given UDP address.
+func NewServer(addr string, idleTimeout time.Duration) *Server {
+	return &Server{
+		addr:                addr,
+		idleTimeout:         idleTimeout,
+		pendingTimeout:      pendingTimeout,
+		maxPending:          maxPendingSessions,
+		maxPendingPerSource: maxPendingPerSource,
+		sessions:            make(map[string]*session),
+		peers:               make(map[string]*session),
+		pending:             make(map[string]int),
+		stop:                make(chan struct{}),
+	}
+}
+
+// Start starts listening and forwarding the peers packets.
+func (s *Server) Start() error {
+	addr, err := net.ResolveUDPAddr("udp4", s.addr)
+	if err != nil {
+		return err
+	}
+	s.conn, err = net.ListenUDP("udp4", addr)
+	if err != nil {
+		return err
+	}
+
+	log.Info().Msgf("Relay server listening on %s", s.conn.LocalAddr())
+	go s.serve()
+	go s.removeIdleSessions()
+	return nil
+}
+
+// Addr returns the address relay server is listening on.
+func (s *Server) Addr() net.Addr {
+	return s.conn.LocalAddr()
+}
+
+// Stop stops the relay server.
+func (s *Server) Stop() {
+	s.once.Do(func() {
+		close(s.stop)
+		if s.conn != nil {
+			s.conn.Close()
+		}
+	})
+}
+
+func (s *Server) serve() {
+	buf := make([]byte, 65535)
+	for {
+		n, addr, err := s.conn.ReadFromUDP(buf)
+		if err != nil {
+			select {
+			case <-s.stop:
+				return
+			default:
+			}
+			log.Warn().Err(err).Msg("Relay server failed to read packet")
+			continue
+		}
+
+		packet := buf[:n]
+		if token, ok := parseBindMsg(packet); ok {
+			s.bind(token, addr)
+			continue
+		}
+		if isControlMsg(packet) {
+			continue
+		}
+		s.forward(packet, addr)
+	}
+}
+
+func (s *Server) bind(token string, addr *net.UDPAddr) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sess, ok := s.sessions[token]
+	if !ok {
+		if old, ok := s.peers[addr.String()]; ok {
+			// Peer address is reused by a new session, the old one is not valid anymore.
+			s.remove(old)
+		}
+		source := addr.IP.String()
+		if s.pendingTotal >= s.maxPending || s.pending[source] >= s.maxPendingPerSource {
+			log.Debug().Msgf("Too many unpaired relay sessions, ignoring bind from %s", addr)
+			return
+		}
+		sess = &session{token: token, source: source, created: time.Now()}
+		s.sessions[token] = sess
+		s.pending[source]++
+		s.pendingTotal++
+	}
+	sess.lastSeen = time.Now()
+
+	if !sess.has(addr) {
+		if sess.paired() {
+			log.Debug().Msgf("Relay session is already paired, ignoring bind from %s", addr)
+			return
+		}
+		if old, ok := s.peers[addr.String()]; ok {
+			// Peer address is reused by a new session, the old one is not valid anymore.
+			s.remove(old)
+		}
+		sess.peers = append(sess.peers, addr)
+		s.peers[addr.String()] = sess
+
+		if sess.paired() {
+			log.Debug().Msgf("Relay session paired %s with %s", sess.peers[0], sess.peers[1])
+			s.removePending(sess)
+			s.send(sess.other(addr), msgReady)
+		}
+	}
+
+	if sess.paired() {
+		s.send(addr, msgReady)
+	} else {
+		s.send(addr, msgWait)
+	}
+}
+
+func (s *Server) forward(packet []byte, addr *net.UDPAddr) {
+	s.mu.Lock()
+	sess, ok := s.peers[addr.String()]
+	if !ok || !sess.paired() {
+		s.mu.Unlock()
+		return
+	}
+	sess.lastSeen = time.Now()
+	to := sess.other(addr)
+	s.mu.Unlock()
+
+	if _, err := s.conn.WriteToUDP(packet, to); err != nil {
+		log.Warn().Err(err).Msgf("Relay server failed to forward packet to %s", to)
+	}
+}
+
+func (s *Server) send(addr *net.UDPAddr, msg string) {
+	if _, err := s.conn.WriteToUDP([]byte(msg), addr); err != nil {
+		log.Warn().Err(err).Msgf("Relay server failed to send message to %s", addr)
+	}
+}
+
+func (s *Server) removeIdleSessions() {
+	interval := s.idleTimeout
+	if s.pendingTimeout < interval {
+		interval = s.pendingTimeout
+	}
+	ticker := time.NewTicker(interval / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			s.mu.Lock()
+			for _, sess := range s.sessions {
+				if time.Since(sess.lastSeen) > s.idleTimeout {
+					s.remove(sess)
+				} else if !sess.paired() && time.Since(sess.created) > s.pendingTimeout {
+					log.Debug().Msgf("Relay session was not paired in %s, removing it", s.pendingTimeout)
+					s.remove(sess)
+				}
+			}
+			s.mu.Unlock()
+		}
+	}
+}
+
+func (s *Server) remove(sess *session) {
+	if !sess.paired() {
+		s.removePending(sess)
+	}
+	for _, peer := range sess.peers {
+		delete(s.peers, peer.String())
+	}
+	delete(s.sessions, sess.token)
+}
+
+func (s *Server) removePending(sess *session) {
+	s.pendingTotal--
+	s.pending[sess.source]--
+	if s.pending[sess.source] <= 0 {
+		delete(s.pending, sess.source)
+	}
+}
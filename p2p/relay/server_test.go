@@ -0,0 +1,161 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package relay
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer_ForwardsPacketsBetweenBoundPeers(t *testing.T) {
+	server := NewServer("127.0.0.1:0", DefaultIdleTimeout)
+	require.NoError(t, server.Start())
+	defer server.Stop()
+
+	token := Token([32]byte{1}, 0)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	conns := make(chan *net.UDPConn, 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			conn, err := Bind(ctx, 0, server.Addr().String(), token)
+			assert.NoError(t, err)
+			conns <- conn
+		}()
+	}
+	conn1, conn2 := <-conns, <-conns
+	require.NotNil(t, conn1)
+	require.NotNil(t, conn2)
+	defer conn1.Close()
+	defer conn2.Close()
+
+	assertReceives(t, conn1, conn2, "ping")
+	assertReceives(t, conn2, conn1, "pong")
+}
+
+func TestServer_DoesNotPairDifferentTokens(t *testing.T) {
+	server := NewServer("127.0.0.1:0", DefaultIdleTimeout)
+	require.NoError(t, server.Start())
+	defer server.Stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+
+	go Bind(ctx, 0, server.Addr().String(), Token([32]byte{1}, 0))
+	_, err := Bind(ctx, 0, server.Addr().String(), Token([32]byte{1}, 1))
+	assert.Equal(t, context.DeadlineExceeded, err)
+}
+
+func TestServer_LimitsPendingSessionsPerSource(t *testing.T) {
+	server := NewServer("127.0.0.1:0", DefaultIdleTimeout)
+	server.maxPendingPerSource = 2
+	require.NoError(t, server.Start())
+	defer server.Stop()
+
+	for i := 0; i < 2; i++ {
+		answer, err := bindOnce(server.Addr().String(), Token([32]byte{1}, i))
+		require.NoError(t, err)
+		assert.Equal(t, msgWait, answer)
+	}
+	_, err := bindOnce(server.Addr().String(), Token([32]byte{1}, 2))
+	assert.Error(t, err)
+}
+
+func TestServer_RemovesUnpairedSessions(t *testing.T) {
+	server := NewServer("127.0.0.1:0", DefaultIdleTimeout)
+	server.pendingTimeout = 100 * time.Millisecond
+	server.maxPendingPerSource = 1
+	require.NoError(t, server.Start())
+	defer server.Stop()
+
+	_, err := bindOnce(server.Addr().String(), Token([32]byte{1}, 0))
+	require.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		server.mu.Lock()
+		defer server.mu.Unlock()
+		return len(server.sessions) == 0 && server.pendingTotal == 0
+	}, 2*time.Second, 10*time.Millisecond)
+
+	answer, err := bindOnce(server.Addr().String(), Token([32]byte{1}, 1))
+	require.NoError(t, err)
+	assert.Equal(t, msgWait, answer)
+}
+
+func TestBind_ReturnsErrorWhenRelayIsUnavailable(t *testing.T) {
+	conn, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.ParseIP("127.0.0.1")})
+	require.NoError(t, err)
+	addr := conn.LocalAddr().String()
+	conn.Close()
+
+	_, err = Bind(context.Background(), 0, addr, Token([32]byte{1}, 0))
+	assert.Equal(t, ErrUnavailable, err)
+}
+
+func TestToken(t *testing.T) {
+	assert.Equal(t, Token([32]byte{1}, 0), Token([32]byte{1}, 0))
+	assert.NotEqual(t, Token([32]byte{1}, 0), Token([32]byte{1}, 1))
+	assert.NotEqual(t, Token([32]byte{1}, 0), Token([32]byte{2}, 0))
+}
+
+func bindOnce(relayAddr, token string) (string, error) {
+	raddr, err := net.ResolveUDPAddr("udp4", relayAddr)
+	if err != nil {
+		return "", err
+	}
+	conn, err := net.DialUDP("udp4", nil, raddr)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+
+	if _, err := conn.Write(bindMsg(token)); err != nil {
+		return "", err
+	}
+	buf := make([]byte, 100)
+	conn.SetReadDeadline(time.Now().Add(500 * time.Millisecond))
+	n, err := conn.Read(buf)
+	if err != nil {
+		return "", err
+	}
+	return string(buf[:n]), nil
+}
+
+func assertReceives(t *testing.T, from, to *net.UDPConn, msg string) {
+	_, err := from.Write([]byte(msg))
+	require.NoError(t, err)
+
+	buf := make([]byte, 100)
+	to.SetReadDeadline(time.Now().Add(2 * time.Second))
+	for {
+		n, err := to.Read(buf)
+		require.NoError(t, err)
+		if isControlMsg(buf[:n]) {
+			// Late answers to the bind requests.
+			continue
+		}
+		assert.Equal(t, msg, string(buf[:n]))
+		return
+	}
+}
@@ -0,0 +1,253 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package p2p
+
+import (
+	"encoding/binary"
+	"hash/crc32"
+	"sync"
+	"time"
+
+	"github.com/xtaci/kcp-go/v5"
+)
+
+// AppTopicChannelStats is a topic for periodic p2p channel statistics of the session.
+const AppTopicChannelStats = "p2p channel stats"
+
+// AppEventChannelStats represents p2p channel statistics of the session.
+type AppEventChannelStats struct {
+	SessionID string
+	Stats     ChannelStats
+}
+
+// ChannelStats represents p2p channel transport statistics.
+type ChannelStats struct {
+	// RTT is smoothed round trip time of the transport segments.
+	RTT time.Duration
+	// SegmentsSent is a number of transport data segments sent including retransmissions.
+	SegmentsSent uint64
+	// Retransmits is a number of retransmitted transport data segments.
+	Retransmits uint64
+	// Loss is a ratio of retransmitted segments to all sent segments.
+	Loss float64
+	// BytesSent is a number of UDP bytes sent to peer.
+	BytesSent uint64
+	// BytesReceived is a number of UDP bytes received from peer.
+	BytesReceived uint64
+	// PeerAddrChangedAt is a time of the last peer address change.
+	PeerAddrChangedAt time.Time
+}
+
+// KCP packet layout constants not exported by kcp-go. Packets are observed in plain text before encryption
+// and after decryption, they contain FEC header as channel sessions use FEC. TestChannel_Stats verifies
+// the layout against the real session.
+const (
+	kcpCryptHeaderSize = 20
+	kcpFECHeaderSize   = 6
+	kcpFECTypeData     = 0xf1
+)
+
+type sentSegment struct {
+	at            time.Time
+	retransmitted bool
+}
+
+// channelStats collects channel transport statistics. KCP doesn't expose
+// per session statistics so they are calculated from the observed segments.
+type channelStats struct {
+	mu sync.Mutex
+
+	srtt              time.Duration
+	segmentsSent      uint64
+	retransmits       uint64
+	bytesSent         uint64
+	bytesReceived     uint64
+	peerAddrChangedAt time.Time
+
+	// inFlight holds send time of the unacknowledged segments by their sequence number.
+	inFlight map[uint32]sentSegment
+	// una is the lowest sequence number not yet acknowledged cumulatively by the peer.
+	una uint32
+}
+
+func newChannelStats() *channelStats {
+	return &channelStats{inFlight: make(map[uint32]sentSegment)}
+}
+
+func (s *channelStats) snapshot() ChannelStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats := ChannelStats{
+		RTT:               s.srtt,
+		SegmentsSent:      s.segmentsSent,
+		Retransmits:       s.retransmits,
+		BytesSent:         s.bytesSent,
+		BytesReceived:     s.bytesReceived,
+		PeerAddrChangedAt: s.peerAddrChangedAt,
+	}
+	if s.segmentsSent > 0 {
+		stats.Loss = float64(s.retransmits) / float64(s.segmentsSent)
+	}
+	return stats
+}
+
+func (s *channelStats) addSent(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.bytesSent += uint64(n)
+}
+
+func (s *channelStats) addReceived(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.bytesReceived += uint64(n)
+}
+
+func (s *channelStats) peerAddrChanged() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.peerAddrChangedAt = time.Now()
+}
+
+// observeOutgoing counts sent and retransmitted data segments.
+func (s *channelStats) observeOutgoing(packet []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	forEachKCPSegment(packet, func(cmd byte, sn, una uint32) {
+		if cmd != kcp.IKCP_CMD_PUSH {
+			return
+		}
+
+		s.segmentsSent++
+		if seg, ok := s.inFlight[sn]; ok {
+			s.retransmits++
+			seg.retransmitted = true
+			s.inFlight[sn] = seg
+			return
+		}
+		s.inFlight[sn] = sentSegment{at: now}
+	})
+}
+
+// observeIncoming measures RTT from the acknowledgements of the segments which were not retransmitted.
+func (s *channelStats) observeIncoming(packet []byte) {
+	if len(packet) < kcpCryptHeaderSize ||
+		crc32.ChecksumIEEE(packet[kcpCryptHeaderSize:]) != binary.LittleEndian.Uint32(packet[kcpCryptHeaderSize-4:]) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	una := s.una
+	forEachKCPSegment(packet, func(cmd byte, sn, segmentUNA uint32) {
+		if cmd == kcp.IKCP_CMD_ACK {
+			if seg, ok := s.inFlight[sn]; ok {
+				if !seg.retransmitted {
+					s.addRTTSample(now.Sub(seg.at))
+				}
+				delete(s.inFlight, sn)
+			}
+		}
+		if segmentUNA > una {
+			una = segmentUNA
+		}
+	})
+	s.acknowledgeUntil(una)
+}
+
+// acknowledgeUntil forgets segments cumulatively acknowledged by the peer.
+func (s *channelStats) acknowledgeUntil(una uint32) {
+	if una <= s.una {
+		return
+	}
+	if int(una-s.una) > len(s.inFlight) {
+		for sn := range s.inFlight {
+			if sn < una {
+				delete(s.inFlight, sn)
+			}
+		}
+	} else {
+		for sn := s.una; sn < una; sn++ {
+			delete(s.inFlight, sn)
+		}
+	}
+	s.una = una
+}
+
+// addRTTSample smooths RTT as described in RFC 6298.
+func (s *channelStats) addRTTSample(rtt time.Duration) {
+	if s.srtt == 0 {
+		s.srtt = rtt
+		return
+	}
+	s.srtt = s.srtt - s.srtt/8 + rtt/8
+}
+
+// forEachKCPSegment calls fn for every KCP segment of the plain text data packet.
+func forEachKCPSegment(packet []byte, fn func(cmd byte, sn, una uint32)) {
+	if len(packet) < kcpCryptHeaderSize+kcpFECHeaderSize+2 {
+		return
+	}
+	data := packet[kcpCryptHeaderSize:]
+	if binary.LittleEndian.Uint16(data[4:]) != kcpFECTypeData {
+		return
+	}
+	size := int(binary.LittleEndian.Uint16(data[kcpFECHeaderSize:]))
+	if size < 2 || kcpFECHeaderSize+size > len(data) {
+		return
+	}
+
+	segments := data[kcpFECHeaderSize+2 : kcpFECHeaderSize+size]
+	for len(segments) >= kcp.IKCP_OVERHEAD {
+		cmd := segments[4]
+		sn := binary.LittleEndian.Uint32(segments[12:])
+		una := binary.LittleEndian.Uint32(segments[16:])
+		length := binary.LittleEndian.Uint32(segments[20:])
+		segments = segments[kcp.IKCP_OVERHEAD:]
+		if uint32(len(segments)) < length {
+			return
+		}
+		segments = segments[length:]
+
+		fn(cmd, sn, una)
+	}
+}
+
+// statsBlockCrypt observes plain text KCP packets for channel statistics.
+type statsBlockCrypt struct {
+	kcp.BlockCrypt
+	stats *channelStats
+}
+
+func (b *statsBlockCrypt) Encrypt(dst, src []byte) {
+	b.stats.observeOutgoing(src)
+	b.BlockCrypt.Encrypt(dst, src)
+}
+
+func (b *statsBlockCrypt) Decrypt(dst, src []byte) {
+	b.BlockCrypt.Decrypt(dst, src)
+	b.stats.observeIncoming(dst)
+}
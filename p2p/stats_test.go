@@ -0,0 +1,85 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package p2p
+
+import (
+	"context"
+	"encoding/binary"
+	"hash/crc32"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xtaci/kcp-go/v5"
+)
+
+func TestChannel_Stats(t *testing.T) {
+	provider, consumer, err := createTestChannels()
+	require.NoError(t, err)
+	defer provider.Close()
+	defer consumer.Close()
+
+	provider.Handle("stats", func(c Context) error {
+		return c.OK()
+	})
+	for i := 0; i < 5; i++ {
+		_, err := consumer.Send(context.Background(), "stats", &Message{Data: []byte("ping")})
+		require.NoError(t, err)
+	}
+
+	stats := consumer.Stats()
+	assert.True(t, stats.RTT > 0)
+	assert.True(t, stats.SegmentsSent >= 5)
+	assert.True(t, stats.BytesSent > 0)
+	assert.True(t, stats.BytesReceived > 0)
+}
+
+func TestChannelStats_Retransmits(t *testing.T) {
+	stats := newChannelStats()
+
+	stats.observeOutgoing(testKCPPacket(kcp.IKCP_CMD_PUSH, 1, 0))
+	stats.observeOutgoing(testKCPPacket(kcp.IKCP_CMD_PUSH, 2, 0))
+	stats.observeOutgoing(testKCPPacket(kcp.IKCP_CMD_PUSH, 2, 0))
+	time.Sleep(10 * time.Millisecond)
+	stats.observeIncoming(testKCPPacket(kcp.IKCP_CMD_ACK, 1, 0))
+	stats.observeIncoming(testKCPPacket(kcp.IKCP_CMD_ACK, 2, 3))
+
+	snapshot := stats.snapshot()
+	assert.Equal(t, uint64(3), snapshot.SegmentsSent)
+	assert.Equal(t, uint64(1), snapshot.Retransmits)
+	assert.InDelta(t, 1.0/3, snapshot.Loss, 0.001)
+	assert.True(t, snapshot.RTT >= 10*time.Millisecond)
+	assert.Empty(t, stats.inFlight)
+}
+
+func testKCPPacket(cmd byte, sn, una uint32) []byte {
+	segment := make([]byte, kcp.IKCP_OVERHEAD+4)
+	binary.LittleEndian.PutUint32(segment, 1)
+	segment[4] = cmd
+	binary.LittleEndian.PutUint32(segment[12:], sn)
+	binary.LittleEndian.PutUint32(segment[16:], una)
+	binary.LittleEndian.PutUint32(segment[20:], 4)
+
+	packet := make([]byte, kcpCryptHeaderSize+kcpFECHeaderSize+2, kcpCryptHeaderSize+kcpFECHeaderSize+2+len(segment))
+	binary.LittleEndian.PutUint16(packet[kcpCryptHeaderSize+4:], kcpFECTypeData)
+	binary.LittleEndian.PutUint16(packet[kcpCryptHeaderSize+kcpFECHeaderSize:], uint16(2+len(segment)))
+	packet = append(packet, segment...)
+	binary.LittleEndian.PutUint32(packet[kcpCryptHeaderSize-4:], crc32.ChecksumIEEE(packet[kcpCryptHeaderSize:]))
+	return packet
+}
@@ -0,0 +1,359 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package p2p
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+)
+
+var (
+	// ErrStreamClosed indicates that stream or underlying channel is closed.
+	ErrStreamClosed = errors.New("p2p stream closed")
+)
+
+const (
+	// streamChunkSize is max data size of the single stream message.
+	streamChunkSize = 16 * 1024
+
+	// streamWindowSize is the amount of data which can be sent without peer reading it.
+	streamWindowSize = 256 * 1024
+)
+
+// Stream represents bidirectional ordered byte stream opened over p2p channel.
+// Writes block when peer doesn't read the data fast enough.
+type Stream interface {
+	io.ReadWriteCloser
+
+	// Topic returns topic the stream was opened for.
+	Topic() string
+}
+
+// StreamHandlerFunc is channel stream handler func signature.
+type StreamHandlerFunc func(s Stream)
+
+// streamKey identifies stream in the channel, both peers assign ids for the streams they open.
+type streamKey struct {
+	id uint64
+	// local is true if stream was opened by this side of the channel.
+	local bool
+}
+
+// byteStream implements Stream interface.
+type byteStream struct {
+	key   streamKey
+	topic string
+	ch    *channel
+
+	// openCh receives peer reply for the stream opened by this side.
+	openCh chan *transportMsg
+
+	mu   sync.Mutex
+	cond *sync.Cond
+
+	readBuf bytes.Buffer
+	// consumed is amount of data read since the last window update sent to the peer.
+	consumed int
+	// sendWindow is amount of data which can be sent before peer reads it.
+	sendWindow int
+
+	// opened is true when peer reply for the stream opened by this side is received.
+	opened       bool
+	closed       bool
+	remoteClosed bool
+}
+
+func newByteStream(ch *channel, key streamKey, topic string) *byteStream {
+	s := &byteStream{
+		key:        key,
+		topic:      topic,
+		ch:         ch,
+		openCh:     make(chan *transportMsg, 1),
+		sendWindow: streamWindowSize,
+	}
+	s.cond = sync.NewCond(&s.mu)
+	return s
+}
+
+// Topic returns topic the stream was opened for.
+func (s *byteStream) Topic() string {
+	return s.topic
+}
+
+// Read reads data sent by peer. It returns io.EOF when peer closes the stream and all data is read.
+func (s *byteStream) Read(p []byte) (int, error) {
+	s.mu.Lock()
+	for s.readBuf.Len() == 0 && !s.remoteClosed && !s.closed {
+		s.cond.Wait()
+	}
+	if s.closed {
+		s.mu.Unlock()
+		return 0, ErrStreamClosed
+	}
+	if s.readBuf.Len() == 0 {
+		s.mu.Unlock()
+		return 0, io.EOF
+	}
+
+	n, _ := s.readBuf.Read(p)
+	s.consumed += n
+	var increment int
+	if s.consumed >= streamWindowSize/2 && !s.remoteClosed {
+		increment = s.consumed
+		s.consumed = 0
+	}
+	s.mu.Unlock()
+
+	if increment > 0 {
+		msg := s.msg(msgTypeStreamWindow)
+		msg.data = []byte(strconv.Itoa(increment))
+		if err := s.ch.sendMsg(msg); err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+// Write writes data to the peer in chunks. It blocks until peer has enough window to receive it.
+func (s *byteStream) Write(p []byte) (int, error) {
+	var n int
+	for len(p) > 0 {
+		s.mu.Lock()
+		for s.sendWindow == 0 && !s.remoteClosed && !s.closed {
+			s.cond.Wait()
+		}
+		if s.closed || s.remoteClosed {
+			s.mu.Unlock()
+			return n, ErrStreamClosed
+		}
+
+		size := len(p)
+		if size > s.sendWindow {
+			size = s.sendWindow
+		}
+		if size > streamChunkSize {
+			size = streamChunkSize
+		}
+		s.sendWindow -= size
+		s.mu.Unlock()
+
+		// Data is encoded as text protocol doesn't preserve line endings of the binary data.
+		msg := s.msg(msgTypeStreamData)
+		msg.data = []byte(base64.StdEncoding.EncodeToString(p[:size]))
+		if err := s.ch.sendMsg(msg); err != nil {
+			return n, err
+		}
+		n += size
+		p = p[size:]
+	}
+	return n, nil
+}
+
+// Close closes the stream for both reading and writing, peer reads the remaining data and gets io.EOF.
+func (s *byteStream) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	remoteClosed := s.remoteClosed
+	s.cond.Broadcast()
+	s.mu.Unlock()
+
+	s.ch.deleteByteStream(s.key)
+	if remoteClosed {
+		return nil
+	}
+	return s.ch.sendMsg(s.msg(msgTypeStreamClose))
+}
+
+// handleMsg processes stream messages sent by peer.
+func (s *byteStream) handleMsg(msg *transportMsg) {
+	switch msg.msgType {
+	case msgTypeStreamOpenReply:
+		s.mu.Lock()
+		if !s.key.local || s.opened {
+			s.mu.Unlock()
+			log.Warn().Msgf("Unexpected open reply for stream %d, dropping it", s.key.id)
+			return
+		}
+		s.opened = true
+		s.mu.Unlock()
+		// Don't block the channel when nobody waits for the reply anymore.
+		select {
+		case s.openCh <- msg:
+		default:
+		}
+	case msgTypeStreamData:
+		data, err := base64.StdEncoding.DecodeString(string(msg.data))
+		if err != nil {
+			log.Warn().Err(err).Msgf("Invalid data for stream %d", s.key.id)
+			return
+		}
+		s.mu.Lock()
+		// Data not acknowledged with window update can't exceed the window advertised to the peer.
+		if s.readBuf.Len()+s.consumed+len(data) > streamWindowSize {
+			s.mu.Unlock()
+			log.Warn().Msgf("Peer exceeded window of stream %d, closing it", s.key.id)
+			go s.Close()
+			return
+		}
+		s.readBuf.Write(data)
+		s.cond.Broadcast()
+		s.mu.Unlock()
+	case msgTypeStreamWindow:
+		increment, err := strconv.Atoi(string(msg.data))
+		if err != nil {
+			log.Warn().Err(err).Msgf("Invalid window update for stream %d", s.key.id)
+			return
+		}
+		s.mu.Lock()
+		s.sendWindow += increment
+		s.cond.Broadcast()
+		s.mu.Unlock()
+	case msgTypeStreamClose:
+		s.remoteClose()
+	}
+}
+
+// remoteClose marks stream as closed by the peer or by closed channel.
+func (s *byteStream) remoteClose() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.remoteClosed = true
+	s.cond.Broadcast()
+}
+
+func (s *byteStream) msg(msgType uint64) *transportMsg {
+	return &transportMsg{
+		id:        s.key.id,
+		msgType:   msgType,
+		initiator: s.key.local,
+	}
+}
+
+// OpenStream opens byte stream to given topic. Peer must register stream handler for the topic with HandleStream.
+func (c *channel) OpenStream(ctx context.Context, topic string) (Stream, error) {
+	c.mu.Lock()
+	c.nextByteStreamID++
+	s := newByteStream(c, streamKey{id: c.nextByteStreamID, local: true}, topic)
+	c.byteStreams[s.key] = s
+	c.mu.Unlock()
+
+	openMsg := s.msg(msgTypeStreamOpen)
+	openMsg.topic = topic
+	if err := c.sendMsg(openMsg); err != nil {
+		c.deleteByteStream(s.key)
+		return nil, err
+	}
+
+	select {
+	case <-ctx.Done():
+		c.deleteByteStream(s.key)
+		return nil, fmt.Errorf("timeout waiting for stream %q to open: %w", topic, ErrSendTimeout)
+	case <-c.stop:
+		return nil, ErrStreamClosed
+	case res := <-s.openCh:
+		if res.statusCode != statusCodeOK {
+			c.deleteByteStream(s.key)
+			return nil, fmt.Errorf("%s: %w", string(res.data), ErrHandlerNotFound)
+		}
+		return s, nil
+	}
+}
+
+// HandleStream registers handler for the streams opened by peer to given topic.
+func (c *channel) HandleStream(topic string, handler StreamHandlerFunc) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.streamHandlers[topic] = handler
+}
+
+// handleStreamMsg dispatches stream message to the stream. It is called from the read loop
+// so stream messages are processed in the order they were sent.
+func (c *channel) handleStreamMsg(msg *transportMsg) {
+	key := streamKey{id: msg.id, local: !msg.initiator}
+	if msg.msgType == msgTypeStreamOpen {
+		c.handleStreamOpen(key, msg.topic)
+		return
+	}
+
+	c.mu.RLock()
+	s, ok := c.byteStreams[key]
+	c.mu.RUnlock()
+	if !ok {
+		log.Debug().Msgf("Stream %d not found, dropping message of type %d", msg.id, msg.msgType)
+		return
+	}
+	s.handleMsg(msg)
+}
+
+func (c *channel) handleStreamOpen(key streamKey, topic string) {
+	c.mu.Lock()
+	handler, ok := c.streamHandlers[topic]
+	var s *byteStream
+	if ok {
+		s = newByteStream(c, key, topic)
+		c.byteStreams[key] = s
+	}
+	c.mu.Unlock()
+
+	reply := &transportMsg{id: key.id, msgType: msgTypeStreamOpenReply, statusCode: statusCodeOK}
+	if !ok {
+		errMsg := fmt.Sprintf("stream handler %q not found", topic)
+		log.Error().Msg(errMsg)
+		reply.statusCode = statusCodeHandlerNotFoundErr
+		reply.data = []byte(errMsg)
+	}
+
+	// Reply is sent before handler starts writing so peer receives it first.
+	go func() {
+		if err := c.sendMsg(reply); err != nil {
+			return
+		}
+		if s != nil {
+			handler(s)
+		}
+	}()
+}
+
+func (c *channel) deleteByteStream(key streamKey) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.byteStreams, key)
+}
+
+// sendMsg puts message to the send queue unless channel is closed.
+func (c *channel) sendMsg(msg *transportMsg) error {
+	select {
+	case <-c.stop:
+		return ErrStreamClosed
+	case c.sendQueue <- msg:
+		return nil
+	}
+}
@@ -0,0 +1,127 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package p2p
+
+import (
+	"context"
+	"net"
+	"sync"
+)
+
+// SwitchableChannel is a Channel which delegates to the underlying channel which can be
+// replaced during its lifetime. Handlers registered on it are kept and re-registered
+// on the new underlying channel, so the session bound to it can continue over a new
+// p2p channel after the peer network changes.
+type SwitchableChannel struct {
+	mu             sync.RWMutex
+	ch             Channel
+	handlers       map[string]HandlerFunc
+	streamHandlers map[string]StreamHandlerFunc
+}
+
+// NewSwitchableChannel returns new switchable channel delegating to the given channel.
+func NewSwitchableChannel(ch Channel) *SwitchableChannel {
+	return &SwitchableChannel{
+		ch:             ch,
+		handlers:       make(map[string]HandlerFunc),
+		streamHandlers: make(map[string]StreamHandlerFunc),
+	}
+}
+
+// Switch replaces the underlying channel with the given one and returns the previous one.
+// Switchable channel given is unwrapped, so that channels are not nested.
+// Caller is responsible for closing the previous channel.
+func (s *SwitchableChannel) Switch(ch Channel) Channel {
+	if switchable, ok := ch.(*SwitchableChannel); ok {
+		ch = switchable.current()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for topic, handler := range s.handlers {
+		ch.Handle(topic, handler)
+	}
+	for topic, handler := range s.streamHandlers {
+		ch.HandleStream(topic, handler)
+	}
+
+	old := s.ch
+	s.ch = ch
+	return old
+}
+
+// Send sends message to given topic using current underlying channel.
+func (s *SwitchableChannel) Send(ctx context.Context, topic string, msg *Message) (*Message, error) {
+	return s.current().Send(ctx, topic, msg)
+}
+
+// Publish sends message to given topic without waiting for peer reply using current underlying channel.
+func (s *SwitchableChannel) Publish(ctx context.Context, topic string, msg *Message) error {
+	return s.current().Publish(ctx, topic, msg)
+}
+
+// OpenStream opens bidirectional byte stream to given topic using current underlying channel.
+func (s *SwitchableChannel) OpenStream(ctx context.Context, topic string) (Stream, error) {
+	return s.current().OpenStream(ctx, topic)
+}
+
+// Handle registers handler for given topic on current and all future underlying channels.
+func (s *SwitchableChannel) Handle(topic string, handler HandlerFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.handlers[topic] = handler
+	s.ch.Handle(topic, handler)
+}
+
+// HandleStream registers stream handler for given topic on current and all future underlying channels.
+func (s *SwitchableChannel) HandleStream(topic string, handler StreamHandlerFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.streamHandlers[topic] = handler
+	s.ch.HandleStream(topic, handler)
+}
+
+// ServiceConn returns UDP connection of current underlying channel which can be used for services.
+func (s *SwitchableChannel) ServiceConn() *net.UDPConn {
+	return s.current().ServiceConn()
+}
+
+// Conn returns UDP connection of current underlying channel.
+func (s *SwitchableChannel) Conn() *net.UDPConn {
+	return s.current().Conn()
+}
+
+// Stats returns transport statistics of current underlying channel.
+func (s *SwitchableChannel) Stats() ChannelStats {
+	return s.current().Stats()
+}
+
+// Close closes current underlying channel.
+func (s *SwitchableChannel) Close() error {
+	return s.current().Close()
+}
+
+func (s *SwitchableChannel) current() Channel {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.ch
+}
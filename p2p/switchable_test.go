@@ -0,0 +1,57 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package p2p
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSwitchableChannel_KeepsHandlersAfterSwitch(t *testing.T) {
+	provider1, consumer1, err := createTestChannels()
+	require.NoError(t, err)
+	provider2, consumer2, err := createTestChannels()
+	require.NoError(t, err)
+	defer provider2.Close()
+	defer consumer2.Close()
+
+	provider := NewSwitchableChannel(provider1)
+	consumer := NewSwitchableChannel(consumer1)
+	provider.Handle("test", func(c Context) error {
+		return c.OkWithReply(&Message{Data: append([]byte("pong "), c.Request().Data...)})
+	})
+
+	res, err := consumer.Send(context.Background(), "test", &Message{Data: []byte("1")})
+	require.NoError(t, err)
+	assert.Equal(t, "pong 1", string(res.Data))
+
+	// Switchable channel of the new session manager is unwrapped.
+	assert.Equal(t, provider1, provider.Switch(NewSwitchableChannel(provider2)))
+	assert.Equal(t, provider2, provider.current())
+	assert.Equal(t, consumer1, consumer.Switch(consumer2))
+	require.NoError(t, provider1.Close())
+	require.NoError(t, consumer1.Close())
+
+	res, err = consumer.Send(context.Background(), "test", &Message{Data: []byte("2")})
+	require.NoError(t, err)
+	assert.Equal(t, "pong 2", string(res.Data))
+	assert.Equal(t, consumer2.Conn(), consumer.Conn())
+}
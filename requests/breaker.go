@@ -0,0 +1,170 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package requests
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned when requests to the host are blocked by the circuit breaker.
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// CircuitState represents the state of a circuit breaker.
+type CircuitState string
+
+const (
+	// CircuitClosed means that requests flow normally.
+	CircuitClosed = CircuitState("closed")
+	// CircuitOpen means that requests are rejected without reaching the host.
+	CircuitOpen = CircuitState("open")
+	// CircuitHalfOpen means that a single probe request is allowed to check if the host has recovered.
+	CircuitHalfOpen = CircuitState("half_open")
+)
+
+// CircuitBreakerConfig describes when circuit breakers trip.
+type CircuitBreakerConfig struct {
+	// FailureThreshold is a number of consecutive failures which opens the circuit.
+	FailureThreshold int
+	// OpenTimeout is a duration after which an open circuit lets a probe request through.
+	OpenTimeout time.Duration
+}
+
+// DefaultCircuitBreakerConfig is a default configuration of circuit breakers.
+var DefaultCircuitBreakerConfig = CircuitBreakerConfig{
+	FailureThreshold: 5,
+	OpenTimeout:      30 * time.Second,
+}
+
+// CircuitStatus describes the circuit breaker state of a single host.
+type CircuitStatus struct {
+	Host     string
+	State    CircuitState
+	Failures int
+	OpenedAt time.Time
+}
+
+type circuit struct {
+	state    CircuitState
+	failures int
+	openedAt time.Time
+}
+
+// CircuitBreakers keeps a circuit breaker per host.
+type CircuitBreakers struct {
+	config   CircuitBreakerConfig
+	now      func() time.Time
+	mu       sync.Mutex
+	circuits map[string]*circuit
+}
+
+// NewCircuitBreakers creates per host circuit breakers.
+func NewCircuitBreakers(config CircuitBreakerConfig) *CircuitBreakers {
+	return &CircuitBreakers{
+		config:   config,
+		now:      time.Now,
+		circuits: make(map[string]*circuit),
+	}
+}
+
+// Allow checks if a request to the host may be sent.
+func (cb *CircuitBreakers) Allow(host string) error {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	c := cb.circuit(host)
+	switch c.state {
+	case CircuitOpen:
+		if cb.now().Sub(c.openedAt) < cb.config.OpenTimeout {
+			return ErrCircuitOpen
+		}
+		c.state = CircuitHalfOpen
+		return nil
+	case CircuitHalfOpen:
+		// Probe request is already in flight.
+		return ErrCircuitOpen
+	default:
+		return nil
+	}
+}
+
+// Success records a successful request to the host.
+func (cb *CircuitBreakers) Success(host string) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	c := cb.circuit(host)
+	c.state = CircuitClosed
+	c.failures = 0
+}
+
+// Failure records a failed request to the host.
+func (cb *CircuitBreakers) Failure(host string) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	c := cb.circuit(host)
+	c.failures++
+	if c.state == CircuitHalfOpen || c.failures >= cb.config.FailureThreshold {
+		c.state = CircuitOpen
+		c.openedAt = cb.now()
+	}
+}
+
+// Abort releases a request which was cancelled before its outcome was known.
+func (cb *CircuitBreakers) Abort(host string) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	c := cb.circuit(host)
+	if c.state == CircuitHalfOpen {
+		c.state = CircuitOpen
+		c.openedAt = cb.now()
+	}
+}
+
+// Status returns circuit breaker states of all known hosts.
+func (cb *CircuitBreakers) Status() []CircuitStatus {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	result := make([]CircuitStatus, 0, len(cb.circuits))
+	for host, c := range cb.circuits {
+		result = append(result, CircuitStatus{
+			Host:     host,
+			State:    c.state,
+			Failures: c.failures,
+			OpenedAt: c.openedAt,
+		})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Host < result[j].Host
+	})
+	return result
+}
+
+func (cb *CircuitBreakers) circuit(host string) *circuit {
+	c, ok := cb.circuits[host]
+	if !ok {
+		c = &circuit{state: CircuitClosed}
+		cb.circuits[host] = c
+	}
+	return c
+}
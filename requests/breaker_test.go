@@ -0,0 +1,68 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package requests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCircuitBreakers(t *testing.T) {
+	now := time.Now()
+	breakers := NewCircuitBreakers(CircuitBreakerConfig{FailureThreshold: 2, OpenTimeout: time.Minute})
+	breakers.now = func() time.Time { return now }
+
+	assert.NoError(t, breakers.Allow("a"))
+	breakers.Failure("a")
+	assert.NoError(t, breakers.Allow("a"))
+	breakers.Failure("a")
+	assert.Equal(t, ErrCircuitOpen, breakers.Allow("a"))
+	assert.NoError(t, breakers.Allow("b"), "other hosts are not affected")
+
+	now = now.Add(time.Minute)
+	assert.NoError(t, breakers.Allow("a"), "probe request is allowed")
+	assert.Equal(t, ErrCircuitOpen, breakers.Allow("a"), "single probe at a time")
+	breakers.Failure("a")
+	assert.Equal(t, ErrCircuitOpen, breakers.Allow("a"))
+
+	now = now.Add(time.Minute)
+	assert.NoError(t, breakers.Allow("a"))
+	breakers.Success("a")
+	assert.NoError(t, breakers.Allow("a"))
+
+	assert.Equal(t, []CircuitStatus{
+		{Host: "a", State: CircuitClosed, Failures: 0, OpenedAt: now.Add(-time.Minute)},
+		{Host: "b", State: CircuitClosed},
+	}, breakers.Status())
+}
+
+func TestCircuitBreakers_AbortedProbeReopensCircuit(t *testing.T) {
+	now := time.Now()
+	breakers := NewCircuitBreakers(CircuitBreakerConfig{FailureThreshold: 1, OpenTimeout: time.Minute})
+	breakers.now = func() time.Time { return now }
+
+	breakers.Failure("a")
+	now = now.Add(time.Minute)
+	assert.NoError(t, breakers.Allow("a"))
+	breakers.Abort("a")
+
+	assert.Equal(t, ErrCircuitOpen, breakers.Allow("a"), "open timeout restarts after aborted probe")
+	assert.Equal(t, now, breakers.Status()[0].OpenedAt)
+}
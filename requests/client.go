@@ -18,7 +18,9 @@
 package requests
 
 import (
+	"context"
 	"encoding/json"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"sync"
@@ -35,11 +37,23 @@ const (
 
 // NewHTTPClient creates a new HTTP client.
 func NewHTTPClient(srcIP string, timeout time.Duration) *HTTPClient {
+	return NewHTTPClientWithProxy(srcIP, timeout, nil)
+}
+
+// NewHTTPClientWithProxy creates a new HTTP client which sends requests through the given upstream proxy.
+func NewHTTPClientWithProxy(srcIP string, timeout time.Duration, proxy *Proxy) *HTTPClient {
 	c := &HTTPClient{
+		proxy: proxy,
+		retryPolicies: map[CallClass]RetryPolicy{
+			CallIdempotent:    RetryPolicyIdempotent,
+			CallNonIdempotent: RetryPolicyNonIdempotent,
+		},
+		breakers: NewCircuitBreakers(DefaultCircuitBreakerConfig),
+		stop:     make(chan struct{}),
 		clientFactory: func() *http.Client {
 			return &http.Client{
 				Timeout:   timeout,
-				Transport: GetDefaultTransport(srcIP),
+				Transport: GetDefaultTransport(srcIP, proxy),
 			}
 		},
 	}
@@ -53,11 +67,177 @@ type HTTPClient struct {
 	client        *http.Client
 	clientMu      sync.Mutex
 	clientFactory func() *http.Client
+	proxy         *Proxy
+	breakers      *CircuitBreakers
+
+	policiesMu    sync.Mutex
+	retryPolicies map[CallClass]RetryPolicy
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// Proxy returns upstream proxy used by the client, nil if requests are sent directly.
+func (c *HTTPClient) Proxy() *Proxy {
+	return c.proxy
+}
+
+// SetRetryPolicy sets retry policy for the given class of requests.
+func (c *HTTPClient) SetRetryPolicy(class CallClass, policy RetryPolicy) {
+	c.policiesMu.Lock()
+	defer c.policiesMu.Unlock()
+	c.retryPolicies[class] = policy
+}
+
+// SetCircuitBreakerConfig replaces circuit breakers of the client with the new ones using given config.
+func (c *HTTPClient) SetCircuitBreakerConfig(config CircuitBreakerConfig) {
+	c.policiesMu.Lock()
+	defer c.policiesMu.Unlock()
+	c.breakers = NewCircuitBreakers(config)
+}
+
+// CircuitStatus returns circuit breaker states of hosts contacted by the client.
+func (c *HTTPClient) CircuitStatus() []CircuitStatus {
+	return c.circuitBreakers().Status()
+}
+
+// Stop cancels all outstanding requests. Requests made afterwards are sent without retries.
+func (c *HTTPClient) Stop() {
+	c.stopOnce.Do(func() {
+		close(c.stop)
+	})
 }
 
 // Do sends an HTTP request and returns an HTTP response.
+// Requests marked with WithCallClass are guarded by circuit breakers and retried according
+// to the retry policy of their call class, other requests are sent once.
 func (c *HTTPClient) Do(req *http.Request) (*http.Response, error) {
-	return c.resolveClient().Do(req)
+	ctx, cancel := c.requestContext(req.Context())
+	req = req.WithContext(ctx)
+
+	class, ok := callClassOf(req)
+	if !ok {
+		res, err := c.resolveClient().Do(req)
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		res.Body = &cancelOnClose{ReadCloser: res.Body, cancel: cancel}
+		return res, nil
+	}
+
+	policy := c.retryPolicy(class)
+	breakers := c.circuitBreakers()
+	host := req.URL.Host
+	boff := policy.newBackOff()
+
+	for attempt := 1; ; attempt++ {
+		if err := breakers.Allow(host); err != nil {
+			cancel()
+			return nil, errors.Wrapf(err, "request to %s blocked", host)
+		}
+
+		res, err := c.attempt(breakers, host, req)
+		if err != nil && ctx.Err() != nil {
+			cancel()
+			return nil, err
+		}
+
+		wait := boff.NextBackOff()
+		if !policy.shouldRetry(attempt, res, err) || !canRewind(req) {
+			if err != nil {
+				cancel()
+				return nil, err
+			}
+			res.Body = &cancelOnClose{ReadCloser: res.Body, cancel: cancel}
+			return res, nil
+		}
+
+		if res != nil {
+			io.Copy(ioutil.Discard, res.Body)
+			res.Body.Close()
+		}
+		if req.GetBody != nil {
+			if req.Body, err = req.GetBody(); err != nil {
+				cancel()
+				return nil, err
+			}
+		}
+
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			cancel()
+			return nil, ctx.Err()
+		}
+	}
+}
+
+func (c *HTTPClient) attempt(breakers *CircuitBreakers, host string, req *http.Request) (*http.Response, error) {
+	res, err := c.resolveClient().Do(req)
+	switch {
+	case err != nil && req.Context().Err() != nil:
+		breakers.Abort(host)
+	case err != nil || res.StatusCode >= http.StatusInternalServerError:
+		breakers.Failure(host)
+	default:
+		breakers.Success(host)
+	}
+	return res, err
+}
+
+func (c *HTTPClient) requestContext(parent context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(parent)
+	select {
+	case <-c.stop:
+		return ctx, cancel
+	default:
+	}
+
+	go func() {
+		select {
+		case <-c.stop:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, cancel
+}
+
+func (c *HTTPClient) retryPolicy(class CallClass) RetryPolicy {
+	select {
+	case <-c.stop:
+		return RetryPolicyNone
+	default:
+	}
+
+	c.policiesMu.Lock()
+	defer c.policiesMu.Unlock()
+	if policy, ok := c.retryPolicies[class]; ok {
+		return policy
+	}
+	return RetryPolicyNone
+}
+
+func (c *HTTPClient) circuitBreakers() *CircuitBreakers {
+	c.policiesMu.Lock()
+	defer c.policiesMu.Unlock()
+	return c.breakers
+}
+
+func canRewind(req *http.Request) bool {
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// cancelOnClose releases the request context once response body is consumed.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c *cancelOnClose) Close() error {
+	defer c.cancel()
+	return c.ReadCloser.Close()
 }
 
 // DoRequest performs HTTP requests and parses error without returning response.
@@ -0,0 +1,138 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package requests
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+)
+
+// CallClass groups requests which share the same retry policy.
+type CallClass string
+
+const (
+	// CallIdempotent is a class of requests which can be safely repeated, e.g. GET, PUT or DELETE.
+	CallIdempotent = CallClass("idempotent")
+	// CallNonIdempotent is a class of requests which must not be repeated once they reached the server, e.g. POST.
+	CallNonIdempotent = CallClass("non_idempotent")
+)
+
+// RetryPolicy describes how failed requests are retried.
+type RetryPolicy struct {
+	// MaxAttempts is a total number of attempts including the first one.
+	MaxAttempts int
+	// InitialInterval is a delay before the first retry.
+	InitialInterval time.Duration
+	// MaxInterval caps the delay between retries.
+	MaxInterval time.Duration
+	// Multiplier increases the delay after each retry.
+	Multiplier float64
+	// Jitter randomizes each delay by the given factor, e.g. 0.5 gives delay in range [0.5*d, 1.5*d].
+	Jitter float64
+	// Retryable decides if request should be retried after the given response or error.
+	Retryable func(res *http.Response, err error) bool
+}
+
+var (
+	// RetryPolicyNone performs a single attempt.
+	RetryPolicyNone = RetryPolicy{MaxAttempts: 1}
+
+	// RetryPolicyIdempotent retries network errors and temporary server failures.
+	RetryPolicyIdempotent = RetryPolicy{
+		MaxAttempts:     3,
+		InitialInterval: 200 * time.Millisecond,
+		MaxInterval:     2 * time.Second,
+		Multiplier:      2,
+		Jitter:          0.5,
+		Retryable:       isTemporaryFailure,
+	}
+
+	// RetryPolicyNonIdempotent retries only requests which never reached the server.
+	RetryPolicyNonIdempotent = RetryPolicy{
+		MaxAttempts:     3,
+		InitialInterval: 200 * time.Millisecond,
+		MaxInterval:     2 * time.Second,
+		Multiplier:      2,
+		Jitter:          0.5,
+		Retryable:       isNotSent,
+	}
+)
+
+func (p RetryPolicy) shouldRetry(attempt int, res *http.Response, err error) bool {
+	if attempt >= p.MaxAttempts || p.Retryable == nil {
+		return false
+	}
+	return p.Retryable(res, err)
+}
+
+func (p RetryPolicy) newBackOff() backoff.BackOff {
+	b := backoff.NewExponentialBackOff()
+	b.InitialInterval = p.InitialInterval
+	b.MaxInterval = p.MaxInterval
+	b.Multiplier = p.Multiplier
+	b.RandomizationFactor = p.Jitter
+	b.MaxElapsedTime = 0
+	b.Reset()
+	return b
+}
+
+// WithCallClass opts request in to retries and circuit breaking using the policy of the given call class.
+// Requests without a call class are sent once and do not affect circuit breakers.
+func WithCallClass(req *http.Request, class CallClass) *http.Request {
+	return req.WithContext(contextWithCallClass(req.Context(), class))
+}
+
+type callClassKey struct{}
+
+func contextWithCallClass(ctx context.Context, class CallClass) context.Context {
+	return context.WithValue(ctx, callClassKey{}, class)
+}
+
+func callClassOf(req *http.Request) (CallClass, bool) {
+	class, ok := req.Context().Value(callClassKey{}).(CallClass)
+	return class, ok
+}
+
+func isTemporaryFailure(res *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+
+	switch res.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+func isNotSent(_ *http.Response, err error) bool {
+	var urlErr *url.Error
+	if !errors.As(err, &urlErr) {
+		return false
+	}
+
+	var opErr *net.OpError
+	return errors.As(urlErr.Err, &opErr) && opErr.Op == "dial"
+}
@@ -0,0 +1,211 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package requests
+
+import (
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var fastRetryPolicy = RetryPolicy{
+	MaxAttempts:     3,
+	InitialInterval: time.Millisecond,
+	MaxInterval:     time.Millisecond,
+	Multiplier:      1,
+	Retryable:       isTemporaryFailure,
+}
+
+func TestHTTPClient_RetriesIdempotentRequests(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("OK"))
+	}))
+	defer server.Close()
+
+	httpClient := NewHTTPClient("0.0.0.0", DefaultTimeout)
+	httpClient.SetRetryPolicy(CallIdempotent, fastRetryPolicy)
+
+	req, err := NewGetRequest(server.URL, "", nil)
+	require.NoError(t, err)
+	res, err := httpClient.Do(WithCallClass(req, CallIdempotent))
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+}
+
+func TestHTTPClient_DoesNotRetryNonIdempotentRequestsWhichReachedServer(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	httpClient := NewHTTPClient("0.0.0.0", DefaultTimeout)
+	policy := fastRetryPolicy
+	policy.Retryable = isNotSent
+	httpClient.SetRetryPolicy(CallNonIdempotent, policy)
+
+	req, err := NewPostRequest(server.URL, "", map[string]string{"key": "value"})
+	require.NoError(t, err)
+	res, err := httpClient.Do(WithCallClass(req, CallNonIdempotent))
+	require.NoError(t, err)
+	res.Body.Close()
+
+	assert.Equal(t, http.StatusServiceUnavailable, res.StatusCode)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestHTTPClient_RetriesRequestBody(t *testing.T) {
+	var calls int32
+	var lastBody string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		lastBody = string(body)
+		if atomic.AddInt32(&calls, 1) < 2 {
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer server.Close()
+
+	httpClient := NewHTTPClient("0.0.0.0", DefaultTimeout)
+	httpClient.SetRetryPolicy(CallIdempotent, fastRetryPolicy)
+
+	req, err := NewPostRequest(server.URL, "", map[string]string{"key": "value"})
+	require.NoError(t, err)
+	err = httpClient.DoRequest(WithCallClass(req, CallIdempotent))
+	require.NoError(t, err)
+
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+	assert.Equal(t, `{"key":"value"}`, lastBody)
+}
+
+func TestHTTPClient_RetriesNonIdempotentRequestsWhichWereNotSent(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	address := listener.Addr().String()
+	listener.Close()
+
+	httpClient := NewHTTPClient("0.0.0.0", DefaultTimeout)
+	policy := fastRetryPolicy
+	policy.Retryable = isNotSent
+	httpClient.SetRetryPolicy(CallNonIdempotent, policy)
+	httpClient.SetCircuitBreakerConfig(CircuitBreakerConfig{FailureThreshold: 10, OpenTimeout: time.Minute})
+
+	req, err := NewPostRequest("http://"+address, "", nil)
+	require.NoError(t, err)
+	_, err = httpClient.Do(WithCallClass(req, CallNonIdempotent))
+	assert.Error(t, err)
+
+	status := httpClient.CircuitStatus()
+	require.Len(t, status, 1)
+	assert.Equal(t, 3, status[0].Failures)
+}
+
+func TestHTTPClient_OpensCircuitAfterFailures(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	httpClient := NewHTTPClient("0.0.0.0", DefaultTimeout)
+	httpClient.SetCircuitBreakerConfig(CircuitBreakerConfig{FailureThreshold: 2, OpenTimeout: time.Minute})
+
+	for i := 0; i < 3; i++ {
+		req, err := NewGetRequest(server.URL, "", nil)
+		require.NoError(t, err)
+		err = httpClient.DoRequest(WithCallClass(req, CallIdempotent))
+		assert.Error(t, err)
+	}
+
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+	status := httpClient.CircuitStatus()
+	require.Len(t, status, 1)
+	assert.Equal(t, CircuitOpen, status[0].State)
+}
+
+func TestHTTPClient_StopCancelsOutstandingRequests(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	httpClient := NewHTTPClient("0.0.0.0", DefaultTimeout)
+
+	done := make(chan error)
+	go func() {
+		req, _ := NewGetRequest(server.URL, "", nil)
+		_, err := httpClient.Do(WithCallClass(req, CallIdempotent))
+		done <- err
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	httpClient.Stop()
+
+	select {
+	case err := <-done:
+		assert.Error(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("request was not cancelled")
+	}
+	assert.Equal(t, CircuitClosed, httpClient.CircuitStatus()[0].State)
+}
+
+func TestHTTPClient_SendsUnmarkedRequestsOnceWithoutCircuitBreaker(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	httpClient := NewHTTPClient("0.0.0.0", DefaultTimeout)
+	httpClient.SetRetryPolicy(CallIdempotent, fastRetryPolicy)
+	httpClient.SetCircuitBreakerConfig(CircuitBreakerConfig{FailureThreshold: 1, OpenTimeout: time.Minute})
+
+	for i := 0; i < 2; i++ {
+		req, err := NewGetRequest(server.URL, "", nil)
+		require.NoError(t, err)
+		err = httpClient.DoRequest(req)
+		assert.Error(t, err)
+	}
+
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+	assert.Empty(t, httpClient.CircuitStatus())
+}
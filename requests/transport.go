@@ -27,11 +27,12 @@ import (
 // should be reused as it caches underlying TCP connections.
 // If connections pooling is not needed consider to set
 // DisableKeepAlives=false and MaxIdleConnsPerHost=-1.
-func GetDefaultTransport(srcIP string) *http.Transport {
+// Requests are sent through the upstream proxy if it is given.
+func GetDefaultTransport(srcIP string, proxy *Proxy) *http.Transport {
 	ipAddress := net.ParseIP(srcIP)
 	localIPAddress := &net.TCPAddr{IP: ipAddress}
 
-	return &http.Transport{
+	transport := &http.Transport{
 		DialContext: (&net.Dialer{
 			Timeout:   60 * time.Second,
 			KeepAlive: 30 * time.Second,
@@ -43,4 +44,8 @@ func GetDefaultTransport(srcIP string) *http.Transport {
 		TLSHandshakeTimeout:   30 * time.Second,
 		ExpectContinueTimeout: 1 * time.Second,
 	}
+	if proxy != nil {
+		transport.Proxy = proxy.transportProxy
+	}
+	return transport
 }
@@ -34,6 +34,7 @@ func NewConnection() (connection.Connection, error) {
 
 // Connection which does no real tunneling
 type Connection struct {
+	mu             sync.Mutex
 	isRunning      bool
 	noopConnection sync.WaitGroup
 	stateCh        chan connection.State
@@ -53,8 +54,10 @@ func (c *Connection) Statistics() (connection.Statistics, error) {
 
 // Start implements the connection.Connection interface
 func (c *Connection) Start(ctx context.Context, params connection.ConnectOptions) error {
+	c.mu.Lock()
 	c.noopConnection.Add(1)
 	c.isRunning = true
+	c.mu.Unlock()
 
 	c.stateCh <- connection.Connecting
 
@@ -65,7 +68,11 @@ func (c *Connection) Start(ctx context.Context, params connection.ConnectOptions
 
 // Wait implements the connection.Connection interface
 func (c *Connection) Wait() error {
-	if c.isRunning {
+	c.mu.Lock()
+	isRunning := c.isRunning
+	c.mu.Unlock()
+
+	if isRunning {
 		c.noopConnection.Wait()
 	}
 	return nil
@@ -73,11 +80,14 @@ func (c *Connection) Wait() error {
 
 // Stop implements the connection.Connection interface
 func (c *Connection) Stop() {
+	c.mu.Lock()
 	if !c.isRunning {
+		c.mu.Unlock()
 		return
 	}
-
 	c.isRunning = false
+	c.mu.Unlock()
+
 	c.stateCh <- connection.Disconnecting
 	time.Sleep(2 * time.Second)
 	c.stateCh <- connection.NotConnected
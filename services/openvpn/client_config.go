@@ -102,6 +102,8 @@ func NewClientConfigFromSession(vpnConfig VPNConfig, configDir string, runtimeDi
 	var remotePort, localPort int
 	if options.ProviderNATConn != nil && vpnConfig.RemoteIP != "127.0.0.1" {
 		options.ProviderNATConn.Close()
+		// Peer connection may go via relay, so its remote address is used instead of the provider one.
+		vpnConfig.RemoteIP = options.ProviderNATConn.RemoteAddr().(*net.UDPAddr).IP.String()
 		remotePort = options.ProviderNATConn.RemoteAddr().(*net.UDPAddr).Port
 		localPort = options.ProviderNATConn.LocalAddr().(*net.UDPAddr).Port
 	} else {
@@ -345,7 +345,7 @@ func (m *Manager) portMappingFailed() bool {
 	if lastEvent.Stage == traversal.StageName {
 		return true
 	}
-	return lastEvent.Stage == mapping.StageName && !lastEvent.Successful
+	return (lastEvent.Stage == mapping.StageName || lastEvent.Stage == mapping.PCPStageName) && !lastEvent.Successful
 }
 
 func (m *Manager) behindNAT(pubIP string) bool {
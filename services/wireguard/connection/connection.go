@@ -49,10 +49,16 @@ func NewConnection(opts Options, ipResolver ip.Resolver, natPinger traversal.NAT
 		return nil, errors.Wrap(err, "could not generate private key")
 	}
 
+	presharedKey, err := key.GeneratePresharedKey()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not generate preshared key")
+	}
+
 	return &Connection{
 		done:                make(chan struct{}),
 		stateCh:             make(chan connection.State, 100),
 		privateKey:          privateKey,
+		presharedKey:        presharedKey,
 		opts:                opts,
 		ipResolver:          ipResolver,
 		natPinger:           natPinger,
@@ -70,6 +76,10 @@ type Connection struct {
 
 	ports               []int
 	privateKey          string
+	presharedKey        string
+	keysMu              sync.Mutex
+	providerPeer        wg.Peer
+	sharedEndpoint      bool
 	ipResolver          ip.Resolver
 	connectionEndpoint  wg.ConnectionEndpoint
 	removeAllowedIPRule func()
@@ -81,6 +91,8 @@ type Connection struct {
 }
 
 var _ connection.Connection = &Connection{}
+var _ connection.KeyRotator = &Connection{}
+var _ connection.ServiceConnUpdater = &Connection{}
 
 // State returns connection state channel.
 func (c *Connection) State() <-chan connection.State {
@@ -121,10 +133,21 @@ func (c *Connection) Start(ctx context.Context, options connection.ConnectOption
 
 	c.stateCh <- connection.Connecting
 
+	if !config.Consumer.PresharedKey {
+		// Provider does not know the preshared key, handshake would never succeed with it.
+		c.presharedKey = ""
+	}
+
 	if options.ProviderNATConn != nil {
 		options.ProviderNATConn.Close()
 		config.LocalPort = options.ProviderNATConn.LocalAddr().(*net.UDPAddr).Port
-		config.Provider.Endpoint.Port = options.ProviderNATConn.RemoteAddr().(*net.UDPAddr).Port
+		if !config.Provider.SharedEndpoint {
+			if remoteIP := options.ProviderNATConn.RemoteAddr().(*net.UDPAddr).IP; !remoteIP.IsLoopback() {
+				// Peer connection may go via relay, so its remote address is used instead of the provider one.
+				config.Provider.Endpoint.IP = remoteIP
+			}
+			config.Provider.Endpoint.Port = options.ProviderNATConn.RemoteAddr().(*net.UDPAddr).Port
+		}
 	} else if len(config.Ports) > 0 { // TODO this backward compatibility block needs to be removed once we migrate to the p2p communication.
 		ip := config.Provider.Endpoint.IP.String()
 		lPort, rPort, err := c.natPinger.PingProvider(ctx, ip, c.ports, config.Ports, 0)
@@ -140,6 +163,7 @@ func (c *Connection) Start(ctx context.Context, options connection.ConnectOption
 	conn, err := c.startConn(wg.ConsumerModeConfig{
 		PrivateKey: c.privateKey,
 		IPAddress:  config.Consumer.IPAddress,
+		IPAddress6: config.Consumer.IPAddress6,
 		ListenPort: config.LocalPort,
 	})
 	if err != nil {
@@ -149,7 +173,15 @@ func (c *Connection) Start(ctx context.Context, options connection.ConnectOption
 
 	log.Info().Msgf("Adding connection peer %s", config.Provider.Endpoint.String())
 
-	if err := c.addProviderPeer(conn, config.Provider.Endpoint, config.Provider.PublicKey); err != nil {
+	c.sharedEndpoint = config.Provider.SharedEndpoint
+	c.providerPeer = wg.Peer{
+		Endpoint:               &config.Provider.Endpoint,
+		PublicKey:              config.Provider.PublicKey,
+		PresharedKey:           c.presharedKey,
+		AllowedIPs:             []string{"0.0.0.0/0", "::/0"},
+		KeepAlivePeriodSeconds: 18,
+	}
+	if err := conn.AddPeer(conn.InterfaceName(), c.providerPeer); err != nil {
 		return errors.Wrap(err, "failed to add peer to the connection endpoint")
 	}
 
@@ -190,14 +222,94 @@ func (c *Connection) startConn(conf wg.ConsumerModeConfig) (wg.ConnectionEndpoin
 	return conn, nil
 }
 
-func (c *Connection) addProviderPeer(conn wg.ConnectionEndpoint, endpoint net.UDPAddr, publicKey string) error {
-	peerInfo := wg.Peer{
-		Endpoint:               &endpoint,
-		PublicKey:              publicKey,
-		AllowedIPs:             []string{"0.0.0.0/0", "::/0"},
-		KeepAlivePeriodSeconds: 18,
+// RotateKeys generates new private and preshared keys, sends them to the provider and applies them to the device.
+// Tunnel stays up, the next handshake with the provider is made using the new keys.
+func (c *Connection) RotateKeys(send func(config connection.ConsumerConfig) error) error {
+	c.keysMu.Lock()
+	defer c.keysMu.Unlock()
+
+	if c.connectionEndpoint == nil || c.presharedKey == "" {
+		return connection.ErrKeyRotationNotSupported
+	}
+
+	privateKey, err := key.GeneratePrivateKey()
+	if err != nil {
+		return errors.Wrap(err, "could not generate private key")
+	}
+	presharedKey, err := key.GeneratePresharedKey()
+	if err != nil {
+		return errors.Wrap(err, "could not generate preshared key")
+	}
+	publicKey, err := key.PrivateKeyToPublicKey(privateKey)
+	if err != nil {
+		return errors.Wrap(err, "could not get public key from private key")
 	}
-	return conn.AddPeer(conn.InterfaceName(), peerInfo)
+
+	if err := send(wg.ConsumerConfig{PublicKey: publicKey, PresharedKey: presharedKey}); err != nil {
+		return err
+	}
+
+	if err := c.connectionEndpoint.SetPrivateKey(privateKey); err != nil {
+		return errors.Wrap(err, "could not apply new private key")
+	}
+	peer := c.providerPeer
+	peer.PresharedKey = presharedKey
+	if err := c.connectionEndpoint.AddPeer(c.connectionEndpoint.InterfaceName(), peer); err != nil {
+		return errors.Wrap(err, "could not apply new preshared key")
+	}
+
+	c.privateKey = privateKey
+	c.presharedKey = presharedKey
+	c.providerPeer = peer
+	return nil
+}
+
+// UpdateServiceConn moves the tunnel to the service connection of the new p2p channel after the session is resumed.
+func (c *Connection) UpdateServiceConn(conn *net.UDPConn) error {
+	conn.Close()
+
+	c.keysMu.Lock()
+	defer c.keysMu.Unlock()
+
+	if c.connectionEndpoint == nil {
+		return errors.New("connection is not started")
+	}
+
+	peer := c.providerPeer
+	if !c.sharedEndpoint {
+		endpoint := *peer.Endpoint
+		if remoteIP := conn.RemoteAddr().(*net.UDPAddr).IP; !remoteIP.IsLoopback() {
+			endpoint.IP = remoteIP
+		}
+		endpoint.Port = conn.RemoteAddr().(*net.UDPAddr).Port
+		peer.Endpoint = &endpoint
+	}
+
+	if !peer.Endpoint.IP.Equal(c.providerPeer.Endpoint.IP) {
+		removeAllowedIPRule, err := firewall.AllowIPAccess(peer.Endpoint.IP.String())
+		if err != nil {
+			return errors.Wrap(err, "failed to add firewall exception for wireguard remote IP")
+		}
+		removePrevious := c.removeAllowedIPRule
+		c.removeAllowedIPRule = func() {
+			removePrevious()
+			removeAllowedIPRule()
+		}
+	}
+
+	if err := c.connectionEndpoint.SetListenPort(conn.LocalAddr().(*net.UDPAddr).Port); err != nil {
+		return errors.Wrap(err, "could not apply new listen port")
+	}
+	if err := c.connectionEndpoint.AddPeer(c.connectionEndpoint.InterfaceName(), peer); err != nil {
+		return errors.Wrap(err, "could not apply new provider endpoint")
+	}
+	c.providerPeer = peer
+
+	// Provider endpoint is routed via the gateway of the new network.
+	if err := c.connectionEndpoint.ConfigureRoutes(peer.Endpoint.IP); err != nil {
+		log.Warn().Err(err).Msg("Failed to configure routes for the new provider endpoint")
+	}
+	return nil
 }
 
 // Wait blocks until wireguard connection not stopped.
@@ -234,9 +346,10 @@ func (c *Connection) GetConfig() (connection.ConsumerConfig, error) {
 	}
 
 	return wg.ConsumerConfig{
-		PublicKey: publicKey,
-		IP:        publicIP,
-		Ports:     c.ports,
+		PublicKey:    publicKey,
+		IP:           publicIP,
+		Ports:        c.ports,
+		PresharedKey: c.presharedKey,
 	}, nil
 }
 
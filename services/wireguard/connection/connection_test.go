@@ -92,6 +92,46 @@ func TestConnectionStopOnceAfterHandshakeErrorAndStopCall(t *testing.T) {
 	assert.Equal(t, connection.NotConnected, <-conn.State())
 }
 
+func TestConnectionRotateKeys(t *testing.T) {
+	conn := newConn(t)
+	config := newServiceConfig()
+	config.Consumer.PresharedKey = true
+	sessionConfig, _ := json.Marshal(config)
+	err := conn.Start(context.Background(), connection.ConnectOptions{DNS: "1.2.3.4", SessionConfig: sessionConfig})
+	assert.NoError(t, err)
+
+	oldKey, oldPSK := conn.privateKey, conn.presharedKey
+	var sent wg.ConsumerConfig
+	err = conn.RotateKeys(func(config connection.ConsumerConfig) error {
+		sent = config.(wg.ConsumerConfig)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.NotEqual(t, oldKey, conn.privateKey)
+	assert.Equal(t, conn.presharedKey, sent.PresharedKey)
+	assert.NotEqual(t, oldPSK, sent.PresharedKey)
+	assert.NotEmpty(t, sent.PublicKey)
+
+	// keys are kept when provider rejects them
+	key, psk := conn.privateKey, conn.presharedKey
+	err = conn.RotateKeys(func(config connection.ConsumerConfig) error {
+		return errors.New("rejected")
+	})
+	assert.Error(t, err)
+	assert.Equal(t, key, conn.privateKey)
+	assert.Equal(t, psk, conn.presharedKey)
+}
+
+func TestConnectionRotateKeysNotSupportedByProvider(t *testing.T) {
+	conn := newConn(t)
+	sessionConfig, _ := json.Marshal(newServiceConfig())
+	err := conn.Start(context.Background(), connection.ConnectOptions{DNS: "1.2.3.4", SessionConfig: sessionConfig})
+	assert.NoError(t, err)
+
+	err = conn.RotateKeys(func(config connection.ConsumerConfig) error { return nil })
+	assert.Equal(t, connection.ErrKeyRotationNotSupported, err)
+}
+
 func newConn(t *testing.T) *Connection {
 	endpointFactory := func() (wg.ConnectionEndpoint, error) {
 		return &mockConnectionEndpoint{}, nil
@@ -110,16 +150,19 @@ func newServiceConfig() wg.ServiceConfig {
 		LocalPort:  51000,
 		RemotePort: 51001,
 		Provider: struct {
-			PublicKey string
-			Endpoint  net.UDPAddr
+			PublicKey      string
+			Endpoint       net.UDPAddr
+			SharedEndpoint bool
 		}{
 			PublicKey: "wg1",
 			Endpoint:  *endpoint,
 		},
 		Consumer: struct {
 			IPAddress    net.IPNet
+			IPAddress6   net.IPNet
 			DNSIPs       string
 			ConnectDelay int
+			PresharedKey bool
 		}{
 			IPAddress: net.IPNet{
 				IP:   net.IPv4(127, 0, 0, 1),
@@ -140,6 +183,8 @@ func (mce *mockConnectionEndpoint) Stop() error
 func (mce *mockConnectionEndpoint) Config() (wg.ServiceConfig, error)                    { return wg.ServiceConfig{}, nil }
 func (mce *mockConnectionEndpoint) AddPeer(_ string, _ wg.Peer) error                    { return nil }
 func (mce *mockConnectionEndpoint) RemovePeer(_ string) error                            { return nil }
+func (mce *mockConnectionEndpoint) SetPrivateKey(_ string) error                         { return nil }
+func (mce *mockConnectionEndpoint) SetListenPort(_ int) error                            { return nil }
 func (mce *mockConnectionEndpoint) ConfigureRoutes(_ net.IP) error                       { return nil }
 func (mce *mockConnectionEndpoint) PeerStats() (*wg.Stats, error) {
 	return &wg.Stats{LastHandshake: time.Now(), BytesSent: 10, BytesReceived: 11}, nil
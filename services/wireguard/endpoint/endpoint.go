@@ -46,6 +46,7 @@ type connectionEndpoint struct {
 	iface             string
 	privateKey        string
 	ipAddr            net.IPNet
+	ipAddr6           net.IPNet
 	endpoint          net.UDPAddr
 	resourceAllocator *resources.Allocator
 	wgClient          wgClient
@@ -64,11 +65,13 @@ func (ce *connectionEndpoint) StartConsumerMode(config wg.ConsumerModeConfig) er
 
 	ce.iface = iface
 	ce.ipAddr = config.IPAddress
+	ce.ipAddr6 = config.IPAddress6
 	ce.privateKey = config.PrivateKey
 
 	deviceConfig := wg.DeviceConfig{
 		IfaceName:  ce.iface,
 		Subnet:     ce.ipAddr,
+		Subnet6:    ce.ipAddr6,
 		ListenPort: config.ListenPort,
 		PrivateKey: ce.privateKey,
 	}
@@ -102,12 +105,17 @@ func (ce *connectionEndpoint) StartProviderMode(config wg.ProviderModeConfig) (e
 
 	ce.ipAddr = config.Network
 	ce.ipAddr.IP = netutil.FirstIP(ce.ipAddr)
+	if config.Network6.IP != nil {
+		ce.ipAddr6 = config.Network6
+		ce.ipAddr6.IP = netutil.FirstIP(ce.ipAddr6)
+	}
 
 	ce.endpoint = net.UDPAddr{IP: net.ParseIP(config.PublicIP), Port: config.ListenPort}
 
 	deviceConfig := wg.DeviceConfig{
 		IfaceName:  ce.iface,
 		Subnet:     ce.ipAddr,
+		Subnet6:    ce.ipAddr6,
 		ListenPort: ce.endpoint.Port,
 		PrivateKey: ce.privateKey,
 	}
@@ -132,6 +140,23 @@ func (ce *connectionEndpoint) RemovePeer(publicKey string) error {
 	return ce.wgClient.RemovePeer(ce.iface, publicKey)
 }
 
+// SetPrivateKey replaces the private key of the wireguard device, keeping its peers.
+func (ce *connectionEndpoint) SetPrivateKey(privateKey string) error {
+	if err := ce.wgClient.SetPrivateKey(ce.iface, privateKey); err != nil {
+		return errors.Wrap(err, "could not set device private key")
+	}
+	ce.privateKey = privateKey
+	return nil
+}
+
+// SetListenPort changes the UDP port the wireguard device listens on, keeping its peers.
+func (ce *connectionEndpoint) SetListenPort(port int) error {
+	if err := ce.wgClient.SetListenPort(ce.iface, port); err != nil {
+		return errors.Wrap(err, "could not set device listen port")
+	}
+	return nil
+}
+
 // PeerStats returns stats information about connected peer.
 func (ce *connectionEndpoint) PeerStats() (*wg.Stats, error) {
 	return ce.wgClient.PeerStats()
@@ -149,9 +174,21 @@ func (ce *connectionEndpoint) Config() (wg.ServiceConfig, error) {
 	config.Provider.Endpoint = ce.endpoint
 	config.Consumer.IPAddress = ce.ipAddr
 	config.Consumer.IPAddress.IP = ce.consumerIP(ce.ipAddr)
+	if ce.ipAddr6.IP != nil {
+		config.Consumer.IPAddress6 = ce.ipAddr6
+		config.Consumer.IPAddress6.IP = consumerIP6(ce.ipAddr6)
+	}
 	return config, nil
 }
 
+// consumerIP6 returns the IPv6 address following the provider one.
+func consumerIP6(providerAddr net.IPNet) net.IP {
+	ip := make(net.IP, len(providerAddr.IP))
+	copy(ip, providerAddr.IP)
+	ip[len(ip)-1]++
+	return ip
+}
+
 func (ce *connectionEndpoint) ConfigureRoutes(ip net.IP) error {
 	return ce.wgClient.ConfigureRoutes(ce.iface, ip)
 }
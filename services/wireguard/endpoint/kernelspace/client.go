@@ -34,6 +34,7 @@ import (
 
 type client struct {
 	iface    string
+	ipv6     bool
 	wgClient *wgctrl.Client
 }
 
@@ -55,10 +56,11 @@ func (c *client) ConfigureDevice(config wg.DeviceConfig) error {
 	}
 	deviceConfig.PrivateKey = &privateKey
 	deviceConfig.ListenPort = &port
-	if err := c.up(config.IfaceName, config.Subnet); err != nil {
+	if err := c.up(config.IfaceName, config.Subnet, config.Subnet6); err != nil {
 		return err
 	}
 	c.iface = config.IfaceName
+	c.ipv6 = config.Subnet6.IP != nil
 	return c.wgClient.ConfigureDevice(c.iface, deviceConfig)
 }
 
@@ -86,16 +88,39 @@ func (c *client) AddPeer(iface string, peer wg.Peer) error {
 		allowedIPs = append(allowedIPs, *network)
 	}
 
+	var presharedKey *wgtypes.Key
+	if peer.PresharedKey != "" {
+		psk, err := stringToKey(peer.PresharedKey)
+		if err != nil {
+			return errors.Wrap(err, "could not convert string key to wgtypes.Key")
+		}
+		presharedKey = &psk
+	}
+
 	var deviceConfig wgtypes.Config
 	deviceConfig.Peers = []wgtypes.PeerConfig{{
 		Endpoint:                    endpoint,
 		PublicKey:                   publicKey,
+		PresharedKey:                presharedKey,
 		AllowedIPs:                  allowedIPs,
 		PersistentKeepaliveInterval: keepAliveInterval,
 	}}
 	return c.wgClient.ConfigureDevice(iface, deviceConfig)
 }
 
+func (c *client) SetPrivateKey(iface string, privateKey string) error {
+	key, err := stringToKey(privateKey)
+	if err != nil {
+		return err
+	}
+
+	return c.wgClient.ConfigureDevice(iface, wgtypes.Config{PrivateKey: &key})
+}
+
+func (c *client) SetListenPort(iface string, port int) error {
+	return c.wgClient.ConfigureDevice(iface, wgtypes.Config{ListenPort: &port})
+}
+
 func (c *client) RemovePeer(iface string, publicKey string) error {
 	key, err := stringToKey(publicKey)
 	if err != nil {
@@ -125,11 +150,28 @@ func (c *client) PeerStats() (*wg.Stats, error) {
 	}, nil
 }
 
+func (c *client) PeersStats() (map[string]wg.Stats, error) {
+	d, err := c.wgClient.Device(c.iface)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := make(map[string]wg.Stats, len(d.Peers))
+	for _, p := range d.Peers {
+		stats[p.PublicKey.String()] = wg.Stats{
+			BytesReceived: uint64(p.ReceiveBytes),
+			BytesSent:     uint64(p.TransmitBytes),
+			LastHandshake: p.LastHandshakeTime,
+		}
+	}
+	return stats, nil
+}
+
 func (c *client) DestroyDevice(name string) error {
 	return cmdutil.SudoExec("ip", "link", "del", "dev", name)
 }
 
-func (c *client) up(iface string, ipAddr net.IPNet) error {
+func (c *client) up(iface string, ipAddr, ipAddr6 net.IPNet) error {
 	if d, err := c.wgClient.Device(iface); err != nil || d.Name != iface {
 		if err := cmdutil.SudoExec("ip", "link", "add", "dev", iface, "type", "wireguard"); err != nil {
 			return err
@@ -140,6 +182,12 @@ func (c *client) up(iface string, ipAddr net.IPNet) error {
 		return err
 	}
 
+	if ipAddr6.IP != nil {
+		if err := cmdutil.SudoExec("ip", "-6", "address", "replace", "dev", iface, ipAddr6.String()); err != nil {
+			return err
+		}
+	}
+
 	return cmdutil.SudoExec("ip", "link", "set", "dev", iface, "up")
 }
 
@@ -147,7 +195,13 @@ func (c *client) ConfigureRoutes(iface string, ip net.IP) error {
 	if err := excludeRoute(ip); err != nil {
 		return err
 	}
-	return addDefaultRoute(iface)
+	if err := addDefaultRoute(iface); err != nil {
+		return err
+	}
+	if c.ipv6 {
+		return addDefaultRoute6(iface)
+	}
+	return nil
 }
 
 func excludeRoute(ip net.IP) error {
@@ -166,6 +220,13 @@ func addDefaultRoute(iface string) error {
 	return cmdutil.SudoExec("ip", "route", "replace", "128.0.0.0/1", "dev", iface)
 }
 
+func addDefaultRoute6(iface string) error {
+	if err := cmdutil.SudoExec("ip", "-6", "route", "replace", "::/1", "dev", iface); err != nil {
+		return err
+	}
+	return cmdutil.SudoExec("ip", "-6", "route", "replace", "8000::/1", "dev", iface)
+}
+
 func (c *client) Close() (err error) {
 	var errs []error
 	defer func() {
@@ -0,0 +1,244 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoint
+
+import (
+	"net"
+	"sync"
+
+	wg "github.com/mysteriumnetwork/node/services/wireguard"
+	"github.com/mysteriumnetwork/node/services/wireguard/key"
+	"github.com/mysteriumnetwork/node/services/wireguard/resources"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+// MultiPeerEndpoint is a provider side wireguard device shared by all the sessions of a service.
+// Every consumer is added as a separate peer with its own address from the shared subnet.
+type MultiPeerEndpoint struct {
+	mu                sync.Mutex
+	iface             string
+	privateKey        string
+	ipAddr            net.IPNet
+	ipAddr6           net.IPNet
+	endpoint          net.UDPAddr
+	pool              *resources.IPPool
+	peers             map[string]net.IP
+	resourceAllocator *resources.Allocator
+	wgClient          wgClient
+}
+
+// NewMultiPeerEndpoint returns new multi peer endpoint instance.
+func NewMultiPeerEndpoint(resourceAllocator *resources.Allocator) (*MultiPeerEndpoint, error) {
+	wgClient, err := newWGClient()
+	if err != nil {
+		return nil, err
+	}
+
+	return &MultiPeerEndpoint{
+		peers:             make(map[string]net.IP),
+		resourceAllocator: resourceAllocator,
+		wgClient:          wgClient,
+	}, nil
+}
+
+// Start creates the wireguard device for the whole network of the given config.
+func (e *MultiPeerEndpoint) Start(config wg.ProviderModeConfig) (err error) {
+	if config.PublicIP == "" {
+		return errors.New("public IP is required")
+	}
+	if config.ListenPort == 0 {
+		return errors.New("listen port is required")
+	}
+
+	e.pool, err = resources.NewIPPool(config.Network)
+	if err != nil {
+		return errors.Wrap(err, "could not create peer IP pool")
+	}
+
+	e.iface, err = e.resourceAllocator.AllocateInterface()
+	if err != nil {
+		return errors.Wrap(err, "could not allocate interface")
+	}
+	defer func() {
+		if err != nil {
+			if err := e.resourceAllocator.ReleaseInterface(e.iface); err != nil {
+				log.Warn().Err(err).Msg("Failed to release interface")
+			}
+		}
+	}()
+
+	e.privateKey, err = key.GeneratePrivateKey()
+	if err != nil {
+		return errors.Wrap(err, "could not generate private key")
+	}
+
+	e.ipAddr = net.IPNet{IP: e.pool.ProviderIP(), Mask: config.Network.Mask}
+	if config.Network6.IP != nil {
+		e.ipAddr6 = net.IPNet{IP: resources.IPv6FromIPv4(config.Network6, e.ipAddr.IP), Mask: config.Network6.Mask}
+	}
+	e.endpoint = net.UDPAddr{IP: net.ParseIP(config.PublicIP), Port: config.ListenPort}
+
+	deviceConfig := wg.DeviceConfig{
+		IfaceName:  e.iface,
+		Subnet:     e.ipAddr,
+		Subnet6:    e.ipAddr6,
+		ListenPort: e.endpoint.Port,
+		PrivateKey: e.privateKey,
+	}
+	if err := e.wgClient.ConfigureDevice(deviceConfig); err != nil {
+		return errors.Wrap(err, "could not configure device")
+	}
+	return nil
+}
+
+// InterfaceName returns the device interface name.
+func (e *MultiPeerEndpoint) InterfaceName() string {
+	return e.iface
+}
+
+// Network returns the device network, the address being the provider one.
+func (e *MultiPeerEndpoint) Network() net.IPNet {
+	return e.ipAddr
+}
+
+// Network6 returns the device IPv6 network, it is empty if IPv6 is not enabled.
+func (e *MultiPeerEndpoint) Network6() net.IPNet {
+	return e.ipAddr6
+}
+
+// peerAllowedIPs returns the addresses routed to the peer, IPv6 one being derived from the IPv4.
+func (e *MultiPeerEndpoint) peerAllowedIPs(ip net.IP) []string {
+	allowedIPs := []string{ip.String() + "/32"}
+	if e.ipAddr6.IP != nil {
+		allowedIPs = append(allowedIPs, resources.IPv6FromIPv4(e.ipAddr6, ip).String()+"/128")
+	}
+	return allowedIPs
+}
+
+// AddPeer allocates an address for the consumer, adds it as a device peer and returns the session config.
+func (e *MultiPeerEndpoint) AddPeer(publicKey, presharedKey string, endpoint *net.UDPAddr) (wg.ServiceConfig, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if _, ok := e.peers[publicKey]; ok {
+		return wg.ServiceConfig{}, errors.New("peer already exists")
+	}
+
+	publicProviderKey, err := key.PrivateKeyToPublicKey(e.privateKey)
+	if err != nil {
+		return wg.ServiceConfig{}, err
+	}
+
+	ip, err := e.pool.Allocate()
+	if err != nil {
+		return wg.ServiceConfig{}, errors.Wrap(err, "could not allocate peer IP")
+	}
+
+	peer := wg.Peer{
+		PublicKey:    publicKey,
+		PresharedKey: presharedKey,
+		Endpoint:     endpoint,
+		AllowedIPs:   e.peerAllowedIPs(ip),
+	}
+	if err := e.wgClient.AddPeer(e.iface, peer); err != nil {
+		if err := e.pool.Release(ip); err != nil {
+			log.Warn().Err(err).Msg("Failed to release peer IP")
+		}
+		return wg.ServiceConfig{}, errors.Wrap(err, "could not add peer")
+	}
+	e.peers[publicKey] = ip
+
+	var config wg.ServiceConfig
+	config.Provider.PublicKey = publicProviderKey
+	config.Provider.Endpoint = e.endpoint
+	config.Provider.SharedEndpoint = true
+	config.Consumer.IPAddress = net.IPNet{IP: ip, Mask: e.ipAddr.Mask}
+	if e.ipAddr6.IP != nil {
+		config.Consumer.IPAddress6 = net.IPNet{IP: resources.IPv6FromIPv4(e.ipAddr6, ip), Mask: e.ipAddr6.Mask}
+	}
+	return config, nil
+}
+
+// ReplacePeer replaces the consumer peer with the one using new keys, keeping the consumer address.
+func (e *MultiPeerEndpoint) ReplacePeer(publicKey, newPublicKey, newPresharedKey string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	ip, ok := e.peers[publicKey]
+	if !ok {
+		return errors.New("peer not found")
+	}
+	if _, ok := e.peers[newPublicKey]; ok {
+		return errors.New("peer already exists")
+	}
+
+	// Allowed IP is moved to the new peer once it is added, so the tunnel keeps working.
+	peer := wg.Peer{
+		PublicKey:    newPublicKey,
+		PresharedKey: newPresharedKey,
+		AllowedIPs:   e.peerAllowedIPs(ip),
+	}
+	if err := e.wgClient.AddPeer(e.iface, peer); err != nil {
+		return errors.Wrap(err, "could not add peer")
+	}
+	e.peers[newPublicKey] = ip
+	delete(e.peers, publicKey)
+
+	return e.wgClient.RemovePeer(e.iface, publicKey)
+}
+
+// RemovePeer removes the consumer peer from the device and releases its address.
+func (e *MultiPeerEndpoint) RemovePeer(publicKey string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	ip, ok := e.peers[publicKey]
+	if !ok {
+		return errors.New("peer not found")
+	}
+	delete(e.peers, publicKey)
+
+	if err := e.pool.Release(ip); err != nil {
+		log.Warn().Err(err).Msg("Failed to release peer IP")
+	}
+	return e.wgClient.RemovePeer(e.iface, publicKey)
+}
+
+// PeerStats returns stats of the given peer.
+func (e *MultiPeerEndpoint) PeerStats(publicKey string) (*wg.Stats, error) {
+	stats, err := e.wgClient.PeersStats()
+	if err != nil {
+		return nil, err
+	}
+
+	s, ok := stats[publicKey]
+	if !ok {
+		return nil, errors.Errorf("no stats for peer %s", publicKey)
+	}
+	return &s, nil
+}
+
+// Stop closes wireguard client and destroys the device.
+func (e *MultiPeerEndpoint) Stop() error {
+	if err := e.wgClient.Close(); err != nil {
+		return err
+	}
+
+	return e.resourceAllocator.ReleaseInterface(e.iface)
+}
@@ -20,11 +20,14 @@ package userspace
 import (
 	"bufio"
 	"encoding/base64"
+	"encoding/hex"
+	"fmt"
 	"net"
 	"strings"
 
 	wg "github.com/mysteriumnetwork/node/services/wireguard"
 	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
 	"golang.zx2c4.com/wireguard/device"
 	"golang.zx2c4.com/wireguard/tun"
 )
@@ -32,6 +35,7 @@ import (
 type client struct {
 	tun    tun.Device
 	devAPI *device.Device
+	ipv6   bool
 }
 
 // NewWireguardClient creates new wireguard user space client.
@@ -44,6 +48,15 @@ func (c *client) ConfigureDevice(config wg.DeviceConfig) (err error) {
 		return errors.Wrap(err, "failed to create TUN device")
 	}
 
+	if config.Subnet6.IP != nil {
+		// IPv6 is optional, the tunnel keeps working over IPv4 only if the address can not be assigned.
+		if err := assignIP6(config.IfaceName, config.Subnet6); err != nil {
+			log.Warn().Err(err).Msg("Failed to assign IPv6 address, continuing with IPv4 only")
+		} else {
+			c.ipv6 = true
+		}
+	}
+
 	c.devAPI = device.NewDevice(c.tun, device.NewLogger(device.LogLevelDebug, "[userspace-wg]"))
 	if err := c.setDeviceConfig(config.Encode()); err != nil {
 		return errors.Wrap(err, "failed to configure initial device")
@@ -58,6 +71,25 @@ func (c *client) AddPeer(_ string, peer wg.Peer) error {
 	return nil
 }
 
+func (c *client) SetPrivateKey(_ string, privateKey string) error {
+	key, err := base64stringTo32ByteArray(privateKey)
+	if err != nil {
+		return err
+	}
+
+	if err := c.setDeviceConfig(fmt.Sprintf("private_key=%s\n", hex.EncodeToString(key[:]))); err != nil {
+		return errors.Wrap(err, "failed to set device private key")
+	}
+	return nil
+}
+
+func (c *client) SetListenPort(_ string, port int) error {
+	if err := c.setDeviceConfig(fmt.Sprintf("listen_port=%d\n", port)); err != nil {
+		return errors.Wrap(err, "failed to set device listen port")
+	}
+	return nil
+}
+
 func (c *client) RemovePeer(_ string, publicKey string) error {
 	key, err := base64stringTo32ByteArray(publicKey)
 	if err != nil {
@@ -77,7 +109,13 @@ func (c *client) ConfigureRoutes(iface string, ip net.IP) error {
 	if err := excludeRoute(ip); err != nil {
 		return err
 	}
-	return addDefaultRoute(iface)
+	if err := addDefaultRoute(iface); err != nil {
+		return err
+	}
+	if c.ipv6 {
+		return addDefaultRoute6(iface)
+	}
+	return nil
 }
 
 func (c *client) PeerStats() (*wg.Stats, error) {
@@ -92,6 +130,14 @@ func (c *client) PeerStats() (*wg.Stats, error) {
 	return stats, nil
 }
 
+func (c *client) PeersStats() (map[string]wg.Stats, error) {
+	deviceState, err := wg.ParseUserspaceDevice(c.devAPI.IpcGetOperation)
+	if err != nil {
+		return nil, err
+	}
+	return wg.ParseDevicePeersStats(deviceState), nil
+}
+
 func (c *client) DestroyDevice(name string) error {
 	return destroyDevice(name)
 }
@@ -19,6 +19,7 @@ package userspace
 
 import (
 	"net"
+	"strconv"
 
 	"github.com/jackpal/gateway"
 	"github.com/mysteriumnetwork/node/utils/cmdutil"
@@ -28,6 +29,11 @@ func assignIP(iface string, subnet net.IPNet) error {
 	return cmdutil.SudoExec("ifconfig", iface, subnet.String(), peerIP(subnet).String())
 }
 
+func assignIP6(iface string, subnet net.IPNet) error {
+	ones, _ := subnet.Mask.Size()
+	return cmdutil.SudoExec("ifconfig", iface, "inet6", subnet.IP.String(), "prefixlen", strconv.Itoa(ones), "alias")
+}
+
 func excludeRoute(ip net.IP) error {
 	gw, err := gateway.DiscoverGateway()
 	if err != nil {
@@ -45,6 +51,14 @@ func addDefaultRoute(iface string) error {
 	return cmdutil.SudoExec("route", "add", "-net", "128.0.0.0/1", "-interface", iface)
 }
 
+func addDefaultRoute6(iface string) error {
+	if err := cmdutil.SudoExec("route", "add", "-inet6", "-net", "::/1", "-interface", iface); err != nil {
+		return err
+	}
+
+	return cmdutil.SudoExec("route", "add", "-inet6", "-net", "8000::/1", "-interface", iface)
+}
+
 func peerIP(subnet net.IPNet) net.IP {
 	lastOctetID := len(subnet.IP) - 1
 	if subnet.IP[lastOctetID] == byte(1) {
@@ -31,6 +31,10 @@ func assignIP(iface string, subnet net.IPNet) error {
 	return cmdutil.SudoExec("ip", "link", "set", "dev", iface, "up")
 }
 
+func assignIP6(iface string, subnet net.IPNet) error {
+	return cmdutil.SudoExec("ip", "-6", "address", "replace", "dev", iface, subnet.String())
+}
+
 func excludeRoute(ip net.IP) error {
 	gw, err := gateway.DiscoverGateway()
 	if err != nil {
@@ -48,6 +52,14 @@ func addDefaultRoute(iface string) error {
 	return cmdutil.SudoExec("route", "add", "-net", "128.0.0.0/1", "-interface", iface)
 }
 
+func addDefaultRoute6(iface string) error {
+	if err := cmdutil.SudoExec("ip", "-6", "route", "replace", "::/1", "dev", iface); err != nil {
+		return err
+	}
+
+	return cmdutil.SudoExec("ip", "-6", "route", "replace", "8000::/1", "dev", iface)
+}
+
 func destroyDevice(name string) error {
 	return cmdutil.SudoExec("ip", "link", "del", "dev", name)
 }
@@ -32,6 +32,10 @@ func assignIP(iface string, subnet net.IPNet) error {
 	return errors.Wrap(err, string(out))
 }
 
+func assignIP6(iface string, subnet net.IPNet) error {
+	return errors.New("IPv6 tunnel address is not supported on windows")
+}
+
 func renameInterface(name, newname string) error {
 	out, err := exec.Command("powershell", "-Command", "netsh interface set interface name=\""+name+"\" newname=\""+newname+"\"").CombinedOutput()
 	return errors.Wrap(err, string(out))
@@ -61,6 +65,10 @@ func addDefaultRoute(name string) error {
 	return errors.Wrap(err, string(out))
 }
 
+func addDefaultRoute6(name string) error {
+	return errors.New("IPv6 tunnel routes are not supported on windows")
+}
+
 func destroyDevice(name string) error {
 	// Windows implementation is using single device that are reused for the future needs.
 	// Nothing to destroy here.
@@ -34,7 +34,10 @@ type wgClient interface {
 	DestroyDevice(name string) error
 	AddPeer(iface string, peer wg.Peer) error
 	RemovePeer(name string, publicKey string) error
+	SetPrivateKey(iface string, privateKey string) error
+	SetListenPort(iface string, port int) error
 	PeerStats() (*wg.Stats, error)
+	PeersStats() (map[string]wg.Stats, error)
 	Close() error
 }
 
@@ -56,3 +56,13 @@ func GeneratePrivateKey() (string, error) {
 
 	return base64.StdEncoding.EncodeToString(randomBytes), nil
 }
+
+// GeneratePresharedKey generates a symmetric preshared key
+func GeneratePresharedKey() (string, error) {
+	randomBytes := make([]byte, keyLength)
+	if _, err := rand.Read(randomBytes); err != nil {
+		return "", errors.Wrapf(err, "failed to generate random bytes for preshared key")
+	}
+
+	return base64.StdEncoding.EncodeToString(randomBytes), nil
+}
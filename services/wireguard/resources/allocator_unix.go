@@ -33,6 +33,9 @@ import (
 // MaxConnections sets the limit to the maximum number of wireguard connections.
 var MaxConnections = 256
 
+// maxPortAttempts limits how many times a port is acquired from the supplier until an unallocated one is found.
+const maxPortAttempts = 10
+
 type portSupplier interface {
 	Acquire() (port.Port, error)
 }
@@ -43,6 +46,7 @@ type Allocator struct {
 	mu          sync.Mutex
 	Ifaces      map[int]struct{}
 	IPAddresses map[int]struct{}
+	Ports       map[int]struct{}
 
 	portSupplier portSupplier
 	subnet       net.IPNet
@@ -53,6 +57,7 @@ func NewAllocator(ports portSupplier, subnet net.IPNet) *Allocator {
 	return &Allocator{
 		Ifaces:      make(map[int]struct{}),
 		IPAddresses: make(map[int]struct{}),
+		Ports:       make(map[int]struct{}),
 
 		portSupplier: ports,
 		subnet:       subnet,
@@ -128,11 +133,31 @@ func (a *Allocator) AllocatePort() (int, error) {
 	a.mu.Lock()
 	defer a.mu.Unlock()
 
-	port, err := a.portSupplier.Acquire()
-	if err != nil {
-		return 0, err
+	// Allocated port may not be bound yet, so the supplier can hand it out again.
+	for i := 0; i < maxPortAttempts; i++ {
+		port, err := a.portSupplier.Acquire()
+		if err != nil {
+			return 0, err
+		}
+		if _, ok := a.Ports[port.Num()]; !ok {
+			a.Ports[port.Num()] = struct{}{}
+			return port.Num(), nil
+		}
+	}
+	return 0, errors.New("no more unused ports")
+}
+
+// ReleasePort releases UDP port of the wireguard endpoint.
+func (a *Allocator) ReleasePort(port int) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if _, ok := a.Ports[port]; !ok {
+		return errors.New("allocated port not found")
 	}
-	return port.Num(), nil
+
+	delete(a.Ports, port)
+	return nil
 }
 
 // ReleaseInterface releases name for the wireguard network interface.
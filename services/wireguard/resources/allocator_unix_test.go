@@ -0,0 +1,46 @@
+//+build !windows
+
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package resources
+
+import (
+	"net"
+	"testing"
+
+	"github.com/mysteriumnetwork/node/core/port"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAllocator_AllocateAndReleasePort(t *testing.T) {
+	allocator := NewAllocator(port.NewPoolFixed(51820), net.IPNet{})
+
+	p, err := allocator.AllocatePort()
+	assert.NoError(t, err)
+	assert.Equal(t, 51820, p)
+
+	_, err = allocator.AllocatePort()
+	assert.Error(t, err)
+
+	assert.NoError(t, allocator.ReleasePort(p))
+	assert.Error(t, allocator.ReleasePort(p))
+
+	p, err = allocator.AllocatePort()
+	assert.NoError(t, err)
+	assert.Equal(t, 51820, p)
+}
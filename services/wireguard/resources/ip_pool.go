@@ -0,0 +1,99 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package resources
+
+import (
+	"encoding/binary"
+	"net"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// IPPool hands out single peer addresses from a shared subnet.
+// The network address, the first address (reserved for the provider) and the broadcast address are never allocated.
+type IPPool struct {
+	mu        sync.Mutex
+	network   net.IPNet
+	base      uint32
+	size      uint32
+	allocated map[uint32]struct{}
+}
+
+// NewIPPool creates a peer address pool for the given IPv4 subnet.
+func NewIPPool(subnet net.IPNet) (*IPPool, error) {
+	ip4 := subnet.IP.Mask(subnet.Mask).To4()
+	ones, bits := subnet.Mask.Size()
+	if ip4 == nil || bits != 32 {
+		return nil, errors.Errorf("IPv4 subnet expected, got %s", subnet.String())
+	}
+	if bits-ones < 2 {
+		return nil, errors.Errorf("subnet %s is too small", subnet.String())
+	}
+
+	return &IPPool{
+		network:   net.IPNet{IP: ip4, Mask: subnet.Mask},
+		base:      binary.BigEndian.Uint32(ip4),
+		size:      uint32(1) << uint(bits-ones),
+		allocated: make(map[uint32]struct{}),
+	}, nil
+}
+
+// ProviderIP returns the address reserved for the provider.
+func (p *IPPool) ProviderIP() net.IP {
+	return p.ip(1)
+}
+
+// Allocate provides an unused peer address.
+func (p *IPPool) Allocate() (net.IP, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for offset := uint32(2); offset < p.size-1; offset++ {
+		if _, ok := p.allocated[offset]; !ok {
+			p.allocated[offset] = struct{}{}
+			return p.ip(offset), nil
+		}
+	}
+	return nil, errors.New("no more unused peer addresses")
+}
+
+// Release returns the peer address back to the pool.
+func (p *IPPool) Release(ip net.IP) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	ip4 := ip.To4()
+	if ip4 == nil || !p.network.Contains(ip4) {
+		return errors.Errorf("address %s does not belong to the pool", ip.String())
+	}
+
+	offset := binary.BigEndian.Uint32(ip4) - p.base
+	if _, ok := p.allocated[offset]; !ok {
+		return errors.Errorf("address %s is not allocated", ip.String())
+	}
+
+	delete(p.allocated, offset)
+	return nil
+}
+
+func (p *IPPool) ip(offset uint32) net.IP {
+	ip := make(net.IP, net.IPv4len)
+	binary.BigEndian.PutUint32(ip, p.base+offset)
+	return ip
+}
@@ -0,0 +1,71 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package resources
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIPPool(t *testing.T) {
+	_, subnet, _ := net.ParseCIDR("10.182.0.0/30")
+	pool, err := NewIPPool(*subnet)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "10.182.0.1", pool.ProviderIP().String())
+
+	ip, err := pool.Allocate()
+	assert.NoError(t, err)
+	assert.Equal(t, "10.182.0.2", ip.String())
+
+	_, err = pool.Allocate()
+	assert.Error(t, err)
+
+	assert.NoError(t, pool.Release(ip))
+	assert.Error(t, pool.Release(ip))
+	assert.Error(t, pool.Release(net.ParseIP("10.183.0.2")))
+
+	ip, err = pool.Allocate()
+	assert.NoError(t, err)
+	assert.Equal(t, "10.182.0.2", ip.String())
+}
+
+func TestIPPool_SpansOctets(t *testing.T) {
+	_, subnet, _ := net.ParseCIDR("10.182.0.0/16")
+	pool, err := NewIPPool(*subnet)
+	assert.NoError(t, err)
+
+	var last net.IP
+	for i := 0; i < 300; i++ {
+		last, err = pool.Allocate()
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, "10.182.1.45", last.String())
+}
+
+func TestNewIPPool_RejectsInvalidSubnets(t *testing.T) {
+	_, small, _ := net.ParseCIDR("10.182.0.0/31")
+	_, v6, _ := net.ParseCIDR("fd00::/64")
+
+	_, err := NewIPPool(*small)
+	assert.Error(t, err)
+	_, err = NewIPPool(*v6)
+	assert.Error(t, err)
+}
@@ -0,0 +1,43 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package resources
+
+import (
+	"net"
+)
+
+// IPNet6 returns the IPv6 /64 network of the session, paired with the session IPv4 /24 network allocated from the subnet.
+// The index of the IPv4 network is used as the last byte of the IPv6 network prefix,
+// so the IPv4 subnet has to be /16 for the session networks to get distinct IPv6 networks.
+func IPNet6(subnet6 net.IPNet, ipnet net.IPNet) net.IPNet {
+	ip := make(net.IP, net.IPv6len)
+	copy(ip, subnet6.IP.To16())
+	if ip4 := ipnet.IP.To4(); ip4 != nil {
+		ip[7] = ip4[2]
+	}
+	return net.IPNet{IP: ip, Mask: net.CIDRMask(64, 128)}
+}
+
+// IPv6FromIPv4 returns the IPv6 address of the network with the IPv4 address in the last 32 bits,
+// so the peers of the multi peer device get IPv6 addresses without a separate pool.
+func IPv6FromIPv4(network6 net.IPNet, ip net.IP) net.IP {
+	ip6 := make(net.IP, net.IPv6len)
+	copy(ip6, network6.IP.Mask(network6.Mask))
+	copy(ip6[net.IPv6len-net.IPv4len:], ip.To4())
+	return ip6
+}
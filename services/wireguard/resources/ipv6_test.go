@@ -0,0 +1,39 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package resources
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIPNet6(t *testing.T) {
+	_, subnet6, _ := net.ParseCIDR("fd00:182::/48")
+	_, ipnet, _ := net.ParseCIDR("10.182.5.0/24")
+
+	network6 := IPNet6(*subnet6, *ipnet)
+	assert.Equal(t, "fd00:182:0:5::/64", network6.String())
+}
+
+func TestIPv6FromIPv4(t *testing.T) {
+	_, network6, _ := net.ParseCIDR("fd00:182::/64")
+
+	assert.Equal(t, "fd00:182::a00:7", IPv6FromIPv4(*network6, net.ParseIP("10.0.0.7")).String())
+}
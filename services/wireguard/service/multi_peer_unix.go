@@ -0,0 +1,238 @@
+//+build !windows
+
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package service
+
+import (
+	"net"
+	"sync"
+	"time"
+
+	"github.com/mysteriumnetwork/node/core/shaper"
+	"github.com/mysteriumnetwork/node/firewall"
+	"github.com/mysteriumnetwork/node/nat"
+	wg "github.com/mysteriumnetwork/node/services/wireguard"
+	"github.com/mysteriumnetwork/node/services/wireguard/resources"
+	"github.com/mysteriumnetwork/node/session"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+// peerStatsSupplier provides the stats of a single peer of the multi peer device.
+type peerStatsSupplier struct {
+	device    MultiPeerDevice
+	publicKey string
+}
+
+func (p peerStatsSupplier) PeerStats() (*wg.Stats, error) {
+	return p.device.PeerStats(p.publicKey)
+}
+
+// startMultiPeerDevice creates the shared device along with the port mapping, NAT and firewall rules for the whole subnet.
+func (m *Manager) startMultiPeerDevice() (err error) {
+	device, err := m.multiPeerDeviceFactory()
+	if err != nil {
+		return errors.Wrap(err, "could not create multi peer device")
+	}
+
+	listenPort, err := m.resourcesAllocator.AllocatePort()
+	if err != nil {
+		return errors.Wrap(err, "could not allocate provider listen port")
+	}
+
+	releasePort := func() {
+		if err := m.resourcesAllocator.ReleasePort(listenPort); err != nil {
+			log.Error().Err(err).Msg("Failed to release provider listen port")
+		}
+	}
+
+	publicIP, err := m.ipResolver.GetPublicIP()
+	if err != nil {
+		releasePort()
+		return errors.Wrap(err, "could not get public IP")
+	}
+
+	releasePortMapping, _ := m.tryAddPortMapping(publicIP, listenPort)
+
+	config := wg.ProviderModeConfig{
+		Network:    m.subnet,
+		ListenPort: listenPort,
+		PublicIP:   publicIP,
+	}
+	if m.subnet6.IP != nil {
+		config.Network6 = resources.IPNet6(m.subnet6, m.subnet)
+	}
+	if err := device.Start(config); err != nil {
+		if releasePortMapping != nil {
+			releasePortMapping()
+		}
+		releasePort()
+		return errors.Wrap(err, "could not start multi peer device")
+	}
+
+	stopDevice := func() {
+		if releasePortMapping != nil {
+			releasePortMapping()
+		}
+		if err := device.Stop(); err != nil {
+			log.Error().Err(err).Msg("Failed to stop multi peer device")
+		}
+		releasePort()
+	}
+	defer func() {
+		if err != nil {
+			stopDevice()
+		}
+	}()
+
+	network := device.Network()
+
+	var dnsIP net.IP
+	var releaseTrafficFirewall firewall.IncomingRuleRemove
+	disableTrafficBlocking := func() {
+		if releaseTrafficFirewall != nil {
+			if err := releaseTrafficFirewall(); err != nil {
+				log.Warn().Err(err).Msg("Failed to disable traffic blocking")
+			}
+		}
+	}
+	if m.dnsOK {
+		if m.serviceInstance.Policies().HasDNSRules() {
+			releaseTrafficFirewall, err = m.trafficFirewall.BlockIncomingTraffic(network)
+			if err != nil {
+				return errors.Wrap(err, "failed to enable traffic blocking")
+			}
+			defer func() {
+				if err != nil {
+					disableTrafficBlocking()
+				}
+			}()
+		}
+		dnsIP = network.IP
+	}
+
+	natRules, err := m.natService.Setup(nat.Options{
+		VPNNetwork:        network,
+		VPNNetwork6:       device.Network6(),
+		DNSIP:             dnsIP,
+		ProviderExtIP:     net.ParseIP(m.outboundIP),
+		EnableDNSRedirect: m.dnsOK,
+		DNSPort:           m.dnsPort,
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to setup NAT/firewall rules")
+	}
+
+	ifaceName := device.InterfaceName()
+	s := shaper.New(m.eventBus)
+	if err := s.Start(ifaceName); err != nil {
+		log.Error().Err(err).Msg("Could not start traffic shaper")
+	}
+
+	m.multiPeerDevice = device
+	m.multiPeerCleanup = func() {
+		s.Clear(ifaceName)
+
+		disableTrafficBlocking()
+
+		if err := m.natService.Del(natRules); err != nil {
+			log.Error().Err(err).Msg("Failed to delete NAT rules")
+		}
+
+		stopDevice()
+	}
+
+	log.Info().Msgf("Wireguard: multi peer device %s listening on %s:%d", ifaceName, publicIP, listenPort)
+	return nil
+}
+
+// provideMultiPeerConfig adds the consumer as a peer of the shared device.
+func (m *Manager) provideMultiPeerConfig(sessionID string, consumerConfig wg.ConsumerConfig, remoteConn *net.UDPConn) (*session.ConfigParams, error) {
+	if remoteConn != nil {
+		// The consumer connects to the shared listen port, the port of the p2p channel is not used.
+		remoteConn.Close()
+	}
+
+	if m.multiPeerDevice == nil {
+		return nil, errors.New("multi peer device is not started")
+	}
+	device := m.multiPeerDevice
+
+	config, err := device.AddPeer(consumerConfig.PublicKey, consumerConfig.PresharedKey, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not add consumer peer")
+	}
+	config.Consumer.ConnectDelay = m.connectDelayMS
+	config.Consumer.PresharedKey = consumerConfig.PresharedKey != ""
+	if m.dnsOK {
+		config.Consumer.DNSIPs = device.Network().IP.String()
+	}
+
+	var consumerKeyMu sync.Mutex
+	consumerPublicKey := consumerConfig.PublicKey
+	stats := newRekeyedStatsSupplier(peerStatsSupplier{device: device, publicKey: consumerPublicKey})
+	statsPublisher := newStatsPublisher(m.eventBus, time.Second)
+	go statsPublisher.start(sessionID, stats)
+
+	rekey := func(newConfig wg.ConsumerConfig) error {
+		return stats.rotate(func() (statsSupplier, error) {
+			consumerKeyMu.Lock()
+			defer consumerKeyMu.Unlock()
+
+			if err := device.ReplacePeer(consumerPublicKey, newConfig.PublicKey, newConfig.PresharedKey); err != nil {
+				return nil, errors.Wrap(err, "could not replace consumer peer")
+			}
+			consumerPublicKey = newConfig.PublicKey
+			return peerStatsSupplier{device: device, publicKey: consumerPublicKey}, nil
+		})
+	}
+
+	updateConn := func(newConn *net.UDPConn) error {
+		// The consumer keeps connecting to the shared listen port, only its address changes.
+		newConn.Close()
+		return nil
+	}
+
+	destroy := func() {
+		log.Info().Msgf("Cleaning up session %s", sessionID)
+		m.sessionCleanupMu.Lock()
+		delete(m.sessionCleanup, sessionID)
+		delete(m.sessionRekey, sessionID)
+		delete(m.sessionConn, sessionID)
+		m.sessionCleanupMu.Unlock()
+
+		statsPublisher.stop()
+
+		consumerKeyMu.Lock()
+		defer consumerKeyMu.Unlock()
+		if err := device.RemovePeer(consumerPublicKey); err != nil {
+			log.Error().Err(err).Msg("Failed to remove consumer peer")
+		}
+	}
+
+	m.sessionCleanupMu.Lock()
+	m.sessionCleanup[sessionID] = destroy
+	if consumerConfig.PresharedKey != "" {
+		m.sessionRekey[sessionID] = rekey
+	}
+	m.sessionConn[sessionID] = updateConn
+	m.sessionCleanupMu.Unlock()
+
+	return &session.ConfigParams{SessionServiceConfig: config, SessionDestroyCallback: destroy}, nil
+}
@@ -0,0 +1,172 @@
+//+build !windows
+
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package service
+
+import (
+	"errors"
+	"net"
+	"sync"
+	"testing"
+
+	"github.com/mysteriumnetwork/node/core/port"
+	"github.com/mysteriumnetwork/node/eventbus"
+	"github.com/mysteriumnetwork/node/nat"
+	wg "github.com/mysteriumnetwork/node/services/wireguard"
+	"github.com/mysteriumnetwork/node/services/wireguard/resources"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Manager_ProvideConfig_MultiPeer(t *testing.T) {
+	device := &mockMultiPeerDevice{peers: map[string]net.IP{}}
+	manager := newManagerStub(pubIP, outIP, country)
+	manager.eventBus = eventbus.New()
+	manager.sessionCleanup = map[string]func(){}
+	manager.sessionConn = map[string]func(*net.UDPConn) error{}
+	manager.multiPeer = true
+	manager.multiPeerDevice = device
+	manager.connectDelayMS = 2000
+
+	params, err := manager.ProvideConfig("session1", []byte(`{"PublicKey": "consumer1"}`), nil)
+	assert.NoError(t, err)
+
+	config := params.SessionServiceConfig.(wg.ServiceConfig)
+	assert.Equal(t, "10.182.0.2/24", config.Consumer.IPAddress.String())
+	assert.Equal(t, 2000, config.Consumer.ConnectDelay)
+	assert.Contains(t, device.peers, "consumer1")
+	assert.Contains(t, manager.sessionCleanup, "session1")
+
+	// Consumer keeps connecting to the shared port once the session is resumed.
+	conn, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.ParseIP("127.0.0.1")})
+	assert.NoError(t, err)
+	assert.NoError(t, manager.UpdateServiceConn("session1", conn))
+	_, err = conn.Write([]byte("closed"))
+	assert.Error(t, err)
+
+	params.SessionDestroyCallback()
+	assert.NotContains(t, device.peers, "consumer1")
+	assert.NotContains(t, manager.sessionCleanup, "session1")
+	assert.NotContains(t, manager.sessionConn, "session1")
+}
+
+func Test_Manager_RotateKeys_MultiPeer(t *testing.T) {
+	device := &mockMultiPeerDevice{peers: map[string]net.IP{}}
+	manager := newManagerStub(pubIP, outIP, country)
+	manager.eventBus = eventbus.New()
+	manager.sessionCleanup = map[string]func(){}
+	manager.sessionRekey = map[string]func(wg.ConsumerConfig) error{}
+	manager.sessionConn = map[string]func(*net.UDPConn) error{}
+	manager.multiPeer = true
+	manager.multiPeerDevice = device
+
+	params, err := manager.ProvideConfig("session1", []byte(`{"PublicKey": "consumer1", "PresharedKey": "psk1"}`), nil)
+	assert.NoError(t, err)
+	assert.True(t, params.SessionServiceConfig.(wg.ServiceConfig).Consumer.PresharedKey)
+
+	err = manager.RotateKeys("session1", []byte(`{"PublicKey": "consumer2", "PresharedKey": "psk2"}`))
+	assert.NoError(t, err)
+	assert.NotContains(t, device.peers, "consumer1")
+	assert.Contains(t, device.peers, "consumer2")
+
+	err = manager.RotateKeys("unknown", []byte(`{"PublicKey": "consumer3", "PresharedKey": "psk3"}`))
+	assert.Error(t, err)
+
+	params.SessionDestroyCallback()
+	assert.Empty(t, device.peers)
+	assert.NotContains(t, manager.sessionRekey, "session1")
+}
+
+func Test_Manager_ProvideConfig_MultiPeerNotStarted(t *testing.T) {
+	manager := newManagerStub(pubIP, outIP, country)
+	manager.multiPeer = true
+
+	params, err := manager.ProvideConfig("session1", []byte(`{"PublicKey": "consumer1"}`), nil)
+	assert.Nil(t, params)
+	assert.EqualError(t, err, "multi peer device is not started")
+}
+
+func Test_Manager_StartMultiPeerDevice_UnwindsOnNATSetupFailure(t *testing.T) {
+	device := &mockMultiPeerDevice{peers: map[string]net.IP{}}
+	manager := newManagerStub(pubIP, outIP, country)
+	manager.outboundIP = "1.2.3.4"
+	manager.resourcesAllocator = resources.NewAllocator(port.NewPoolFixed(51820), net.IPNet{})
+	manager.natService = &failingNATService{}
+	manager.multiPeerDeviceFactory = func() (MultiPeerDevice, error) {
+		return device, nil
+	}
+
+	err := manager.startMultiPeerDevice()
+	assert.EqualError(t, err, "failed to setup NAT/firewall rules: nat setup failed")
+	assert.True(t, device.stopped)
+	assert.Empty(t, manager.resourcesAllocator.Ports)
+	assert.Nil(t, manager.multiPeerDevice)
+	assert.Nil(t, manager.multiPeerCleanup)
+}
+
+type failingNATService struct {
+	serviceFake
+}
+
+func (service *failingNATService) Setup(nat.Options) ([]interface{}, error) {
+	return nil, errors.New("nat setup failed")
+}
+
+type mockMultiPeerDevice struct {
+	mu      sync.Mutex
+	peers   map[string]net.IP
+	stopped bool
+}
+
+func (d *mockMultiPeerDevice) Start(_ wg.ProviderModeConfig) error { return nil }
+func (d *mockMultiPeerDevice) InterfaceName() string               { return "wg0" }
+func (d *mockMultiPeerDevice) Network() net.IPNet {
+	return net.IPNet{IP: net.ParseIP("10.182.0.1").To4(), Mask: net.CIDRMask(24, 32)}
+}
+func (d *mockMultiPeerDevice) Network6() net.IPNet { return net.IPNet{} }
+func (d *mockMultiPeerDevice) AddPeer(publicKey, _ string, _ *net.UDPAddr) (wg.ServiceConfig, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	ip := net.ParseIP("10.182.0.2").To4()
+	d.peers[publicKey] = ip
+
+	var config wg.ServiceConfig
+	config.Consumer.IPAddress = net.IPNet{IP: ip, Mask: net.CIDRMask(24, 32)}
+	return config, nil
+}
+func (d *mockMultiPeerDevice) ReplacePeer(publicKey, newPublicKey, _ string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.peers[newPublicKey] = d.peers[publicKey]
+	delete(d.peers, publicKey)
+	return nil
+}
+func (d *mockMultiPeerDevice) RemovePeer(publicKey string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	delete(d.peers, publicKey)
+	return nil
+}
+func (d *mockMultiPeerDevice) PeerStats(_ string) (*wg.Stats, error) { return &wg.Stats{}, nil }
+func (d *mockMultiPeerDevice) Stop() error {
+	d.stopped = true
+	return nil
+}
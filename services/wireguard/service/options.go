@@ -25,6 +25,7 @@ import (
 	"github.com/mysteriumnetwork/node/core/port"
 	"github.com/mysteriumnetwork/node/core/service"
 	"github.com/mysteriumnetwork/node/services/wireguard/resources"
+	"github.com/pkg/errors"
 	"github.com/rs/zerolog/log"
 )
 
@@ -33,6 +34,9 @@ type Options struct {
 	ConnectDelay int
 	Ports        *port.Range
 	Subnet       net.IPNet
+	// Subnet6 is an optional IPv6 ULA subnet, IPv6 egress is not provided if it is empty.
+	Subnet6   net.IPNet
+	MultiPeer bool
 }
 
 // DefaultOptions is a wireguard service configuration that will be used if no options provided.
@@ -63,13 +67,60 @@ func GetOptions() Options {
 			"using default value", resources.MaxConnections)
 		portRange = port.UnspecifiedRange()
 	}
+	subnet6, err := parseSubnet6(config.GetString(config.FlagWireguardListenSubnet6))
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to parse IPv6 subnet option, IPv6 is disabled")
+		subnet6 = net.IPNet{}
+	}
+	if err := validateSubnetPair(*ipnet, subnet6); err != nil {
+		log.Warn().Err(err).Msg("IPv6 subnet can not be used with the IPv4 subnet, IPv6 is disabled")
+		subnet6 = net.IPNet{}
+	}
 	return Options{
 		ConnectDelay: config.GetInt(config.FlagWireguardConnectDelay),
 		Ports:        portRange,
 		Subnet:       *ipnet,
+		Subnet6:      subnet6,
+		MultiPeer:    config.GetBool(config.FlagWireguardMultiPeer),
+	}
+}
+
+// parseSubnet6 parses the IPv6 subnet, every session gets a /64 from it so it has to be /56 or larger.
+func parseSubnet6(subnet string) (net.IPNet, error) {
+	if subnet == "" {
+		return net.IPNet{}, nil
+	}
+
+	ip, ipnet, err := net.ParseCIDR(subnet)
+	if err != nil {
+		return net.IPNet{}, err
+	}
+	if ip.To4() != nil {
+		return net.IPNet{}, errors.Errorf("%s is not an IPv6 subnet", subnet)
+	}
+	if !uniqueLocalNetwork.Contains(ip) {
+		return net.IPNet{}, errors.Errorf("%s is not an IPv6 unique local subnet (fc00::/7)", subnet)
 	}
+	if ones, _ := ipnet.Mask.Size(); ones > 56 {
+		return net.IPNet{}, errors.Errorf("IPv6 subnet %s is too small, /56 or larger is required", subnet)
+	}
+	return *ipnet, nil
 }
 
+// validateSubnetPair checks that every session network of the IPv4 subnet gets its own IPv6 /64.
+// Session /24 networks are told apart by the third octet only, which is also the index of the IPv6 /64.
+func validateSubnetPair(subnet, subnet6 net.IPNet) error {
+	if subnet6.IP == nil {
+		return nil
+	}
+	if ones, bits := subnet.Mask.Size(); bits != 8*net.IPv4len || ones != 16 {
+		return errors.Errorf("IPv4 subnet %s has to be /16 to be paired with the IPv6 subnet %s", subnet.String(), subnet6.String())
+	}
+	return nil
+}
+
+var uniqueLocalNetwork = net.IPNet{IP: net.ParseIP("fc00::"), Mask: net.CIDRMask(7, 128)}
+
 // ParseJSONOptions function fills in Wireguard options from JSON request
 func ParseJSONOptions(request *json.RawMessage) (service.Options, error) {
 	var requestOptions = GetOptions()
@@ -88,19 +139,32 @@ func (o Options) MarshalJSON() ([]byte, error) {
 		ConnectDelay int    `json:"connectDelay"`
 		Ports        string `json:"ports"`
 		Subnet       string `json:"subnet"`
+		Subnet6      string `json:"subnet6,omitempty"`
+		MultiPeer    bool   `json:"multiPeer"`
 	}{
 		ConnectDelay: o.ConnectDelay,
 		Ports:        o.Ports.String(),
 		Subnet:       o.Subnet.String(),
+		Subnet6:      o.subnet6String(),
+		MultiPeer:    o.MultiPeer,
 	})
 }
 
+func (o Options) subnet6String() string {
+	if o.Subnet6.IP == nil {
+		return ""
+	}
+	return o.Subnet6.String()
+}
+
 // UnmarshalJSON implements json.Unmarshaler interface to receive human readable configuration.
 func (o *Options) UnmarshalJSON(data []byte) error {
 	var options struct {
 		ConnectDelay int    `json:"connectDelay"`
 		Ports        string `json:"ports"`
 		Subnet       string `json:"subnet"`
+		Subnet6      string `json:"subnet6"`
+		MultiPeer    bool   `json:"multiPeer"`
 	}
 
 	if err := json.Unmarshal(data, &options); err != nil {
@@ -124,6 +188,17 @@ func (o *Options) UnmarshalJSON(data []byte) error {
 		}
 		o.Subnet = *ipnet
 	}
+	if len(options.Subnet6) > 0 {
+		ipnet, err := parseSubnet6(options.Subnet6)
+		if err != nil {
+			return err
+		}
+		o.Subnet6 = ipnet
+	}
+	if err := validateSubnetPair(o.Subnet, o.Subnet6); err != nil {
+		return err
+	}
+	o.MultiPeer = options.MultiPeer
 
 	return nil
 }
@@ -48,7 +48,7 @@ func Test_ParseJSONOptions_HandlesEmptyRequest(t *testing.T) {
 
 func Test_ParseJSONOptions_ValidRequest(t *testing.T) {
 	configureDefaults()
-	request := json.RawMessage(`{"connectDelay": 3000, "ports": "52820:53075", "subnet":"10.10.0.0/16"}`)
+	request := json.RawMessage(`{"connectDelay": 3000, "ports": "52820:53075", "subnet":"10.10.0.0/16", "multiPeer": true}`)
 	options, err := ParseJSONOptions(&request)
 
 	assert.NoError(t, err)
@@ -59,6 +59,7 @@ func Test_ParseJSONOptions_ValidRequest(t *testing.T) {
 			IP:   net.ParseIP("10.10.0.0").To4(),
 			Mask: net.IPv4Mask(255, 255, 0, 0),
 		},
+		MultiPeer: true,
 	}, options)
 }
 
@@ -70,3 +71,25 @@ func configureDefaults() {
 func emptyContext() *cli.Context {
 	return cli.NewContext(nil, flag.NewFlagSet("", flag.ContinueOnError), nil)
 }
+
+func Test_ParseJSONOptions_Subnet6(t *testing.T) {
+	configureDefaults()
+	request := json.RawMessage(`{"subnet6": "fd00:182::/48"}`)
+	options, err := ParseJSONOptions(&request)
+
+	assert.NoError(t, err)
+	subnet6 := options.(Options).Subnet6
+	assert.Equal(t, "fd00:182::/48", subnet6.String())
+
+	for _, subnet := range []string{"10.10.0.0/16", "2001:db8::/48", "fd00:182::/64"} {
+		request := json.RawMessage(`{"subnet6": "` + subnet + `"}`)
+		_, err := ParseJSONOptions(&request)
+		assert.Error(t, err, subnet)
+	}
+
+	for _, subnet := range []string{"10.0.0.0/8", "10.10.16.0/20"} {
+		request := json.RawMessage(`{"subnet": "` + subnet + `", "subnet6": "fd00:182::/48"}`)
+		_, err := ParseJSONOptions(&request)
+		assert.Error(t, err, subnet)
+	}
+}
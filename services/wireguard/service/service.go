@@ -18,13 +18,15 @@
 package service
 
 import (
+	"net"
+
 	"github.com/mysteriumnetwork/node/core/location"
 	"github.com/mysteriumnetwork/node/market"
 	wg "github.com/mysteriumnetwork/node/services/wireguard"
 )
 
 // GetProposal returns the proposal for wireguard service
-func GetProposal(location location.Location) market.ServiceProposal {
+func GetProposal(location location.Location, options Options) market.ServiceProposal {
 	marketLocation := market.Location{
 		Continent: location.Continent,
 		Country:   location.Country,
@@ -40,6 +42,20 @@ func GetProposal(location location.Location) market.ServiceProposal {
 		ServiceDefinition: wg.ServiceDefinition{
 			Location:          marketLocation,
 			LocationOriginate: marketLocation,
+			IPv6:              options.Subnet6.IP != nil,
 		},
 	}
 }
+
+// MultiPeerDevice is a single wireguard device serving many consumer peers.
+type MultiPeerDevice interface {
+	Start(config wg.ProviderModeConfig) error
+	InterfaceName() string
+	Network() net.IPNet
+	Network6() net.IPNet
+	AddPeer(publicKey, presharedKey string, endpoint *net.UDPAddr) (wg.ServiceConfig, error)
+	ReplacePeer(publicKey, newPublicKey, newPresharedKey string) error
+	RemovePeer(publicKey string) error
+	PeerStats(publicKey string) (*wg.Stats, error)
+	Stop() error
+}
@@ -54,10 +54,17 @@ func Test_GetProposal(t *testing.T) {
 				LocationOriginate: market.Location{Country: country},
 			},
 		},
-		GetProposal(location.Location{Country: country}),
+		GetProposal(location.Location{Country: country}, Options{}),
 	)
 }
 
+func Test_GetProposal_IPv6(t *testing.T) {
+	_, subnet6, _ := net.ParseCIDR("fd00:182::/48")
+
+	proposal := GetProposal(location.Location{Country: country}, Options{Subnet6: *subnet6})
+	assert.True(t, proposal.ServiceDefinition.(wg.ServiceDefinition).IPv6)
+}
+
 func Test_Manager_Stop(t *testing.T) {
 	manager := newManagerStub(pubIP, outIP, country)
 	service := service.NewInstance(
@@ -103,6 +110,8 @@ func (mce *mockConnectionEndpoint) Stop() error
 func (mce *mockConnectionEndpoint) Config() (wg.ServiceConfig, error)                    { return wg.ServiceConfig{}, nil }
 func (mce *mockConnectionEndpoint) AddPeer(_ string, _ wg.Peer) error                    { return nil }
 func (mce *mockConnectionEndpoint) RemovePeer(_ string) error                            { return nil }
+func (mce *mockConnectionEndpoint) SetPrivateKey(_ string) error                         { return nil }
+func (mce *mockConnectionEndpoint) SetListenPort(_ int) error                            { return nil }
 func (mce *mockConnectionEndpoint) ConfigureRoutes(_ net.IP) error                       { return nil }
 func (mce *mockConnectionEndpoint) PeerStats() (*wg.Stats, error) {
 	return &wg.Stats{LastHandshake: time.Now()}, nil
@@ -88,9 +88,17 @@ func NewManager(
 		connEndpointFactory: func() (wg.ConnectionEndpoint, error) {
 			return endpoint.NewConnectionEndpoint(resourcesAllocator)
 		},
+		multiPeer: options.MultiPeer,
+		subnet:    options.Subnet,
+		subnet6:   options.Subnet6,
+		multiPeerDeviceFactory: func() (MultiPeerDevice, error) {
+			return endpoint.NewMultiPeerEndpoint(resourcesAllocator)
+		},
 		country:        country,
 		connectDelayMS: options.ConnectDelay,
 		sessionCleanup: map[string]func(){},
+		sessionRekey:   map[string]func(wg.ConsumerConfig) error{},
+		sessionConn:    map[string]func(*net.UDPConn) error{},
 	}
 }
 
@@ -115,10 +123,19 @@ type Manager struct {
 
 	connEndpointFactory func() (wg.ConnectionEndpoint, error)
 
+	multiPeer              bool
+	subnet                 net.IPNet
+	subnet6                net.IPNet
+	multiPeerDevice        MultiPeerDevice
+	multiPeerDeviceFactory func() (MultiPeerDevice, error)
+	multiPeerCleanup       func()
+
 	ipResolver ip.Resolver
 
 	serviceInstance  *service.Instance
 	sessionCleanup   map[string]func()
+	sessionRekey     map[string]func(wg.ConsumerConfig) error
+	sessionConn      map[string]func(*net.UDPConn) error
 	sessionCleanupMu sync.Mutex
 
 	country        string
@@ -126,6 +143,12 @@ type Manager struct {
 	outboundIP     string
 }
 
+// SetMultiPeerDeviceFactory replaces the factory of the device shared by all the sessions in multi peer mode,
+// e.g. with the one creating userspace device on top of externally provided TUN device.
+func (m *Manager) SetMultiPeerDeviceFactory(factory func() (MultiPeerDevice, error)) {
+	m.multiPeerDeviceFactory = factory
+}
+
 // ProvideConfig provides the config for consumer and handles new WireGuard connection.
 func (m *Manager) ProvideConfig(sessionID string, sessionConfig json.RawMessage, remoteConn *net.UDPConn) (*session.ConfigParams, error) {
 	log.Info().Msg("Accepting new WireGuard connection")
@@ -135,11 +158,18 @@ func (m *Manager) ProvideConfig(sessionID string, sessionConfig json.RawMessage,
 		return nil, errors.Wrap(err, "could not unmarshal wg consumer config")
 	}
 
+	if m.multiPeer {
+		return m.provideMultiPeerConfig(sessionID, consumerConfig, remoteConn)
+	}
+
 	providerConfig := wg.ProviderModeConfig{}
 	providerConfig.Network, err = m.resourcesAllocator.AllocateIPNet()
 	if err != nil {
 		return nil, errors.Wrap(err, "could not allocate provider IP NET")
 	}
+	if m.subnet6.IP != nil {
+		providerConfig.Network6 = resources.IPNet6(m.subnet6, providerConfig.Network)
+	}
 
 	var traversalParams traversal.Params
 	var releasePortMapping func()
@@ -190,9 +220,10 @@ func (m *Manager) ProvideConfig(sessionID string, sessionConfig json.RawMessage,
 		config.Consumer.ConnectDelay = m.connectDelayMS
 	}
 
-	if err := m.addConsumerPeer(conn, config.LocalPort, config.RemotePort, consumerConfig.PublicKey); err != nil {
+	if err := m.addConsumerPeer(conn, config.LocalPort, config.RemotePort, consumerConfig.PublicKey, consumerConfig.PresharedKey); err != nil {
 		return nil, errors.Wrap(err, "could not add consumer peer")
 	}
+	config.Consumer.PresharedKey = consumerConfig.PresharedKey != ""
 
 	var dnsIP net.IP
 	var releaseTrafficFirewall firewall.IncomingRuleRemove
@@ -210,6 +241,7 @@ func (m *Manager) ProvideConfig(sessionID string, sessionConfig json.RawMessage,
 
 	natRules, err := m.natService.Setup(nat.Options{
 		VPNNetwork:        config.Consumer.IPAddress,
+		VPNNetwork6:       config.Consumer.IPAddress6,
 		DNSIP:             dnsIP,
 		ProviderExtIP:     net.ParseIP(m.outboundIP),
 		EnableDNSRedirect: m.dnsOK,
@@ -219,8 +251,30 @@ func (m *Manager) ProvideConfig(sessionID string, sessionConfig json.RawMessage,
 		return nil, errors.Wrap(err, "failed to setup NAT/firewall rules")
 	}
 
+	stats := newRekeyedStatsSupplier(conn)
 	statsPublisher := newStatsPublisher(m.eventBus, time.Second)
-	go statsPublisher.start(sessionID, conn)
+	go statsPublisher.start(sessionID, stats)
+
+	consumerPublicKey := consumerConfig.PublicKey
+	rekey := func(newConfig wg.ConsumerConfig) error {
+		return stats.rotate(func() (statsSupplier, error) {
+			// Allowed IPs are moved to the new peer once it is added, so the tunnel keeps working.
+			if err := m.addConsumerPeer(conn, config.LocalPort, config.RemotePort, newConfig.PublicKey, newConfig.PresharedKey); err != nil {
+				return nil, errors.Wrap(err, "could not add rotated consumer peer")
+			}
+			if err := conn.RemovePeer(consumerPublicKey); err != nil {
+				return nil, errors.Wrap(err, "could not remove replaced consumer peer")
+			}
+			consumerPublicKey = newConfig.PublicKey
+			return conn, nil
+		})
+	}
+
+	updateConn := func(newConn *net.UDPConn) error {
+		newConn.Close()
+		// Consumer punched the hole to the port of the new p2p channel, so the device is moved to it.
+		return conn.SetListenPort(newConn.LocalAddr().(*net.UDPAddr).Port)
+	}
 
 	ifaceName := conn.InterfaceName()
 	s := shaper.New(m.eventBus)
@@ -233,6 +287,8 @@ func (m *Manager) ProvideConfig(sessionID string, sessionConfig json.RawMessage,
 		log.Info().Msgf("Cleaning up session %s", sessionID)
 		m.sessionCleanupMu.Lock()
 		delete(m.sessionCleanup, sessionID)
+		delete(m.sessionRekey, sessionID)
+		delete(m.sessionConn, sessionID)
 		m.sessionCleanupMu.Unlock()
 
 		statsPublisher.stop()
@@ -263,15 +319,68 @@ func (m *Manager) ProvideConfig(sessionID string, sessionConfig json.RawMessage,
 		if err := m.resourcesAllocator.ReleaseIPNet(providerConfig.Network); err != nil {
 			log.Error().Err(err).Msg("Failed to release IP network")
 		}
+
+		if remoteConn == nil {
+			if err := m.resourcesAllocator.ReleasePort(providerConfig.ListenPort); err != nil {
+				log.Error().Err(err).Msg("Failed to release provider listen port")
+			}
+		}
 	}
 
 	m.sessionCleanupMu.Lock()
 	m.sessionCleanup[sessionID] = destroy
+	if consumerConfig.PresharedKey != "" {
+		m.sessionRekey[sessionID] = rekey
+	}
+	if remoteConn != nil {
+		m.sessionConn[sessionID] = updateConn
+	}
 	m.sessionCleanupMu.Unlock()
 
 	return &session.ConfigParams{SessionServiceConfig: config, SessionDestroyCallback: destroy, TraversalParams: traversalParams}, nil
 }
 
+// RotateKeys replaces the consumer peer of the session with the one using rotated keys.
+func (m *Manager) RotateKeys(sessionID string, sessionConfig json.RawMessage) error {
+	consumerConfig := wg.ConsumerConfig{}
+	if err := json.Unmarshal(sessionConfig, &consumerConfig); err != nil {
+		return errors.Wrap(err, "could not unmarshal wg consumer config")
+	}
+	if consumerConfig.PublicKey == "" || consumerConfig.PresharedKey == "" {
+		return errors.New("public and preshared keys are required")
+	}
+
+	m.sessionCleanupMu.Lock()
+	rekey, ok := m.sessionRekey[sessionID]
+	m.sessionCleanupMu.Unlock()
+	if !ok {
+		return errors.Errorf("key rotation is not enabled for session %s", sessionID)
+	}
+
+	if err := rekey(consumerConfig); err != nil {
+		return err
+	}
+	log.Info().Msgf("Keys of session %s rotated", sessionID)
+	return nil
+}
+
+// UpdateServiceConn moves the tunnel of the session to the service connection of the p2p channel it was resumed over.
+func (m *Manager) UpdateServiceConn(sessionID string, conn *net.UDPConn) error {
+	m.sessionCleanupMu.Lock()
+	updateConn, ok := m.sessionConn[sessionID]
+	m.sessionCleanupMu.Unlock()
+	if !ok {
+		conn.Close()
+		return errors.Errorf("service connection can not be updated for session %s", sessionID)
+	}
+
+	if err := updateConn(conn); err != nil {
+		return err
+	}
+	log.Info().Msgf("Session %s moved to the new service connection", sessionID)
+	return nil
+}
+
 func (m *Manager) tryAddPortMapping(pubIP string, port int) (release func(), ok bool) {
 	if !m.behindNAT(pubIP) {
 		return nil, false
@@ -296,7 +405,7 @@ func (m *Manager) startNewConnection(config wg.ProviderModeConfig) (wg.Connectio
 	return connEndpoint, nil
 }
 
-func (m *Manager) addConsumerPeer(conn wg.ConnectionEndpoint, consumerPort, providerPort int, peerPublicKey string) error {
+func (m *Manager) addConsumerPeer(conn wg.ConnectionEndpoint, consumerPort, providerPort int, peerPublicKey, presharedKey string) error {
 	var peerEndpoint *net.UDPAddr
 	if consumerPort > 0 {
 		var err error
@@ -306,9 +415,10 @@ func (m *Manager) addConsumerPeer(conn wg.ConnectionEndpoint, consumerPort, prov
 		}
 	}
 	peerOpts := wg.Peer{
-		PublicKey:  peerPublicKey,
-		Endpoint:   peerEndpoint,
-		AllowedIPs: []string{"0.0.0.0/0", "::/0"},
+		PublicKey:    peerPublicKey,
+		PresharedKey: presharedKey,
+		Endpoint:     peerEndpoint,
+		AllowedIPs:   []string{"0.0.0.0/0", "::/0"},
 	}
 	return conn.AddPeer(conn.InterfaceName(), peerOpts)
 }
@@ -385,6 +495,13 @@ func (m *Manager) Serve(instance *service.Instance) error {
 		log.Warn().Err(err).Msg("Provider DNS will not be available")
 	}
 
+	if m.multiPeer {
+		if err := m.startMultiPeerDevice(); err != nil {
+			m.startStopMu.Unlock()
+			return err
+		}
+	}
+
 	m.startStopMu.Unlock()
 	log.Info().Msg("Wireguard: started")
 	<-m.done
@@ -407,6 +524,10 @@ func (m *Manager) Stop() error {
 	}
 	cleanupWg.Wait()
 
+	if m.multiPeerCleanup != nil {
+		m.multiPeerCleanup()
+	}
+
 	// Stop DNS proxy.
 	if m.dnsProxy != nil {
 		if err := m.dnsProxy.Stop(); err != nil {
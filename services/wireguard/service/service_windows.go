@@ -64,6 +64,9 @@ func NewManager(
 // Manager represents an instance of Wireguard service
 type Manager struct{}
 
+// SetMultiPeerDeviceFactory replaces the factory of the device shared by all the sessions in multi peer mode.
+func (manager *Manager) SetMultiPeerDeviceFactory(_ func() (MultiPeerDevice, error)) {}
+
 // ProvideConfig provides the config for consumer
 func (manager *Manager) ProvideConfig(_ string, _ json.RawMessage, _ *net.UDPConn) (*session.ConfigParams, error) {
 	return nil, errors.New("not implemented")
@@ -18,6 +18,7 @@
 package service
 
 import (
+	"sync"
 	"time"
 
 	"github.com/mysteriumnetwork/node/eventbus"
@@ -68,3 +69,51 @@ func (s statsPublisher) start(sessionID string, supplier statsSupplier) {
 func (s statsPublisher) stop() {
 	s.done <- struct{}{}
 }
+
+// rekeyedStatsSupplier keeps the session stats growing across the peer key rotations,
+// as the counters of a peer added with the new key start from zero.
+type rekeyedStatsSupplier struct {
+	mu       sync.Mutex
+	supplier statsSupplier
+	base     wg.Stats
+}
+
+func newRekeyedStatsSupplier(supplier statsSupplier) *rekeyedStatsSupplier {
+	return &rekeyedStatsSupplier{supplier: supplier}
+}
+
+func (s *rekeyedStatsSupplier) PeerStats() (*wg.Stats, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats, err := s.supplier.PeerStats()
+	if err != nil {
+		return nil, err
+	}
+	return &wg.Stats{
+		BytesSent:     s.base.BytesSent + stats.BytesSent,
+		BytesReceived: s.base.BytesReceived + stats.BytesReceived,
+		LastHandshake: stats.LastHandshake,
+	}, nil
+}
+
+// rotate replaces the peer using rekey func, counters of the replaced peer are carried over.
+func (s *rekeyedStatsSupplier) rotate(rekey func() (statsSupplier, error)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats, err := s.supplier.PeerStats()
+	if err != nil {
+		return err
+	}
+
+	next, err := rekey()
+	if err != nil {
+		return err
+	}
+
+	s.base.BytesSent += stats.BytesSent
+	s.base.BytesReceived += stats.BytesReceived
+	s.supplier = next
+	return nil
+}
@@ -60,3 +60,17 @@ func Test_statsPublisher_start(t *testing.T) {
 		return bus.Pop() != nil
 	}, 1*time.Second, 5*time.Millisecond)
 }
+
+func Test_rekeyedStatsSupplier(t *testing.T) {
+	supplier := newRekeyedStatsSupplier(&fakeSupplier{})
+
+	err := supplier.rotate(func() (statsSupplier, error) {
+		return &fakeSupplier{}, nil
+	})
+	assert.NoError(t, err)
+
+	stats, err := supplier.PeerStats()
+	assert.NoError(t, err)
+	assert.EqualValues(t, 50, stats.BytesSent)
+	assert.EqualValues(t, 104, stats.BytesReceived)
+}
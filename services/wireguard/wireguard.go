@@ -40,6 +40,9 @@ type ServiceDefinition struct {
 	// Approximate information on location where the actual tunnelled traffic will originate from.
 	// This is used by providers having their own means of setting tunnels to other remote exit points.
 	LocationOriginate market.Location `json:"location_originate"`
+
+	// IPv6 indicates that the tunnel provides IPv6 egress in addition to IPv4.
+	IPv6 bool `json:"ipv6,omitempty"`
 }
 
 // GetLocation returns geographic location of service definition provider
@@ -47,6 +50,11 @@ func (service ServiceDefinition) GetLocation() market.Location {
 	return service.Location
 }
 
+// SupportsIPv6 tells if the tunnel provides IPv6 egress.
+func (service ServiceDefinition) SupportsIPv6() bool {
+	return service.IPv6
+}
+
 // EndpointFactory creates new connection endpoint.
 type EndpointFactory func() (ConnectionEndpoint, error)
 
@@ -56,6 +64,9 @@ type ConnectionEndpoint interface {
 	StartConsumerMode(config ConsumerModeConfig) error
 	StartProviderMode(config ProviderModeConfig) error
 	AddPeer(iface string, peer Peer) error
+	RemovePeer(publicKey string) error
+	SetPrivateKey(privateKey string) error
+	SetListenPort(port int) error
 	PeerStats() (*Stats, error)
 	ConfigureRoutes(ip net.IP) error
 	Config() (ServiceConfig, error)
@@ -67,12 +78,15 @@ type ConnectionEndpoint interface {
 type ConsumerModeConfig struct {
 	PrivateKey string
 	IPAddress  net.IPNet
+	IPAddress6 net.IPNet
 	ListenPort int
 }
 
 // ProviderModeConfig is provider endpoint startup configuration.
 type ProviderModeConfig struct {
-	Network    net.IPNet
+	Network net.IPNet
+	// Network6 is an optional IPv6 network of the tunnel, IPv4 only tunnel is created if it is empty.
+	Network6   net.IPNet
 	ListenPort int
 	PublicIP   string
 }
@@ -83,6 +97,8 @@ type ConsumerConfig struct {
 	// IP is needed when provider is behind NAT. In such case provider parses this IP and tries to ping consumer.
 	IP    string `json:"IP,omitempty"`
 	Ports []int  `json:"Ports"`
+	// PresharedKey is an additional symmetric key mixed into the handshake, it is sent over the encrypted p2p channel only.
+	PresharedKey string `json:"PresharedKey,omitempty"`
 }
 
 // ServiceConfig represent a Wireguard service provider configuration that will be passed to the consumer for establishing a connection.
@@ -95,24 +111,34 @@ type ServiceConfig struct {
 	Provider struct {
 		PublicKey string
 		Endpoint  net.UDPAddr
+		// SharedEndpoint tells that Endpoint is the listen port shared by all the peers of the provider,
+		// so it is used instead of the p2p channel address.
+		SharedEndpoint bool
 	}
 	Consumer struct {
-		IPAddress    net.IPNet
+		IPAddress net.IPNet
+		// IPAddress6 is empty if the provider does not offer IPv6 egress.
+		IPAddress6   net.IPNet
 		DNSIPs       string
 		ConnectDelay int
+		// PresharedKey tells if the provider applied the consumer preshared key and supports the key rotation.
+		PresharedKey bool
 	}
 }
 
 // MarshalJSON implements json.Marshaler interface to provide human readable configuration.
 func (s ServiceConfig) MarshalJSON() ([]byte, error) {
 	type provider struct {
-		PublicKey string `json:"public_key"`
-		Endpoint  string `json:"endpoint"`
+		PublicKey      string `json:"public_key"`
+		Endpoint       string `json:"endpoint"`
+		SharedEndpoint bool   `json:"shared_endpoint,omitempty"`
 	}
 	type consumer struct {
 		IPAddress    string `json:"ip_address"`
+		IPAddress6   string `json:"ip_address6,omitempty"`
 		DNSIPs       string `json:"dns_ips"`
 		ConnectDelay int    `json:"connect_delay"`
+		PresharedKey bool   `json:"preshared_key,omitempty"`
 	}
 
 	return json.Marshal(&struct {
@@ -126,13 +152,16 @@ func (s ServiceConfig) MarshalJSON() ([]byte, error) {
 		LocalPort:  s.LocalPort,
 		RemotePort: s.RemotePort,
 		Provider: provider{
-			PublicKey: s.Provider.PublicKey,
-			Endpoint:  s.Provider.Endpoint.String(),
+			PublicKey:      s.Provider.PublicKey,
+			Endpoint:       s.Provider.Endpoint.String(),
+			SharedEndpoint: s.Provider.SharedEndpoint,
 		},
 		Consumer: consumer{
 			IPAddress:    s.Consumer.IPAddress.String(),
+			IPAddress6:   ipNet6String(s.Consumer.IPAddress6),
 			ConnectDelay: s.Consumer.ConnectDelay,
 			DNSIPs:       s.Consumer.DNSIPs,
+			PresharedKey: s.Consumer.PresharedKey,
 		},
 	})
 }
@@ -140,13 +169,16 @@ func (s ServiceConfig) MarshalJSON() ([]byte, error) {
 // UnmarshalJSON implements json.Unmarshaler interface to receive human readable configuration.
 func (s *ServiceConfig) UnmarshalJSON(data []byte) error {
 	type provider struct {
-		PublicKey string `json:"public_key"`
-		Endpoint  string `json:"endpoint"`
+		PublicKey      string `json:"public_key"`
+		Endpoint       string `json:"endpoint"`
+		SharedEndpoint bool   `json:"shared_endpoint,omitempty"`
 	}
 	type consumer struct {
 		IPAddress    string `json:"ip_address"`
+		IPAddress6   string `json:"ip_address6,omitempty"`
 		DNSIPs       string `json:"dns_ips"`
 		ConnectDelay int    `json:"connect_delay"`
+		PresharedKey bool   `json:"preshared_key,omitempty"`
 	}
 	var config struct {
 		LocalPort  int      `json:"local_port"`
@@ -174,18 +206,38 @@ func (s *ServiceConfig) UnmarshalJSON(data []byte) error {
 	s.RemotePort = config.RemotePort
 	s.Provider.Endpoint = *endpoint
 	s.Provider.PublicKey = config.Provider.PublicKey
+	s.Provider.SharedEndpoint = config.Provider.SharedEndpoint
 	s.Consumer.DNSIPs = config.Consumer.DNSIPs
 	s.Consumer.IPAddress = *ipnet
 	s.Consumer.IPAddress.IP = ip
 	s.Consumer.ConnectDelay = config.Consumer.ConnectDelay
+	s.Consumer.PresharedKey = config.Consumer.PresharedKey
+
+	if config.Consumer.IPAddress6 != "" {
+		ip6, ipnet6, err := net.ParseCIDR(config.Consumer.IPAddress6)
+		if err != nil {
+			return err
+		}
+		s.Consumer.IPAddress6 = *ipnet6
+		s.Consumer.IPAddress6.IP = ip6
+	}
 
 	return nil
 }
 
+func ipNet6String(ipNet net.IPNet) string {
+	if ipNet.IP == nil {
+		return ""
+	}
+	return ipNet.String()
+}
+
 // DeviceConfig describes wireguard device configuration.
 type DeviceConfig struct {
 	IfaceName string
 	Subnet    net.IPNet
+	// Subnet6 is an optional IPv6 address of the device.
+	Subnet6 net.IPNet
 
 	PrivateKey string
 	ListenPort int
@@ -209,6 +261,7 @@ func (dc *DeviceConfig) Encode() string {
 // Peer represents wireguard peer.
 type Peer struct {
 	PublicKey              string
+	PresharedKey           string
 	Endpoint               *net.UDPAddr
 	AllowedIPs             []string
 	KeepAlivePeriodSeconds int
@@ -225,6 +278,13 @@ func (p *Peer) Encode() string {
 	}
 	hexKey := hex.EncodeToString(keyBytes)
 	res.WriteString(fmt.Sprintf("public_key=%s\n", hexKey))
+	if p.PresharedKey != "" {
+		pskBytes, err := base64.StdEncoding.DecodeString(p.PresharedKey)
+		if err != nil {
+			return ""
+		}
+		res.WriteString(fmt.Sprintf("preshared_key=%s\n", hex.EncodeToString(pskBytes)))
+	}
 	res.WriteString(fmt.Sprintf("persistent_keepalive_interval=%d\n", p.KeepAlivePeriodSeconds))
 	if p.Endpoint != nil {
 		res.WriteString(fmt.Sprintf("endpoint=%s\n", p.Endpoint.String()))
@@ -255,3 +315,16 @@ func ParseDevicePeerStats(d *UserspaceDevice) (*Stats, error) {
 		LastHandshake: p.LastHandshakeTime,
 	}, nil
 }
+
+// ParseDevicePeersStats parses stats of all the device peers, keyed by peer public key.
+func ParseDevicePeersStats(d *UserspaceDevice) map[string]Stats {
+	stats := make(map[string]Stats, len(d.Peers))
+	for _, p := range d.Peers {
+		stats[p.PublicKey] = Stats{
+			BytesSent:     uint64(p.TransmitBytes),
+			BytesReceived: uint64(p.ReceiveBytes),
+			LastHandshake: p.LastHandshakeTime,
+		}
+	}
+	return stats
+}
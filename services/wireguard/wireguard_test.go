@@ -176,6 +176,17 @@ persistent_keepalive_interval=20
 endpoint=182.122.22.19:3233
 allowed_ip=192.168.4.10/32
 allowed_ip=192.168.4.11/32
+`,
+		},
+		{
+			name: "Test encode with preshared key",
+			peer: Peer{
+				PublicKey:    "DyxwLJ++jVO+azusu7rPEnzdgfm+0fiOBQ1GTbkk3QQ=",
+				PresharedKey: "AAECAwQFBgcICQoLDA0ODxAREhMUFRYXGBkaGxwdHh8=",
+			},
+			expected: `public_key=0f2c702c9fbe8d53be6b3bacbbbacf127cdd81f9bed1f88e050d464db924dd04
+preshared_key=000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f
+persistent_keepalive_interval=0
 `,
 		},
 		{
@@ -249,16 +260,19 @@ func TestServiceConfig_MarshalJSON(t *testing.T) {
 		LocalPort:  51000,
 		RemotePort: 51001,
 		Provider: struct {
-			PublicKey string
-			Endpoint  net.UDPAddr
+			PublicKey      string
+			Endpoint       net.UDPAddr
+			SharedEndpoint bool
 		}{
 			PublicKey: "wg1",
 			Endpoint:  *endpoint,
 		},
 		Consumer: struct {
 			IPAddress    net.IPNet
+			IPAddress6   net.IPNet
 			DNSIPs       string
 			ConnectDelay int
+			PresharedKey bool
 		}{
 			IPAddress: net.IPNet{
 				IP:   net.IPv4(127, 0, 0, 1),
@@ -285,16 +299,19 @@ func TestServiceConfig_UnmarshalJSON(t *testing.T) {
 		LocalPort:  51000,
 		RemotePort: 51001,
 		Provider: struct {
-			PublicKey string
-			Endpoint  net.UDPAddr
+			PublicKey      string
+			Endpoint       net.UDPAddr
+			SharedEndpoint bool
 		}{
 			PublicKey: "wg1",
 			Endpoint:  *endpoint,
 		},
 		Consumer: struct {
 			IPAddress    net.IPNet
+			IPAddress6   net.IPNet
 			DNSIPs       string
 			ConnectDelay int
+			PresharedKey bool
 		}{
 			IPAddress: net.IPNet{
 				IP:   net.IPv4(127, 0, 0, 1),
@@ -311,3 +328,21 @@ func TestServiceConfig_UnmarshalJSON(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, expecteConfig, actualConfig)
 }
+
+func TestServiceConfig_SharedEndpointRoundTrip(t *testing.T) {
+	endpoint, _ := net.ResolveUDPAddr("udp4", "1.2.3.4:51820")
+	config := ServiceConfig{}
+	config.Provider.PublicKey = "wg1"
+	config.Provider.Endpoint = *endpoint
+	config.Provider.SharedEndpoint = true
+	config.Consumer.IPAddress = net.IPNet{IP: net.IPv4(10, 182, 0, 2), Mask: net.IPv4Mask(255, 255, 0, 0)}
+
+	configBytes, err := json.Marshal(config)
+	assert.NoError(t, err)
+	assert.Contains(t, string(configBytes), `"shared_endpoint":true`)
+
+	var actualConfig ServiceConfig
+	assert.NoError(t, json.Unmarshal(configBytes, &actualConfig))
+	assert.True(t, actualConfig.Provider.SharedEndpoint)
+	assert.Equal(t, "1.2.3.4:51820", actualConfig.Provider.Endpoint.String())
+}
@@ -0,0 +1,41 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package session
+
+import "sync/atomic"
+
+// Drain is shared by session managers of all services, once started managers reject new sessions
+// while the running ones are left to finish.
+type Drain struct {
+	draining int32
+}
+
+// NewDrain returns drain which is not started.
+func NewDrain() *Drain {
+	return &Drain{}
+}
+
+// Start makes session managers reject new sessions.
+func (d *Drain) Start() {
+	atomic.StoreInt32(&d.draining, 1)
+}
+
+// Draining checks if new sessions are rejected.
+func (d *Drain) Draining() bool {
+	return d != nil && atomic.LoadInt32(&d.draining) == 1
+}
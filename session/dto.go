@@ -22,6 +22,7 @@ import (
 
 	"github.com/gofrs/uuid"
 	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/p2p"
 )
 
 // ID represents session id type.
@@ -48,8 +49,12 @@ type Session struct {
 	CreatedAt       time.Time
 	DataTransferred DataTransferred
 	TokensEarned    uint64
+	KeyRotations    int
+	KeyRotatedAt    time.Time
+	ChannelStats    *p2p.ChannelStats
 	Last            bool
 	done            chan struct{}
+	channel         p2p.Channel
 }
 
 // Done returns readonly done channel.
@@ -18,6 +18,9 @@
 package session
 
 import (
+	"time"
+
+	"github.com/mysteriumnetwork/node/p2p"
 	"github.com/mysteriumnetwork/node/session/event"
 )
 
@@ -31,6 +34,8 @@ type storage interface {
 	GetAll() []Session
 	UpdateDataTransfer(id ID, up, down uint64)
 	UpdateEarnings(id ID, total uint64)
+	UpdateKeyRotation(id ID, at time.Time)
+	UpdateChannelStats(id ID, stats p2p.ChannelStats)
 	Find(id ID) (Session, bool)
 	FindBy(opts FindOpts) (ID, bool)
 	Remove(id ID)
@@ -89,6 +94,27 @@ func (ebs *EventBasedStorage) UpdateDataTransfer(id ID, up, down uint64) {
 	})
 }
 
+// UpdateKeyRotation records the session key rotation
+func (ebs *EventBasedStorage) UpdateKeyRotation(id ID, at time.Time) {
+	ebs.storage.UpdateKeyRotation(id, at)
+	go ebs.bus.Publish(event.AppTopicSession, event.Payload{
+		ID:     string(id),
+		Action: event.Updated,
+	})
+}
+
+func (ebs *EventBasedStorage) consumeChannelStatsEvent(e p2p.AppEventChannelStats) {
+	if _, found := ebs.storage.Find(ID(e.SessionID)); !found {
+		return
+	}
+
+	ebs.storage.UpdateChannelStats(ID(e.SessionID), e.Stats)
+	go ebs.bus.Publish(event.AppTopicSession, event.Payload{
+		ID:     e.SessionID,
+		Action: event.Updated,
+	})
+}
+
 // Find finds a session
 func (ebs *EventBasedStorage) Find(id ID) (Session, bool) {
 	return ebs.storage.Find(id)
@@ -120,5 +146,8 @@ func (ebs *EventBasedStorage) Subscribe() error {
 	if err := ebs.bus.SubscribeAsync(event.AppTopicSessionTokensEarned, ebs.consumeTokensEarnedEvent); err != nil {
 		return err
 	}
+	if err := ebs.bus.SubscribeAsync(p2p.AppTopicChannelStats, ebs.consumeChannelStatsEvent); err != nil {
+		return err
+	}
 	return nil
 }
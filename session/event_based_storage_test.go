@@ -23,6 +23,7 @@ import (
 
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/mocks"
+	"github.com/mysteriumnetwork/node/p2p"
 	sessionEvent "github.com/mysteriumnetwork/node/session/event"
 	"github.com/stretchr/testify/assert"
 )
@@ -88,6 +89,27 @@ func TestNewEventBasedStorage_HandlesAppEventTokensEarned(t *testing.T) {
 	assert.Eventually(t, lastEventMatches(mp, session.ID, sessionEvent.Updated), 2*time.Second, 10*time.Millisecond)
 }
 
+func TestNewEventBasedStorage_HandlesAppEventChannelStats(t *testing.T) {
+	// given
+	session := expectedSession
+	mp := mocks.NewEventBus()
+	sessionStore := NewEventBasedStorage(mp, NewStorageMemory())
+	sessionStore.Add(session)
+
+	assert.Eventually(t, lastEventMatches(mp, session.ID, sessionEvent.Created), 1*time.Second, 5*time.Millisecond)
+
+	// when
+	sessionStore.consumeChannelStatsEvent(p2p.AppEventChannelStats{
+		SessionID: string(session.ID),
+		Stats:     p2p.ChannelStats{RTT: 40 * time.Millisecond, Retransmits: 2},
+	})
+	// then
+	storedSession, ok := sessionStore.Find(session.ID)
+	assert.True(t, ok)
+	assert.Equal(t, &p2p.ChannelStats{RTT: 40 * time.Millisecond, Retransmits: 2}, storedSession.ChannelStats)
+	assert.Eventually(t, lastEventMatches(mp, session.ID, sessionEvent.Updated), 2*time.Second, 10*time.Millisecond)
+}
+
 func TestEventBasedStorage_PublishesEventsOnRemoveForService(t *testing.T) {
 	session := expectedSession
 	mp := mocks.NewEventBus()
@@ -20,6 +20,7 @@ package session
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"net"
 	"sync"
 	"time"
@@ -42,8 +43,22 @@ var (
 	ErrorSessionNotExists = errors.New("session does not exists")
 	// ErrorWrongSessionOwner returned when consumer tries to destroy session that does not belongs to him
 	ErrorWrongSessionOwner = errors.New("wrong session owner")
+	// ErrorInvalidSignature returned when consumer signature of session resume request is not valid
+	ErrorInvalidSignature = errors.New("invalid signature")
+	// ErrorResumeNotSupported returned when session can't be moved to another p2p channel
+	ErrorResumeNotSupported = errors.New("session resume is not supported")
+	// ErrorDraining returned when provider is shutting down and does not accept new sessions
+	ErrorDraining = errors.New("provider is shutting down, new sessions are not accepted")
 )
 
+// ResumeMaxClockSkew is the maximum allowed difference between session resume request timestamp and local time.
+const ResumeMaxClockSkew = 10 * time.Minute
+
+// ResumeMessage returns the message which consumer signs to resume the session over a new p2p channel.
+func ResumeMessage(sessionID ID, timestamp int64) []byte {
+	return []byte(fmt.Sprintf("resume:%s:%d", sessionID, timestamp))
+}
+
 // IDGenerator defines method for session id generation
 type IDGenerator func() (ID, error)
 
@@ -101,6 +116,7 @@ type PromiseProcessor interface {
 type Storage interface {
 	Add(sessionInstance Session)
 	Find(id ID) (Session, bool)
+	UpdateKeyRotation(id ID, at time.Time)
 	Remove(id ID)
 }
 
@@ -122,6 +138,7 @@ func NewManager(
 	serviceId string,
 	publisher publisher,
 	channel p2p.Channel,
+	drain *Drain,
 	config Config,
 ) *Manager {
 	return &Manager{
@@ -133,6 +150,7 @@ func NewManager(
 		publisher:            publisher,
 		paymentEngineFactory: paymentEngineFactory,
 		channel:              channel,
+		drain:                drain,
 		config:               config,
 	}
 }
@@ -148,6 +166,7 @@ type Manager struct {
 	publisher            publisher
 	creationLock         sync.Mutex
 	channel              p2p.Channel
+	drain                *Drain
 	config               Config
 }
 
@@ -157,6 +176,10 @@ func (manager *Manager) Start(session *Session, consumerID identity.Identity, co
 	manager.creationLock.Lock()
 	defer manager.creationLock.Unlock()
 
+	if manager.drain.Draining() {
+		return ErrorDraining
+	}
+
 	if manager.currentProposal.ID != proposalID {
 		err = ErrorInvalidProposal
 		return
@@ -168,6 +191,7 @@ func (manager *Manager) Start(session *Session, consumerID identity.Identity, co
 	session.done = make(chan struct{})
 	session.Config = config
 	session.CreatedAt = time.Now().UTC()
+	session.channel = manager.channel
 
 	log.Info().Msg("Using new payments")
 	engine, err := manager.paymentEngineFactory(identity.FromAddress(manager.currentProposal.ProviderID), consumerID, consumerInfo.AccountantID, string(session.ID))
@@ -223,6 +247,68 @@ func (manager *Manager) Acknowledge(consumerID identity.Identity, sessionID stri
 	return nil
 }
 
+// RotateKeys rotates the keys of the given session using rotate func and records the rotation.
+func (manager *Manager) RotateKeys(consumerID identity.Identity, sessionID string, rotate func() error) error {
+	session, found := manager.sessionStorage.Find(ID(sessionID))
+	if !found {
+		return ErrorSessionNotExists
+	}
+
+	if session.ConsumerID != consumerID {
+		return ErrorWrongSessionOwner
+	}
+
+	if err := rotate(); err != nil {
+		return err
+	}
+
+	manager.sessionStorage.UpdateKeyRotation(ID(sessionID), time.Now().UTC())
+	return nil
+}
+
+// Resume moves the session to the p2p channel of this manager, so that payments and keep alive pings
+// continue over it and the tunnel is moved to its service connection using updateConn func.
+// Consumer proves the ownership of the session by signing ResumeMessage.
+// Previous p2p channel of the session is returned, caller is responsible for closing it.
+func (manager *Manager) Resume(consumerID identity.Identity, sessionID string, timestamp int64, signature []byte, updateConn func(conn *net.UDPConn) error) (p2p.Channel, error) {
+	manager.creationLock.Lock()
+	defer manager.creationLock.Unlock()
+
+	session, found := manager.sessionStorage.Find(ID(sessionID))
+	if !found {
+		return nil, ErrorSessionNotExists
+	}
+
+	if session.ConsumerID != consumerID {
+		return nil, ErrorWrongSessionOwner
+	}
+
+	switchable, ok := session.channel.(*p2p.SwitchableChannel)
+	if !ok || manager.channel == nil {
+		return nil, ErrorResumeNotSupported
+	}
+
+	verifier := identity.NewVerifierIdentity(consumerID)
+	if !verifier.Verify(ResumeMessage(session.ID, timestamp), identity.SignatureBytes(signature)) {
+		return nil, ErrorInvalidSignature
+	}
+
+	skew := time.Since(time.Unix(timestamp, 0))
+	if skew > ResumeMaxClockSkew || skew < -ResumeMaxClockSkew {
+		return nil, fmt.Errorf("resume request timestamp is off by %s: %w", skew, ErrorInvalidSignature)
+	}
+
+	if conn := manager.channel.ServiceConn(); conn != nil {
+		if err := updateConn(conn); err != nil {
+			return nil, fmt.Errorf("could not move session to the new service connection: %w", err)
+		}
+	}
+
+	previous := switchable.Switch(manager.channel)
+	log.Info().Msgf("Session %s resumed over new p2p channel", sessionID)
+	return previous, nil
+}
+
 // Destroy destroys session by given sessionID
 func (manager *Manager) Destroy(consumerID identity.Identity, sessionID string) error {
 	manager.creationLock.Lock()
@@ -279,6 +365,8 @@ func (manager *Manager) keepAliveLoop(sess *Session, channel p2p.Channel) {
 			} else {
 				errCount = 0
 			}
+
+			manager.publisher.Publish(p2p.AppTopicChannelStats, p2p.AppEventChannelStats{SessionID: string(sess.ID), Stats: channel.Stats()})
 		}
 	}
 }
@@ -18,16 +18,23 @@
 package session
 
 import (
+	"context"
+	"errors"
+	"net"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/mysteriumnetwork/node/eventbus"
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/market"
 	"github.com/mysteriumnetwork/node/mocks"
 	"github.com/mysteriumnetwork/node/nat/event"
 	"github.com/mysteriumnetwork/node/nat/traversal"
+	"github.com/mysteriumnetwork/node/p2p"
 	sessionEvent "github.com/mysteriumnetwork/node/session/event"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 var (
@@ -95,6 +102,21 @@ func TestManager_Start_RejectsUnknownProposal(t *testing.T) {
 	assert.Empty(t, session.CreatedAt)
 }
 
+func TestManager_Start_RejectsWhenDraining(t *testing.T) {
+	sessionStore := NewStorageMemory()
+	drain := NewDrain()
+
+	manager := NewManager(currentProposal, sessionStore, mockPaymentEngineFactory, traversal.NewNoopPinger(),
+		&MockNatEventTracker{}, "test service id", mocks.NewEventBus(), nil, drain, DefaultConfig())
+	drain.Start()
+
+	session, err := NewSession()
+	assert.NoError(t, err)
+	err = manager.Start(session, consumerID, ConsumerInfo{IssuerID: consumerID}, currentProposalID, nil, nil)
+	assert.Exactly(t, ErrorDraining, err)
+	assert.Empty(t, sessionStore.GetAll())
+}
+
 type MockNatEventTracker struct {
 }
 
@@ -143,7 +165,171 @@ func TestManager_AcknowledgeSession_PublishesEvent(t *testing.T) {
 	assert.Eventually(t, lastEventMatches(mp, session.ID, sessionEvent.Acknowledged), 2*time.Second, 10*time.Millisecond)
 }
 
+func TestManager_RotateKeys(t *testing.T) {
+	sessionStore := NewStorageMemory()
+	manager := newManager(currentProposal, sessionStore)
+
+	session, err := NewSession()
+	assert.NoError(t, err)
+	err = manager.Start(session, consumerID, ConsumerInfo{IssuerID: consumerID}, currentProposalID, nil, nil)
+	assert.NoError(t, err)
+
+	var rotated int
+	rotate := func() error {
+		rotated++
+		return nil
+	}
+
+	err = manager.RotateKeys(identity.FromAddress("some other id"), string(session.ID), rotate)
+	assert.Exactly(t, ErrorWrongSessionOwner, err)
+	err = manager.RotateKeys(consumerID, "unknown", rotate)
+	assert.Exactly(t, ErrorSessionNotExists, err)
+
+	err = manager.RotateKeys(consumerID, string(session.ID), rotate)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, rotated)
+
+	stored, found := sessionStore.Find(session.ID)
+	assert.True(t, found)
+	assert.Equal(t, 1, stored.KeyRotations)
+	assert.False(t, stored.KeyRotatedAt.IsZero())
+}
+
+func TestManager_Resume(t *testing.T) {
+	ks := identity.NewKeystoreFilesystem("dir", identity.NewMockKeystore(identity.MockKeys), identity.MockDecryptFunc)
+	owner := identity.FromAddress("0x53a835143c0ef3bbcbfa796d7eb738ca7dd28f68")
+	require.NoError(t, identity.NewIdentityManager(ks, eventbus.New()).Unlock(owner.Address, ""))
+	signer := identity.NewSigner(ks, owner)
+
+	serviceConn, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.ParseIP("127.0.0.1")})
+	require.NoError(t, err)
+	defer serviceConn.Close()
+
+	sessionStore := NewStorageMemory()
+	oldChannel, newChannel := &mockChannel{}, &mockChannel{serviceConn: serviceConn}
+	var updatedConn *net.UDPConn
+	updateConn := func(conn *net.UDPConn) error {
+		updatedConn = conn
+		return nil
+	}
+	manager := NewManager(currentProposal, sessionStore, mockPaymentEngineFactory, traversal.NewNoopPinger(),
+		&MockNatEventTracker{}, "test service id", mocks.NewEventBus(), p2p.NewSwitchableChannel(oldChannel), nil, DefaultConfig())
+	resumeManager := NewManager(currentProposal, sessionStore, mockPaymentEngineFactory, traversal.NewNoopPinger(),
+		&MockNatEventTracker{}, "test service id", mocks.NewEventBus(), p2p.NewSwitchableChannel(newChannel), nil, DefaultConfig())
+
+	session, err := NewSession()
+	require.NoError(t, err)
+	err = manager.Start(session, owner, ConsumerInfo{IssuerID: owner}, currentProposalID, nil, nil)
+	require.NoError(t, err)
+	defer manager.Destroy(owner, string(session.ID))
+
+	now := time.Now().Unix()
+	signature, err := signer.Sign(ResumeMessage(session.ID, now))
+	require.NoError(t, err)
+
+	_, err = resumeManager.Resume(owner, "unknown", now, signature.Bytes(), updateConn)
+	assert.Exactly(t, ErrorSessionNotExists, err)
+	_, err = resumeManager.Resume(consumerID, string(session.ID), now, signature.Bytes(), updateConn)
+	assert.Exactly(t, ErrorWrongSessionOwner, err)
+	_, err = resumeManager.Resume(owner, string(session.ID), now+1, signature.Bytes(), updateConn)
+	assert.Exactly(t, ErrorInvalidSignature, err)
+
+	expired := time.Now().Add(-time.Hour).Unix()
+	expiredSignature, err := signer.Sign(ResumeMessage(session.ID, expired))
+	require.NoError(t, err)
+	_, err = resumeManager.Resume(owner, string(session.ID), expired, expiredSignature.Bytes(), updateConn)
+	assert.True(t, errors.Is(err, ErrorInvalidSignature))
+	assert.False(t, oldChannel.isClosed())
+	assert.Nil(t, updatedConn)
+
+	previous, err := resumeManager.Resume(owner, string(session.ID), now, signature.Bytes(), updateConn)
+	assert.NoError(t, err)
+	assert.Equal(t, serviceConn, updatedConn)
+	assert.Equal(t, oldChannel, previous)
+	assert.False(t, newChannel.isClosed())
+
+	// Handlers of the session are moved to the new channel.
+	assert.Eventually(t, func() bool {
+		return newChannel.handles(p2p.TopicKeepAlive)
+	}, 2*time.Second, 10*time.Millisecond)
+}
+
+func TestManager_Resume_RejectsSessionWithoutSwitchableChannel(t *testing.T) {
+	sessionStore := NewStorageMemory()
+	manager := newManager(currentProposal, sessionStore)
+
+	session, err := NewSession()
+	require.NoError(t, err)
+	err = manager.Start(session, consumerID, ConsumerInfo{IssuerID: consumerID}, currentProposalID, nil, nil)
+	require.NoError(t, err)
+
+	_, err = manager.Resume(consumerID, string(session.ID), time.Now().Unix(), nil, func(_ *net.UDPConn) error { return nil })
+	assert.Exactly(t, ErrorResumeNotSupported, err)
+}
+
+type mockChannel struct {
+	mu          sync.Mutex
+	handlers    map[string]p2p.HandlerFunc
+	serviceConn *net.UDPConn
+	closed      bool
+}
+
+func (m *mockChannel) Send(_ context.Context, _ string, _ *p2p.Message) (*p2p.Message, error) {
+	return &p2p.Message{}, nil
+}
+
+func (m *mockChannel) Publish(_ context.Context, _ string, _ *p2p.Message) error {
+	return nil
+}
+
+func (m *mockChannel) OpenStream(_ context.Context, _ string) (p2p.Stream, error) {
+	return nil, p2p.ErrHandlerNotFound
+}
+
+func (m *mockChannel) Handle(topic string, handler p2p.HandlerFunc) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.handlers == nil {
+		m.handlers = make(map[string]p2p.HandlerFunc)
+	}
+	m.handlers[topic] = handler
+}
+
+func (m *mockChannel) HandleStream(_ string, _ p2p.StreamHandlerFunc) {}
+
+func (m *mockChannel) ServiceConn() *net.UDPConn {
+	return m.serviceConn
+}
+
+func (m *mockChannel) Conn() *net.UDPConn {
+	return nil
+}
+
+func (m *mockChannel) Stats() p2p.ChannelStats {
+	return p2p.ChannelStats{}
+}
+
+func (m *mockChannel) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.closed = true
+	return nil
+}
+
+func (m *mockChannel) isClosed() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.closed
+}
+
+func (m *mockChannel) handles(topic string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, ok := m.handlers[topic]
+	return ok
+}
+
 func newManager(proposal market.ServiceProposal, sessionStore *StorageMemory) *Manager {
 	return NewManager(proposal, sessionStore, mockPaymentEngineFactory, traversal.NewNoopPinger(),
-		&MockNatEventTracker{}, "test service id", mocks.NewEventBus(), nil, DefaultConfig())
+		&MockNatEventTracker{}, "test service id", mocks.NewEventBus(), nil, nil, DefaultConfig())
 }
@@ -145,7 +145,8 @@ func (ac *AccountantCaller) RevealR(r, provider string, agreementID uint64) erro
 	if err != nil {
 		return fmt.Errorf("could not form reveal_r request: %w", err)
 	}
-	err = ac.doRequest(req, &RevealSuccess{})
+	// Revealing the same R again has no side effects, so the request is safe to retry.
+	err = ac.doRequest(requests.WithCallClass(req, requests.CallIdempotent), &RevealSuccess{})
 	if err != nil {
 		return fmt.Errorf("could not reveal R for accountant: %w", err)
 	}
@@ -160,6 +161,7 @@ func (ac *AccountantCaller) GetConsumerData(id string) (ConsumerData, error) {
 		return ConsumerData{}, fmt.Errorf("could not form consumer data request: %w", err)
 	}
 	var resp ConsumerData
+	// Retried by the caller, see ConsumerBalanceTracker.recoverGrandTotalPromised.
 	err = ac.doRequest(req, &resp)
 	if err != nil {
 		return ConsumerData{}, fmt.Errorf("could not request consumer data from accountant: %w", err)
@@ -25,6 +25,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/mysteriumnetwork/node/config"
 	nodevent "github.com/mysteriumnetwork/node/core/node/event"
 	"github.com/mysteriumnetwork/node/core/service/servicestate"
 	"github.com/mysteriumnetwork/node/eventbus"
@@ -35,7 +36,6 @@ import (
 	"github.com/mysteriumnetwork/payments/client"
 	"github.com/mysteriumnetwork/payments/crypto"
 	"github.com/pkg/errors"
-	"github.com/rs/zerolog/log"
 )
 
 type providerChannelStatusProvider interface {
@@ -64,6 +64,11 @@ type promiseStorage interface {
 	Get(providerID, accountantID identity.Identity) (AccountantPromise, error)
 }
 
+type settlementPolicyStorage interface {
+	SetUser(key string, value interface{})
+	SaveUserConfig() error
+}
+
 type receivedPromise struct {
 	provider identity.Identity
 	promise  crypto.Promise
@@ -73,9 +78,14 @@ type receivedPromise struct {
 type AccountantPromiseSettler interface {
 	GetEarnings(id identity.Identity) event.Earnings
 	ForceSettle(providerID, accountantID identity.Identity) error
+	SettlementPolicy() event.SettlementPolicy
+	SetSettlementPolicy(policy event.SettlementPolicy) error
 	Subscribe() error
 }
 
+// settlementScheduleCheckInterval determines how often the scheduled settlement policy is evaluated.
+const settlementScheduleCheckInterval = time.Minute
+
 // accountantPromiseSettler is responsible for settling the accountant promises.
 type accountantPromiseSettler struct {
 	eventBus                   eventbus.EventBus
@@ -87,22 +97,27 @@ type accountantPromiseSettler struct {
 	ks                         ks
 	transactor                 transactor
 	promiseStorage             promiseStorage
+	policyStorage              settlementPolicyStorage
 
 	currentState map[identity.Identity]settlementState
 	settleQueue  chan receivedPromise
 	stop         chan struct{}
 	once         sync.Once
+
+	policyLock       sync.RWMutex
+	policy           event.SettlementPolicy
+	lastScheduledRun time.Time
 }
 
 // AccountantPromiseSettlerConfig configures the accountant promise settler accordingly.
 type AccountantPromiseSettlerConfig struct {
 	AccountantAddress    common.Address
-	Threshold            float64
+	Policy               event.SettlementPolicy
 	MaxWaitForSettlement time.Duration
 }
 
 // NewAccountantPromiseSettler creates a new instance of accountant promise settler.
-func NewAccountantPromiseSettler(eventBus eventbus.EventBus, transactor transactor, promiseStorage promiseStorage, providerChannelStatusProvider providerChannelStatusProvider, registrationStatusProvider registrationStatusProvider, ks ks, config AccountantPromiseSettlerConfig) *accountantPromiseSettler {
+func NewAccountantPromiseSettler(eventBus eventbus.EventBus, transactor transactor, promiseStorage promiseStorage, providerChannelStatusProvider providerChannelStatusProvider, registrationStatusProvider registrationStatusProvider, ks ks, policyStorage settlementPolicyStorage, config AccountantPromiseSettlerConfig) *accountantPromiseSettler {
 	return &accountantPromiseSettler{
 		eventBus:                   eventBus,
 		bc:                         providerChannelStatusProvider,
@@ -111,11 +126,15 @@ func NewAccountantPromiseSettler(eventBus eventbus.EventBus, transactor transact
 		config:                     config,
 		currentState:               make(map[identity.Identity]settlementState),
 		promiseStorage:             promiseStorage,
+		policyStorage:              policyStorage,
 
 		// defaulting to a queue of 5, in case we have a few active identities.
 		settleQueue: make(chan receivedPromise, 5),
 		stop:        make(chan struct{}),
 		transactor:  transactor,
+
+		policy:           config.Policy,
+		lastScheduledRun: time.Now(),
 	}
 }
 
@@ -159,20 +178,71 @@ func (aps *accountantPromiseSettler) resyncState(id identity.Identity) error {
 		registered:  true,
 	}
 
-	go aps.publishChangeEvent(id, aps.currentState[id], s)
+	go aps.publishChangeEvent(id, aps.earnings(aps.currentState[id]), aps.earnings(s))
 	aps.currentState[id] = s
 	log.Info().Msgf("Loaded state for provider %q: balance %v, available balance %v, unsettled balance %v", id, s.balance(), s.availableBalance(), s.unsettledBalance())
 	return nil
 }
 
-func (aps *accountantPromiseSettler) publishChangeEvent(id identity.Identity, before, after settlementState) {
+func (aps *accountantPromiseSettler) publishChangeEvent(id identity.Identity, before, after event.Earnings) {
 	aps.eventBus.Publish(event.AppTopicEarningsChanged, event.AppEventEarningsChanged{
 		Identity: id,
-		Previous: before.Earnings(),
-		Current:  after.Earnings(),
+		Previous: before,
+		Current:  after,
 	})
 }
 
+func (aps *accountantPromiseSettler) earnings(s settlementState) event.Earnings {
+	earnings := s.Earnings()
+	earnings.SettlementPolicy = aps.SettlementPolicy()
+	return earnings
+}
+
+// SettlementPolicy returns the currently active automatic settlement policy.
+func (aps *accountantPromiseSettler) SettlementPolicy() event.SettlementPolicy {
+	aps.policyLock.RLock()
+	defer aps.policyLock.RUnlock()
+
+	return aps.policy
+}
+
+// SetSettlementPolicy validates, activates and persists the given automatic settlement policy.
+func (aps *accountantPromiseSettler) SetSettlementPolicy(policy event.SettlementPolicy) error {
+	if err := ValidateSettlementPolicy(policy); err != nil {
+		return err
+	}
+
+	if err := aps.persistSettlementPolicy(policy); err != nil {
+		return err
+	}
+
+	aps.policyLock.Lock()
+	previous := aps.policy
+	aps.policy = policy
+	aps.lastScheduledRun = time.Now()
+	aps.policyLock.Unlock()
+	log.Info().Msgf("Settlement policy changed from %q to %q", previous.Type, policy.Type)
+
+	aps.lock.RLock()
+	defer aps.lock.RUnlock()
+	for id, s := range aps.currentState {
+		before := s.Earnings()
+		before.SettlementPolicy = previous
+		go aps.publishChangeEvent(id, before, aps.earnings(s))
+	}
+	return nil
+}
+
+// persistSettlementPolicy stores the policy in the user configuration, so it is picked up on the next start.
+func (aps *accountantPromiseSettler) persistSettlementPolicy(policy event.SettlementPolicy) error {
+	aps.policyStorage.SetUser(config.FlagPaymentsAccountantPromiseSettlePolicy.Name, string(policy.Type))
+	aps.policyStorage.SetUser(config.FlagPaymentsAccountantPromiseSettleThreshold.Name, policy.Threshold)
+	aps.policyStorage.SetUser(config.FlagPaymentsAccountantPromiseSettleAmount.Name, policy.Amount)
+	aps.policyStorage.SetUser(config.FlagPaymentsAccountantPromiseSettleTime.Name, policy.Time)
+	aps.policyStorage.SetUser(config.FlagPaymentsAccountantPromiseSettleMaxFeeRatio.Name, policy.MaxFeeRatio)
+	return errors.Wrap(aps.policyStorage.SaveUserConfig(), "could not save settlement policy")
+}
+
 // Subscribe subscribes the accountant promise settler to the appropriate events
 func (aps *accountantPromiseSettler) Subscribe() error {
 	err := aps.eventBus.SubscribeAsync(nodevent.AppTopicNode, aps.handleNodeEvent)
@@ -264,11 +334,11 @@ func (aps *accountantPromiseSettler) handleAccountantPromiseReceived(apep event.
 	}
 	s.lastPromise = apep.Promise
 
-	go aps.publishChangeEvent(id, aps.currentState[id], s)
+	go aps.publishChangeEvent(id, aps.earnings(aps.currentState[id]), aps.earnings(s))
 	aps.currentState[apep.ProviderID] = s
 	log.Info().Msgf("Accountant promise state updated for provider %q", id)
 
-	if s.needsSettling(aps.config.Threshold) {
+	if s.needsSettlingByPolicy(aps.SettlementPolicy()) {
 		aps.settleQueue <- receivedPromise{
 			provider: apep.ProviderID,
 			promise:  apep.Promise,
@@ -282,14 +352,87 @@ func (aps *accountantPromiseSettler) listenForSettlementRequests() {
 		log.Info().Msg("Stopped listening for settlement events")
 	}()
 
+	ticker := time.NewTicker(settlementScheduleCheckInterval)
+	defer ticker.Stop()
+
 	for {
 		select {
 		case <-aps.stop:
 			return
 		case p := <-aps.settleQueue:
-			go aps.settle(p)
+			go aps.settleAutomatically(p)
+		case now := <-ticker.C:
+			aps.settleScheduled(now)
+		}
+	}
+}
+
+// settleAutomatically settles the given promise unless the settlement fees exceed the ones allowed by the policy.
+func (aps *accountantPromiseSettler) settleAutomatically(p receivedPromise) {
+	policy := aps.SettlementPolicy()
+	if policy.MaxFeeRatio > 0 {
+		fees, err := aps.transactor.FetchSettleFees()
+		if err != nil {
+			log.Error().Err(err).Msgf("Could not fetch settlement fees, skipping settlement for %v", p.provider)
+			return
+		}
+
+		aps.lock.RLock()
+		unsettled := aps.currentState[p.provider].unsettledBalance()
+		aps.lock.RUnlock()
+
+		if !feesAcceptable(policy, fees.Fee, unsettled) {
+			log.Info().Msgf("Settlement fee %v is too high for unsettled balance %v, skipping settlement for %v", fees.Fee, unsettled, p.provider)
+			return
 		}
 	}
+
+	if err := aps.settle(p); err != nil {
+		log.Error().Err(err).Msgf("Automatic settlement failed for %v", p.provider)
+	}
+}
+
+// settleScheduled settles all the providers with unsettled balance, if the scheduled settlement time has passed.
+func (aps *accountantPromiseSettler) settleScheduled(now time.Time) {
+	aps.policyLock.Lock()
+	policy := aps.policy
+	if policy.Type != event.SettlementPolicySchedule {
+		aps.policyLock.Unlock()
+		return
+	}
+
+	scheduled, err := lastScheduledSettlement(policy, now)
+	if err != nil {
+		aps.policyLock.Unlock()
+		log.Error().Err(err).Msg("Could not calculate scheduled settlement time")
+		return
+	}
+
+	if !aps.lastScheduledRun.Before(scheduled) {
+		aps.policyLock.Unlock()
+		return
+	}
+	aps.lastScheduledRun = now
+	aps.policyLock.Unlock()
+
+	aps.lock.RLock()
+	var due []identity.Identity
+	for id, s := range aps.currentState {
+		if s.needsScheduledSettling() {
+			due = append(due, id)
+		}
+	}
+	aps.lock.RUnlock()
+
+	log.Info().Msgf("Running scheduled settlement for %v provider(s)", len(due))
+	for _, id := range due {
+		p, err := aps.loadPromise(id, identity.FromAddress(aps.config.AccountantAddress.Hex()))
+		if err != nil {
+			log.Error().Err(err).Msgf("Could not load promise for scheduled settlement of %v", id)
+			continue
+		}
+		go aps.settleAutomatically(p)
+	}
 }
 
 // GetEarnings returns current settlement status for given identity
@@ -297,7 +440,7 @@ func (aps *accountantPromiseSettler) GetEarnings(id identity.Identity) event.Ear
 	aps.lock.RLock()
 	defer aps.lock.RUnlock()
 
-	return aps.currentState[id].Earnings()
+	return aps.earnings(aps.currentState[id])
 }
 
 // ErrNothingToSettle indicates that there is nothing to settle.
@@ -305,24 +448,33 @@ var ErrNothingToSettle = errors.New("nothing to settle for the given provider")
 
 // ForceSettle forces the settlement for a provider
 func (aps *accountantPromiseSettler) ForceSettle(providerID, accountantID identity.Identity) error {
+	p, err := aps.loadPromise(providerID, accountantID)
+	if err != nil {
+		return err
+	}
+
+	return aps.settle(p)
+}
+
+func (aps *accountantPromiseSettler) loadPromise(providerID, accountantID identity.Identity) (receivedPromise, error) {
 	promise, err := aps.promiseStorage.Get(providerID, accountantID)
 	if err == ErrNotFound {
-		return ErrNothingToSettle
+		return receivedPromise{}, ErrNothingToSettle
 	}
 	if err != nil {
-		return errors.Wrap(err, "could not get promise from storage")
+		return receivedPromise{}, errors.Wrap(err, "could not get promise from storage")
 	}
 
 	hexR, err := hex.DecodeString(promise.R)
 	if err != nil {
-		return errors.Wrap(err, "could not decode R")
+		return receivedPromise{}, errors.Wrap(err, "could not decode R")
 	}
 
 	promise.Promise.R = hexR
-	return aps.settle(receivedPromise{
+	return receivedPromise{
 		promise:  promise.Promise,
 		provider: providerID,
-	})
+	}, nil
 }
 
 // ErrSettleTimeout indicates that the settlement has timed out
@@ -491,6 +643,29 @@ func (ss settlementState) needsSettling(threshold float64) bool {
 	return false
 }
 
+// needsSettlingByPolicy checks if the state should be settled according to the given policy.
+// Amount and schedule policies still settle if the channel balance is exhausted, as no more promises could be issued otherwise.
+func (ss settlementState) needsSettlingByPolicy(policy event.SettlementPolicy) bool {
+	switch policy.Type {
+	case event.SettlementPolicyNever:
+		return false
+	case event.SettlementPolicyAmount:
+		if ss.needsSettling(0) {
+			return true
+		}
+		return ss.registered && !ss.settleInProgress && ss.unsettledBalance() >= policy.Amount
+	case event.SettlementPolicySchedule:
+		return ss.needsSettling(0)
+	default:
+		return ss.needsSettling(policy.Threshold)
+	}
+}
+
+// needsScheduledSettling checks if the state has anything to settle on a scheduled settlement.
+func (ss settlementState) needsScheduledSettling() bool {
+	return ss.registered && !ss.settleInProgress && ss.unsettledBalance() > 0
+}
+
 func (ss settlementState) Earnings() event.Earnings {
 	return event.Earnings{
 		LifetimeBalance:  ss.lifetimeBalance(),
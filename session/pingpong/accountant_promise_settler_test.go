@@ -26,6 +26,7 @@ import (
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/mysteriumnetwork/node/config"
 	"github.com/mysteriumnetwork/node/core/service/servicestate"
 	"github.com/mysteriumnetwork/node/eventbus"
 	"github.com/mysteriumnetwork/node/identity"
@@ -49,7 +50,7 @@ func TestPromiseSettler_resyncState_returns_errors(t *testing.T) {
 
 	ks := identity.NewKeystoreFilesystem(dir, identity.NewMockKeystore(identity.MockKeys), identity.MockDecryptFunc)
 
-	settler := NewAccountantPromiseSettler(eventbus.New(), &mockTransactor{}, mapg, channelStatusProvider, mrsp, ks, cfg)
+	settler := NewAccountantPromiseSettler(eventbus.New(), &mockTransactor{}, mapg, channelStatusProvider, mrsp, ks, &mockSettlementPolicyStorage{}, cfg)
 	err = settler.resyncState(mockID)
 	assert.Equal(t, fmt.Sprintf("could not get provider channel for %v: %v", mockID, errMock.Error()), err.Error())
 
@@ -74,7 +75,7 @@ func TestPromiseSettler_resyncState_handles_no_promise(t *testing.T) {
 	ks := identity.NewKeystoreFilesystem(dir, identity.NewMockKeystore(identity.MockKeys), identity.MockDecryptFunc)
 
 	id := identity.FromAddress("test")
-	settler := NewAccountantPromiseSettler(eventbus.New(), &mockTransactor{}, mapg, channelStatusProvider, mrsp, ks, cfg)
+	settler := NewAccountantPromiseSettler(eventbus.New(), &mockTransactor{}, mapg, channelStatusProvider, mrsp, ks, &mockSettlementPolicyStorage{}, cfg)
 	err = settler.resyncState(id)
 	assert.NoError(t, err)
 
@@ -103,7 +104,7 @@ func TestPromiseSettler_resyncState_takes_promise_into_account(t *testing.T) {
 
 	ks := identity.NewKeystoreFilesystem(dir, identity.NewMockKeystore(identity.MockKeys), identity.MockDecryptFunc)
 
-	settler := NewAccountantPromiseSettler(eventbus.New(), &mockTransactor{}, mapg, channelStatusProvider, mrsp, ks, cfg)
+	settler := NewAccountantPromiseSettler(eventbus.New(), &mockTransactor{}, mapg, channelStatusProvider, mrsp, ks, &mockSettlementPolicyStorage{}, cfg)
 	err = settler.resyncState(mockID)
 	assert.NoError(t, err)
 
@@ -132,7 +133,7 @@ func TestPromiseSettler_loadInitialState(t *testing.T) {
 
 	ks := identity.NewKeystoreFilesystem(dir, identity.NewMockKeystore(identity.MockKeys), identity.MockDecryptFunc)
 
-	settler := NewAccountantPromiseSettler(eventbus.New(), &mockTransactor{}, mapg, channelStatusProvider, mrsp, ks, cfg)
+	settler := NewAccountantPromiseSettler(eventbus.New(), &mockTransactor{}, mapg, channelStatusProvider, mrsp, ks, &mockSettlementPolicyStorage{}, cfg)
 
 	settler.currentState[mockID] = settlementState{}
 
@@ -202,7 +203,7 @@ func TestPromiseSettler_handleServiceEvent(t *testing.T) {
 
 	ks := identity.NewKeystoreFilesystem(dir, identity.NewMockKeystore(identity.MockKeys), identity.MockDecryptFunc)
 
-	settler := NewAccountantPromiseSettler(eventbus.New(), &mockTransactor{}, mapg, channelStatusProvider, mrsp, ks, cfg)
+	settler := NewAccountantPromiseSettler(eventbus.New(), &mockTransactor{}, mapg, channelStatusProvider, mrsp, ks, &mockSettlementPolicyStorage{}, cfg)
 
 	statusesWithNoChangeExpected := []string{string(servicestate.Starting), string(servicestate.NotRunning)}
 
@@ -244,7 +245,7 @@ func TestPromiseSettler_handleRegistrationEvent(t *testing.T) {
 
 	ks := identity.NewKeystoreFilesystem(dir, identity.NewMockKeystore(identity.MockKeys), identity.MockDecryptFunc)
 
-	settler := NewAccountantPromiseSettler(eventbus.New(), &mockTransactor{}, mapg, channelStatusProvider, mrsp, ks, cfg)
+	settler := NewAccountantPromiseSettler(eventbus.New(), &mockTransactor{}, mapg, channelStatusProvider, mrsp, ks, &mockSettlementPolicyStorage{}, cfg)
 
 	statusesWithNoChangeExpected := []registry.RegistrationStatus{registry.RegisteredConsumer, registry.Unregistered, registry.InProgress, registry.Promoting, registry.RegistrationError}
 	for _, v := range statusesWithNoChangeExpected {
@@ -286,7 +287,7 @@ func TestPromiseSettler_handleAccountantPromiseReceived(t *testing.T) {
 	ks := identity.NewKeystoreFilesystem(dir, identity.NewMockKeystore(identity.MockKeys), identity.MockDecryptFunc)
 
 	// no receive on unknown provider
-	settler := NewAccountantPromiseSettler(eventbus.New(), &mockTransactor{}, mapg, channelStatusProvider, mrsp, ks, cfg)
+	settler := NewAccountantPromiseSettler(eventbus.New(), &mockTransactor{}, mapg, channelStatusProvider, mrsp, ks, &mockSettlementPolicyStorage{}, cfg)
 	settler.handleAccountantPromiseReceived(event.AppEventAccountantPromise{
 		AccountantID: identity.FromAddress(cfg.AccountantAddress.Hex()),
 		ProviderID:   mockID,
@@ -375,7 +376,7 @@ func TestPromiseSettler_handleNodeStart(t *testing.T) {
 		},
 	}
 
-	settler := NewAccountantPromiseSettler(eventbus.New(), &mockTransactor{}, mapg, channelStatusProvider, mrsp, ks, cfg)
+	settler := NewAccountantPromiseSettler(eventbus.New(), &mockTransactor{}, mapg, channelStatusProvider, mrsp, ks, &mockSettlementPolicyStorage{}, cfg)
 
 	settler.handleNodeStart()
 
@@ -419,6 +420,96 @@ func TestPromiseSettlerState_needsSettling(t *testing.T) {
 	assert.False(t, s.needsSettling(0.1), "should be false with 10.01% missing")
 }
 
+func TestPromiseSettlerState_needsSettlingByPolicy(t *testing.T) {
+	s := settlementState{
+		channel:     client.ProviderChannel{Balance: big.NewInt(10000)},
+		lastPromise: crypto.Promise{Amount: 9000},
+		registered:  true,
+	}
+	assert.True(t, s.needsSettlingByPolicy(event.SettlementPolicy{Type: event.SettlementPolicyThreshold, Threshold: 0.1}))
+	assert.False(t, s.needsSettlingByPolicy(event.SettlementPolicy{Type: event.SettlementPolicyNever}))
+	assert.True(t, s.needsSettlingByPolicy(event.SettlementPolicy{Type: event.SettlementPolicyAmount, Amount: 9000}))
+	assert.False(t, s.needsSettlingByPolicy(event.SettlementPolicy{Type: event.SettlementPolicyAmount, Amount: 9001}))
+	assert.False(t, s.needsSettlingByPolicy(event.SettlementPolicy{Type: event.SettlementPolicySchedule, Time: "00:00"}))
+
+	s.lastPromise = crypto.Promise{Amount: 10000}
+	assert.True(t, s.needsSettlingByPolicy(event.SettlementPolicy{Type: event.SettlementPolicyAmount, Amount: 20000}), "should settle exhausted channel")
+	assert.True(t, s.needsSettlingByPolicy(event.SettlementPolicy{Type: event.SettlementPolicySchedule, Time: "00:00"}), "should settle exhausted channel")
+	assert.False(t, s.needsSettlingByPolicy(event.SettlementPolicy{Type: event.SettlementPolicyNever}))
+
+	s.settleInProgress = true
+	assert.False(t, s.needsSettlingByPolicy(event.SettlementPolicy{Type: event.SettlementPolicyAmount, Amount: 1}))
+}
+
+func TestPromiseSettler_SetSettlementPolicy(t *testing.T) {
+	settler := NewAccountantPromiseSettler(eventbus.New(), &mockTransactor{}, &mockAccountantPromiseGetter{}, &mockProviderChannelStatusProvider{}, &mockRegistrationStatusProvider{}, nil, &mockSettlementPolicyStorage{}, cfg)
+	settler.currentState[mockID] = settlementState{registered: true}
+
+	err := settler.SetSettlementPolicy(event.SettlementPolicy{Type: "sometimes"})
+	assert.Error(t, err)
+	assert.Equal(t, cfg.Policy, settler.SettlementPolicy())
+
+	policy := event.SettlementPolicy{Type: event.SettlementPolicyAmount, Amount: 100}
+	err = settler.SetSettlementPolicy(policy)
+	assert.NoError(t, err)
+	assert.Equal(t, policy, settler.SettlementPolicy())
+	assert.Equal(t, policy, settler.GetEarnings(mockID).SettlementPolicy)
+}
+
+func TestPromiseSettler_SetSettlementPolicy_Persists(t *testing.T) {
+	storage := &mockSettlementPolicyStorage{}
+	settler := NewAccountantPromiseSettler(eventbus.New(), &mockTransactor{}, &mockAccountantPromiseGetter{}, &mockProviderChannelStatusProvider{}, &mockRegistrationStatusProvider{}, nil, storage, cfg)
+
+	err := settler.SetSettlementPolicy(event.SettlementPolicy{Type: "sometimes"})
+	assert.Error(t, err)
+	assert.False(t, storage.saved)
+
+	err = settler.SetSettlementPolicy(event.SettlementPolicy{Type: event.SettlementPolicySchedule, Time: "03:30", MaxFeeRatio: 0.2})
+	assert.NoError(t, err)
+	assert.True(t, storage.saved)
+	assert.Equal(t, "schedule", storage.values[config.FlagPaymentsAccountantPromiseSettlePolicy.Name])
+	assert.Equal(t, "03:30", storage.values[config.FlagPaymentsAccountantPromiseSettleTime.Name])
+	assert.Equal(t, 0.2, storage.values[config.FlagPaymentsAccountantPromiseSettleMaxFeeRatio.Name])
+}
+
+func TestPromiseSettler_SetSettlementPolicy_PersistFails(t *testing.T) {
+	storage := &mockSettlementPolicyStorage{saveErr: errors.New("disk is full")}
+	settler := NewAccountantPromiseSettler(eventbus.New(), &mockTransactor{}, &mockAccountantPromiseGetter{}, &mockProviderChannelStatusProvider{}, &mockRegistrationStatusProvider{}, nil, storage, cfg)
+
+	err := settler.SetSettlementPolicy(event.SettlementPolicy{Type: event.SettlementPolicyAmount, Amount: 100})
+	assert.EqualError(t, err, "could not save settlement policy: disk is full")
+	assert.Equal(t, cfg.Policy, settler.SettlementPolicy())
+}
+
+func TestPromiseSettler_settleScheduled(t *testing.T) {
+	storage := &mockAccountantPromiseGetter{
+		promise: AccountantPromise{
+			Promise: crypto.Promise{Amount: 100},
+			R:       "abc123",
+		},
+	}
+	settler := NewAccountantPromiseSettler(eventbus.New(), &mockTransactor{}, storage, &mockProviderChannelStatusProvider{subError: errMock}, &mockRegistrationStatusProvider{}, nil, &mockSettlementPolicyStorage{}, cfg)
+	settler.currentState[mockID] = settlementState{
+		channel:     client.ProviderChannel{Balance: big.NewInt(10000)},
+		lastPromise: crypto.Promise{Amount: 100},
+		registered:  true,
+	}
+	now := time.Date(2020, 5, 10, 4, 0, 0, 0, time.UTC)
+
+	// nothing happens with a non scheduled policy
+	settler.lastScheduledRun = now.Add(-time.Hour * 24)
+	settler.settleScheduled(now)
+	assert.Equal(t, now.Add(-time.Hour*24), settler.lastScheduledRun)
+
+	settler.policy = event.SettlementPolicy{Type: event.SettlementPolicySchedule, Time: "03:30"}
+	settler.settleScheduled(now)
+	assert.Equal(t, now, settler.lastScheduledRun)
+
+	// should not run twice for the same scheduled time
+	settler.settleScheduled(now.Add(time.Minute))
+	assert.Equal(t, now, settler.lastScheduledRun)
+}
+
 func TestPromiseSettlerState_balance(t *testing.T) {
 	s := settlementState{
 		channel: client.ProviderChannel{
@@ -465,8 +556,11 @@ func (mpcsp *mockProviderChannelStatusProvider) GetProviderChannel(accountantAdd
 }
 
 var cfg = AccountantPromiseSettlerConfig{
-	AccountantAddress:    common.HexToAddress("0x9a8B6d979e188fA3DeAa93A470C3537362FdaE92"),
-	Threshold:            0.1,
+	AccountantAddress: common.HexToAddress("0x9a8B6d979e188fA3DeAa93A470C3537362FdaE92"),
+	Policy: event.SettlementPolicy{
+		Type:      event.SettlementPolicyThreshold,
+		Threshold: 0.1,
+	},
 	MaxWaitForSettlement: time.Millisecond * 10,
 }
 
@@ -505,6 +599,27 @@ var mockProviderChannel = client.ProviderChannel{
 	Loan:    big.NewInt(12312323),
 }
 
+type mockSettlementPolicyStorage struct {
+	values  map[string]interface{}
+	saved   bool
+	saveErr error
+}
+
+func (mps *mockSettlementPolicyStorage) SetUser(key string, value interface{}) {
+	if mps.values == nil {
+		mps.values = make(map[string]interface{})
+	}
+	mps.values[key] = value
+}
+
+func (mps *mockSettlementPolicyStorage) SaveUserConfig() error {
+	if mps.saveErr != nil {
+		return mps.saveErr
+	}
+	mps.saved = true
+	return nil
+}
+
 type mockTransactor struct {
 	registerError error
 	feesToReturn  registry.FeesResponse
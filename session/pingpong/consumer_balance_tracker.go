@@ -19,6 +19,7 @@ package pingpong
 
 import (
 	"context"
+	"errors"
 	"sync"
 	"time"
 
@@ -31,7 +32,6 @@ import (
 	"github.com/mysteriumnetwork/node/session/pingpong/event"
 	"github.com/mysteriumnetwork/payments/bindings"
 	"github.com/mysteriumnetwork/payments/client"
-	"github.com/rs/zerolog/log"
 )
 
 // ConsumerBalanceTracker keeps track of consumer balances.
@@ -249,7 +249,7 @@ func (cbt *ConsumerBalanceTracker) recoverGrandTotalPromised(identity identity.I
 	toRetry := func() error {
 		d, err := cbt.consumerInfoGetter.GetConsumerData(identity.Address)
 		if err != nil {
-			if err != ErrAccountantNotFound {
+			if !errors.Is(err, ErrAccountantNotFound) {
 				return err
 			}
 			log.Debug().Msgf("No previous invoice grand total, assuming zero")
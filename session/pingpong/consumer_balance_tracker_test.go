@@ -18,6 +18,7 @@
 package pingpong
 
 import (
+	"fmt"
 	"math/big"
 	"testing"
 	"time"
@@ -180,6 +181,24 @@ func TestConsumerBalanceTracker_Handles_TopUp(t *testing.T) {
 	}, defaultWaitTime, defaultWaitInterval)
 }
 
+func TestConsumerBalanceTracker_RecoverGrandTotalPromised_NotFound(t *testing.T) {
+	id := identity.FromAddress("0x000000001")
+	accountantID := identity.FromAddress("0x000000acc")
+
+	mcts := mockConsumerTotalsStorage{
+		bus:        eventbus.New(),
+		calledWith: 1,
+	}
+	fetcher := mockAccountantBalanceFetcher{
+		err: fmt.Errorf("could not request consumer data from accountant: %w", ErrAccountantNotFound),
+	}
+	cbt := NewConsumerBalanceTracker(eventbus.New(), mockMystSCaddress, accountantID, &mockConsumerBalanceChecker{}, &mockChannelAddressCalculator{}, &mcts, &fetcher)
+
+	err := cbt.recoverGrandTotalPromised(id)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0), mcts.calledWith)
+}
+
 type mockAccountantBalanceFetcher struct {
 	consumerData ConsumerData
 	err          error
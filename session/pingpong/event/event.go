@@ -58,6 +58,34 @@ type AppEventEarningsChanged struct {
 type Earnings struct {
 	LifetimeBalance  uint64
 	UnsettledBalance uint64
+	SettlementPolicy SettlementPolicy
+}
+
+// SettlementPolicyType represents the kind of automatic settlement policy.
+type SettlementPolicyType string
+
+const (
+	// SettlementPolicyThreshold settles once the remaining channel balance drops below a ratio of the available balance.
+	SettlementPolicyThreshold = SettlementPolicyType("threshold")
+	// SettlementPolicyAmount settles once the unsettled balance exceeds an absolute amount.
+	SettlementPolicyAmount = SettlementPolicyType("amount")
+	// SettlementPolicySchedule settles daily at the given time.
+	SettlementPolicySchedule = SettlementPolicyType("schedule")
+	// SettlementPolicyNever disables automatic settlement.
+	SettlementPolicyNever = SettlementPolicyType("never")
+)
+
+// SettlementPolicy describes when the accountant promises are settled automatically.
+type SettlementPolicy struct {
+	Type SettlementPolicyType
+	// Threshold is the ratio of available balance used by the threshold policy.
+	Threshold float64
+	// Amount is the unsettled balance used by the amount policy.
+	Amount uint64
+	// Time is the daily settlement time in UTC, formatted as 15:04, used by the schedule policy.
+	Time string
+	// MaxFeeRatio, if set, skips automatic settlement while the transactor fee exceeds this ratio of the unsettled balance.
+	MaxFeeRatio float64
 }
 
 // AppEventInvoicePaid is an update on paid invoices during current session
@@ -33,7 +33,6 @@ import (
 	"github.com/mysteriumnetwork/node/session/mbtime"
 	"github.com/mysteriumnetwork/payments/crypto"
 	"github.com/pkg/errors"
-	"github.com/rs/zerolog/log"
 )
 
 const (
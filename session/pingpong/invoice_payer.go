@@ -34,7 +34,6 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/mysteriumnetwork/payments/crypto"
 	"github.com/pkg/errors"
-	"github.com/rs/zerolog/log"
 )
 
 // ErrWrongProvider represents an issue where the wrong provider is supplied.
@@ -40,7 +40,6 @@ import (
 	"github.com/mysteriumnetwork/node/session/pingpong/event"
 	"github.com/mysteriumnetwork/payments/crypto"
 	"github.com/pkg/errors"
-	"github.com/rs/zerolog/log"
 )
 
 // ErrConsumerPromiseValidationFailed represents an error where consumer tries to cheat us with incorrect promises.
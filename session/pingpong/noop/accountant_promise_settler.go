@@ -40,3 +40,13 @@ func (n *NoopAccountantPromiseSettler) GetEarnings(_ identity.Identity) event.Ea
 func (n *NoopAccountantPromiseSettler) ForceSettle(_, _ identity.Identity) error {
 	return nil
 }
+
+// SettlementPolicy returns an empty policy.
+func (n *NoopAccountantPromiseSettler) SettlementPolicy() event.SettlementPolicy {
+	return event.SettlementPolicy{}
+}
+
+// SetSettlementPolicy does nothing.
+func (n *NoopAccountantPromiseSettler) SetSettlementPolicy(_ event.SettlementPolicy) error {
+	return nil
+}
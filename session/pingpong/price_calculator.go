@@ -22,7 +22,6 @@ import (
 	"time"
 
 	"github.com/mysteriumnetwork/node/market"
-	"github.com/rs/zerolog/log"
 )
 
 func isServiceFree(method market.PaymentMethod) bool {
@@ -0,0 +1,85 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package pingpong
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mysteriumnetwork/node/session/pingpong/event"
+	"github.com/pkg/errors"
+)
+
+const settlementScheduleLayout = "15:04"
+
+// ErrInvalidSettlementPolicy indicates that the given settlement policy can not be applied.
+var ErrInvalidSettlementPolicy = errors.New("invalid settlement policy")
+
+// ValidateSettlementPolicy checks if the given settlement policy is complete and sane.
+func ValidateSettlementPolicy(p event.SettlementPolicy) error {
+	if p.MaxFeeRatio < 0 {
+		return errors.Wrap(ErrInvalidSettlementPolicy, "max fee ratio can not be negative")
+	}
+
+	switch p.Type {
+	case event.SettlementPolicyThreshold:
+		if p.Threshold < 0 || p.Threshold > 1 {
+			return errors.Wrap(ErrInvalidSettlementPolicy, "threshold should be between 0 and 1")
+		}
+	case event.SettlementPolicyAmount:
+		if p.Amount == 0 {
+			return errors.Wrap(ErrInvalidSettlementPolicy, "amount should be greater than zero")
+		}
+	case event.SettlementPolicySchedule:
+		if _, err := time.Parse(settlementScheduleLayout, p.Time); err != nil {
+			return errors.Wrap(ErrInvalidSettlementPolicy, fmt.Sprintf("time %q should be formatted as HH:MM", p.Time))
+		}
+	case event.SettlementPolicyNever:
+	default:
+		return errors.Wrap(ErrInvalidSettlementPolicy, fmt.Sprintf("unknown policy type %q", p.Type))
+	}
+	return nil
+}
+
+// lastScheduledSettlement returns the most recent scheduled settlement moment that is not after now.
+func lastScheduledSettlement(p event.SettlementPolicy, now time.Time) (time.Time, error) {
+	at, err := time.Parse(settlementScheduleLayout, p.Time)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	now = now.UTC()
+	scheduled := time.Date(now.Year(), now.Month(), now.Day(), at.Hour(), at.Minute(), 0, 0, time.UTC)
+	if scheduled.After(now) {
+		scheduled = scheduled.AddDate(0, 0, -1)
+	}
+	return scheduled, nil
+}
+
+// feesAcceptable checks if the settlement fee is within the ratio allowed by the policy.
+func feesAcceptable(p event.SettlementPolicy, fee, unsettled uint64) bool {
+	if p.MaxFeeRatio == 0 {
+		return true
+	}
+
+	if unsettled == 0 {
+		return false
+	}
+
+	return float64(fee) <= p.MaxFeeRatio*float64(unsettled)
+}
@@ -0,0 +1,75 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package pingpong
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mysteriumnetwork/node/session/pingpong/event"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateSettlementPolicy(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy event.SettlementPolicy
+		valid  bool
+	}{
+		{"threshold", event.SettlementPolicy{Type: event.SettlementPolicyThreshold, Threshold: 0.1}, true},
+		{"threshold out of bounds", event.SettlementPolicy{Type: event.SettlementPolicyThreshold, Threshold: 1.1}, false},
+		{"amount", event.SettlementPolicy{Type: event.SettlementPolicyAmount, Amount: 100}, true},
+		{"zero amount", event.SettlementPolicy{Type: event.SettlementPolicyAmount}, false},
+		{"schedule", event.SettlementPolicy{Type: event.SettlementPolicySchedule, Time: "03:30"}, true},
+		{"schedule with bad time", event.SettlementPolicy{Type: event.SettlementPolicySchedule, Time: "25:00"}, false},
+		{"never", event.SettlementPolicy{Type: event.SettlementPolicyNever}, true},
+		{"negative fee ratio", event.SettlementPolicy{Type: event.SettlementPolicyNever, MaxFeeRatio: -1}, false},
+		{"unknown", event.SettlementPolicy{Type: "sometimes"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateSettlementPolicy(tt.policy)
+			if tt.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.Equal(t, ErrInvalidSettlementPolicy, errors.Cause(err))
+			}
+		})
+	}
+}
+
+func TestLastScheduledSettlement(t *testing.T) {
+	policy := event.SettlementPolicy{Type: event.SettlementPolicySchedule, Time: "03:30"}
+
+	scheduled, err := lastScheduledSettlement(policy, time.Date(2020, 5, 10, 4, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2020, 5, 10, 3, 30, 0, 0, time.UTC), scheduled)
+
+	scheduled, err = lastScheduledSettlement(policy, time.Date(2020, 5, 10, 3, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2020, 5, 9, 3, 30, 0, 0, time.UTC), scheduled)
+}
+
+func TestFeesAcceptable(t *testing.T) {
+	assert.True(t, feesAcceptable(event.SettlementPolicy{}, 100, 1), "should accept any fee with no limit")
+	assert.True(t, feesAcceptable(event.SettlementPolicy{MaxFeeRatio: 0.1}, 10, 100))
+	assert.False(t, feesAcceptable(event.SettlementPolicy{MaxFeeRatio: 0.1}, 11, 100))
+	assert.False(t, feesAcceptable(event.SettlementPolicy{MaxFeeRatio: 0.1}, 0, 0), "should not settle with nothing unsettled")
+}
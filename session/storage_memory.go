@@ -19,8 +19,10 @@ package session
 
 import (
 	"sync"
+	"time"
 
 	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/p2p"
 )
 
 // NewStorageMemory initiates new session storage
@@ -99,6 +101,27 @@ func (storage *StorageMemory) UpdateEarnings(id ID, total uint64) {
 	}
 }
 
+// UpdateKeyRotation records the session key rotation.
+func (storage *StorageMemory) UpdateKeyRotation(id ID, at time.Time) {
+	storage.lock.Lock()
+	defer storage.lock.Unlock()
+	if session, found := storage.sessions[id]; found {
+		session.KeyRotations++
+		session.KeyRotatedAt = at
+		storage.sessions[id] = session
+	}
+}
+
+// UpdateChannelStats updates the last known p2p channel statistics of the session.
+func (storage *StorageMemory) UpdateChannelStats(id ID, stats p2p.ChannelStats) {
+	storage.lock.Lock()
+	defer storage.lock.Unlock()
+	if session, found := storage.sessions[id]; found {
+		session.ChannelStats = &stats
+		storage.sessions[id] = session
+	}
+}
+
 // FindOpts provides fields to search sessions.
 type FindOpts struct {
 	Peer        *identity.Identity
@@ -305,6 +305,14 @@ func (client *Client) proposals(query url.Values) ([]ProposalDTO, error) {
 	return proposals.Proposals, err
 }
 
+// ProposalsForConsumer returns proposals without the providers blocked by the given consumer,
+// with the favourite providers flagged
+func (client *Client) ProposalsForConsumer(consumerID string) ([]ProposalDTO, error) {
+	queryParams := url.Values{}
+	queryParams.Add("consumer_id", consumerID)
+	return client.proposals(queryParams)
+}
+
 // ProposalsByPrice returns all available proposals within the given price range
 func (client *Client) ProposalsByPrice(lowerTime, upperTime, lowerGB, upperGB uint64) ([]ProposalDTO, error) {
 	values := url.Values{}
@@ -346,6 +354,58 @@ func (client *Client) Payout(identity, ethAddress string) error {
 	return nil
 }
 
+// ProviderPreferences returns the favourite and blocked providers of the given consumer
+func (client *Client) ProviderPreferences(consumerID string) (contract.ProviderPreferencesDTO, error) {
+	prefs := contract.ProviderPreferencesDTO{}
+
+	response, err := client.http.Get(fmt.Sprintf("identities/%s/providers", consumerID), url.Values{})
+	if err != nil {
+		return prefs, err
+	}
+	defer response.Body.Close()
+
+	err = parseResponseJSON(response, &prefs)
+	return prefs, err
+}
+
+// AddFavouriteProvider marks the provider as favourite for the given consumer
+func (client *Client) AddFavouriteProvider(consumerID, providerID string) error {
+	return client.changeProviderPreference(http.MethodPut, consumerID, "favourites", providerID)
+}
+
+// RemoveFavouriteProvider removes the provider from the favourites of the given consumer
+func (client *Client) RemoveFavouriteProvider(consumerID, providerID string) error {
+	return client.changeProviderPreference(http.MethodDelete, consumerID, "favourites", providerID)
+}
+
+// BlockProvider adds the provider to the blocklist of the given consumer
+func (client *Client) BlockProvider(consumerID, providerID string) error {
+	return client.changeProviderPreference(http.MethodPut, consumerID, "blocked", providerID)
+}
+
+// UnblockProvider removes the provider from the blocklist of the given consumer
+func (client *Client) UnblockProvider(consumerID, providerID string) error {
+	return client.changeProviderPreference(http.MethodDelete, consumerID, "blocked", providerID)
+}
+
+func (client *Client) changeProviderPreference(method, consumerID, list, providerID string) error {
+	path := fmt.Sprintf("identities/%s/providers/%s/%s", consumerID, list, providerID)
+
+	var response *http.Response
+	var err error
+	if method == http.MethodDelete {
+		response, err = client.http.Delete(path, nil)
+	} else {
+		response, err = client.http.Put(path, nil)
+	}
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	return nil
+}
+
 // Stop kills mysterium client
 func (client *Client) Stop() error {
 	emptyPayload := struct{}{}
@@ -473,6 +533,104 @@ func (client *Client) NATStatus() (NATStatusDTO, error) {
 	return status, err
 }
 
+// NATType returns type of the NAT detected by STUN probes
+func (client *Client) NATType() (NATTypeDTO, error) {
+	natType := NATTypeDTO{}
+
+	response, err := client.http.Get("nat/type", nil)
+	if err != nil {
+		return natType, err
+	}
+	defer response.Body.Close()
+
+	err = parseResponseJSON(response, &natType)
+	return natType, err
+}
+
+// CircuitBreakers returns circuit breaker states of remote hosts contacted by the node
+func (client *Client) CircuitBreakers() (CircuitBreakerListDTO, error) {
+	breakers := CircuitBreakerListDTO{}
+
+	response, err := client.http.Get("circuit-breakers", nil)
+	if err != nil {
+		return breakers, err
+	}
+	defer response.Body.Close()
+
+	err = parseResponseJSON(response, &breakers)
+	return breakers, err
+}
+
+// AuditEntries returns audit log entries, newest first. Empty query returns the latest entries.
+func (client *Client) AuditEntries(query url.Values) (AuditEntryListDTO, error) {
+	entries := AuditEntryListDTO{}
+
+	response, err := client.http.Get("audit", query)
+	if err != nil {
+		return entries, err
+	}
+	defer response.Body.Close()
+
+	err = parseResponseJSON(response, &entries)
+	return entries, err
+}
+
+// Diagnostics runs node self-diagnostics and returns the report
+func (client *Client) Diagnostics() (contract.DiagnosticsDTO, error) {
+	report := contract.DiagnosticsDTO{}
+
+	response, err := client.http.Get("diagnostics", nil)
+	if err != nil {
+		return report, err
+	}
+	defer response.Body.Close()
+
+	err = parseResponseJSON(response, &report)
+	return report, err
+}
+
+// ConfigSchema returns the schema of configuration options
+func (client *Client) ConfigSchema() (contract.ConfigSchemaDTO, error) {
+	schema := contract.ConfigSchemaDTO{}
+
+	response, err := client.http.Get("config/schema", nil)
+	if err != nil {
+		return schema, err
+	}
+	defer response.Body.Close()
+
+	err = parseResponseJSON(response, &schema)
+	return schema, err
+}
+
+// LogLevels returns the default log level and component log level overrides
+func (client *Client) LogLevels() (contract.LogLevelsDTO, error) {
+	levels := contract.LogLevelsDTO{}
+
+	response, err := client.http.Get("logs/level", nil)
+	if err != nil {
+		return levels, err
+	}
+	defer response.Body.Close()
+
+	err = parseResponseJSON(response, &levels)
+	return levels, err
+}
+
+// SetLogLevels changes log levels of the running node
+func (client *Client) SetLogLevels(request contract.SetLogLevelsRequest) (contract.LogLevelsDTO, error) {
+	levels := contract.LogLevelsDTO{}
+
+	response, err := client.http.Put("logs/level", request)
+	if err != nil {
+		return levels, err
+	}
+	defer response.Body.Close()
+
+	err = parseResponseJSON(response, &levels)
+	return levels, err
+}
+
 // ServiceSessions returns all currently running sessions
 func (client *Client) ServiceSessions() (ServiceSessionListDTO, error) {
 	sessions := ServiceSessionListDTO{}
@@ -20,6 +20,7 @@ package client
 import (
 	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/mysteriumnetwork/node/core/connection"
 	"github.com/mysteriumnetwork/node/money"
@@ -53,6 +54,7 @@ type ProposalDTO struct {
 	AccessPolicies    []AccessPolicy       `json:"access_policies"`
 	PaymentMethodType string               `json:"payment_method_type"`
 	PaymentMethod     paymentMethodRes     `json:"payment_method"`
+	Favourite         bool                 `json:"favourite"`
 }
 
 type paymentMethodRes struct {
@@ -180,6 +182,40 @@ type NATStatusDTO struct {
 	Error  string `json:"error,omitempty"`
 }
 
+// NATTypeDTO gives information about NAT type detected by STUN probes
+type NATTypeDTO struct {
+	Type string `json:"type"`
+}
+
+// CircuitBreakerListDTO gives information about circuit breakers of remote hosts
+type CircuitBreakerListDTO struct {
+	CircuitBreakers []CircuitBreakerDTO `json:"circuit_breakers"`
+}
+
+// CircuitBreakerDTO gives information about circuit breaker state of a single host
+type CircuitBreakerDTO struct {
+	Host     string     `json:"host"`
+	State    string     `json:"state"`
+	Failures int        `json:"failures"`
+	OpenedAt *time.Time `json:"opened_at,omitempty"`
+}
+
+// AuditEntryListDTO gives information about audit log entries
+type AuditEntryListDTO struct {
+	Entries []AuditEntryDTO `json:"entries"`
+}
+
+// AuditEntryDTO gives information about a single security relevant action performed on the node
+type AuditEntryDTO struct {
+	Timestamp  time.Time              `json:"timestamp"`
+	Actor      string                 `json:"actor"`
+	Source     string                 `json:"source,omitempty"`
+	Action     string                 `json:"action"`
+	Parameters map[string]interface{} `json:"parameters,omitempty"`
+	Outcome    string                 `json:"outcome"`
+	Error      string                 `json:"error,omitempty"`
+}
+
 // SettleRequest represents the request to settle accountant promises
 type SettleRequest struct {
 	AccountantID string `json:"accountant_id"`
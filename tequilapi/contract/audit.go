@@ -0,0 +1,70 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package contract
+
+import (
+	"time"
+
+	"github.com/mysteriumnetwork/node/core/audit"
+)
+
+// AuditEntryDTO represents a single audit log record.
+// swagger:model AuditEntryDTO
+type AuditEntryDTO struct {
+	// example: 2020-06-01T12:00:00Z
+	Timestamp time.Time `json:"timestamp"`
+	// User which performed the action
+	// example: myst
+	Actor string `json:"actor"`
+	// Address the action was requested from
+	// example: 127.0.0.1
+	Source string `json:"source,omitempty"`
+	// example: identity.unlock
+	Action string `json:"action"`
+	// Action parameters with secrets redacted
+	Parameters map[string]interface{} `json:"parameters,omitempty"`
+	// Action outcome: success or failure
+	// example: success
+	Outcome string `json:"outcome"`
+	// Failure reason
+	// example: wrong passphrase
+	Error string `json:"error,omitempty"`
+}
+
+// AuditEntryListDTO represents a list of audit log records.
+// swagger:model AuditEntryListDTO
+type AuditEntryListDTO struct {
+	Entries []AuditEntryDTO `json:"entries"`
+}
+
+// NewAuditEntryListDTO maps to API audit log entry list.
+func NewAuditEntryListDTO(entries []audit.Entry) AuditEntryListDTO {
+	result := AuditEntryListDTO{Entries: make([]AuditEntryDTO, 0, len(entries))}
+	for _, entry := range entries {
+		result.Entries = append(result.Entries, AuditEntryDTO{
+			Timestamp:  entry.Timestamp,
+			Actor:      entry.Actor,
+			Source:     entry.Source,
+			Action:     entry.Action,
+			Parameters: entry.Parameters,
+			Outcome:    string(entry.Outcome),
+			Error:      entry.Error,
+		})
+	}
+	return result
+}
@@ -0,0 +1,64 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package contract
+
+import (
+	"time"
+
+	"github.com/mysteriumnetwork/node/requests"
+)
+
+// CircuitBreakerDTO represents the circuit breaker state of a remote host.
+// swagger:model CircuitBreakerDTO
+type CircuitBreakerDTO struct {
+	// example: api.mysterium.network
+	Host string `json:"host"`
+	// Circuit state: closed, open or half_open
+	// example: closed
+	State string `json:"state"`
+	// Consecutive failed requests
+	// example: 0
+	Failures int `json:"failures"`
+	// Time when the circuit was last opened
+	// example: 2020-06-01T12:00:00Z
+	OpenedAt *time.Time `json:"opened_at,omitempty"`
+}
+
+// CircuitBreakerListDTO represents a list of circuit breakers.
+// swagger:model CircuitBreakerListDTO
+type CircuitBreakerListDTO struct {
+	CircuitBreakers []CircuitBreakerDTO `json:"circuit_breakers"`
+}
+
+// NewCircuitBreakerListDTO maps to API circuit breaker list.
+func NewCircuitBreakerListDTO(statuses []requests.CircuitStatus) CircuitBreakerListDTO {
+	result := CircuitBreakerListDTO{CircuitBreakers: make([]CircuitBreakerDTO, 0, len(statuses))}
+	for _, status := range statuses {
+		dto := CircuitBreakerDTO{
+			Host:     status.Host,
+			State:    string(status.State),
+			Failures: status.Failures,
+		}
+		if !status.OpenedAt.IsZero() {
+			openedAt := status.OpenedAt.UTC()
+			dto.OpenedAt = &openedAt
+		}
+		result.CircuitBreakers = append(result.CircuitBreakers, dto)
+	}
+	return result
+}
@@ -0,0 +1,64 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package contract
+
+import (
+	"github.com/mysteriumnetwork/node/config"
+)
+
+// ConfigSchemaDTO describes configuration options of the node.
+// swagger:model ConfigSchemaDTO
+type ConfigSchemaDTO struct {
+	Fields []ConfigFieldDTO `json:"fields"`
+}
+
+// ConfigFieldDTO describes a single configuration option.
+// swagger:model ConfigFieldDTO
+type ConfigFieldDTO struct {
+	// example: openvpn.proto
+	Key string `json:"key"`
+	// One of bool, int, uint64, float64, duration, string or string_slice
+	// example: string
+	Type string `json:"type"`
+	// example: udp
+	Default interface{} `json:"default"`
+	// example: ["udp","tcp"]
+	AllowedValues []string `json:"allowed_values,omitempty"`
+	// example: Openvpn protocol to use. Options: { udp, tcp }
+	Description string `json:"description"`
+	// Whether node has to be restarted for the change to take effect
+	// example: true
+	RequiresRestart bool `json:"requires_restart"`
+}
+
+// NewConfigSchemaDTO maps to API configuration schema.
+func NewConfigSchemaDTO(schema *config.Schema) ConfigSchemaDTO {
+	fields := schema.Fields()
+	result := ConfigSchemaDTO{Fields: make([]ConfigFieldDTO, len(fields))}
+	for i, field := range fields {
+		result.Fields[i] = ConfigFieldDTO{
+			Key:             field.Key,
+			Type:            string(field.Type),
+			Default:         field.Default,
+			AllowedValues:   field.AllowedValues,
+			Description:     field.Description,
+			RequiresRestart: field.RequiresRestart,
+		}
+	}
+	return result
+}
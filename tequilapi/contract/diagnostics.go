@@ -0,0 +1,73 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package contract
+
+import (
+	"time"
+
+	"github.com/mysteriumnetwork/node/core/diagnostics"
+)
+
+// DiagnosticsDTO represents node self-diagnostics report.
+// swagger:model DiagnosticsDTO
+type DiagnosticsDTO struct {
+	// Worst status of all checks
+	// example: warn
+	Status string `json:"status"`
+	// Time when diagnostics were started
+	// example: 2020-06-01T12:00:00Z
+	StartedAt time.Time            `json:"started_at"`
+	Checks    []DiagnosticCheckDTO `json:"checks"`
+}
+
+// DiagnosticCheckDTO represents outcome of a single diagnostic check.
+// swagger:model DiagnosticCheckDTO
+type DiagnosticCheckDTO struct {
+	// example: wireguard
+	Name string `json:"name"`
+	// One of pass, warn or fail
+	// example: warn
+	Status string `json:"status"`
+	// example: kernel module is not loaded, userspace implementation is used
+	Message string `json:"message"`
+	// Remediation hint, present when the check did not pass
+	// example: Install WireGuard kernel module for better performance
+	Hint string `json:"hint,omitempty"`
+	// Check duration in milliseconds
+	// example: 12
+	DurationMs int64 `json:"duration_ms"`
+}
+
+// NewDiagnosticsDTO maps to API diagnostics report.
+func NewDiagnosticsDTO(report diagnostics.Report) DiagnosticsDTO {
+	result := DiagnosticsDTO{
+		Status:    string(report.Status),
+		StartedAt: report.StartedAt.UTC(),
+		Checks:    make([]DiagnosticCheckDTO, len(report.Results)),
+	}
+	for i, check := range report.Results {
+		result.Checks[i] = DiagnosticCheckDTO{
+			Name:       check.Name,
+			Status:     string(check.Status),
+			Message:    check.Message,
+			Hint:       check.Hint,
+			DurationMs: check.Duration.Milliseconds(),
+		}
+	}
+	return result
+}
@@ -43,6 +43,8 @@ type IdentityDTO struct {
 	Balance            uint64 `json:"balance"`
 	Earnings           uint64 `json:"earnings"`
 	EarningsTotal      uint64 `json:"earnings_total"`
+	// automatic settlement policy, present for identities with earnings data
+	SettlementPolicy *SettlementPolicyDTO `json:"settlement_policy,omitempty"`
 }
 
 // NewIdentityDTO maps to API identity.
@@ -0,0 +1,92 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package contract
+
+import (
+	"time"
+
+	"github.com/mysteriumnetwork/node/logconfig"
+)
+
+// LogLevelsDTO represents node log levels.
+// swagger:model LogLevelsDTO
+type LogLevelsDTO struct {
+	// Level of messages logged without a component
+	// example: info
+	Level string `json:"level"`
+	// Time when the default level is reverted
+	// example: 2020-06-01T12:00:00Z
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	// Component level overrides
+	Overrides []ComponentLogLevelDTO `json:"overrides"`
+	// Components which have named loggers
+	// example: ["eventbus", "nat/traversal", "p2p", "pingpong"]
+	Components []string `json:"components"`
+}
+
+// ComponentLogLevelDTO represents log level override of a component.
+// swagger:model ComponentLogLevelDTO
+type ComponentLogLevelDTO struct {
+	// example: p2p
+	Component string `json:"component"`
+	// example: trace
+	Level string `json:"level"`
+	// Time when the override is reverted
+	// example: 2020-06-01T12:00:00Z
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// NewLogLevelsDTO maps to API log levels.
+func NewLogLevelsDTO(state logconfig.LevelState) LogLevelsDTO {
+	result := LogLevelsDTO{
+		Level:      state.Default.String(),
+		ExpiresAt:  optionalTime(state.DefaultExpiresAt),
+		Overrides:  make([]ComponentLogLevelDTO, 0, len(state.Overrides)),
+		Components: state.Components,
+	}
+	for _, override := range state.Overrides {
+		result.Overrides = append(result.Overrides, ComponentLogLevelDTO{
+			Component: override.Component,
+			Level:     override.Level.String(),
+			ExpiresAt: optionalTime(override.ExpiresAt),
+		})
+	}
+	return result
+}
+
+// SetLogLevelsRequest represents a request to change node log levels.
+// swagger:model SetLogLevelsRequestDTO
+type SetLogLevelsRequest struct {
+	// Level of messages logged without a component, unchanged if empty
+	// example: info
+	Level string `json:"level,omitempty"`
+	// Component levels, "default" restores the level of the component
+	// example: {"p2p": "trace", "pingpong": "default"}
+	Components map[string]string `json:"components,omitempty"`
+	// Duration after which the changes are reverted, permanent if empty
+	// example: 10m
+	TTL string `json:"ttl,omitempty"`
+}
+
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	utc := t.UTC()
+	return &utc
+}
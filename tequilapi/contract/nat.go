@@ -0,0 +1,35 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package contract
+
+import (
+	"github.com/mysteriumnetwork/node/nat/stun"
+)
+
+// NATTypeDTO represents the NAT type detected by STUN probes.
+// swagger:model NATTypeDTO
+type NATTypeDTO struct {
+	// NAT type: unknown, none, full_cone, restricted_cone, port_restricted_cone or symmetric
+	// example: port_restricted_cone
+	Type string `json:"type"`
+}
+
+// NewNATTypeDTO maps to API NAT type.
+func NewNATTypeDTO(natType stun.NATType) NATTypeDTO {
+	return NATTypeDTO{Type: string(natType)}
+}
@@ -0,0 +1,68 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package contract
+
+import (
+	pingpong_event "github.com/mysteriumnetwork/node/session/pingpong/event"
+)
+
+// SettlementPolicyDTO represents the automatic settlement policy.
+// swagger:model SettlementPolicyDTO
+type SettlementPolicyDTO struct {
+	// policy type: threshold, amount, schedule or never
+	// example: threshold
+	Type string `json:"type"`
+
+	// ratio of the available balance below which the promises are settled, used by threshold policy
+	// example: 0.1
+	Threshold float64 `json:"threshold,omitempty"`
+
+	// unsettled amount above which the promises are settled, used by amount policy
+	// example: 1000000000
+	Amount uint64 `json:"amount,omitempty"`
+
+	// daily settlement time in UTC, used by schedule policy
+	// example: 03:00
+	Time string `json:"time,omitempty"`
+
+	// maximum ratio of settlement fee to unsettled balance, 0 means any fee is accepted
+	// example: 0.05
+	MaxFeeRatio float64 `json:"max_fee_ratio,omitempty"`
+}
+
+// NewSettlementPolicyDTO maps to API settlement policy.
+func NewSettlementPolicyDTO(policy pingpong_event.SettlementPolicy) SettlementPolicyDTO {
+	return SettlementPolicyDTO{
+		Type:        string(policy.Type),
+		Threshold:   policy.Threshold,
+		Amount:      policy.Amount,
+		Time:        policy.Time,
+		MaxFeeRatio: policy.MaxFeeRatio,
+	}
+}
+
+// ToPolicy maps API settlement policy to the settler one.
+func (dto SettlementPolicyDTO) ToPolicy() pingpong_event.SettlementPolicy {
+	return pingpong_event.SettlementPolicy{
+		Type:        pingpong_event.SettlementPolicyType(dto.Type),
+		Threshold:   dto.Threshold,
+		Amount:      dto.Amount,
+		Time:        dto.Time,
+		MaxFeeRatio: dto.MaxFeeRatio,
+	}
+}
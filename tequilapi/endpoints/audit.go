@@ -0,0 +1,148 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/mysteriumnetwork/node/core/audit"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+	"github.com/mysteriumnetwork/node/tequilapi/utils"
+	"github.com/mysteriumnetwork/node/tequilapi/validation"
+)
+
+const (
+	auditDefaultLimit = 100
+	auditMaxLimit     = 1000
+)
+
+type auditLog interface {
+	Query(filter audit.Filter) ([]audit.Entry, error)
+}
+
+type auditEndpoint struct {
+	log auditLog
+}
+
+// Audit lists audit log entries
+// swagger:operation GET /audit Audit listAuditEntries
+// ---
+// summary: Lists audit log entries
+// description: Returns security relevant actions performed on the node, newest first
+// parameters:
+//   - in: query
+//     name: actor
+//     description: User which performed the action
+//     type: string
+//   - in: query
+//     name: action
+//     description: Action name or action group, e.g. "identity.unlock" or "identity"
+//     type: string
+//   - in: query
+//     name: outcome
+//     description: Action outcome, "success" or "failure"
+//     type: string
+//   - in: query
+//     name: from
+//     description: Earliest entry time in RFC3339 format
+//     type: string
+//   - in: query
+//     name: to
+//     description: Latest entry time in RFC3339 format
+//     type: string
+//   - in: query
+//     name: limit
+//     description: Maximum number of entries to return, defaults to 100
+//     type: integer
+//
+// responses:
+//
+//	200:
+//	  description: List of audit log entries
+//	  schema:
+//	    "$ref": "#/definitions/AuditEntryListDTO"
+//	422:
+//	  description: Parameters validation error
+//	  schema:
+//	    "$ref": "#/definitions/ValidationErrorDTO"
+//	500:
+//	  description: Internal server error
+//	  schema:
+//	    "$ref": "#/definitions/ErrorMessageDTO"
+func (ae *auditEndpoint) Audit(resp http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	filter, errorMap := toAuditFilter(req)
+	if errorMap.HasErrors() {
+		utils.SendValidationErrorMessage(resp, errorMap)
+		return
+	}
+
+	entries, err := ae.log.Query(filter)
+	if err != nil {
+		utils.SendError(resp, err, http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteAsJSON(contract.NewAuditEntryListDTO(entries), resp)
+}
+
+func toAuditFilter(req *http.Request) (audit.Filter, *validation.FieldErrorMap) {
+	query := req.URL.Query()
+	errorMap := validation.NewErrorMap()
+	filter := audit.Filter{
+		Actor:   query.Get("actor"),
+		Action:  query.Get("action"),
+		Outcome: audit.Outcome(query.Get("outcome")),
+		Limit:   auditDefaultLimit,
+	}
+
+	if filter.Outcome != "" && filter.Outcome != audit.OutcomeSuccess && filter.Outcome != audit.OutcomeFailure {
+		errorMap.ForField("outcome").AddError("invalid", "Outcome must be success or failure")
+	}
+	for field, target := range map[string]*time.Time{"from": &filter.From, "to": &filter.To} {
+		value := query.Get(field)
+		if value == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			errorMap.ForField(field).AddError("invalid", "Time must be in RFC3339 format")
+			continue
+		}
+		*target = parsed
+	}
+	if value := query.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > auditMaxLimit {
+			errorMap.ForField("limit").AddError("invalid", "Limit must be between 1 and "+strconv.Itoa(auditMaxLimit))
+		} else {
+			filter.Limit = limit
+		}
+	}
+
+	return filter, errorMap
+}
+
+// AddRoutesForAudit adds audit log routes to given router
+func AddRoutesForAudit(router *httprouter.Router, log auditLog) {
+	endpoint := &auditEndpoint{log: log}
+
+	router.GET("/audit", endpoint.Audit)
+}
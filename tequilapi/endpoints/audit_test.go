@@ -0,0 +1,115 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/mysteriumnetwork/node/core/audit"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Audit_ListsEntries(t *testing.T) {
+	log := &mockAuditLog{entries: []audit.Entry{
+		{
+			Timestamp:  time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC),
+			Actor:      "myst",
+			Source:     "127.0.0.1",
+			Action:     "identity.unlock",
+			Parameters: map[string]interface{}{"id": "0x1", "passphrase": audit.Redacted},
+			Outcome:    audit.OutcomeFailure,
+			Error:      "wrong passphrase",
+		},
+	}}
+
+	req, err := http.NewRequest(http.MethodGet, "/audit?actor=myst&action=identity&outcome=failure&from=2020-06-01T00:00:00Z&limit=10", nil)
+	assert.Nil(t, err)
+	resp := httptest.NewRecorder()
+	router := httprouter.New()
+	AddRoutesForAudit(router, log)
+
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, audit.Filter{
+		Actor:   "myst",
+		Action:  "identity",
+		Outcome: audit.OutcomeFailure,
+		From:    time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC),
+		Limit:   10,
+	}, log.filter)
+	assert.JSONEq(t, `{"entries": [{
+		"timestamp": "2020-06-01T12:00:00Z",
+		"actor": "myst",
+		"source": "127.0.0.1",
+		"action": "identity.unlock",
+		"parameters": {"id": "0x1", "passphrase": "[REDACTED]"},
+		"outcome": "failure",
+		"error": "wrong passphrase"
+	}]}`, resp.Body.String())
+}
+
+func Test_Audit_DefaultsLimit(t *testing.T) {
+	log := &mockAuditLog{}
+
+	req, err := http.NewRequest(http.MethodGet, "/audit", nil)
+	assert.Nil(t, err)
+	resp := httptest.NewRecorder()
+	router := httprouter.New()
+	AddRoutesForAudit(router, log)
+
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, audit.Filter{Limit: auditDefaultLimit}, log.filter)
+	assert.JSONEq(t, `{"entries": []}`, resp.Body.String())
+}
+
+func Test_Audit_ValidatesFilter(t *testing.T) {
+	req, err := http.NewRequest(http.MethodGet, "/audit?outcome=maybe&to=yesterday&limit=0", nil)
+	assert.Nil(t, err)
+	resp := httptest.NewRecorder()
+	router := httprouter.New()
+	AddRoutesForAudit(router, &mockAuditLog{})
+
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+	assert.JSONEq(t, `{
+		"message": "validation_error",
+		"errors": {
+			"outcome": [{"code": "invalid", "message": "Outcome must be success or failure"}],
+			"to": [{"code": "invalid", "message": "Time must be in RFC3339 format"}],
+			"limit": [{"code": "invalid", "message": "Limit must be between 1 and 1000"}]
+		}
+	}`, resp.Body.String())
+}
+
+type mockAuditLog struct {
+	entries []audit.Entry
+	filter  audit.Filter
+}
+
+func (m *mockAuditLog) Query(filter audit.Filter) ([]audit.Entry, error) {
+	m.filter = filter
+	return m.entries, nil
+}
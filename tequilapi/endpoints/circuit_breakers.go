@@ -0,0 +1,56 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/mysteriumnetwork/node/requests"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+	"github.com/mysteriumnetwork/node/tequilapi/utils"
+)
+
+type circuitStatusProvider interface {
+	CircuitStatus() []requests.CircuitStatus
+}
+
+type circuitBreakersEndpoint struct {
+	statusProvider circuitStatusProvider
+}
+
+// CircuitBreakers lists circuit breaker states of remote hosts
+// swagger:operation GET /circuit-breakers CircuitBreakers listCircuitBreakers
+// ---
+// summary: Lists circuit breakers
+// description: Returns circuit breaker states of remote hosts contacted by the node
+// responses:
+//   200:
+//     description: List of circuit breakers
+//     schema:
+//       "$ref": "#/definitions/CircuitBreakerListDTO"
+func (ce *circuitBreakersEndpoint) CircuitBreakers(resp http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	utils.WriteAsJSON(contract.NewCircuitBreakerListDTO(ce.statusProvider.CircuitStatus()), resp)
+}
+
+// AddRoutesForCircuitBreakers adds circuit breaker routes to given router
+func AddRoutesForCircuitBreakers(router *httprouter.Router, statusProvider circuitStatusProvider) {
+	endpoint := &circuitBreakersEndpoint{statusProvider: statusProvider}
+
+	router.GET("/circuit-breakers", endpoint.CircuitBreakers)
+}
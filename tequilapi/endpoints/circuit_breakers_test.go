@@ -0,0 +1,59 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/mysteriumnetwork/node/requests"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_CircuitBreakers_ListsStates(t *testing.T) {
+	openedAt := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+	provider := &mockCircuitStatusProvider{statuses: []requests.CircuitStatus{
+		{Host: "api.mysterium.network", State: requests.CircuitClosed},
+		{Host: "broker.mysterium.network", State: requests.CircuitOpen, Failures: 5, OpenedAt: openedAt},
+	}}
+
+	req, err := http.NewRequest(http.MethodGet, "/circuit-breakers", nil)
+	assert.Nil(t, err)
+	resp := httptest.NewRecorder()
+	router := httprouter.New()
+	AddRoutesForCircuitBreakers(router, provider)
+
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{"circuit_breakers": [
+		{"host": "api.mysterium.network", "state": "closed", "failures": 0},
+		{"host": "broker.mysterium.network", "state": "open", "failures": 5, "opened_at": "2020-06-01T12:00:00Z"}
+	]}`, resp.Body.String())
+}
+
+type mockCircuitStatusProvider struct {
+	statuses []requests.CircuitStatus
+}
+
+func (m *mockCircuitStatusProvider) CircuitStatus() []requests.CircuitStatus {
+	return m.statuses
+}
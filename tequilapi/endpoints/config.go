@@ -24,7 +24,9 @@ import (
 
 	"github.com/julienschmidt/httprouter"
 	"github.com/mysteriumnetwork/node/config"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
 	"github.com/mysteriumnetwork/node/tequilapi/utils"
+	"github.com/mysteriumnetwork/node/tequilapi/validation"
 	"github.com/rs/zerolog/log"
 )
 
@@ -43,10 +45,25 @@ type configPayload struct {
 
 type configAPI struct {
 	config configProvider
+	schema *config.Schema
 }
 
-func newConfigAPI(config configProvider) *configAPI {
-	return &configAPI{config: config}
+func newConfigAPI(config configProvider, schema *config.Schema) *configAPI {
+	return &configAPI{config: config, schema: schema}
+}
+
+// GetSchema returns configuration schema
+// swagger:operation GET /config/schema Configuration getConfigSchema
+// ---
+// summary: Returns configuration schema
+// description: Returns type, default value, allowed values and description of every configuration option
+// responses:
+//   200:
+//     description: Configuration schema
+//     schema:
+//       "$ref": "#/definitions/ConfigSchemaDTO"
+func (api *configAPI) GetSchema(writer http.ResponseWriter, httpReq *http.Request, params httprouter.Params) {
+	utils.WriteAsJSON(contract.NewConfigSchemaDTO(api.schema), writer)
 }
 
 // GetUserConfig returns current user configuration
@@ -72,7 +89,7 @@ func (api *configAPI) GetUserConfig(writer http.ResponseWriter, httpReq *http.Re
 // swagger:operation POST /user/config Configuration serUserConfig
 // ---
 // summary: Sets and returns user configuration
-// description: For keys present in the payload, it will set or remove the user config values (if the key is null). Changes are persisted to the config file.
+// description: For keys present in the payload, it will set or remove the user config values (if the key is null). Values are validated against the configuration schema, nothing is changed if any of them is invalid. Changes are persisted to the config file.
 // parameters:
 //   - in: body
 //     name: body
@@ -84,6 +101,10 @@ func (api *configAPI) GetUserConfig(writer http.ResponseWriter, httpReq *http.Re
 //     description: User configuration
 //     schema:
 //       "$ref": "#/definitions/configPayload"
+//   422:
+//     description: Parameters validation error
+//     schema:
+//       "$ref": "#/definitions/ValidationErrorDTO"
 //   500:
 //     description: Internal server error
 //     schema:
@@ -95,6 +116,10 @@ func (api *configAPI) SetUserConfig(writer http.ResponseWriter, httpReq *http.Re
 		utils.SendError(writer, err, http.StatusBadRequest)
 		return
 	}
+	if errorMap := api.validate(req.Data); errorMap.HasErrors() {
+		utils.SendValidationErrorMessage(writer, errorMap)
+		return
+	}
 	for k, v := range req.Data {
 		if isNil(v) {
 			log.Debug().Msgf("Clearing user config value: %q", v)
@@ -112,6 +137,24 @@ func (api *configAPI) SetUserConfig(writer http.ResponseWriter, httpReq *http.Re
 	api.GetUserConfig(writer, nil, nil)
 }
 
+func (api *configAPI) validate(data map[string]interface{}) *validation.FieldErrorMap {
+	values := make(map[string]interface{})
+	for k, v := range data {
+		if !isNil(v) {
+			values[k] = v
+		}
+	}
+	errorMap := validation.NewErrorMap()
+	for _, fieldErr := range api.schema.ValidateAll(values) {
+		// Keys unknown to the node, e.g. settings of UI clients, are stored as is.
+		if fieldErr.Code == config.FieldErrorUnknown {
+			continue
+		}
+		errorMap.ForField(fieldErr.Key).AddError(fieldErr.Code, fieldErr.Message)
+	}
+	return errorMap
+}
+
 func isNil(val interface{}) bool {
 	if val == nil {
 		return true
@@ -126,8 +169,10 @@ func isNil(val interface{}) bool {
 // AddRoutesForConfig registers /config endpoints in Tequilapi
 func AddRoutesForConfig(
 	router *httprouter.Router,
+	schema *config.Schema,
 ) {
-	api := newConfigAPI(config.Current)
+	api := newConfigAPI(config.Current, schema)
+	router.GET("/config/schema", api.GetSchema)
 	router.GET("/config/user", api.GetUserConfig)
 	router.POST("/config/user", api.SetUserConfig)
 }
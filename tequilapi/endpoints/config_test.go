@@ -0,0 +1,129 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/mysteriumnetwork/node/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/urfave/cli/v2"
+)
+
+var testConfigSchema = config.NewSchema([]cli.Flag{
+	&cli.IntFlag{Name: "openvpn.port", Usage: "OpenVPN port to use", Value: 1194},
+	&cli.BoolFlag{Name: "shaper.enabled", Usage: "Limit bandwidth"},
+})
+
+func newTestConfigRouter(cfg configProvider) *httprouter.Router {
+	api := newConfigAPI(cfg, testConfigSchema)
+	router := httprouter.New()
+	router.GET("/config/schema", api.GetSchema)
+	router.POST("/config/user", api.SetUserConfig)
+	return router
+}
+
+func Test_Config_GetSchema(t *testing.T) {
+	req, err := http.NewRequest(http.MethodGet, "/config/schema", nil)
+	assert.Nil(t, err)
+	resp := httptest.NewRecorder()
+
+	newTestConfigRouter(&mockConfigProvider{}).ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{
+		"fields": [
+			{"key": "openvpn.port", "type": "int", "default": 1194, "description": "OpenVPN port to use", "requires_restart": true},
+			{"key": "shaper.enabled", "type": "bool", "default": false, "description": "Limit bandwidth", "requires_restart": false}
+		]
+	}`, resp.Body.String())
+}
+
+func Test_Config_SetUserConfig_RejectsInvalidValues(t *testing.T) {
+	cfg := &mockConfigProvider{}
+	req, err := http.NewRequest(
+		http.MethodPost,
+		"/config/user",
+		bytes.NewBufferString(`{"data": {"openvpn": {"port": "abc"}, "shaper.enabled": true, "unknown.key": 1}}`),
+	)
+	assert.Nil(t, err)
+	resp := httptest.NewRecorder()
+
+	newTestConfigRouter(cfg).ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+	assert.JSONEq(t, `{
+		"message": "validation_error",
+		"errors": {
+			"openvpn.port": [{"code": "invalid_type", "message": "Value must be of type int"}]
+		}
+	}`, resp.Body.String())
+	assert.Empty(t, cfg.set)
+	assert.False(t, cfg.saved)
+}
+
+func Test_Config_SetUserConfig_SavesValidValues(t *testing.T) {
+	cfg := &mockConfigProvider{}
+	req, err := http.NewRequest(
+		http.MethodPost,
+		"/config/user",
+		bytes.NewBufferString(`{"data": {"openvpn.port": 5522, "shaper.enabled": null, "ui": {"theme": "dark"}}}`),
+	)
+	assert.Nil(t, err)
+	resp := httptest.NewRecorder()
+
+	newTestConfigRouter(cfg).ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, map[string]interface{}{
+		"openvpn.port": float64(5522),
+		"ui":           map[string]interface{}{"theme": "dark"},
+	}, cfg.set)
+	assert.Equal(t, []string{"shaper.enabled"}, cfg.removed)
+	assert.True(t, cfg.saved)
+}
+
+type mockConfigProvider struct {
+	set     map[string]interface{}
+	removed []string
+	saved   bool
+}
+
+func (m *mockConfigProvider) GetUserConfig() map[string]interface{} {
+	return m.set
+}
+
+func (m *mockConfigProvider) SetUser(key string, value interface{}) {
+	if m.set == nil {
+		m.set = make(map[string]interface{})
+	}
+	m.set[key] = value
+}
+
+func (m *mockConfigProvider) RemoveUser(key string) {
+	m.removed = append(m.removed, key)
+}
+
+func (m *mockConfigProvider) SaveUserConfig() error {
+	m.saved = true
+	return nil
+}
@@ -25,6 +25,7 @@ import (
 	"github.com/julienschmidt/httprouter"
 	"github.com/mysteriumnetwork/node/core/connection"
 	"github.com/mysteriumnetwork/node/core/discovery/proposal"
+	stateEvent "github.com/mysteriumnetwork/node/core/state/event"
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/identity/registry"
 	"github.com/mysteriumnetwork/node/market"
@@ -94,6 +95,9 @@ type connectionResponse struct {
 
 	// example: {"id":1,"provider_id":"0x71ccbdee7f6afe85a5bc7106323518518cd23b94","servcie_type":"openvpn","service_definition":{"location_originate":{"asn":"","country":"CA"}}}
 	Proposal *proposalDTO `json:"proposal,omitempty"`
+
+	// last known p2p channel statistics of the session
+	ChannelStats *stateEvent.ChannelStats `json:"channel_stats,omitempty"`
 }
 
 // swagger:model IPDTO
@@ -170,6 +174,10 @@ func (ce *ConnectionEndpoint) Status(resp http.ResponseWriter, _ *http.Request,
 //     description: Bad request
 //     schema:
 //       "$ref": "#/definitions/ErrorMessageDTO"
+//   403:
+//     description: Forbidden. Provider is blocked by the consumer
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
 //   409:
 //     description: Conflict. Connection already exists
 //     schema:
@@ -238,6 +246,8 @@ func (ce *ConnectionEndpoint) Create(resp http.ResponseWriter, req *http.Request
 			utils.SendError(resp, err, http.StatusConflict)
 		case connection.ErrConnectionCancelled:
 			utils.SendError(resp, err, statusConnectCancelled)
+		case connection.ErrProviderBlocked:
+			utils.SendError(resp, err, http.StatusForbidden)
 		default:
 			log.Error().Err(err).Msg("")
 			utils.SendError(resp, err, http.StatusInternalServerError)
@@ -349,9 +359,10 @@ func validateConnectionRequest(cr *connectionRequest) *validation.FieldErrorMap
 
 func toConnectionResponse(status connection.Status) connectionResponse {
 	response := connectionResponse{
-		Status:     string(status.State),
-		SessionID:  string(status.SessionID),
-		ConsumerID: status.ConsumerID.Address,
+		Status:       string(status.State),
+		SessionID:    string(status.SessionID),
+		ConsumerID:   status.ConsumerID.Address,
+		ChannelStats: stateEvent.NewChannelStats(status.ChannelStats),
 	}
 
 	if status.Proposal.ProviderID != "" {
@@ -31,6 +31,7 @@ import (
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/identity/registry"
 	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/p2p"
 	"github.com/mysteriumnetwork/payments/crypto"
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
@@ -245,6 +246,45 @@ func TestConnectedStateAndSessionIdIsReturnedWhenIsConnected(t *testing.T) {
 
 }
 
+func TestChannelStatsAreReturnedWhenIsConnected(t *testing.T) {
+	var fakeManager = mockConnectionManager{}
+	fakeManager.onStatusReturn = connection.Status{
+		State:     connection.Connected,
+		SessionID: "My-super-session",
+		ChannelStats: &p2p.ChannelStats{
+			RTT:           42 * time.Millisecond,
+			Loss:          0.5,
+			Retransmits:   1,
+			SegmentsSent:  2,
+			BytesSent:     100,
+			BytesReceived: 200,
+		},
+	}
+
+	connEndpoint := NewConnectionEndpoint(&fakeManager, nil, &mockProposalRepository{}, mockIdentityRegistryInstance)
+	req := httptest.NewRequest(http.MethodGet, "/irrelevant", nil)
+	resp := httptest.NewRecorder()
+
+	connEndpoint.Status(resp, req, httprouter.Params{})
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(
+		t,
+		`{
+			"status" : "Connected",
+			"session_id" : "My-super-session",
+			"channel_stats": {
+				"rtt_ms": 42,
+				"loss": 0.5,
+				"retransmits": 1,
+				"segments_sent": 2,
+				"bytes_sent": 100,
+				"bytes_received": 200
+			}
+		}`,
+		resp.Body.String())
+}
+
 func TestPutReturns400ErrorIfRequestBodyIsNotJSON(t *testing.T) {
 	fakeManager := mockConnectionManager{}
 
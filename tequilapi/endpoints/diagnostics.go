@@ -0,0 +1,58 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/mysteriumnetwork/node/core/diagnostics"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+	"github.com/mysteriumnetwork/node/tequilapi/utils"
+)
+
+type diagnosticsRunner interface {
+	Run(ctx context.Context) diagnostics.Report
+}
+
+type diagnosticsEndpoint struct {
+	runner diagnosticsRunner
+}
+
+// Diagnostics runs node self-diagnostics
+// swagger:operation GET /diagnostics Diagnostics runDiagnostics
+// ---
+// summary: Runs node self-diagnostics
+// description: Actively checks the services and system facilities the node depends on, each check has pass, warn or fail status and a remediation hint
+// responses:
+//   200:
+//     description: Diagnostics report
+//     schema:
+//       "$ref": "#/definitions/DiagnosticsDTO"
+func (de *diagnosticsEndpoint) Diagnostics(resp http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	report := de.runner.Run(req.Context())
+	utils.WriteAsJSON(contract.NewDiagnosticsDTO(report), resp)
+}
+
+// AddRoutesForDiagnostics adds diagnostics routes to given router
+func AddRoutesForDiagnostics(router *httprouter.Router, runner diagnosticsRunner) {
+	endpoint := &diagnosticsEndpoint{runner: runner}
+
+	router.GET("/diagnostics", endpoint.Diagnostics)
+}
@@ -0,0 +1,67 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/mysteriumnetwork/node/core/diagnostics"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Diagnostics_ReturnsReport(t *testing.T) {
+	runner := &mockDiagnosticsRunner{report: diagnostics.Report{
+		Status:    diagnostics.StatusWarn,
+		StartedAt: time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC),
+		Results: []diagnostics.Result{
+			{Name: "broker", Status: diagnostics.StatusPass, Message: "broker is reachable", Duration: 15 * time.Millisecond},
+			{Name: "wireguard", Status: diagnostics.StatusWarn, Message: "kernel module is not loaded", Hint: "Install it"},
+		},
+	}}
+
+	req, err := http.NewRequest(http.MethodGet, "/diagnostics", nil)
+	assert.Nil(t, err)
+	resp := httptest.NewRecorder()
+	router := httprouter.New()
+	AddRoutesForDiagnostics(router, runner)
+
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{
+		"status": "warn",
+		"started_at": "2020-06-01T12:00:00Z",
+		"checks": [
+			{"name": "broker", "status": "pass", "message": "broker is reachable", "duration_ms": 15},
+			{"name": "wireguard", "status": "warn", "message": "kernel module is not loaded", "hint": "Install it", "duration_ms": 0}
+		]
+	}`, resp.Body.String())
+}
+
+type mockDiagnosticsRunner struct {
+	report diagnostics.Report
+}
+
+func (m *mockDiagnosticsRunner) Run(_ context.Context) diagnostics.Report {
+	return m.report
+}
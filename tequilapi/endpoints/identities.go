@@ -284,6 +284,10 @@ func (endpoint *identitiesAPI) Get(resp http.ResponseWriter, _ *http.Request, pa
 		Earnings:           settlement.UnsettledBalance,
 		EarningsTotal:      settlement.LifetimeBalance,
 	}
+	if settlement.SettlementPolicy.Type != "" {
+		policy := contract.NewSettlementPolicyDTO(settlement.SettlementPolicy)
+		status.SettlementPolicy = &policy
+	}
 	utils.WriteAsJSON(status, resp)
 }
 
@@ -0,0 +1,314 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/mysteriumnetwork/node/logconfig"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+	"github.com/mysteriumnetwork/node/tequilapi/utils"
+	"github.com/mysteriumnetwork/node/tequilapi/validation"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+// componentLevelDefault restores the level the component logger was created with.
+const componentLevelDefault = "default"
+
+const logTailDefaultLines = 100
+
+type logLevels interface {
+	State() logconfig.LevelState
+	SetDefaultLevel(level zerolog.Level, ttl time.Duration)
+	SetComponentLevel(component string, level zerolog.Level, ttl time.Duration) error
+	ResetComponentLevel(component string) error
+}
+
+type logTail interface {
+	Subscribe() (recent [][]byte, lines <-chan []byte, unsubscribe func())
+}
+
+type logsEndpoint struct {
+	levels logLevels
+	tail   logTail
+}
+
+// LogLevels returns node log levels
+// swagger:operation GET /logs/level Logs getLogLevels
+// ---
+// summary: Returns log levels
+// description: Returns the default log level and log level overrides of node components
+// responses:
+//   200:
+//     description: Log levels
+//     schema:
+//       "$ref": "#/definitions/LogLevelsDTO"
+func (le *logsEndpoint) LogLevels(resp http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	utils.WriteAsJSON(contract.NewLogLevelsDTO(le.levels.State()), resp)
+}
+
+// SetLogLevels changes node log levels
+// swagger:operation PUT /logs/level Logs setLogLevels
+// ---
+// summary: Changes log levels
+// description: Changes the default log level and log levels of node components without restarting the node. Changes are reverted after the given TTL.
+// parameters:
+// - in: body
+//   name: body
+//   schema:
+//     $ref: "#/definitions/SetLogLevelsRequestDTO"
+// responses:
+//   200:
+//     description: Log levels changed
+//     schema:
+//       "$ref": "#/definitions/LogLevelsDTO"
+//   400:
+//     description: Body parsing error
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   422:
+//     description: Parameters validation error
+//     schema:
+//       "$ref": "#/definitions/ValidationErrorDTO"
+func (le *logsEndpoint) SetLogLevels(resp http.ResponseWriter, request *http.Request, _ httprouter.Params) {
+	req := contract.SetLogLevelsRequest{}
+	if err := json.NewDecoder(request.Body).Decode(&req); err != nil {
+		utils.SendError(resp, errors.Wrap(err, "failed to parse log levels"), http.StatusBadRequest)
+		return
+	}
+
+	errorMap := le.validateLogLevels(req)
+	if errorMap.HasErrors() {
+		utils.SendValidationErrorMessage(resp, errorMap)
+		return
+	}
+
+	var ttl time.Duration
+	if req.TTL != "" {
+		ttl, _ = time.ParseDuration(req.TTL)
+	}
+	if req.Level != "" {
+		level, _ := zerolog.ParseLevel(req.Level)
+		le.levels.SetDefaultLevel(level, ttl)
+	}
+	for component, value := range req.Components {
+		var err error
+		if value == componentLevelDefault {
+			err = le.levels.ResetComponentLevel(component)
+		} else {
+			level, _ := zerolog.ParseLevel(value)
+			err = le.levels.SetComponentLevel(component, level, ttl)
+		}
+		if err != nil {
+			utils.SendError(resp, err, http.StatusInternalServerError)
+			return
+		}
+	}
+
+	utils.WriteAsJSON(contract.NewLogLevelsDTO(le.levels.State()), resp)
+}
+
+func (le *logsEndpoint) validateLogLevels(req contract.SetLogLevelsRequest) *validation.FieldErrorMap {
+	errorMap := validation.NewErrorMap()
+	if req.Level != "" && !isLogLevel(req.Level) {
+		errorMap.ForField("level").AddError("invalid", "Invalid log level")
+	}
+	if req.TTL != "" {
+		if ttl, err := time.ParseDuration(req.TTL); err != nil || ttl <= 0 {
+			errorMap.ForField("ttl").AddError("invalid", "TTL must be a positive duration, e.g. 10m")
+		}
+	}
+
+	known := make(map[string]bool)
+	for _, component := range le.levels.State().Components {
+		known[component] = true
+	}
+	for component, value := range req.Components {
+		field := "components." + component
+		if !known[component] {
+			errorMap.ForField(field).AddError("unknown", "Unknown component")
+			continue
+		}
+		if value != componentLevelDefault && !isLogLevel(value) {
+			errorMap.ForField(field).AddError("invalid", "Invalid log level")
+		}
+	}
+	return errorMap
+}
+
+func isLogLevel(value string) bool {
+	level, err := zerolog.ParseLevel(value)
+	return err == nil && level != zerolog.NoLevel
+}
+
+// LogTail streams node log
+// swagger:operation GET /logs/tail Logs tailLogs
+// ---
+// summary: Streams node log
+// description: Returns recent node log messages as JSON lines and keeps streaming new ones until the client disconnects
+// parameters:
+// - in: query
+//   name: lines
+//   description: Number of recent messages to return before streaming, defaults to 100
+//   type: integer
+// - in: query
+//   name: level
+//   description: Minimum level of messages
+//   type: string
+// - in: query
+//   name: component
+//   description: Component of messages, e.g. "p2p"
+//   type: string
+// - in: query
+//   name: follow
+//   description: Set to false to return recent messages without streaming
+//   type: boolean
+// produces:
+// - application/x-ndjson
+// responses:
+//   200:
+//     description: Log messages
+//   422:
+//     description: Parameters validation error
+//     schema:
+//       "$ref": "#/definitions/ValidationErrorDTO"
+func (le *logsEndpoint) LogTail(resp http.ResponseWriter, request *http.Request, _ httprouter.Params) {
+	filter, lines, follow, errorMap := toLogTailParams(request)
+	if errorMap.HasErrors() {
+		utils.SendValidationErrorMessage(resp, errorMap)
+		return
+	}
+
+	recent, stream, unsubscribe := le.tail.Subscribe()
+	defer unsubscribe()
+
+	resp.Header().Set("Content-Type", "application/x-ndjson")
+	resp.WriteHeader(http.StatusOK)
+
+	var matching [][]byte
+	for _, line := range recent {
+		if filter.matches(line) {
+			matching = append(matching, line)
+		}
+	}
+	if len(matching) > lines {
+		matching = matching[len(matching)-lines:]
+	}
+	for _, line := range matching {
+		if _, err := resp.Write(line); err != nil {
+			return
+		}
+	}
+
+	flusher, ok := resp.(http.Flusher)
+	if !follow || !ok {
+		return
+	}
+	flusher.Flush()
+
+	for {
+		select {
+		case <-request.Context().Done():
+			return
+		case line := <-stream:
+			if !filter.matches(line) {
+				continue
+			}
+			if _, err := resp.Write(line); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+type logLineFilter struct {
+	level     zerolog.Level
+	component string
+}
+
+func (f logLineFilter) matches(line []byte) bool {
+	if f.level == zerolog.NoLevel && f.component == "" {
+		return true
+	}
+
+	var fields struct {
+		Level     string `json:"level"`
+		Component string `json:"component"`
+	}
+	if err := json.Unmarshal(line, &fields); err != nil {
+		return false
+	}
+	if f.component != "" && f.component != fields.Component {
+		return false
+	}
+	if f.level != zerolog.NoLevel {
+		level, err := zerolog.ParseLevel(fields.Level)
+		if err != nil || level < f.level {
+			return false
+		}
+	}
+	return true
+}
+
+func toLogTailParams(request *http.Request) (logLineFilter, int, bool, *validation.FieldErrorMap) {
+	query := request.URL.Query()
+	errorMap := validation.NewErrorMap()
+	filter := logLineFilter{level: zerolog.NoLevel, component: query.Get("component")}
+	lines := logTailDefaultLines
+	follow := true
+
+	if value := query.Get("level"); value != "" {
+		if isLogLevel(value) {
+			filter.level, _ = zerolog.ParseLevel(value)
+		} else {
+			errorMap.ForField("level").AddError("invalid", "Invalid log level")
+		}
+	}
+	if value := query.Get("lines"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 0 {
+			errorMap.ForField("lines").AddError("invalid", "Lines must be a non-negative number")
+		} else {
+			lines = parsed
+		}
+	}
+	if value := query.Get("follow"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			errorMap.ForField("follow").AddError("invalid", "Follow must be true or false")
+		} else {
+			follow = parsed
+		}
+	}
+	return filter, lines, follow, errorMap
+}
+
+// AddRoutesForLogs adds log control routes to given router
+func AddRoutesForLogs(router *httprouter.Router, levels logLevels, tail logTail) {
+	endpoint := &logsEndpoint{levels: levels, tail: tail}
+
+	router.GET("/logs/level", endpoint.LogLevels)
+	router.PUT("/logs/level", endpoint.SetLogLevels)
+	router.GET("/logs/tail", endpoint.LogTail)
+}
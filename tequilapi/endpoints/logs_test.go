@@ -0,0 +1,192 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/mysteriumnetwork/node/logconfig"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_LogLevels_ReturnsLevels(t *testing.T) {
+	expiresAt := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+	levels := &mockLogLevels{state: logconfig.LevelState{
+		Default:    zerolog.InfoLevel,
+		Overrides:  []logconfig.ComponentLevel{{Component: "p2p", Level: zerolog.TraceLevel, ExpiresAt: expiresAt}},
+		Components: []string{"p2p", "pingpong"},
+	}}
+
+	resp := serveLogsRequest(t, levels, &mockLogTail{}, http.MethodGet, "/logs/level", "")
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{
+		"level": "info",
+		"overrides": [{"component": "p2p", "level": "trace", "expires_at": "2020-06-01T12:00:00Z"}],
+		"components": ["p2p", "pingpong"]
+	}`, resp.Body.String())
+}
+
+func Test_SetLogLevels_ChangesLevels(t *testing.T) {
+	levels := &mockLogLevels{state: logconfig.LevelState{
+		Default:    zerolog.InfoLevel,
+		Components: []string{"p2p", "pingpong"},
+	}}
+
+	resp := serveLogsRequest(t, levels, &mockLogTail{}, http.MethodPut, "/logs/level",
+		`{"level": "warn", "components": {"p2p": "trace", "pingpong": "default"}, "ttl": "10m"}`)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, zerolog.WarnLevel, levels.defaultLevel)
+	assert.Equal(t, map[string]zerolog.Level{"p2p": zerolog.TraceLevel}, levels.componentLevels)
+	assert.Equal(t, []string{"pingpong"}, levels.reset)
+	assert.Equal(t, 10*time.Minute, levels.ttl)
+}
+
+func Test_SetLogLevels_ValidatesRequest(t *testing.T) {
+	levels := &mockLogLevels{state: logconfig.LevelState{Components: []string{"p2p"}}}
+
+	resp := serveLogsRequest(t, levels, &mockLogTail{}, http.MethodPut, "/logs/level",
+		`{"level": "loud", "components": {"p2p": "verbose", "dns": "debug"}, "ttl": "-1m"}`)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+	assert.JSONEq(t, `{
+		"message": "validation_error",
+		"errors": {
+			"level": [{"code": "invalid", "message": "Invalid log level"}],
+			"ttl": [{"code": "invalid", "message": "TTL must be a positive duration, e.g. 10m"}],
+			"components.p2p": [{"code": "invalid", "message": "Invalid log level"}],
+			"components.dns": [{"code": "unknown", "message": "Unknown component"}]
+		}
+	}`, resp.Body.String())
+	assert.Empty(t, levels.componentLevels)
+}
+
+func Test_LogTail_ReturnsFilteredRecentLines(t *testing.T) {
+	tail := &mockLogTail{recent: [][]byte{
+		[]byte(`{"level":"debug","component":"p2p","message":"one"}` + "\n"),
+		[]byte(`{"level":"info","message":"two"}` + "\n"),
+		[]byte(`{"level":"warn","component":"p2p","message":"three"}` + "\n"),
+		[]byte(`{"level":"error","component":"p2p","message":"four"}` + "\n"),
+	}}
+
+	resp := serveLogsRequest(t, &mockLogLevels{}, tail, http.MethodGet, "/logs/tail?follow=false&component=p2p&level=warn&lines=1", "")
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "application/x-ndjson", resp.Header().Get("Content-Type"))
+	assert.Equal(t, `{"level":"error","component":"p2p","message":"four"}`+"\n", resp.Body.String())
+	assert.True(t, tail.unsubscribed)
+}
+
+func Test_LogTail_StreamsNewLines(t *testing.T) {
+	tail := &mockLogTail{lines: make(chan []byte, 2)}
+	router := httprouter.New()
+	AddRoutesForLogs(router, &mockLogLevels{}, tail)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, err := http.NewRequest(http.MethodGet, server.URL+"/logs/tail?level=info", nil)
+	require.NoError(t, err)
+	resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	tail.lines <- []byte(`{"level":"debug","message":"skipped"}` + "\n")
+	tail.lines <- []byte(`{"level":"info","message":"streamed"}` + "\n")
+
+	line, err := bufio.NewReader(resp.Body).ReadString('\n')
+	require.NoError(t, err)
+	assert.Equal(t, `{"level":"info","message":"streamed"}`+"\n", line)
+}
+
+func Test_LogTail_ValidatesParams(t *testing.T) {
+	resp := serveLogsRequest(t, &mockLogLevels{}, &mockLogTail{}, http.MethodGet, "/logs/tail?level=loud&lines=-1&follow=maybe", "")
+
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+	assert.JSONEq(t, `{
+		"message": "validation_error",
+		"errors": {
+			"level": [{"code": "invalid", "message": "Invalid log level"}],
+			"lines": [{"code": "invalid", "message": "Lines must be a non-negative number"}],
+			"follow": [{"code": "invalid", "message": "Follow must be true or false"}]
+		}
+	}`, resp.Body.String())
+}
+
+func serveLogsRequest(t *testing.T, levels logLevels, tail logTail, method, url, body string) *httptest.ResponseRecorder {
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	require.NoError(t, err)
+	resp := httptest.NewRecorder()
+	router := httprouter.New()
+	AddRoutesForLogs(router, levels, tail)
+
+	router.ServeHTTP(resp, req)
+	return resp
+}
+
+type mockLogLevels struct {
+	state           logconfig.LevelState
+	defaultLevel    zerolog.Level
+	componentLevels map[string]zerolog.Level
+	reset           []string
+	ttl             time.Duration
+}
+
+func (m *mockLogLevels) State() logconfig.LevelState {
+	return m.state
+}
+
+func (m *mockLogLevels) SetDefaultLevel(level zerolog.Level, ttl time.Duration) {
+	m.defaultLevel = level
+	m.ttl = ttl
+}
+
+func (m *mockLogLevels) SetComponentLevel(component string, level zerolog.Level, ttl time.Duration) error {
+	if m.componentLevels == nil {
+		m.componentLevels = make(map[string]zerolog.Level)
+	}
+	m.componentLevels[component] = level
+	m.ttl = ttl
+	return nil
+}
+
+func (m *mockLogLevels) ResetComponentLevel(component string) error {
+	m.reset = append(m.reset, component)
+	return nil
+}
+
+type mockLogTail struct {
+	recent       [][]byte
+	lines        chan []byte
+	unsubscribed bool
+}
+
+func (m *mockLogTail) Subscribe() ([][]byte, <-chan []byte, func()) {
+	return m.recent, m.lines, func() { m.unsubscribed = true }
+}
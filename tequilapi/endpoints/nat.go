@@ -21,18 +21,26 @@ import (
 	"net/http"
 
 	"github.com/julienschmidt/httprouter"
+	"github.com/mysteriumnetwork/node/nat/stun"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
 	"github.com/mysteriumnetwork/node/tequilapi/utils"
 )
 
+type natTypeProvider interface {
+	NATType() stun.NATType
+}
+
 // NATEndpoint struct represents endpoints about NAT traversal
 type NATEndpoint struct {
-	stateProvider stateProvider
+	stateProvider   stateProvider
+	natTypeProvider natTypeProvider
 }
 
 // NewNATEndpoint creates and returns nat endpoint
-func NewNATEndpoint(stateProvider stateProvider) *NATEndpoint {
+func NewNATEndpoint(stateProvider stateProvider, natTypeProvider natTypeProvider) *NATEndpoint {
 	return &NATEndpoint{
-		stateProvider: stateProvider,
+		stateProvider:   stateProvider,
+		natTypeProvider: natTypeProvider,
 	}
 }
 
@@ -50,9 +58,24 @@ func (ne *NATEndpoint) NATStatus(resp http.ResponseWriter, _ *http.Request, _ ht
 	utils.WriteAsJSON(ne.stateProvider.GetState().NATStatus, resp)
 }
 
+// NATType provides NAT type detected by STUN probes
+// swagger:operation GET /nat/type NAT NATTypeDTO
+// ---
+// summary: Shows NAT type
+// description: NAT type returns the NAT type of the node detected using STUN servers
+// responses:
+//   200:
+//     description: NAT type ("unknown"/"none"/"full_cone"/"restricted_cone"/"port_restricted_cone"/"symmetric")
+//     schema:
+//       "$ref": "#/definitions/NATTypeDTO"
+func (ne *NATEndpoint) NATType(resp http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	utils.WriteAsJSON(contract.NewNATTypeDTO(ne.natTypeProvider.NATType()), resp)
+}
+
 // AddRoutesForNAT adds nat routes to given router
-func AddRoutesForNAT(router *httprouter.Router, stateProvider stateProvider) {
-	natEndpoint := NewNATEndpoint(stateProvider)
+func AddRoutesForNAT(router *httprouter.Router, stateProvider stateProvider, natTypeProvider natTypeProvider) {
+	natEndpoint := NewNATEndpoint(stateProvider, natTypeProvider)
 
 	router.GET("/nat/status", natEndpoint.NATStatus)
+	router.GET("/nat/type", natEndpoint.NATType)
 }
@@ -25,6 +25,7 @@ import (
 
 	"github.com/julienschmidt/httprouter"
 	stateEvent "github.com/mysteriumnetwork/node/core/state/event"
+	"github.com/mysteriumnetwork/node/nat/stun"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -43,10 +44,31 @@ func Test_NATStatus_ReturnsStatusSuccessful_WithSuccessfulEvent(t *testing.T) {
 	assert.Nil(t, err)
 	resp := httptest.NewRecorder()
 	router := httprouter.New()
-	AddRoutesForNAT(router, provider)
+	AddRoutesForNAT(router, provider, &mockNATTypeProvider{})
 
 	router.ServeHTTP(resp, req)
 
 	assert.Equal(t, http.StatusOK, resp.Code)
 	assert.JSONEq(t, string(expectedJSON), resp.Body.String())
 }
+
+func Test_NATType_ReturnsDetectedType(t *testing.T) {
+	req, err := http.NewRequest(http.MethodGet, "/nat/type", nil)
+	assert.Nil(t, err)
+	resp := httptest.NewRecorder()
+	router := httprouter.New()
+	AddRoutesForNAT(router, &mockStateProvider{}, &mockNATTypeProvider{natType: stun.TypePortRestrictedCone})
+
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{"type": "port_restricted_cone"}`, resp.Body.String())
+}
+
+type mockNATTypeProvider struct {
+	natType stun.NATType
+}
+
+func (m *mockNATTypeProvider) NATType() stun.NATType {
+	return m.natType
+}
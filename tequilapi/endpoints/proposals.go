@@ -19,11 +19,14 @@ package endpoints
 
 import (
 	"net/http"
+	"sort"
 	"strconv"
 
 	"github.com/julienschmidt/httprouter"
 	"github.com/mysteriumnetwork/node/core/discovery/proposal"
+	"github.com/mysteriumnetwork/node/core/preference"
 	"github.com/mysteriumnetwork/node/core/quality"
+	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/market"
 	"github.com/mysteriumnetwork/node/money"
 	"github.com/mysteriumnetwork/node/tequilapi/utils"
@@ -32,6 +35,9 @@ import (
 // swagger:model ProposalsList
 type proposalsRes struct {
 	Proposals []*proposalDTO `json:"proposals"`
+
+	// true if proposals were served from an outdated cache, because discovery is not reachable or not refreshed yet
+	Stale bool `json:"stale,omitempty"`
 }
 
 // swagger:model ServiceLocationDTO
@@ -55,12 +61,43 @@ type locationRes struct {
 // swagger:model ServiceDefinitionDTO
 type serviceDefinitionRes struct {
 	LocationOriginate locationRes `json:"location_originate"`
+
+	// true if the service provides IPv6 egress
+	IPv6 bool `json:"ipv6,omitempty"`
 }
 
 type metricsRes struct {
 	ConnectCount quality.ConnectCount `json:"connect_count"`
 }
 
+// swagger:model ProviderQualityDTO
+type providerQualityRes struct {
+	// example: 0x0000000000000000000000000000000000000001
+	ProviderID string `json:"provider_id"`
+
+	// local quality score from 0 to 1, 0.5 if there is no history with the provider
+	// example: 0.75
+	Score float64 `json:"score"`
+
+	ConnectCount quality.ConnectCount `json:"connect_count"`
+
+	// example: 1500
+	AvgConnectTimeMillis int64 `json:"avg_connect_time_ms"`
+
+	// example: 3
+	Sessions int `json:"sessions"`
+
+	// example: 600
+	AvgSessionSeconds int64 `json:"avg_session_seconds"`
+
+	// average download speed in bits per second
+	// example: 5000000
+	AvgThroughput uint64 `json:"avg_throughput"`
+
+	// number of connections ended by each reason
+	DisconnectReasons map[string]int `json:"disconnect_reasons"`
+}
+
 type paymentRateRes struct {
 	PerSeconds uint64 `json:"per_seconds"`
 	PerBytes   uint64 `json:"per_bytes"`
@@ -97,6 +134,9 @@ type proposalDTO struct {
 
 	// PaymentMethod
 	PaymentMethod paymentMethodRes `json:"payment_method"`
+
+	// true if the consumer marked the provider as favourite
+	Favourite bool `json:"favourite,omitempty"`
 }
 
 func proposalToRes(p market.ServiceProposal) *proposalDTO {
@@ -114,6 +154,7 @@ func proposalToRes(p market.ServiceProposal) *proposalDTO {
 				ISP:      p.ServiceDefinition.GetLocation().ISP,
 				NodeType: p.ServiceDefinition.GetLocation().NodeType,
 			},
+			IPv6: supportsIPv6(p.ServiceDefinition),
 		},
 		AccessPolicies: p.AccessPolicies,
 		PaymentMethod: paymentMethodRes{
@@ -127,28 +168,53 @@ func proposalToRes(p market.ServiceProposal) *proposalDTO {
 	}
 }
 
+func supportsIPv6(service market.ServiceDefinition) bool {
+	capable, ok := service.(market.IPv6Capable)
+	return ok && capable.SupportsIPv6()
+}
+
+// staleAwareRepository provides proposals along with the indication whether they are outdated.
+type staleAwareRepository interface {
+	ProposalsWithStatus(filter *proposal.Filter) ([]market.ServiceProposal, bool, error)
+}
+
 // QualityFinder allows to fetch proposal quality data
 type QualityFinder interface {
 	ProposalsMetrics() []quality.ConnectMetric
 }
 
+// LocalQualityProvider allows to fetch the quality of providers from the local connection history
+type LocalQualityProvider interface {
+	ProviderQuality(providerID string) (quality.ProviderQuality, error)
+	Score(providerID string) float64
+}
+
+// ProviderPreferencesGetter allows to fetch the favourite and blocked providers of a consumer
+type ProviderPreferencesGetter interface {
+	Get(consumerID identity.Identity) (preference.ProviderPreferences, error)
+}
+
 type proposalsEndpoint struct {
 	proposalRepository proposal.Repository
 	qualityProvider    QualityFinder
+	localQuality       LocalQualityProvider
+	preferences        ProviderPreferencesGetter
 }
 
 // NewProposalsEndpoint creates and returns proposal creation endpoint
-func NewProposalsEndpoint(proposalRepository proposal.Repository, qualityProvider QualityFinder) *proposalsEndpoint {
+func NewProposalsEndpoint(proposalRepository proposal.Repository, qualityProvider QualityFinder, localQuality LocalQualityProvider, preferences ProviderPreferencesGetter) *proposalsEndpoint {
 	return &proposalsEndpoint{
 		proposalRepository: proposalRepository,
 		qualityProvider:    qualityProvider,
+		localQuality:       localQuality,
+		preferences:        preferences,
 	}
 }
 
 // swagger:operation GET /proposals Proposal listProposals
 // ---
 // summary: Returns proposals
-// description: Returns list of proposals filtered by provider id
+// description: Returns list of proposals filtered by provider id, ordered by the local quality score of providers
 // parameters:
 //   - in: query
 //     name: provider_id
@@ -167,6 +233,14 @@ func NewProposalsEndpoint(proposalRepository proposal.Repository, qualityProvide
 //     description: the access policy source to filter the proposals by
 //     type: string
 //   - in: query
+//     name: ipv6
+//     description: if set to true, returns only the proposals providing IPv6 egress
+//     type: boolean
+//   - in: query
+//     name: consumer_id
+//     description: consumer identity, whose blocked providers are excluded and favourite providers are flagged
+//     type: string
+//   - in: query
 //     name: fetch_connect_counts
 //     description: if set to true, fetches the connection success metrics for nodes. False by default.
 //     type: boolean
@@ -204,7 +278,7 @@ func (pe *proposalsEndpoint) List(resp http.ResponseWriter, req *http.Request, p
 		return
 	}
 
-	proposals, err := pe.proposalRepository.Proposals(&proposal.Filter{
+	filter := &proposal.Filter{
 		ProviderID:          req.URL.Query().Get("provider_id"),
 		ServiceType:         req.URL.Query().Get("service_type"),
 		AccessPolicyID:      req.URL.Query().Get("access_policy_id"),
@@ -214,16 +288,41 @@ func (pe *proposalsEndpoint) List(resp http.ResponseWriter, req *http.Request, p
 		LowerTimePriceBound: lowerTimePriceBound,
 		UpperTimePriceBound: upperTimePriceBound,
 		ExcludeUnsupported:  true,
-	})
+		IPv6:                req.URL.Query().Get("ipv6") == "true",
+	}
+
+	var proposals []market.ServiceProposal
+	var stale bool
+	if repository, ok := pe.proposalRepository.(staleAwareRepository); ok {
+		proposals, stale, err = repository.ProposalsWithStatus(filter)
+	} else {
+		proposals, err = pe.proposalRepository.Proposals(filter)
+	}
 
 	if err != nil {
 		utils.SendError(resp, err, http.StatusInternalServerError)
 		return
 	}
 
-	proposalsRes := proposalsRes{Proposals: []*proposalDTO{}}
+	prefs := preference.ProviderPreferences{}
+	if consumerID := req.URL.Query().Get("consumer_id"); consumerID != "" {
+		prefs, err = pe.preferences.Get(identity.FromAddress(consumerID))
+		if err != nil {
+			utils.SendError(resp, err, http.StatusInternalServerError)
+			return
+		}
+	}
+
+	pe.sortByLocalQuality(proposals)
+
+	proposalsRes := proposalsRes{Proposals: []*proposalDTO{}, Stale: stale}
 	for _, p := range proposals {
-		proposalsRes.Proposals = append(proposalsRes.Proposals, proposalToRes(p))
+		if prefs.IsBlocked(p.ProviderID) {
+			continue
+		}
+		dto := proposalToRes(p)
+		dto.Favourite = prefs.IsFavourite(p.ProviderID)
+		proposalsRes.Proposals = append(proposalsRes.Proposals, dto)
 	}
 
 	if fetchConnectCounts == "true" {
@@ -234,6 +333,58 @@ func (pe *proposalsEndpoint) List(resp http.ResponseWriter, req *http.Request, p
 	utils.WriteAsJSON(proposalsRes, resp)
 }
 
+// swagger:operation GET /proposals/{provider_id}/quality Proposal providerQuality
+// ---
+// summary: Returns local quality of provider
+// description: Returns connection quality of the provider recorded by this node, available even if the remote quality service is disabled
+// parameters:
+//   - in: path
+//     name: provider_id
+//     description: id of provider
+//     type: string
+//     required: true
+// responses:
+//   200:
+//     description: Local provider quality
+//     schema:
+//       "$ref": "#/definitions/ProviderQualityDTO"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+func (pe *proposalsEndpoint) Quality(resp http.ResponseWriter, req *http.Request, params httprouter.Params) {
+	pq, err := pe.localQuality.ProviderQuality(params.ByName("provider_id"))
+	if err != nil {
+		utils.SendError(resp, err, http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteAsJSON(providerQualityRes{
+		ProviderID:           pq.ProviderID,
+		Score:                pq.Score(),
+		ConnectCount:         quality.ConnectCount{Success: pq.ConnectSuccess, Fail: pq.ConnectFail},
+		AvgConnectTimeMillis: pq.AvgConnectTime().Milliseconds(),
+		Sessions:             pq.Sessions,
+		AvgSessionSeconds:    int64(pq.AvgSessionDuration().Seconds()),
+		AvgThroughput:        uint64(pq.AvgThroughput()),
+		DisconnectReasons:    pq.DisconnectReasons,
+	}, resp)
+}
+
+// sortByLocalQuality orders proposals by the local quality score of their providers, best first.
+func (pe *proposalsEndpoint) sortByLocalQuality(proposals []market.ServiceProposal) {
+	scores := make(map[string]float64)
+	for _, p := range proposals {
+		if _, ok := scores[p.ProviderID]; !ok {
+			scores[p.ProviderID] = pe.localQuality.Score(p.ProviderID)
+		}
+	}
+
+	sort.SliceStable(proposals, func(i, j int) bool {
+		return scores[proposals[i].ProviderID] > scores[proposals[j].ProviderID]
+	})
+}
+
 func parsePriceBound(req *http.Request, key string) (*uint64, error) {
 	bound := req.URL.Query().Get(key)
 	if bound == "" {
@@ -244,9 +395,10 @@ func parsePriceBound(req *http.Request, key string) (*uint64, error) {
 }
 
 // AddRoutesForProposals attaches proposals endpoints to router
-func AddRoutesForProposals(router *httprouter.Router, proposalRepository proposal.Repository, qualityProvider QualityFinder) {
-	pe := NewProposalsEndpoint(proposalRepository, qualityProvider)
+func AddRoutesForProposals(router *httprouter.Router, proposalRepository proposal.Repository, qualityProvider QualityFinder, localQuality LocalQualityProvider, preferences ProviderPreferencesGetter) {
+	pe := NewProposalsEndpoint(proposalRepository, qualityProvider, localQuality, preferences)
 	router.GET("/proposals", pe.List)
+	router.GET("/proposals/:provider_id/quality", pe.Quality)
 }
 
 // addProposalMetrics adds quality metrics to proposals.
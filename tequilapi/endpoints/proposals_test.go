@@ -18,14 +18,19 @@
 package endpoints
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"testing"
+	"time"
 
+	"github.com/julienschmidt/httprouter"
 	"github.com/mysteriumnetwork/node/core/discovery/proposal"
+	"github.com/mysteriumnetwork/node/core/preference"
 	"github.com/mysteriumnetwork/node/core/quality"
+	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/market"
 	"github.com/mysteriumnetwork/node/mocks"
 	"github.com/stretchr/testify/assert"
@@ -80,7 +85,7 @@ func TestProposalsEndpointListByNodeId(t *testing.T) {
 	req.URL.RawQuery = query.Encode()
 
 	resp := httptest.NewRecorder()
-	handlerFunc := NewProposalsEndpoint(repository, &mockQualityProvider{}).List
+	handlerFunc := NewProposalsEndpoint(repository, &mockQualityProvider{}, &mockLocalQuality{}, &mockPreferences{}).List
 	handlerFunc(resp, req, nil)
 
 	assert.JSONEq(
@@ -148,7 +153,7 @@ func TestProposalsEndpointAcceptsAccessPolicyParams(t *testing.T) {
 	req.URL.RawQuery = query.Encode()
 
 	resp := httptest.NewRecorder()
-	handlerFunc := NewProposalsEndpoint(repository, &mockQualityProvider{}).List
+	handlerFunc := NewProposalsEndpoint(repository, &mockQualityProvider{}, &mockLocalQuality{}, &mockPreferences{}).List
 	handlerFunc(resp, req, nil)
 
 	assert.JSONEq(
@@ -205,7 +210,7 @@ func TestProposalsEndpointList(t *testing.T) {
 	assert.Nil(t, err)
 
 	resp := httptest.NewRecorder()
-	handlerFunc := NewProposalsEndpoint(repository, &mockQualityProvider{}).List
+	handlerFunc := NewProposalsEndpoint(repository, &mockQualityProvider{}, &mockLocalQuality{}, &mockPreferences{}).List
 	handlerFunc(resp, req, nil)
 
 	assert.JSONEq(
@@ -277,7 +282,7 @@ func TestProposalsEndpointListFetchConnectCounts(t *testing.T) {
 
 	resp := httptest.NewRecorder()
 
-	handlerFunc := NewProposalsEndpoint(repository, &mockQualityProvider{}).List
+	handlerFunc := NewProposalsEndpoint(repository, &mockQualityProvider{}, &mockLocalQuality{}, &mockPreferences{}).List
 	handlerFunc(resp, req, nil)
 
 	assert.JSONEq(
@@ -343,6 +348,133 @@ func TestProposalsEndpointListFetchConnectCounts(t *testing.T) {
 	)
 }
 
+func TestProposalsEndpointListMarksStaleProposals(t *testing.T) {
+	repository := &mockStaleProposalRepository{
+		mockProposalRepository: mockProposalRepository{proposals: []market.ServiceProposal{serviceProposals[0]}},
+		stale:                  true,
+	}
+
+	req, err := http.NewRequest(http.MethodGet, "/irrelevant", nil)
+	assert.Nil(t, err)
+
+	resp := httptest.NewRecorder()
+	handlerFunc := NewProposalsEndpoint(repository, &mockQualityProvider{}, &mockLocalQuality{}, &mockPreferences{}).List
+	handlerFunc(resp, req, nil)
+
+	parsed := proposalsRes{}
+	assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &parsed))
+	assert.True(t, parsed.Stale)
+	assert.Len(t, parsed.Proposals, 1)
+}
+
+func TestProposalsEndpointListAppliesConsumerPreferences(t *testing.T) {
+	repository := &mockProposalRepository{
+		proposals: serviceProposals,
+	}
+	preferences := &mockPreferences{
+		prefs: preference.ProviderPreferences{
+			Favourites: []string{"0xProviderId"},
+			Blocked:    []string{"other_provider"},
+		},
+	}
+
+	req, err := http.NewRequest(http.MethodGet, "/irrelevant?consumer_id=0xConsumer", nil)
+	assert.Nil(t, err)
+
+	resp := httptest.NewRecorder()
+	handlerFunc := NewProposalsEndpoint(repository, &mockQualityProvider{}, &mockLocalQuality{}, preferences).List
+	handlerFunc(resp, req, nil)
+
+	parsed := proposalsRes{}
+	assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &parsed))
+	assert.Len(t, parsed.Proposals, 1)
+	assert.Equal(t, "0xProviderId", parsed.Proposals[0].ProviderID)
+	assert.True(t, parsed.Proposals[0].Favourite)
+	assert.Equal(t, identity.FromAddress("0xConsumer"), preferences.recordedConsumer)
+}
+
+func TestProposalsEndpointListSortsByLocalQuality(t *testing.T) {
+	repository := &mockProposalRepository{
+		proposals: []market.ServiceProposal{serviceProposals[0], serviceProposals[1]},
+	}
+	localQuality := &mockLocalQuality{scores: map[string]float64{"other_provider": 0.9}}
+
+	req, err := http.NewRequest(http.MethodGet, "/irrelevant", nil)
+	assert.Nil(t, err)
+
+	resp := httptest.NewRecorder()
+	handlerFunc := NewProposalsEndpoint(repository, &mockQualityProvider{}, localQuality, &mockPreferences{}).List
+	handlerFunc(resp, req, nil)
+
+	parsed := proposalsRes{}
+	assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &parsed))
+	assert.Len(t, parsed.Proposals, 2)
+	assert.Equal(t, "other_provider", parsed.Proposals[0].ProviderID)
+	assert.Equal(t, "0xProviderId", parsed.Proposals[1].ProviderID)
+}
+
+func TestProposalsEndpointQuality(t *testing.T) {
+	localQuality := &mockLocalQuality{quality: quality.ProviderQuality{
+		ProviderID:           "0xProviderId",
+		ConnectSuccess:       2,
+		ConnectFail:          1,
+		TotalConnectTime:     3 * time.Second,
+		Sessions:             2,
+		TotalSessionDuration: 10 * time.Minute,
+		ThroughputSamples:    2,
+		TotalThroughput:      8_000_000,
+		DisconnectReasons:    map[string]int{"consumer": 3},
+	}}
+
+	req, err := http.NewRequest(http.MethodGet, "/irrelevant", nil)
+	assert.Nil(t, err)
+
+	resp := httptest.NewRecorder()
+	handlerFunc := NewProposalsEndpoint(&mockProposalRepository{}, &mockQualityProvider{}, localQuality, &mockPreferences{}).Quality
+	handlerFunc(resp, req, httprouter.Params{{Key: "provider_id", Value: "0xProviderId"}})
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "0xProviderId", localQuality.recordedProviderID)
+	assert.JSONEq(t, fmt.Sprintf(`{
+		"provider_id": "0xProviderId",
+		"score": %v,
+		"connect_count": {"success": 2, "fail": 1, "timeout": 0},
+		"avg_connect_time_ms": 1500,
+		"sessions": 2,
+		"avg_session_seconds": 300,
+		"avg_throughput": 4000000,
+		"disconnect_reasons": {"consumer": 3}
+	}`, localQuality.quality.Score()), resp.Body.String())
+}
+
+type mockLocalQuality struct {
+	scores             map[string]float64
+	quality            quality.ProviderQuality
+	recordedProviderID string
+}
+
+func (m *mockLocalQuality) ProviderQuality(providerID string) (quality.ProviderQuality, error) {
+	m.recordedProviderID = providerID
+	return m.quality, nil
+}
+
+func (m *mockLocalQuality) Score(providerID string) float64 {
+	if score, ok := m.scores[providerID]; ok {
+		return score
+	}
+	return 0.5
+}
+
+type mockPreferences struct {
+	prefs            preference.ProviderPreferences
+	recordedConsumer identity.Identity
+}
+
+func (m *mockPreferences) Get(consumerID identity.Identity) (preference.ProviderPreferences, error) {
+	m.recordedConsumer = consumerID
+	return m.prefs, nil
+}
+
 type mockQualityProvider struct{}
 
 func (m *mockQualityProvider) ProposalsMetrics() []quality.ConnectMetric {
@@ -379,6 +511,16 @@ func (m *mockProposalRepository) Proposals(filter *proposal.Filter) ([]market.Se
 	return m.proposals, nil
 }
 
+type mockStaleProposalRepository struct {
+	mockProposalRepository
+	stale bool
+}
+
+func (m *mockStaleProposalRepository) ProposalsWithStatus(filter *proposal.Filter) ([]market.ServiceProposal, bool, error) {
+	proposals, err := m.Proposals(filter)
+	return proposals, m.stale, err
+}
+
 func setPricingBounds(v url.Values) {
 	v.Add("upper_time_price_bound", fmt.Sprintf("%v", upperTimePriceBound))
 	v.Add("lower_time_price_bound", fmt.Sprintf("%v", lowerTimePriceBound))
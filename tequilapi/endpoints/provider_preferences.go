@@ -0,0 +1,202 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/mysteriumnetwork/node/core/preference"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+	"github.com/mysteriumnetwork/node/tequilapi/utils"
+)
+
+type providerPreferences interface {
+	Get(consumerID identity.Identity) (preference.ProviderPreferences, error)
+	AddFavourite(consumerID identity.Identity, providerID string) error
+	RemoveFavourite(consumerID identity.Identity, providerID string) error
+	Block(consumerID identity.Identity, providerID string) error
+	Unblock(consumerID identity.Identity, providerID string) error
+}
+
+type providerPreferencesEndpoint struct {
+	preferences providerPreferences
+}
+
+// NewProviderPreferencesEndpoint creates and returns provider preferences endpoint.
+func NewProviderPreferencesEndpoint(preferences providerPreferences) *providerPreferencesEndpoint {
+	return &providerPreferencesEndpoint{
+		preferences: preferences,
+	}
+}
+
+// swagger:operation GET /identities/{id}/providers Identity getProviderPreferences
+// ---
+// summary: Returns favourite and blocked providers
+// description: Returns providers marked as favourite or blocked by the given consumer identity
+// parameters:
+// - name: id
+//   in: path
+//   description: Consumer identity
+//   type: string
+//   required: true
+// responses:
+//   200:
+//     description: Provider preferences
+//     schema:
+//       "$ref": "#/definitions/ProviderPreferencesDTO"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+func (ppe *providerPreferencesEndpoint) Get(resp http.ResponseWriter, _ *http.Request, params httprouter.Params) {
+	prefs, err := ppe.preferences.Get(identity.FromAddress(params.ByName("id")))
+	if err != nil {
+		utils.SendError(resp, err, http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteAsJSON(contract.ProviderPreferencesDTO{
+		Favourites: prefs.Favourites,
+		Blocked:    prefs.Blocked,
+	}, resp)
+}
+
+// swagger:operation PUT /identities/{id}/providers/favourites/{provider_id} Identity addFavouriteProvider
+// ---
+// summary: Marks provider as favourite
+// description: Adds provider to the favourites of the given consumer identity, unblocking it if needed
+// parameters:
+// - name: id
+//   in: path
+//   description: Consumer identity
+//   type: string
+//   required: true
+// - name: provider_id
+//   in: path
+//   description: Provider identity
+//   type: string
+//   required: true
+// responses:
+//   202:
+//     description: Provider added to favourites
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+func (ppe *providerPreferencesEndpoint) AddFavourite(resp http.ResponseWriter, _ *http.Request, params httprouter.Params) {
+	ppe.change(resp, params, ppe.preferences.AddFavourite)
+}
+
+// swagger:operation DELETE /identities/{id}/providers/favourites/{provider_id} Identity removeFavouriteProvider
+// ---
+// summary: Removes provider from favourites
+// description: Removes provider from the favourites of the given consumer identity
+// parameters:
+// - name: id
+//   in: path
+//   description: Consumer identity
+//   type: string
+//   required: true
+// - name: provider_id
+//   in: path
+//   description: Provider identity
+//   type: string
+//   required: true
+// responses:
+//   202:
+//     description: Provider removed from favourites
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+func (ppe *providerPreferencesEndpoint) RemoveFavourite(resp http.ResponseWriter, _ *http.Request, params httprouter.Params) {
+	ppe.change(resp, params, ppe.preferences.RemoveFavourite)
+}
+
+// swagger:operation PUT /identities/{id}/providers/blocked/{provider_id} Identity blockProvider
+// ---
+// summary: Blocks provider
+// description: Adds provider to the blocklist of the given consumer identity, removing it from favourites if needed
+// parameters:
+// - name: id
+//   in: path
+//   description: Consumer identity
+//   type: string
+//   required: true
+// - name: provider_id
+//   in: path
+//   description: Provider identity
+//   type: string
+//   required: true
+// responses:
+//   202:
+//     description: Provider blocked
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+func (ppe *providerPreferencesEndpoint) Block(resp http.ResponseWriter, _ *http.Request, params httprouter.Params) {
+	ppe.change(resp, params, ppe.preferences.Block)
+}
+
+// swagger:operation DELETE /identities/{id}/providers/blocked/{provider_id} Identity unblockProvider
+// ---
+// summary: Unblocks provider
+// description: Removes provider from the blocklist of the given consumer identity
+// parameters:
+// - name: id
+//   in: path
+//   description: Consumer identity
+//   type: string
+//   required: true
+// - name: provider_id
+//   in: path
+//   description: Provider identity
+//   type: string
+//   required: true
+// responses:
+//   202:
+//     description: Provider unblocked
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+func (ppe *providerPreferencesEndpoint) Unblock(resp http.ResponseWriter, _ *http.Request, params httprouter.Params) {
+	ppe.change(resp, params, ppe.preferences.Unblock)
+}
+
+func (ppe *providerPreferencesEndpoint) change(resp http.ResponseWriter, params httprouter.Params, change func(identity.Identity, string) error) {
+	err := change(identity.FromAddress(params.ByName("id")), params.ByName("provider_id"))
+	if err != nil {
+		utils.SendError(resp, err, http.StatusInternalServerError)
+		return
+	}
+	resp.WriteHeader(http.StatusAccepted)
+}
+
+// AddRoutesForProviderPreferences attaches provider preferences endpoints to router.
+func AddRoutesForProviderPreferences(router *httprouter.Router, preferences providerPreferences) {
+	ppe := NewProviderPreferencesEndpoint(preferences)
+	router.GET("/identities/:id/providers", ppe.Get)
+	router.PUT("/identities/:id/providers/favourites/:provider_id", ppe.AddFavourite)
+	router.DELETE("/identities/:id/providers/favourites/:provider_id", ppe.RemoveFavourite)
+	router.PUT("/identities/:id/providers/blocked/:provider_id", ppe.Block)
+	router.DELETE("/identities/:id/providers/blocked/:provider_id", ppe.Unblock)
+}
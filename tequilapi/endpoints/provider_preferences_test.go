@@ -0,0 +1,65 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/mysteriumnetwork/node/core/preference"
+	"github.com/mysteriumnetwork/node/core/storage/boltdb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProviderPreferencesEndpoint(t *testing.T) {
+	dir, err := ioutil.TempDir("", "providerPreferencesEndpointTest")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	bolt, err := boltdb.NewStorage(dir)
+	assert.NoError(t, err)
+	defer bolt.Close()
+
+	router := httprouter.New()
+	AddRoutesForProviderPreferences(router, preference.NewStorage(bolt))
+
+	for _, r := range []struct {
+		method, path string
+	}{
+		{http.MethodPut, "/identities/0xConsumer/providers/favourites/0xProvider1"},
+		{http.MethodPut, "/identities/0xConsumer/providers/favourites/0xProvider2"},
+		{http.MethodDelete, "/identities/0xConsumer/providers/favourites/0xProvider2"},
+		{http.MethodPut, "/identities/0xConsumer/providers/blocked/0xProvider3"},
+	} {
+		req := httptest.NewRequest(r.method, r.path, nil)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		assert.Equal(t, http.StatusAccepted, resp.Code, r.path)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/identities/0xConsumer/providers", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{"favourites": ["0xProvider1"], "blocked": ["0xProvider3"]}`, resp.Body.String())
+}
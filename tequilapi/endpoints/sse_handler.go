@@ -234,6 +234,10 @@ func mapState(event stateEvent.State) stateRes {
 			Earnings:           identity.Earnings,
 			EarningsTotal:      identity.EarningsTotal,
 		}
+		if identity.SettlementPolicy.Type != "" {
+			policy := contract.NewSettlementPolicyDTO(identity.SettlementPolicy)
+			identitiesRes[idx].SettlementPolicy = &policy
+		}
 	}
 
 	connectionRes := consumerConnectionRes{
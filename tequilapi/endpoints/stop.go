@@ -19,34 +19,77 @@ package endpoints
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/julienschmidt/httprouter"
+	"github.com/mysteriumnetwork/node/tequilapi/utils"
+	"github.com/mysteriumnetwork/node/tequilapi/validation"
 	"github.com/rs/zerolog/log"
 )
 
 // ApplicationStopper stops application and performs required cleanup tasks
 type ApplicationStopper func()
 
+// ProviderDrainer lets running provider sessions finish before the application is stopped
+type ProviderDrainer interface {
+	Drain(timeout time.Duration) error
+}
+
 // AddRouteForStop adds stop route to given router
-func AddRouteForStop(router *httprouter.Router, stop ApplicationStopper) {
-	router.POST("/stop", newStopHandler(stop))
+func AddRouteForStop(router *httprouter.Router, stop ApplicationStopper, drainer ProviderDrainer) {
+	router.POST("/stop", newStopHandler(stop, drainer))
 }
 
 // swagger:operation POST /stop Client applicationStop
 // ---
 // summary: Stops client
-// description: Initiates client termination
+// description: Initiates client termination, provider can first let the running sessions finish
+// parameters:
+//   - in: query
+//     name: drain
+//     description: Time to wait for provider sessions to end before stopping, e.g. "10m". New sessions are rejected meanwhile
+//     type: string
 // responses:
 //   202:
 //     description: Request accepted, stopping
-func newStopHandler(stop ApplicationStopper) httprouter.Handle {
+//   422:
+//     description: Parameters validation error
+//     schema:
+//       "$ref": "#/definitions/ValidationErrorDTO"
+func newStopHandler(stop ApplicationStopper, drainer ProviderDrainer) httprouter.Handle {
 	return func(response http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+		var drainTimeout time.Duration
+		if value := req.URL.Query().Get("drain"); value != "" {
+			timeout, err := time.ParseDuration(value)
+			if err != nil || timeout <= 0 {
+				errorMap := validation.NewErrorMap()
+				errorMap.ForField("drain").AddError("invalid", "Drain must be a positive duration, e.g. 10m")
+				utils.SendValidationErrorMessage(response, errorMap)
+				return
+			}
+			drainTimeout = timeout
+		}
+
 		log.Info().Msg("Application stop requested")
+		stopFn := stop
+		if drainTimeout > 0 && drainer != nil {
+			stopFn = drainBeforeStop(drainer, drainTimeout, stop)
+		}
 
-		go callStopWhenNotified(req.Context().Done(), stop)
+		go callStopWhenNotified(req.Context().Done(), stopFn)
 		response.WriteHeader(http.StatusAccepted)
 	}
 }
+
+func drainBeforeStop(drainer ProviderDrainer, timeout time.Duration, stop ApplicationStopper) ApplicationStopper {
+	return func() {
+		if err := drainer.Drain(timeout); err != nil {
+			log.Error().Err(err).Msg("Provider drain failed")
+		}
+		stop()
+	}
+}
+
 func callStopWhenNotified(notify <-chan struct{}, stopApplication ApplicationStopper) {
 	<-notify
 	stopApplication()
@@ -49,7 +49,7 @@ func TestAddRouteForStop(t *testing.T) {
 		stopped:     make(chan struct{}, 1),
 	}
 	router := httprouter.New()
-	AddRouteForStop(router, stopper.Stop)
+	AddRouteForStop(router, stopper.Stop, nil)
 
 	resp := httptest.NewRecorder()
 
@@ -68,3 +68,80 @@ func TestAddRouteForStop(t *testing.T) {
 		t.Error("Stopper was not executed")
 	}
 }
+
+type fakeDrainer struct {
+	timeout time.Duration
+	calls   int
+}
+
+func (fd *fakeDrainer) Drain(timeout time.Duration) error {
+	fd.timeout = timeout
+	fd.calls++
+	return nil
+}
+
+func TestAddRouteForStop_DrainsBeforeStop(t *testing.T) {
+	stopped := make(chan struct{}, 1)
+	drainer := &fakeDrainer{}
+	router := httprouter.New()
+	AddRouteForStop(router, func() { stopped <- struct{}{} }, drainer)
+
+	resp := httptest.NewRecorder()
+	cancelCtx, finishRequestHandling := context.WithCancel(context.Background())
+	req := httptest.NewRequest("POST", "/stop?drain=10m", strings.NewReader("")).WithContext(cancelCtx)
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusAccepted, resp.Code)
+	finishRequestHandling()
+
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Error("Stopper was not executed")
+	}
+	assert.Equal(t, 10*time.Minute, drainer.timeout)
+}
+
+func TestAddRouteForStop_DrainAppliesOnlyToItsRequest(t *testing.T) {
+	stopped := make(chan struct{}, 1)
+	drainer := &fakeDrainer{}
+	router := httprouter.New()
+	AddRouteForStop(router, func() { stopped <- struct{}{} }, drainer)
+
+	requestStop := func(url string) {
+		resp := httptest.NewRecorder()
+		cancelCtx, finishRequestHandling := context.WithCancel(context.Background())
+		req := httptest.NewRequest("POST", url, strings.NewReader("")).WithContext(cancelCtx)
+		router.ServeHTTP(resp, req)
+		assert.Equal(t, http.StatusAccepted, resp.Code)
+		finishRequestHandling()
+
+		select {
+		case <-stopped:
+		case <-time.After(time.Second):
+			t.Error("Stopper was not executed")
+		}
+	}
+
+	requestStop("/stop?drain=10m")
+	assert.Equal(t, 1, drainer.calls)
+
+	requestStop("/stop")
+	assert.Equal(t, 1, drainer.calls)
+}
+
+func TestAddRouteForStop_InvalidDrain(t *testing.T) {
+	router := httprouter.New()
+	AddRouteForStop(router, func() { t.Error("Stopper should not be executed") }, &fakeDrainer{})
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/stop?drain=soon", strings.NewReader(""))
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+	assert.JSONEq(t, `{
+		"message": "validation_error",
+		"errors": {
+			"drain": [{"code": "invalid", "message": "Drain must be a positive duration, e.g. 10m"}]
+		}
+	}`, resp.Body.String())
+}
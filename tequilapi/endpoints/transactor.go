@@ -27,6 +27,9 @@ import (
 
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/identity/registry"
+	"github.com/mysteriumnetwork/node/session/pingpong"
+	pingpong_event "github.com/mysteriumnetwork/node/session/pingpong/event"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
 	"github.com/mysteriumnetwork/node/tequilapi/utils"
 )
 
@@ -41,6 +44,8 @@ type Transactor interface {
 // promiseSettler settles the given promises
 type promiseSettler interface {
 	ForceSettle(providerID, accountantID identity.Identity) error
+	SettlementPolicy() pingpong_event.SettlementPolicy
+	SetSettlementPolicy(policy pingpong_event.SettlementPolicy) error
 }
 
 type transactorEndpoint struct {
@@ -176,6 +181,64 @@ func (te *transactorEndpoint) settle(request *http.Request, settler func(identit
 	return errors.Wrap(settler(identity.FromAddress(req.ProviderID), identity.FromAddress(req.AccountantID)), "settling failed")
 }
 
+// swagger:operation GET /transactor/settle/policy SettlementPolicy
+// ---
+// summary: Returns the automatic settlement policy
+// description: Returns the policy used to decide when the accountant promises are settled automatically
+// responses:
+//   200:
+//     description: active settlement policy
+//     schema:
+//       "$ref": "#/definitions/SettlementPolicyDTO"
+func (te *transactorEndpoint) SettlementPolicy(resp http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	utils.WriteAsJSON(contract.NewSettlementPolicyDTO(te.promiseSettler.SettlementPolicy()), resp)
+}
+
+// swagger:operation PUT /transactor/settle/policy SetSettlementPolicy
+// ---
+// summary: Changes the automatic settlement policy
+// description: Changes the policy used to decide when the accountant promises are settled automatically
+// parameters:
+// - in: body
+//   name: body
+//   description: settlement policy
+//   schema:
+//     $ref: "#/definitions/SettlementPolicyDTO"
+// responses:
+//   200:
+//     description: settlement policy changed
+//     schema:
+//       "$ref": "#/definitions/SettlementPolicyDTO"
+//   400:
+//     description: Bad request
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+func (te *transactorEndpoint) SetSettlementPolicy(resp http.ResponseWriter, request *http.Request, _ httprouter.Params) {
+	req := contract.SettlementPolicyDTO{}
+
+	err := json.NewDecoder(request.Body).Decode(&req)
+	if err != nil {
+		utils.SendError(resp, errors.Wrap(err, "failed to parse settlement policy"), http.StatusBadRequest)
+		return
+	}
+
+	err = te.promiseSettler.SetSettlementPolicy(req.ToPolicy())
+	if errors.Cause(err) == pingpong.ErrInvalidSettlementPolicy {
+		utils.SendError(resp, err, http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		utils.SendError(resp, err, http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteAsJSON(contract.NewSettlementPolicyDTO(te.promiseSettler.SettlementPolicy()), resp)
+}
+
 // swagger:operation POST /transactor/topup
 // ---
 // summary: tops up myst to the given identity
@@ -270,4 +333,6 @@ func AddRoutesForTransactor(router *httprouter.Router, transactor Transactor, pr
 	router.POST("/transactor/topup", te.TopUp)
 	router.POST("/transactor/settle/sync", te.SettleSync)
 	router.POST("/transactor/settle/async", te.SettleAsync)
+	router.GET("/transactor/settle/policy", te.SettlementPolicy)
+	router.PUT("/transactor/settle/policy", te.SetSettlementPolicy)
 }
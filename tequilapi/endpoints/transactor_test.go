@@ -32,6 +32,8 @@ import (
 
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/identity/registry"
+	"github.com/mysteriumnetwork/node/session/pingpong"
+	pingpong_event "github.com/mysteriumnetwork/node/session/pingpong/event"
 )
 
 var identityRegData = `{
@@ -264,10 +266,70 @@ func (fs *fakeSigner) Sign(message []byte) (identity.Signature, error) {
 	return identity.SignatureBytes(b), nil
 }
 
+func Test_SettlementPolicy(t *testing.T) {
+	router := httprouter.New()
+	settler := &mockSettler{
+		policy: pingpong_event.SettlementPolicy{Type: pingpong_event.SettlementPolicyThreshold, Threshold: 0.1},
+	}
+	AddRoutesForTransactor(router, nil, settler)
+
+	req, err := http.NewRequest(http.MethodGet, "/transactor/settle/policy", nil)
+	assert.Nil(t, err)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{"type": "threshold", "threshold": 0.1}`, resp.Body.String())
+
+	req, err = http.NewRequest(http.MethodPut, "/transactor/settle/policy", bytes.NewBufferString(`{"type": "schedule", "time": "03:00", "max_fee_ratio": 0.05}`))
+	assert.Nil(t, err)
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{"type": "schedule", "time": "03:00", "max_fee_ratio": 0.05}`, resp.Body.String())
+	assert.Equal(t, pingpong_event.SettlementPolicy{Type: pingpong_event.SettlementPolicySchedule, Time: "03:00", MaxFeeRatio: 0.05}, settler.policy)
+}
+
+func Test_SetSettlementPolicy_InvalidPolicy(t *testing.T) {
+	router := httprouter.New()
+	settler := &mockSettler{errToReturn: pingpong.ErrInvalidSettlementPolicy}
+	AddRoutesForTransactor(router, nil, settler)
+
+	req, err := http.NewRequest(http.MethodPut, "/transactor/settle/policy", bytes.NewBufferString(`{"type": "sometimes"}`))
+	assert.Nil(t, err)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+}
+
+func Test_SetSettlementPolicy_PersistFails(t *testing.T) {
+	router := httprouter.New()
+	settler := &mockSettler{errToReturn: errors.New("could not save settlement policy")}
+	AddRoutesForTransactor(router, nil, settler)
+
+	req, err := http.NewRequest(http.MethodPut, "/transactor/settle/policy", bytes.NewBufferString(`{"type": "amount", "amount": 100}`))
+	assert.Nil(t, err)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+}
+
 type mockSettler struct {
 	errToReturn error
+	policy      pingpong_event.SettlementPolicy
 }
 
 func (ms *mockSettler) ForceSettle(providerID, accountantID identity.Identity) error {
 	return ms.errToReturn
 }
+
+func (ms *mockSettler) SettlementPolicy() pingpong_event.SettlementPolicy {
+	return ms.policy
+}
+
+func (ms *mockSettler) SetSettlementPolicy(policy pingpong_event.SettlementPolicy) error {
+	if ms.errToReturn != nil {
+		return ms.errToReturn
+	}
+	ms.policy = policy
+	return nil
+}
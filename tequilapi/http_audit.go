@@ -0,0 +1,217 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package tequilapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/mysteriumnetwork/node/core/audit"
+	"github.com/mysteriumnetwork/node/core/auth"
+	"github.com/rs/zerolog/log"
+)
+
+// maxAuditedBodySize limits how much of request and error response bodies is kept for the audit log.
+const maxAuditedBodySize = 64 * 1024
+
+// Auditor records audit log entries.
+type Auditor interface {
+	Record(entry audit.Entry) error
+}
+
+// TokenUserResolver resolves the user a JWT token was issued to.
+type TokenUserResolver interface {
+	Username(token string) (string, error)
+}
+
+type auditedRoute struct {
+	method string
+	path   string
+	action string
+}
+
+// auditedRoutes lists security relevant API calls recorded in the audit log.
+var auditedRoutes = []auditedRoute{
+	{http.MethodPost, "/auth/login", "auth.login"},
+	{http.MethodPut, "/auth/password", "auth.change_password"},
+	{http.MethodPost, "/identities", "identity.create"},
+	{http.MethodPut, "/identities/current", "identity.set_current"},
+	{http.MethodPut, "/identities/:id/unlock", "identity.unlock"},
+	{http.MethodPost, "/identities/:id/register", "identity.register"},
+	{http.MethodPut, "/identities/:id/payout", "payout.update"},
+	{http.MethodPost, "/services", "service.start"},
+	{http.MethodDelete, "/services/:id", "service.stop"},
+	{http.MethodPut, "/connection", "connection.create"},
+	{http.MethodDelete, "/connection", "connection.kill"},
+	{http.MethodPost, "/transactor/topup", "transactor.topup"},
+	{http.MethodPost, "/transactor/settle/sync", "settlement.settle"},
+	{http.MethodPost, "/transactor/settle/async", "settlement.settle_async"},
+	{http.MethodPut, "/transactor/settle/policy", "settlement.update_policy"},
+	{http.MethodPost, "/config/user", "config.update"},
+	{http.MethodPut, "/logs/level", "logs.set_level"},
+	{http.MethodPost, "/stop", "node.stop"},
+}
+
+// matchAuditedRoute finds the audit action of the request and extracts its path parameters.
+func matchAuditedRoute(method, path string) (string, map[string]interface{}, bool) {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for _, route := range auditedRoutes {
+		if route.method != method {
+			continue
+		}
+		if params, ok := matchRoutePath(strings.Split(strings.Trim(route.path, "/"), "/"), segments); ok {
+			return route.action, params, true
+		}
+	}
+	return "", nil, false
+}
+
+func matchRoutePath(pattern, segments []string) (map[string]interface{}, bool) {
+	if len(pattern) != len(segments) {
+		return nil, false
+	}
+	params := make(map[string]interface{})
+	for i := range pattern {
+		if strings.HasPrefix(pattern[i], ":") {
+			params[pattern[i][1:]] = segments[i]
+			continue
+		}
+		if pattern[i] != segments[i] {
+			return nil, false
+		}
+	}
+	return params, true
+}
+
+type auditHandler struct {
+	originalHandler http.Handler
+	auditor         Auditor
+	users           TokenUserResolver
+}
+
+// ApplyAudit wraps original handler by recording security relevant requests to the audit log AFTER they are served
+func ApplyAudit(original http.Handler, auditor Auditor, users TokenUserResolver) http.Handler {
+	return &auditHandler{originalHandler: original, auditor: auditor, users: users}
+}
+
+func (ah *auditHandler) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
+	action, params, ok := matchAuditedRoute(req.Method, req.URL.Path)
+	if !ok {
+		ah.originalHandler.ServeHTTP(resp, req)
+		return
+	}
+
+	for key, value := range readAuditedBody(req) {
+		if _, exists := params[key]; !exists {
+			params[key] = value
+		}
+	}
+
+	recorder := &auditResponseRecorder{ResponseWriter: resp, status: http.StatusOK}
+	ah.originalHandler.ServeHTTP(recorder, req)
+
+	entry := audit.Entry{
+		Actor:      ah.actor(req, params),
+		Source:     remoteHost(req.RemoteAddr),
+		Action:     action,
+		Parameters: params,
+		Outcome:    audit.OutcomeSuccess,
+	}
+	if recorder.status >= http.StatusBadRequest {
+		entry.Outcome = audit.OutcomeFailure
+		entry.Error = recorder.errorMessage()
+	}
+	if err := ah.auditor.Record(entry); err != nil {
+		log.Error().Err(err).Msgf("Failed to record audit entry for %s", action)
+	}
+}
+
+// actor identifies the user by the JWT cookie, falling back to the username given when logging in.
+func (ah *auditHandler) actor(req *http.Request, params map[string]interface{}) string {
+	if cookie, err := req.Cookie(auth.JWTCookieName); err == nil {
+		if username, err := ah.users.Username(cookie.Value); err == nil {
+			return username
+		}
+	}
+	if username, ok := params["username"].(string); ok && username != "" {
+		return username
+	}
+	return audit.ActorAnonymous
+}
+
+// readAuditedBody reads JSON object fields of the request body leaving the body intact for the handler.
+func readAuditedBody(req *http.Request) map[string]interface{} {
+	if req.Body == nil {
+		return nil
+	}
+	body, err := ioutil.ReadAll(io.LimitReader(req.Body, maxAuditedBodySize))
+	req.Body = ioutil.NopCloser(io.MultiReader(bytes.NewReader(body), req.Body))
+	if err != nil || len(body) == 0 {
+		return nil
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return nil
+	}
+	return fields
+}
+
+func remoteHost(remoteAddr string) string {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		return remoteAddr
+	}
+	return host
+}
+
+type auditResponseRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (r *auditResponseRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *auditResponseRecorder) Write(data []byte) (int, error) {
+	if r.status >= http.StatusBadRequest && r.body.Len() < maxAuditedBodySize {
+		r.body.Write(data)
+	}
+	return r.ResponseWriter.Write(data)
+}
+
+func (r *auditResponseRecorder) errorMessage() string {
+	var message struct {
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(r.body.Bytes(), &message); err == nil && message.Message != "" {
+		return message.Message
+	}
+	if text := strings.TrimSpace(r.body.String()); text != "" {
+		return text
+	}
+	return http.StatusText(r.status)
+}
@@ -0,0 +1,130 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package tequilapi
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/mysteriumnetwork/node/core/audit"
+	"github.com/mysteriumnetwork/node/core/auth"
+	"github.com/mysteriumnetwork/node/tequilapi/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAudit_RecordsAuditedRequest(t *testing.T) {
+	auditor := &mockAuditor{}
+	router := httprouter.New()
+	var handlerBody string
+	router.PUT("/identities/:id/unlock", func(resp http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+		body, _ := ioutil.ReadAll(req.Body)
+		handlerBody = string(body)
+	})
+
+	req := httptest.NewRequest(http.MethodPut, "/identities/0x1/unlock", strings.NewReader(`{"passphrase": "secret"}`))
+	req.RemoteAddr = "127.0.0.1:54321"
+	req.AddCookie(&http.Cookie{Name: auth.JWTCookieName, Value: "valid"})
+
+	ApplyAudit(router, auditor, &mockTokenUserResolver{username: "myst"}).ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Equal(t, `{"passphrase": "secret"}`, handlerBody, "handler should receive the original body")
+	require.Len(t, auditor.entries, 1)
+	assert.Equal(t, audit.Entry{
+		Actor:      "myst",
+		Source:     "127.0.0.1",
+		Action:     "identity.unlock",
+		Parameters: map[string]interface{}{"id": "0x1", "passphrase": "secret"},
+		Outcome:    audit.OutcomeSuccess,
+	}, auditor.entries[0])
+}
+
+func TestAudit_RecordsFailure(t *testing.T) {
+	auditor := &mockAuditor{}
+	router := httprouter.New()
+	router.POST("/auth/login", func(resp http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+		utils.SendError(resp, errors.New("bad credentials"), http.StatusUnauthorized)
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/auth/login", strings.NewReader(`{"username": "myst", "password": "wrong"}`))
+	resp := httptest.NewRecorder()
+
+	ApplyAudit(router, auditor, &mockTokenUserResolver{err: errors.New("no token")}).ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+	assert.JSONEq(t, `{"message": "bad credentials"}`, resp.Body.String())
+	require.Len(t, auditor.entries, 1)
+	entry := auditor.entries[0]
+	assert.Equal(t, "myst", entry.Actor)
+	assert.Equal(t, "auth.login", entry.Action)
+	assert.Equal(t, audit.OutcomeFailure, entry.Outcome)
+	assert.Equal(t, "bad credentials", entry.Error)
+}
+
+func TestAudit_SkipsNotAuditedRequests(t *testing.T) {
+	auditor := &mockAuditor{}
+	mock := &mockedHTTPHandler{}
+
+	for _, req := range []*http.Request{
+		httptest.NewRequest(http.MethodGet, "/identities", nil),
+		httptest.NewRequest(http.MethodGet, "/connection", nil),
+		httptest.NewRequest(http.MethodPut, "/identities/0x1/unlock/extra", nil),
+	} {
+		ApplyAudit(mock, auditor, &mockTokenUserResolver{}).ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	assert.True(t, mock.wasCalled)
+	assert.Empty(t, auditor.entries)
+}
+
+func TestMatchAuditedRoute(t *testing.T) {
+	action, params, ok := matchAuditedRoute(http.MethodDelete, "/services/6ba7b810")
+	assert.True(t, ok)
+	assert.Equal(t, "service.stop", action)
+	assert.Equal(t, map[string]interface{}{"id": "6ba7b810"}, params)
+
+	action, _, ok = matchAuditedRoute(http.MethodPut, "/identities/current")
+	assert.True(t, ok)
+	assert.Equal(t, "identity.set_current", action)
+
+	_, _, ok = matchAuditedRoute(http.MethodGet, "/services/6ba7b810")
+	assert.False(t, ok)
+}
+
+type mockAuditor struct {
+	entries []audit.Entry
+}
+
+func (m *mockAuditor) Record(entry audit.Entry) error {
+	m.entries = append(m.entries, entry)
+	return nil
+}
+
+type mockTokenUserResolver struct {
+	username string
+	err      error
+}
+
+func (m *mockTokenUserResolver) Username(_ string) (string, error) {
+	return m.username, m.err
+}
@@ -0,0 +1,256 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package testkit
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	ethCrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/julienschmidt/httprouter"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/session/pingpong"
+	"github.com/mysteriumnetwork/payments/crypto"
+	"github.com/pkg/errors"
+)
+
+// Agreement describes payments of a single provider agreement as seen by the accountant.
+type Agreement struct {
+	ID       uint64
+	Total    uint64
+	Revealed bool
+}
+
+type agreementKey struct {
+	provider string
+	id       uint64
+}
+
+type agreement struct {
+	Agreement
+	hashlock []byte
+}
+
+// Accountant is a fake accountant which exchanges consumer promises to
+// accountant promises for providers, the same way the real accountant API does.
+type Accountant struct {
+	ID identity.Identity
+
+	server   *httptest.Server
+	keystore *identity.Keystore
+	channels *pingpong.ChannelAddressCalculator
+
+	mu             sync.Mutex
+	agreements     map[agreementKey]*agreement
+	providerTotals map[string]uint64
+	consumers      map[string]crypto.Promise
+}
+
+// NewAccountant creates accountant identity in the given directory and starts a fake accountant HTTP server.
+func NewAccountant(dir, channelImplementation, registryAddress string) (*Accountant, error) {
+	ks := identity.NewKeystoreFilesystem(dir, keystore.NewKeyStore(dir, keystore.LightScryptN, keystore.LightScryptP), keystore.DecryptKey)
+	account, err := ks.NewAccount("")
+	if err != nil {
+		return nil, errors.Wrap(err, "could not create accountant identity")
+	}
+	if err := ks.Unlock(account, ""); err != nil {
+		return nil, errors.Wrap(err, "could not unlock accountant identity")
+	}
+
+	id := identity.FromAddress(account.Address.Hex())
+	a := &Accountant{
+		ID:             id,
+		keystore:       ks,
+		channels:       pingpong.NewChannelAddressCalculator(id.Address, channelImplementation, registryAddress),
+		agreements:     make(map[agreementKey]*agreement),
+		providerTotals: make(map[string]uint64),
+		consumers:      make(map[string]crypto.Promise),
+	}
+
+	router := httprouter.New()
+	router.POST("/request_promise", a.requestPromise)
+	router.POST("/reveal_r", a.revealR)
+	router.GET("/data/consumer/:id", a.consumerData)
+	a.server = httptest.NewServer(router)
+
+	return a, nil
+}
+
+// Address returns the base URL of the accountant API.
+func (a *Accountant) Address() string {
+	return a.server.URL
+}
+
+// Agreements returns agreements of the provider ordered by ID.
+func (a *Accountant) Agreements(providerID identity.Identity) []Agreement {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	var result []Agreement
+	for key, agr := range a.agreements {
+		if key.provider == addressKey(providerID.Address) {
+			result = append(result, agr.Agreement)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ID < result[j].ID
+	})
+	return result
+}
+
+// Promised returns the total amount promised to the provider.
+func (a *Accountant) Promised(providerID identity.Identity) uint64 {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return a.providerTotals[addressKey(providerID.Address)]
+}
+
+// Stop shuts the accountant down.
+func (a *Accountant) Stop() {
+	a.server.Close()
+}
+
+func (a *Accountant) requestPromise(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var rp pingpong.RequestPromise
+	if err := json.NewDecoder(req.Body).Decode(&rp); err != nil {
+		writeAccountantError(w, http.StatusBadRequest, pingpong.ErrAccountantMalformedJSON)
+		return
+	}
+
+	em := rp.ExchangeMessage
+	consumer, err := em.Promise.RecoverSigner()
+	if err != nil || !em.IsMessageValid(consumer) {
+		writeAccountantError(w, http.StatusBadRequest, pingpong.ErrAccountantInvalidSignature)
+		return
+	}
+
+	providerChannel, err := a.channels.GetChannelAddress(identity.FromAddress(em.Provider))
+	if err != nil {
+		writeAccountantError(w, http.StatusInternalServerError, pingpong.ErrAccountantInternal)
+		return
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	provider := addressKey(em.Provider)
+	key := agreementKey{provider: provider, id: em.AgreementID}
+	agr, ok := a.agreements[key]
+	if !ok {
+		agr = &agreement{Agreement: Agreement{ID: em.AgreementID}}
+		a.agreements[key] = agr
+	}
+	if em.AgreementTotal < agr.Total {
+		writeAccountantError(w, http.StatusBadRequest, pingpong.ErrAccountantPaymentValueTooLow)
+		return
+	}
+
+	total := a.providerTotals[provider] + em.AgreementTotal - agr.Total
+	promise, err := crypto.CreatePromise(
+		providerChannel.Hex(),
+		total,
+		rp.TransactorFee,
+		hex.EncodeToString(em.Promise.Hashlock),
+		a.keystore,
+		a.ID.ToCommonAddress(),
+	)
+	if err != nil {
+		writeAccountantError(w, http.StatusInternalServerError, pingpong.ErrAccountantInternal)
+		return
+	}
+
+	agr.Total = em.AgreementTotal
+	agr.Revealed = false
+	agr.hashlock = em.Promise.Hashlock
+	a.providerTotals[provider] = total
+	a.consumers[addressKey(consumer.Hex())] = em.Promise
+
+	writeJSON(w, http.StatusOK, promise)
+}
+
+func (a *Accountant) revealR(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var reveal pingpong.RevealObject
+	if err := json.NewDecoder(req.Body).Decode(&reveal); err != nil {
+		writeAccountantError(w, http.StatusBadRequest, pingpong.ErrAccountantMalformedJSON)
+		return
+	}
+
+	r, err := hex.DecodeString(strings.TrimPrefix(reveal.R, "0x"))
+	if err != nil {
+		writeAccountantError(w, http.StatusBadRequest, pingpong.ErrAccountantMalformedJSON)
+		return
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	agr, ok := a.agreements[agreementKey{provider: addressKey(reveal.Provider), id: reveal.AgreementID}]
+	if !ok {
+		writeAccountantError(w, http.StatusNotFound, pingpong.ErrAccountantNotFound)
+		return
+	}
+	if !bytes.Equal(ethCrypto.Keccak256(r), agr.hashlock) {
+		writeAccountantError(w, http.StatusBadRequest, pingpong.ErrAccountantHashlockMissmatch)
+		return
+	}
+
+	agr.Revealed = true
+	writeJSON(w, http.StatusOK, pingpong.RevealSuccess{Message: "R revealed"})
+}
+
+func (a *Accountant) consumerData(w http.ResponseWriter, _ *http.Request, params httprouter.Params) {
+	id := params.ByName("id")
+
+	a.mu.Lock()
+	promise, ok := a.consumers[addressKey(id)]
+	a.mu.Unlock()
+	if !ok {
+		writeAccountantError(w, http.StatusNotFound, pingpong.ErrAccountantNotFound)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, pingpong.ConsumerData{
+		Identity: id,
+		LatestPromise: pingpong.LatestPromise{
+			ChannelID: "0x" + hex.EncodeToString(promise.ChannelID),
+			Amount:    promise.Amount,
+			Fee:       promise.Fee,
+			Hashlock:  "0x" + hex.EncodeToString(promise.Hashlock),
+			Signature: promise.GetSignatureHexString(),
+		},
+	})
+}
+
+func writeAccountantError(w http.ResponseWriter, status int, cause error) {
+	writeJSON(w, status, map[string]string{
+		"cause":   cause.Error(),
+		"message": cause.Error(),
+	})
+}
+
+func addressKey(address string) string {
+	return strings.ToLower(address)
+}
@@ -0,0 +1,144 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package testkit
+
+import (
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/mysteriumnetwork/payments/bindings"
+	"github.com/mysteriumnetwork/payments/client"
+)
+
+// Blockchain is an in-memory replacement of the payments smart contracts.
+// It keeps identity registrations and consumer channel balances.
+type Blockchain struct {
+	mu            sync.Mutex
+	registered    map[common.Address]bool
+	balances      map[common.Address]uint64
+	subscriptions map[common.Address][]*balanceSubscription
+	accountantFee uint16
+}
+
+type balanceSubscription struct {
+	events chan *bindings.MystTokenTransfer
+	once   sync.Once
+}
+
+func (s *balanceSubscription) close() {
+	s.once.Do(func() {
+		close(s.events)
+	})
+}
+
+// NewBlockchain creates an empty blockchain.
+func NewBlockchain() *Blockchain {
+	return &Blockchain{
+		registered:    make(map[common.Address]bool),
+		balances:      make(map[common.Address]uint64),
+		subscriptions: make(map[common.Address][]*balanceSubscription),
+	}
+}
+
+// Register marks identity as registered in the registry.
+func (bc *Blockchain) Register(id common.Address) {
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+
+	bc.registered[id] = true
+}
+
+// TopUp adds given amount of tokens to the channel and notifies balance subscribers.
+func (bc *Blockchain) TopUp(channel common.Address, amount uint64) {
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+
+	bc.balances[channel] += amount
+	for _, sub := range bc.subscriptions[channel] {
+		sub.events <- &bindings.MystTokenTransfer{
+			To:    channel,
+			Value: new(big.Int).SetUint64(amount),
+		}
+		sub.close()
+	}
+	delete(bc.subscriptions, channel)
+}
+
+// Balance returns current balance of the channel.
+func (bc *Blockchain) Balance(channel common.Address) uint64 {
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+
+	return bc.balances[channel]
+}
+
+// IsRegistered checks if identity is registered in the registry.
+func (bc *Blockchain) IsRegistered(_, addressToCheck common.Address) (bool, error) {
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+
+	return bc.registered[addressToCheck], nil
+}
+
+// GetAccountantFee returns the fee accountant charges for promises.
+func (bc *Blockchain) GetAccountantFee(_ common.Address) (uint16, error) {
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+
+	return bc.accountantFee, nil
+}
+
+// GetConsumerChannel returns consumer channel state.
+func (bc *Blockchain) GetConsumerChannel(addr common.Address, _ common.Address) (client.ConsumerChannel, error) {
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+
+	return client.ConsumerChannel{
+		Balance: new(big.Int).SetUint64(bc.balances[addr]),
+		Settled: new(big.Int),
+	}, nil
+}
+
+// SubscribeToConsumerBalanceEvent subscribes to the next top-up of the channel.
+// The returned channel is closed after the first event or on timeout.
+func (bc *Blockchain) SubscribeToConsumerBalanceEvent(channel, _ common.Address, timeout time.Duration) (chan *bindings.MystTokenTransfer, func(), error) {
+	sub := &balanceSubscription{events: make(chan *bindings.MystTokenTransfer, 1)}
+
+	bc.mu.Lock()
+	bc.subscriptions[channel] = append(bc.subscriptions[channel], sub)
+	bc.mu.Unlock()
+
+	cancel := func() {
+		bc.mu.Lock()
+		defer bc.mu.Unlock()
+
+		subs := bc.subscriptions[channel]
+		for i := range subs {
+			if subs[i] == sub {
+				bc.subscriptions[channel] = append(subs[:i], subs[i+1:]...)
+				break
+			}
+		}
+		sub.close()
+	}
+	time.AfterFunc(timeout, cancel)
+
+	return sub.events, cancel, nil
+}
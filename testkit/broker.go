@@ -0,0 +1,58 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package testkit
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/nats-io/nats-server/server"
+	"github.com/pkg/errors"
+)
+
+// Broker is an in-process NATS broker used for dialogs between nodes.
+type Broker struct {
+	server *server.Server
+}
+
+// NewBroker starts a NATS broker listening on a random local port.
+func NewBroker() (*Broker, error) {
+	srv := server.New(&server.Options{
+		Host:   "127.0.0.1",
+		Port:   server.RANDOM_PORT,
+		NoLog:  true,
+		NoSigs: true,
+	})
+	go srv.Start()
+
+	if !srv.ReadyForConnections(5 * time.Second) {
+		srv.Shutdown()
+		return nil, errors.New("broker is not ready for connections")
+	}
+	return &Broker{server: srv}, nil
+}
+
+// Address returns the URL which nodes use to connect to the broker.
+func (b *Broker) Address() string {
+	return fmt.Sprintf("nats://%s", b.server.Addr())
+}
+
+// Stop shuts the broker down.
+func (b *Broker) Stop() {
+	b.server.Shutdown()
+}
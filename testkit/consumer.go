@@ -0,0 +1,149 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package testkit
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/mysteriumnetwork/node/communication"
+	"github.com/mysteriumnetwork/node/communication/nats"
+	nats_dialog "github.com/mysteriumnetwork/node/communication/nats/dialog"
+	"github.com/mysteriumnetwork/node/core/connection"
+	"github.com/mysteriumnetwork/node/core/ip"
+	"github.com/mysteriumnetwork/node/core/preference"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
+	service_noop "github.com/mysteriumnetwork/node/services/noop"
+	"github.com/mysteriumnetwork/node/session/connectivity"
+	"github.com/mysteriumnetwork/node/session/pingpong"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+// Consumer is an in-process consumer node which connects to noop services.
+type Consumer struct {
+	*node
+
+	// Connections manages the consumer connection.
+	Connections connection.Manager
+	// Balances tracks the consumer balance.
+	Balances *pingpong.ConsumerBalanceTracker
+}
+
+// NewConsumer starts a consumer node on the network. Its identity is registered and its channel topped up.
+func (n *Network) NewConsumer() (*Consumer, error) {
+	dir, err := n.nodeDir("consumer")
+	if err != nil {
+		return nil, err
+	}
+	base, err := newNode(n, dir)
+	if err != nil {
+		return nil, err
+	}
+	c := &Consumer{node: base}
+	n.addStop(c.stop)
+
+	if err := c.register(); err != nil {
+		return nil, err
+	}
+	if err := c.transactor.TopUp(c.ID.Address); err != nil {
+		return nil, errors.Wrap(err, "could not top up consumer channel")
+	}
+
+	channels := pingpong.NewChannelAddressCalculator(n.Accountant.ID.Address, n.ChannelImplementation, n.RegistryAddress)
+	totalsStorage := pingpong.NewConsumerTotalsStorage(c.storage, c.EventBus)
+	c.Balances = pingpong.NewConsumerBalanceTracker(
+		c.EventBus,
+		common.Address{},
+		n.Accountant.ID,
+		n.Blockchain,
+		channels,
+		totalsStorage,
+		pingpong.NewAccountantCaller(c.httpClient, n.Accountant.Address()),
+	)
+	if err := c.Balances.Subscribe(c.EventBus); err != nil {
+		return nil, errors.Wrap(err, "could not subscribe consumer balance tracker")
+	}
+
+	if err := c.unlock(); err != nil {
+		return nil, err
+	}
+	c.Balances.ForceBalanceUpdate(c.ID)
+
+	connections := connection.NewRegistry()
+	connections.Register(service_noop.ServiceType, service_noop.NewConnection)
+
+	c.Connections = connection.NewManager(
+		func(consumerID, providerID identity.Identity, contact market.Contact) (communication.Dialog, error) {
+			establisher := nats_dialog.NewDialogEstablisher(consumerID, c.signers(consumerID), nats.NewBrokerConnector())
+			return establisher.EstablishDialog(providerID, contact)
+		},
+		pingpong.ExchangeFactoryFunc(
+			c.keystore,
+			c.signers,
+			totalsStorage,
+			n.ChannelImplementation,
+			n.RegistryAddress,
+			c.EventBus,
+			20,
+		),
+		connections.CreateConnection,
+		c.EventBus,
+		connectivity.NewStatusSender(),
+		// Public IP changes once the connection is established.
+		ip.NewResolverMockMultiple("127.0.0.1", "10.0.0.1", "10.0.0.2"),
+		connection.DefaultConfig(),
+		connection.DefaultStatsReportInterval,
+		connection.NewValidator(c.Balances, c.identities, preference.NewStorage(c.storage)),
+		nil,
+		c.signers,
+	)
+	return c, nil
+}
+
+// Connect connects the consumer to the given service of the provider.
+func (c *Consumer) Connect(providerID identity.Identity, serviceType string) error {
+	proposal, err := c.network.Discovery.Proposal(market.ProposalID{
+		ProviderID:  providerID.Address,
+		ServiceType: serviceType,
+	})
+	if err != nil {
+		return err
+	}
+	return c.Connections.Connect(c.ID, c.network.Accountant.ID, *proposal, connection.ConnectParams{
+		DNS: connection.DNSOptionAuto,
+	})
+}
+
+// Disconnect closes the current connection.
+func (c *Consumer) Disconnect() error {
+	return c.Connections.Disconnect()
+}
+
+// Balance returns current consumer balance.
+func (c *Consumer) Balance() uint64 {
+	return c.Balances.GetBalance(c.ID)
+}
+
+func (c *Consumer) stop() {
+	if c.Connections != nil && c.Connections.Status().State != connection.NotConnected {
+		if err := c.Connections.Disconnect(); err != nil {
+			log.Warn().Err(err).Msg("Failed to disconnect consumer")
+		}
+	}
+	c.close()
+}
@@ -0,0 +1,88 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package testkit
+
+import (
+	"sync"
+
+	"github.com/mysteriumnetwork/node/core/discovery/brokerdiscovery"
+	"github.com/mysteriumnetwork/node/core/discovery/proposal"
+	"github.com/mysteriumnetwork/node/core/service"
+	"github.com/mysteriumnetwork/node/eventbus"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
+)
+
+// Discovery is an in-memory discovery shared by all nodes of the network.
+// Providers announce their proposals to it and consumers look them up.
+type Discovery struct {
+	storage *brokerdiscovery.ProposalStorage
+}
+
+// NewDiscovery creates an empty discovery.
+func NewDiscovery() *Discovery {
+	return &Discovery{
+		storage: brokerdiscovery.NewStorage(eventbus.New()),
+	}
+}
+
+// Proposal returns a single proposal by its ID.
+func (d *Discovery) Proposal(id market.ProposalID) (*market.ServiceProposal, error) {
+	return d.storage.GetProposal(id)
+}
+
+// Proposals returns proposals matching the filter.
+func (d *Discovery) Proposals(filter *proposal.Filter) ([]market.ServiceProposal, error) {
+	return d.storage.FindProposals(*filter)
+}
+
+// Factory returns discovery factory for the provider service manager.
+func (d *Discovery) Factory() service.DiscoveryFactory {
+	return func() service.Discovery {
+		return &announcement{storage: d.storage}
+	}
+}
+
+// announcement keeps a single service proposal in discovery while the service is running.
+type announcement struct {
+	storage *brokerdiscovery.ProposalStorage
+	id      market.ProposalID
+	wg      sync.WaitGroup
+	once    sync.Once
+}
+
+func (a *announcement) Start(_ identity.Identity, proposal market.ServiceProposal) {
+	a.id = proposal.UniqueID()
+	a.wg.Add(1)
+	a.storage.AddProposal(proposal)
+}
+
+func (a *announcement) UpdateProposal(proposal market.ServiceProposal) {
+	a.storage.AddProposal(proposal)
+}
+
+func (a *announcement) Stop() {
+	a.once.Do(func() {
+		a.storage.RemoveProposal(a.id)
+		a.wg.Done()
+	})
+}
+
+func (a *announcement) Wait() {
+	a.wg.Wait()
+}
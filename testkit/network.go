@@ -0,0 +1,131 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Package testkit runs provider and consumer nodes in a single process against
+// in-memory replacements of the broker, accountant, transactor, discovery and blockchain,
+// so that connections with real payment exchange can be tested with `go test`.
+package testkit
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/mysteriumnetwork/node/metadata"
+	"github.com/pkg/errors"
+)
+
+// Options configures the test network.
+type Options struct {
+	// Dir is a directory where nodes keep their data.
+	Dir string
+	// InvoicePeriod defines how often providers send invoices to consumers.
+	InvoicePeriod time.Duration
+	// TopUpAmount is an amount of tokens consumer channel receives on top-up.
+	TopUpAmount uint64
+}
+
+// DefaultOptions returns options suitable for most tests.
+func DefaultOptions(dir string) Options {
+	return Options{
+		Dir:           dir,
+		InvoicePeriod: time.Second,
+		TopUpAmount:   10000000000,
+	}
+}
+
+// Network is a set of fake Mysterium Network services shared by the nodes started on it.
+type Network struct {
+	Broker     *Broker
+	Accountant *Accountant
+	Transactor *Transactor
+	Discovery  *Discovery
+	Blockchain *Blockchain
+
+	RegistryAddress       string
+	ChannelImplementation string
+
+	options Options
+
+	mu    sync.Mutex
+	nodes int
+	stops []func()
+}
+
+// NewNetwork starts fake network services.
+func NewNetwork(options Options) (*Network, error) {
+	n := &Network{
+		RegistryAddress:       metadata.TestnetDefinition.RegistryAddress,
+		ChannelImplementation: metadata.TestnetDefinition.ChannelImplAddress,
+		Discovery:             NewDiscovery(),
+		Blockchain:            NewBlockchain(),
+		options:               options,
+	}
+
+	broker, err := NewBroker()
+	if err != nil {
+		return nil, err
+	}
+	n.Broker = broker
+	n.addStop(broker.Stop)
+
+	accountant, err := NewAccountant(filepath.Join(options.Dir, "accountant"), n.ChannelImplementation, n.RegistryAddress)
+	if err != nil {
+		n.Stop()
+		return nil, err
+	}
+	n.Accountant = accountant
+	n.addStop(accountant.Stop)
+
+	n.Transactor = NewTransactor(n.Blockchain, options.TopUpAmount)
+	n.addStop(n.Transactor.Stop)
+
+	return n, nil
+}
+
+// Stop stops all nodes and network services in reverse order of their start.
+func (n *Network) Stop() {
+	n.mu.Lock()
+	stops := n.stops
+	n.stops = nil
+	n.mu.Unlock()
+
+	for i := len(stops) - 1; i >= 0; i-- {
+		stops[i]()
+	}
+}
+
+func (n *Network) addStop(stop func()) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.stops = append(n.stops, stop)
+}
+
+func (n *Network) nodeDir(role string) (string, error) {
+	n.mu.Lock()
+	n.nodes++
+	dir := filepath.Join(n.options.Dir, fmt.Sprintf("%s-%d", role, n.nodes))
+	n.mu.Unlock()
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", errors.Wrap(err, "could not create node directory")
+	}
+	return dir, nil
+}
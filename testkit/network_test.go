@@ -0,0 +1,66 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package testkit
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/mysteriumnetwork/node/core/connection"
+	service_noop "github.com/mysteriumnetwork/node/services/noop"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNetwork_NoopConnectionIsPaid(t *testing.T) {
+	dir, err := ioutil.TempDir("", "testkit")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	options := DefaultOptions(dir)
+	network, err := NewNetwork(options)
+	require.NoError(t, err)
+	defer network.Stop()
+
+	provider, err := network.NewProvider()
+	require.NoError(t, err)
+	_, err = provider.StartNoopService(0, 6)
+	require.NoError(t, err)
+
+	consumer, err := network.NewConsumer()
+	require.NoError(t, err)
+	assert.Equal(t, options.TopUpAmount, consumer.Balance())
+
+	require.NoError(t, consumer.Connect(provider.ID, service_noop.ServiceType))
+	assert.Equal(t, connection.Connected, consumer.Connections.Status().State)
+
+	assert.Eventually(t, func() bool {
+		return network.Accountant.Promised(provider.ID) > 0
+	}, 10*time.Second, 100*time.Millisecond)
+	assert.Eventually(t, func() bool {
+		return consumer.Balance() < options.TopUpAmount
+	}, 5*time.Second, 100*time.Millisecond)
+
+	require.NoError(t, consumer.Disconnect())
+	assert.Eventually(t, func() bool {
+		agreements := network.Accountant.Agreements(provider.ID)
+		return len(agreements) == 1 && agreements[0].Revealed
+	}, 10*time.Second, 100*time.Millisecond)
+}
@@ -0,0 +1,121 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package testkit
+
+import (
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/mysteriumnetwork/node/communication/nats"
+	"github.com/mysteriumnetwork/node/core/storage/boltdb"
+	"github.com/mysteriumnetwork/node/eventbus"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/identity/registry"
+	"github.com/mysteriumnetwork/node/requests"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+// node holds components which are common to provider and consumer nodes.
+type node struct {
+	// ID is the identity of the node.
+	ID identity.Identity
+	// EventBus is the event bus of the node.
+	EventBus eventbus.EventBus
+
+	network    *Network
+	keystore   *identity.Keystore
+	identities identity.Manager
+	signers    identity.SignerFactory
+	storage    *boltdb.Bolt
+	httpClient *requests.HTTPClient
+	broker     nats.Connection
+	transactor *registry.Transactor
+}
+
+func newNode(network *Network, dir string) (*node, error) {
+	storage, err := boltdb.NewStorage(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	bus := eventbus.New()
+	ks := identity.NewKeystoreFilesystem(dir, keystore.NewKeyStore(dir, keystore.LightScryptN, keystore.LightScryptP), keystore.DecryptKey)
+	identities := identity.NewIdentityManager(ks, bus)
+	id, err := identities.CreateNewIdentity("")
+	if err != nil {
+		storage.Close()
+		return nil, errors.Wrap(err, "could not create node identity")
+	}
+
+	broker, err := nats.NewBrokerConnector().Connect(network.Broker.Address())
+	if err != nil {
+		storage.Close()
+		return nil, errors.Wrap(err, "could not connect to broker")
+	}
+
+	n := &node{
+		ID:         id,
+		EventBus:   bus,
+		network:    network,
+		keystore:   ks,
+		identities: identities,
+		storage:    storage,
+		httpClient: requests.NewHTTPClient("127.0.0.1", 10*time.Second),
+		broker:     broker,
+	}
+	n.signers = func(id identity.Identity) identity.Signer {
+		return identity.NewSigner(ks, id)
+	}
+	n.transactor = registry.NewTransactor(
+		n.httpClient,
+		network.Transactor.Address(),
+		network.RegistryAddress,
+		network.Accountant.ID.Address,
+		network.ChannelImplementation,
+		n.signers,
+		bus,
+	)
+	return n, nil
+}
+
+// register registers the node identity in the network. The key is unlocked
+// without announcing it, so that components are not notified before the node is set up.
+func (n *node) register() error {
+	if err := n.keystore.Unlock(accounts.Account{Address: n.ID.ToCommonAddress()}, ""); err != nil {
+		return errors.Wrap(err, "could not unlock node key")
+	}
+	if err := n.transactor.RegisterIdentity(n.ID.Address, &registry.IdentityRegistrationRequestDTO{}); err != nil {
+		return errors.Wrap(err, "could not register node identity")
+	}
+	return nil
+}
+
+// unlock unlocks the node identity and notifies node components about it.
+func (n *node) unlock() error {
+	return errors.Wrap(n.identities.Unlock(n.ID.Address, ""), "could not unlock node identity")
+}
+
+func (n *node) close() {
+	n.httpClient.Stop()
+	n.broker.Close()
+	if err := n.storage.Close(); err != nil {
+		log.Warn().Err(err).Msg("Failed to close node storage")
+	}
+}
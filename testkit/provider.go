@@ -0,0 +1,184 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package testkit
+
+import (
+	"fmt"
+
+	"github.com/mysteriumnetwork/node/communication"
+	nats_dialog "github.com/mysteriumnetwork/node/communication/nats/dialog"
+	"github.com/mysteriumnetwork/node/core/location"
+	"github.com/mysteriumnetwork/node/core/policy"
+	"github.com/mysteriumnetwork/node/core/service"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
+	nat_event "github.com/mysteriumnetwork/node/nat/event"
+	"github.com/mysteriumnetwork/node/nat/traversal"
+	"github.com/mysteriumnetwork/node/p2p"
+	service_noop "github.com/mysteriumnetwork/node/services/noop"
+	"github.com/mysteriumnetwork/node/session"
+	"github.com/mysteriumnetwork/node/session/connectivity"
+	"github.com/mysteriumnetwork/node/session/pingpong"
+	"github.com/rs/zerolog/log"
+)
+
+// ProviderLocation is the location announced in proposals of testkit providers.
+var ProviderLocation = location.Location{Continent: "EU", Country: "LT", City: "Vilnius"}
+
+// Provider is an in-process provider node serving noop services.
+type Provider struct {
+	*node
+
+	// Services manages services of the provider.
+	Services *service.Manager
+	// Sessions keeps sessions served by the provider.
+	Sessions *session.EventBasedStorage
+}
+
+// NewProvider starts a provider node on the network.
+func (n *Network) NewProvider() (*Provider, error) {
+	dir, err := n.nodeDir("provider")
+	if err != nil {
+		return nil, err
+	}
+	base, err := newNode(n, dir)
+	if err != nil {
+		return nil, err
+	}
+	p := &Provider{node: base}
+	n.addStop(p.stop)
+
+	if err := p.register(); err != nil {
+		return nil, err
+	}
+	if err := p.unlock(); err != nil {
+		return nil, err
+	}
+
+	p.Sessions = session.NewEventBasedStorage(p.EventBus, session.NewStorageMemory())
+	if err := p.Sessions.Subscribe(); err != nil {
+		return nil, err
+	}
+
+	registry := service.NewRegistry()
+	registry.Register(
+		service_noop.ServiceType,
+		func(_ service.Options) (service.Service, market.ServiceProposal, error) {
+			return service_noop.NewManager(), service_noop.GetProposal(ProviderLocation), nil
+		},
+	)
+
+	statusStorage := connectivity.NewStatusStorage()
+	p.Services = service.NewManager(
+		registry,
+		p.newDialogWaiter,
+		func(proposal market.ServiceProposal, configProvider session.ConfigProvider, serviceID string) (communication.DialogHandler, error) {
+			return session.NewDialogHandler(
+				p.newSessionManagerFactory(proposal, serviceID),
+				configProvider,
+				p.ID,
+				connectivity.NewStatusSubscriber(statusStorage),
+			), nil
+		},
+		n.Discovery.Factory(),
+		p.EventBus,
+		nil,
+		&dialogOnlyListener{},
+		nil,
+		statusStorage,
+	)
+	return p, nil
+}
+
+// StartNoopService starts noop service with given prices and announces it in discovery.
+func (p *Provider) StartNoopService(pricePerGB, pricePerMinute float64) (service.ID, error) {
+	return p.Services.Start(
+		p.ID,
+		service_noop.ServiceType,
+		nil,
+		nil,
+		pingpong.NewPaymentMethod(pricePerGB, pricePerMinute),
+	)
+}
+
+func (p *Provider) newDialogWaiter(providerID identity.Identity, serviceType string, policies *policy.Repository) (communication.DialogWaiter, error) {
+	return nats_dialog.NewDialogWaiter(
+		p.broker,
+		fmt.Sprintf("%v.%v", providerID.Address, serviceType),
+		p.signers(providerID),
+		policy.ValidateAllowedIdentity(policies),
+	), nil
+}
+
+func (p *Provider) newSessionManagerFactory(proposal market.ServiceProposal, serviceID string) session.ManagerFactory {
+	invoiceStorage := pingpong.NewProviderInvoiceStorage(pingpong.NewInvoiceStorage(p.storage))
+	promiseStorage := pingpong.NewAccountantPromiseStorage(p.storage)
+	settle := func(_, _ identity.Identity) error { return nil }
+
+	return func(dialog communication.Dialog) *session.Manager {
+		paymentEngineFactory := pingpong.InvoiceFactoryCreator(
+			dialog, nil, p.network.options.InvoicePeriod,
+			pingpong.PromiseWaitTimeout, invoiceStorage,
+			pingpong.NewAccountantCaller(p.httpClient, p.network.Accountant.Address()),
+			promiseStorage,
+			p.network.RegistryAddress,
+			p.network.ChannelImplementation,
+			pingpong.DefaultAccountantFailureCount,
+			1500,
+			p.network.Blockchain,
+			p.EventBus,
+			p.transactor,
+			proposal,
+			settle,
+			p.keystore,
+		)
+		return session.NewManager(
+			proposal,
+			p.Sessions,
+			paymentEngineFactory,
+			traversal.NewNoopPinger(),
+			nat_event.NewTracker(),
+			serviceID,
+			p.EventBus,
+			nil,
+			nil,
+			session.DefaultConfig(),
+		)
+	}
+}
+
+func (p *Provider) stop() {
+	if p.Services != nil {
+		if err := p.Services.Kill(); err != nil {
+			log.Warn().Err(err).Msg("Failed to stop provider services")
+		}
+	}
+	p.close()
+}
+
+// dialogOnlyListener makes providers announce only the broker contact,
+// so consumers talk to them over NATS dialogs.
+type dialogOnlyListener struct{}
+
+func (l *dialogOnlyListener) Listen(_ identity.Identity, _ string, _ func(ch p2p.Channel)) error {
+	return nil
+}
+
+func (l *dialogOnlyListener) GetContact() market.Contact {
+	return market.Contact{}
+}
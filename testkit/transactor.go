@@ -0,0 +1,120 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package testkit
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/julienschmidt/httprouter"
+	"github.com/mysteriumnetwork/node/identity/registry"
+)
+
+// Transactor is a fake transactor which registers identities and tops up
+// channels directly on the in-memory blockchain.
+type Transactor struct {
+	server      *httptest.Server
+	chain       *Blockchain
+	fee         uint64
+	topUpAmount uint64
+
+	mu          sync.Mutex
+	settlements []registry.PromiseSettlementRequest
+}
+
+// NewTransactor starts a fake transactor HTTP server.
+func NewTransactor(chain *Blockchain, topUpAmount uint64) *Transactor {
+	t := &Transactor{
+		chain:       chain,
+		topUpAmount: topUpAmount,
+	}
+
+	router := httprouter.New()
+	router.GET("/fee/register", t.fees)
+	router.GET("/fee/settle", t.fees)
+	router.POST("/identity/register", t.register)
+	router.POST("/identity/settle_and_rebalance", t.settle)
+	router.POST("/topup", t.topUp)
+	t.server = httptest.NewServer(router)
+
+	return t
+}
+
+// Address returns the base URL of the transactor API.
+func (t *Transactor) Address() string {
+	return t.server.URL
+}
+
+// Settlements returns settlement requests received by the transactor.
+func (t *Transactor) Settlements() []registry.PromiseSettlementRequest {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return append([]registry.PromiseSettlementRequest(nil), t.settlements...)
+}
+
+// Stop shuts the transactor down.
+func (t *Transactor) Stop() {
+	t.server.Close()
+}
+
+func (t *Transactor) fees(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	writeJSON(w, http.StatusOK, registry.FeesResponse{
+		Fee:        t.fee,
+		ValidUntil: time.Now().Add(time.Hour),
+	})
+}
+
+func (t *Transactor) register(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var reg registry.IdentityRegistrationRequest
+	if err := json.NewDecoder(req.Body).Decode(&reg); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"message": err.Error()})
+		return
+	}
+
+	t.chain.Register(common.HexToAddress(reg.Identity))
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (t *Transactor) topUp(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var topUp registry.TopUpRequest
+	if err := json.NewDecoder(req.Body).Decode(&topUp); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"message": err.Error()})
+		return
+	}
+
+	t.chain.TopUp(common.HexToAddress(topUp.Identity), t.topUpAmount)
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (t *Transactor) settle(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var settlement registry.PromiseSettlementRequest
+	if err := json.NewDecoder(req.Body).Decode(&settlement); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"message": err.Error()})
+		return
+	}
+
+	t.mu.Lock()
+	t.settlements = append(t.settlements, settlement)
+	t.mu.Unlock()
+	w.WriteHeader(http.StatusAccepted)
+}